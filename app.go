@@ -1,14 +1,22 @@
 package main
 
 import (
+	"Sid/internal/api"
+	"Sid/internal/clipboard"
 	"Sid/internal/config"
 	"Sid/internal/models"
 	"Sid/internal/repository"
 	"Sid/internal/service"
 	"Sid/internal/window"
 	"context"
+	"encoding/base64"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 	clipboardLib "golang.design/x/clipboard"
 )
 
@@ -19,6 +27,16 @@ type App struct {
 	clipboardService service.ClipboardService
 	chatService      service.ChatService
 	tagService       service.TagService
+	taggingService   service.TaggingService
+	retentionService service.RetentionService
+	backupService    service.BackupService
+	ruleService      service.RuleService
+	embeddings       service.EmbeddingService
+	encryption       service.EncryptionService
+	apiServer        api.Server
+	configManager    config.Manager
+	events           service.EventBus
+	stopEvents       func()
 	db               *repository.Database
 }
 
@@ -33,6 +51,12 @@ func NewApp() *App {
 		log.Fatal("无法连接数据库:", err)
 	}
 
+	// 密钥保存在系统密钥环中的加密数据库自动解锁，口令加密的数据库等待前端解锁
+	encryptionService := service.NewEncryptionService(db, configManager.Keyring(), configManager.Secrets())
+	if err := encryptionService.AutoUnlock(); err != nil {
+		log.Printf("⚠️  自动解锁数据库失败: %v", err)
+	}
+
 	// 加载配置
 	settings, err := configManager.Load()
 	if err != nil {
@@ -40,22 +64,66 @@ func NewApp() *App {
 	}
 
 	// 创建仓库层
-	clipboardRepo := repository.NewClipboardRepository(db.DB)
-	chatRepo := repository.NewChatRepository(db.DB)
+	clipboardRepo := repository.NewClipboardRepository(db.DB, db.Cipher())
+	chatRepo := repository.NewChatRepository(db.DB, db.Cipher())
 	tagRepo := repository.NewTagRepository(db.DB)
+	taggingRepo := repository.NewTaggingRepository(db.DB)
+	ruleRepo := repository.NewRuleRepository(db.DB)
+	embeddingRepo := repository.NewEmbeddingRepository(db.DB, db.Cipher())
 
 	// 创建服务层
-	chatService := service.NewChatService(chatRepo)
-	tagService := service.NewTagService(tagRepo, clipboardRepo)
-	clipboardService := service.NewClipboardService(clipboardRepo, settings, chatService, tagService)
+	chatModels := service.NewChatModelProvider(configManager)
+	chatService := service.NewChatService(chatRepo, chatModels)
+	chatService.UpdateSettings(settings.LLM)
+	chatService.SetClipboardRetriever(service.NewClipboardRetriever(clipboardRepo))
+	tagService := service.NewTagService(tagRepo, clipboardRepo, chatModels)
+	taggingService := service.NewTaggingService(taggingRepo, clipboardRepo, service.DefaultTaggingOptions())
+	clipboardService := service.NewClipboardService(clipboardRepo, settings, chatService, tagService, taggingService)
+	ruleService := service.NewRuleService(ruleRepo)
+	clipboardService.SetCaptureRules(ruleService)
+	embeddingService := service.NewEmbeddingService(embeddingRepo, clipboardRepo, service.NewEmbedderProvider(configManager), service.DefaultEmbeddingOptions())
+	embeddingService.UpdateSettings(settings.LLM.Embedding)
+	clipboardService.SetEmbeddings(embeddingService)
+	if tools, err := service.NewClipboardTools(clipboardService, tagService); err != nil {
+		log.Printf("⚠️  创建聊天工具失败: %v", err)
+	} else {
+		chatService.SetTools(tools)
+	}
+	retentionService := service.NewRetentionService(clipboardRepo, settings)
+	backupService := service.NewBackupService(db, clipboardService, settings)
 	windowManager := window.NewManager()
-	appService := service.NewAppService(configManager, windowManager, clipboardService, chatService)
+	appService := service.NewAppService(configManager, windowManager, clipboardService, chatService, retentionService, backupService, chatModels, embeddingService)
+
+	// 各服务的事件统一发布到事件总线，再由总线转发给前端和本地 API 的订阅者
+	events := service.NewEventBus()
+	chatService.SetEventEmitter(events.Publish)
+	tagService.SetEventEmitter(events.Publish)
+	taggingService.SetEventEmitter(events.Publish)
+	clipboardService.SetEventEmitter(events.Publish)
+	retentionService.SetEventEmitter(events.Publish)
+	backupService.SetEventEmitter(events.Publish)
+
+	// 本地 HTTP API，访问令牌首次使用时生成
+	apiToken, err := config.EnsureAPIToken(configManager.Secrets())
+	if err != nil {
+		log.Printf("⚠️  读取本地 API 令牌失败: %v", err)
+	}
+	apiServer := api.NewServer(api.Services{Clipboard: clipboardService, Tags: tagService, Chat: chatService, Events: events}, apiToken)
 
 	return &App{
 		appService:       appService,
 		clipboardService: clipboardService,
 		chatService:      chatService,
 		tagService:       tagService,
+		taggingService:   taggingService,
+		retentionService: retentionService,
+		backupService:    backupService,
+		ruleService:      ruleService,
+		embeddings:       embeddingService,
+		encryption:       encryptionService,
+		apiServer:        apiServer,
+		configManager:    configManager,
+		events:           events,
 		db:               db,
 	}
 }
@@ -77,6 +145,37 @@ func (a *App) startup(ctx context.Context) {
 		return
 	}
 
+	// 事件总线上的事件转发为 Wails 事件推送到前端
+	a.bridgeEvents(ctx)
+
+	// 上次退出时没有生成完的回复标记为中断
+	if _, err := a.chatService.RepairInterruptedMessages(); err != nil {
+		log.Printf("⚠️  修复未完成的聊天消息失败: %v", err)
+	}
+
+	// 启动后台打标签队列
+	if err := a.taggingService.Start(ctx); err != nil {
+		log.Printf("启动打标签队列失败: %v", err)
+	}
+
+	// 启动后台语义索引
+	if err := a.embeddings.Start(ctx); err != nil {
+		log.Printf("启动语义索引失败: %v", err)
+	}
+
+	// 启动定期清理
+	a.retentionService.Start(ctx)
+
+	// 启动定期备份
+	a.backupService.Start(ctx)
+
+	// 按配置启动本地 HTTP API
+	if settings, err := a.appService.GetSettings(); err == nil {
+		if err := a.apiServer.Apply(settings.API); err != nil {
+			log.Printf("❌ 启动本地 API 失败: %v", err)
+		}
+	}
+
 	log.Println("✅ 应用程序初始化完成")
 }
 
@@ -84,6 +183,17 @@ func (a *App) startup(ctx context.Context) {
 func (a *App) shutdown(ctx context.Context) {
 	// 关闭应用程序服务
 	a.appService.Shutdown()
+	a.clipboardService.CancelRetag()
+	a.taggingService.Stop()
+	a.embeddings.Stop()
+	a.retentionService.Stop()
+	a.backupService.Stop()
+	if err := a.apiServer.Stop(ctx); err != nil {
+		log.Printf("⚠️  停止本地 API 失败: %v", err)
+	}
+	if a.stopEvents != nil {
+		a.stopEvents()
+	}
 
 	// 关闭数据库连接
 	if a.db != nil {
@@ -93,6 +203,17 @@ func (a *App) shutdown(ctx context.Context) {
 	log.Println("✅ 应用程序已关闭")
 }
 
+// bridgeEvents 订阅事件总线并转发为 Wails 事件，事件名称与总线一致
+func (a *App) bridgeEvents(ctx context.Context) {
+	events, unsubscribe := a.events.Subscribe(256)
+	a.stopEvents = unsubscribe
+	go func() {
+		for event := range events {
+			runtime.EventsEmit(ctx, event.Name, event.Data)
+		}
+	}()
+}
+
 // === 剪切板管理 API ===
 
 // GetClipboardItems 获取剪切板条目列表
@@ -105,9 +226,20 @@ func (a *App) SearchClipboardItems(query models.SearchQuery) (models.SearchResul
 	return a.clipboardService.SearchItems(query)
 }
 
+// SemanticSearch 按语义相似度搜索剪切板条目
+func (a *App) SemanticSearch(query string, k int) (models.SearchResult, error) {
+	return a.clipboardService.SemanticSearch(query, k)
+}
+
+// GetEmbeddingStatus 获取语义索引状态
+func (a *App) GetEmbeddingStatus() (models.EmbeddingStatus, error) {
+	return a.embeddings.Status()
+}
+
 // CreateClipboardItem 创建剪切板条目
 func (a *App) CreateClipboardItem(content string) error {
-	return a.clipboardService.CreateItem(content)
+	_, err := a.clipboardService.CreateItem(content)
+	return err
 }
 
 // UpdateClipboardItem 更新剪切板条目
@@ -125,6 +257,60 @@ func (a *App) UseClipboardItem(id string) error {
 	return a.clipboardService.UseItem(id)
 }
 
+// PreviewClipboardItemTransform 预览文本条目转换后的内容
+func (a *App) PreviewClipboardItemTransform(id string, transform string) (string, error) {
+	return a.clipboardService.TransformItem(id, transform)
+}
+
+// UseClipboardItemTransformed 将文本条目转换后复制到剪切板，返回转换结果
+func (a *App) UseClipboardItemTransformed(id string, transform string) (string, error) {
+	return a.clipboardService.UseItemTransformed(id, transform)
+}
+
+// SaveClipboardItemTransformed 将文本条目转换后的内容保存为新条目
+func (a *App) SaveClipboardItemTransformed(id string, transform string) (*models.ClipboardItem, error) {
+	return a.clipboardService.SaveTransformedItem(id, transform)
+}
+
+// GetClipboardItemImage 获取图片条目的原图（data URL）
+func (a *App) GetClipboardItemImage(id string) (string, error) {
+	image, err := a.clipboardService.GetItemImage(id)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(image.Data), nil
+}
+
+// GetTaggingQueueStatus 获取后台打标签队列状态
+func (a *App) GetTaggingQueueStatus() (models.TaggingQueueStatus, error) {
+	return a.taggingService.Status()
+}
+
+// StartRetag 对符合条件的历史条目批量重新生成AI标签，进度通过 retag:progress 事件推送
+func (a *App) StartRetag(options models.RetagOptions) error {
+	return a.clipboardService.StartRetag(a.ctx, options)
+}
+
+// PauseRetag 暂停批量打标签任务
+func (a *App) PauseRetag() error {
+	return a.clipboardService.PauseRetag()
+}
+
+// ResumeRetag 恢复批量打标签任务
+func (a *App) ResumeRetag() error {
+	return a.clipboardService.ResumeRetag()
+}
+
+// CancelRetag 取消批量打标签任务
+func (a *App) CancelRetag() error {
+	return a.clipboardService.CancelRetag()
+}
+
+// GetRetagStatus 获取批量打标签任务的进度与结果汇总
+func (a *App) GetRetagStatus() models.RetagSummary {
+	return a.clipboardService.GetRetagStatus()
+}
+
 // GenerateTagsForClipboardItem 为剪切板条目生成AI标签
 func (a *App) GenerateTagsForClipboardItem(id string) ([]string, error) {
 	return a.clipboardService.GenerateTagsForItem(a.ctx, id)
@@ -182,7 +368,202 @@ func (a *App) GetSettings() (models.Settings, error) {
 
 // UpdateSettings 更新设置
 func (a *App) UpdateSettings(settings models.Settings) error {
-	return a.appService.UpdateSettings(&settings)
+	if err := a.appService.UpdateSettings(&settings); err != nil {
+		return err
+	}
+	// 本地 API 的开关或端口可能已变化
+	return a.apiServer.Apply(settings.API)
+}
+
+// GetAPIToken 获取本地 HTTP API 的访问令牌
+func (a *App) GetAPIToken() (string, error) {
+	return config.EnsureAPIToken(a.configManager.Secrets())
+}
+
+// RegenerateAPIToken 重新生成本地 HTTP API 的访问令牌，旧令牌立即失效
+func (a *App) RegenerateAPIToken() (string, error) {
+	token, err := config.RegenerateAPIToken(a.configManager.Secrets())
+	if err != nil {
+		return "", err
+	}
+	a.apiServer.SetToken(token)
+	return token, nil
+}
+
+// RunRetentionNow 立即按保留策略清理历史条目，返回本次移除的条目统计
+func (a *App) RunRetentionNow() (models.RetentionReport, error) {
+	return a.retentionService.RunNow()
+}
+
+// === 数据库加密 API ===
+
+// GetEncryptionStatus 获取数据库加密状态，Locked 为 true 时前端应提示输入口令解锁
+func (a *App) GetEncryptionStatus() (models.EncryptionStatus, error) {
+	return a.encryption.GetStatus()
+}
+
+// EnableEncryption 启用数据库加密并加密已有数据，keySource 为 passphrase 或 keyring
+func (a *App) EnableEncryption(keySource, passphrase string) error {
+	return a.encryption.Enable(keySource, passphrase)
+}
+
+// UnlockDatabase 使用口令解锁加密的数据库
+// 启动时数据库未解锁无法修复中断的回复，解锁后再执行一次
+func (a *App) UnlockDatabase(passphrase string) error {
+	if err := a.encryption.Unlock(passphrase); err != nil {
+		return err
+	}
+	if _, err := a.chatService.RepairInterruptedMessages(); err != nil {
+		log.Printf("⚠️  修复未完成的聊天消息失败: %v", err)
+	}
+	return nil
+}
+
+// DisableEncryption 解密全部数据并关闭数据库加密
+func (a *App) DisableEncryption() error {
+	return a.encryption.Disable()
+}
+
+// === 导入导出 API ===
+
+// exportExtensions 导出格式对应的文件扩展名
+var exportExtensions = map[string]string{
+	models.ExportFormatJSONL:    "jsonl",
+	models.ExportFormatCSV:      "csv",
+	models.ExportFormatMarkdown: "md",
+}
+
+// ChooseExportFile 打开保存对话框选择导出文件，用户取消时返回空字符串
+func (a *App) ChooseExportFile(format string) (string, error) {
+	ext, ok := exportExtensions[format]
+	if !ok {
+		return "", fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	return runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出剪切板历史",
+		DefaultFilename: fmt.Sprintf("sid-export-%s.%s", time.Now().Format("20060102"), ext),
+		Filters:         []runtime.FileFilter{{DisplayName: strings.ToUpper(ext), Pattern: "*." + ext}},
+	})
+}
+
+// ChooseImportFile 打开文件对话框选择要导入的 jsonl 文件，用户取消时返回空字符串
+func (a *App) ChooseImportFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "导入剪切板历史",
+		Filters: []runtime.FileFilter{{DisplayName: "JSON Lines", Pattern: "*.jsonl"}},
+	})
+}
+
+// ExportToFile 将符合条件的条目（jsonl 格式还包括标签和聊天会话）导出到文件
+func (a *App) ExportToFile(path string, options models.ExportOptions) (models.ExportReport, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return models.ExportReport{}, err
+	}
+	report, err := a.clipboardService.Export(file, options)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return report, err
+}
+
+// ImportFromFile 从 jsonl 文件导入数据
+func (a *App) ImportFromFile(path string, options models.ImportOptions) (models.ImportReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	defer file.Close()
+	return a.clipboardService.Import(file, options)
+}
+
+// === 备份 API ===
+
+// BackupNow 立即备份数据库
+func (a *App) BackupNow() (models.BackupInfo, error) {
+	return a.backupService.BackupNow()
+}
+
+// ListBackups 列出已有的备份，按时间从新到旧排列
+func (a *App) ListBackups() ([]models.BackupInfo, error) {
+	return a.backupService.ListBackups()
+}
+
+// RestoreBackup 从备份文件恢复数据库，恢复前会自动备份当前数据
+// 备份若是加密数据库，恢复后尝试自动解锁，口令加密的需要前端重新解锁
+func (a *App) RestoreBackup(path string) error {
+	if err := a.backupService.RestoreBackup(path); err != nil {
+		return err
+	}
+	if err := a.encryption.AutoUnlock(); err != nil {
+		log.Printf("⚠️  恢复后自动解锁数据库失败: %v", err)
+	}
+	if err := a.ruleService.Reload(); err != nil {
+		log.Printf("⚠️  恢复后加载捕获规则失败: %v", err)
+	}
+	return nil
+}
+
+// === 捕获规则 API ===
+
+// GetCaptureRules 获取全部捕获规则，按匹配顺序排列
+func (a *App) GetCaptureRules() ([]models.CaptureRule, error) {
+	return a.ruleService.ListRules()
+}
+
+// CreateCaptureRule 创建捕获规则，新规则排在最后
+func (a *App) CreateCaptureRule(rule models.CaptureRule) (*models.CaptureRule, error) {
+	return a.ruleService.CreateRule(rule)
+}
+
+// UpdateCaptureRule 更新捕获规则
+func (a *App) UpdateCaptureRule(rule models.CaptureRule) error {
+	return a.ruleService.UpdateRule(rule)
+}
+
+// DeleteCaptureRule 删除捕获规则
+func (a *App) DeleteCaptureRule(id string) error {
+	return a.ruleService.DeleteRule(id)
+}
+
+// ReorderCaptureRules 按给定的规则ID顺序调整匹配顺序
+func (a *App) ReorderCaptureRules(ids []string) error {
+	return a.ruleService.ReorderRules(ids)
+}
+
+// TestCaptureRules 用样例文本试运行捕获规则，返回命中的规则和将要保存的条目
+func (a *App) TestCaptureRules(request models.RuleTestRequest) (models.RuleTestResult, error) {
+	return a.clipboardService.TestCaptureRules(request)
+}
+
+// GetTransforms 获取可用的文本转换，用于规则的 transform 动作和粘贴时转换
+func (a *App) GetTransforms() []models.TransformInfo {
+	return clipboard.Transforms()
+}
+
+// GetLLMSettings 获取大模型配置
+func (a *App) GetLLMSettings() (models.LLMSettings, error) {
+	return a.appService.GetLLMSettings()
+}
+
+// UpdateLLMSettings 更新大模型配置（api_key 留空表示保持不变）
+func (a *App) UpdateLLMSettings(settings models.LLMSettings) error {
+	return a.appService.UpdateLLMSettings(settings)
+}
+
+// TestLLMConnection 测试大模型连接
+func (a *App) TestLLMConnection(settings models.LLMSettings) error {
+	return a.appService.TestLLMConnection(a.ctx, settings)
+}
+
+// GetLLMProviderStatus 获取各大模型服务的健康状态
+func (a *App) GetLLMProviderStatus() ([]models.LLMProviderStatus, error) {
+	return a.appService.GetLLMProviderStatus(a.ctx)
+}
+
+// ListLocalModels 获取本地大模型服务的模型列表（baseURL 为空时使用已保存的地址）
+func (a *App) ListLocalModels(baseURL string) ([]string, error) {
+	return a.appService.ListLocalModels(a.ctx, baseURL)
 }
 
 // === 窗口管理 API ===
@@ -244,6 +625,11 @@ func (a *App) SendChatMessage(sessionID, message string) (*models.ChatMessage, e
 	return a.chatService.SendMessage(a.ctx, sessionID, message)
 }
 
+// ConfirmChatToolCall 确认或拒绝聊天中需要确认的工具调用，confirmationID 为 tool_confirm 事件中的 confirmation_id
+func (a *App) ConfirmChatToolCall(confirmationID string, approved bool) error {
+	return a.chatService.ConfirmToolCall(confirmationID, approved)
+}
+
 // GetChatMessages 获取聊天消息列表
 func (a *App) GetChatMessages(sessionID string, limit, offset int) (*models.ChatMessageListResponse, error) {
 	return a.chatService.GetMessages(a.ctx, sessionID, limit, offset)
@@ -375,17 +761,8 @@ func (a *App) GetSimilarTags(tagName string, limit int) ([]models.Tag, error) {
 func (a *App) SendChatMessageStream(sessionID, message string) error {
 	log.Printf("🔄 开始流式聊天处理: sessionID=%s, message=%s", sessionID, message)
 	
-	// 导入 runtime 包需要在文件开头添加
-	// "github.com/wailsapp/wails/v2/pkg/runtime"
-	
-	// 使用 chatService 的流式方法
-	err := a.chatService.SendMessageStream(a.ctx, sessionID, message, func(response *models.StreamResponse) {
-		// 临时注释掉 runtime 调用，稍后修复
-		log.Printf("📤 发送事件: type=%s", response.Type)
-		
-		// TODO: 添加 runtime.EventsEmit 调用
-		// runtime.EventsEmit(a.ctx, "chat:stream:message", ...)
-	})
+	// 流式片段由 chatService 作为 chat:stream 事件发布，经事件总线推送到前端
+	err := a.chatService.SendMessageStream(a.ctx, sessionID, message, nil)
 	
 	if err != nil {
 		log.Printf("❌ 流式聊天处理失败: %v", err)
@@ -395,3 +772,18 @@ func (a *App) SendChatMessageStream(sessionID, message string) error {
 	log.Printf("✅ 流式聊天处理完成")
 	return nil
 }
+
+// CancelChatStream 停止生成回复，已生成的内容保存并标记为中断
+func (a *App) CancelChatStream(messageID string) error {
+	return a.chatService.CancelStream(messageID)
+}
+
+// RegenerateChatMessage 重新生成回复，流式片段通过 chat:stream 事件推送
+func (a *App) RegenerateChatMessage(messageID string) error {
+	return a.chatService.RegenerateMessage(a.ctx, messageID, nil)
+}
+
+// EditChatMessage 修改用户消息并重新生成之后的回复，流式片段通过 chat:stream 事件推送
+func (a *App) EditChatMessage(messageID, content string) error {
+	return a.chatService.EditUserMessage(a.ctx, messageID, content, nil)
+}
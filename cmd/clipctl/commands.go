@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	clipboardLib "golang.design/x/clipboard"
+
+	"Sid/internal/models"
+)
+
+// defaultLimit 列表和搜索默认返回的条目数
+const defaultLimit = 20
+
+// runList 列出最近的条目或回收站条目
+func runList(env *cliEnv, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	limit := flags.Int("limit", defaultLimit, "返回条目数")
+	offset := flags.Int("offset", 0, "跳过的条目数")
+	trash := flags.Bool("trash", false, "列出回收站中的条目")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return errUsage
+	}
+
+	var items []models.ClipboardItem
+	if *trash {
+		items, err = env.clipboard.GetTrashItems(*limit, *offset)
+	} else {
+		items, err = env.clipboard.GetItems(*limit, *offset)
+	}
+	if err != nil {
+		return err
+	}
+	return env.printItems(items)
+}
+
+// runSearch 按关键词、分类、标签和创建时间搜索条目，参数与 SearchQuery 一一对应
+func runSearch(env *cliEnv, args []string) error {
+	var tags stringList
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	category := flags.String("category", "", "分类")
+	flags.Var(&tags, "tag", "标签名称，可重复或用逗号分隔")
+	tagMode := flags.String("tag-mode", "all", "标签匹配方式：all、any 或 none")
+	untagged := flags.Bool("untagged", false, "仅返回没有任何标签的条目")
+	after := flags.String("after", "", "创建时间不早于（2006-01-02 或 RFC3339）")
+	before := flags.String("before", "", "创建时间早于（2006-01-02 或 RFC3339）")
+	app := flags.String("app", "", "来源应用")
+	language := flags.String("language", "", "内容语言，如 zh、en")
+	limit := flags.Int("limit", defaultLimit, "返回条目数")
+	offset := flags.Int("offset", 0, "跳过的条目数")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	switch *tagMode {
+	case "all", "any", "none":
+	default:
+		return fmt.Errorf("%w: 不支持的标签匹配方式 %s", errUsage, *tagMode)
+	}
+
+	query := models.SearchQuery{
+		Query:    strings.Join(positional, " "),
+		Category: *category,
+		Tags:     tags,
+		TagMode:  *tagMode,
+		Untagged: *untagged,
+		Limit:    *limit,
+		Offset:   *offset,
+
+		SourceApp: *app,
+		Language:  *language,
+	}
+	if query.CreatedAfter, err = parseTime(*after); err != nil {
+		return err
+	}
+	if query.CreatedBefore, err = parseTime(*before); err != nil {
+		return err
+	}
+
+	result, err := env.clipboard.SearchItems(query)
+	if err != nil {
+		return err
+	}
+	if env.format == formatJSON {
+		return env.printJSON(result)
+	}
+	if err := env.printItems(result.Items); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.errOut, "共 %d 条\n", result.Total)
+	return nil
+}
+
+// runGet 显示条目的完整内容
+func runGet(env *cliEnv, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	item, err := env.lookup(args[0])
+	if err != nil {
+		return err
+	}
+	return env.printItem(item)
+}
+
+// runCopy 将条目写入系统剪切板，并像 GUI 中点击条目一样更新使用次数；
+// GUI 开启了本地 API 时由 GUI 写入，否则由 clipctl 写入，Linux 下保持运行直到剪切板内容被替换
+func runCopy(env *cliEnv, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if _, err := env.lookup(args[0]); err != nil {
+		return err
+	}
+
+	// GUI 运行时交给 GUI 写入，剪切板内容由 GUI 进程持有，clipctl 可以立即退出
+	if env.gui != nil {
+		err := env.gui.useItem(args[0])
+		if err == nil {
+			fmt.Fprintf(env.errOut, "已复制 %s\n", args[0])
+			return nil
+		}
+		if !errors.Is(err, errGUIUnavailable) {
+			return err
+		}
+	}
+
+	if err := clipboardLib.Init(); err != nil {
+		return fmt.Errorf("无法访问系统剪切板: %w", err)
+	}
+	changed, err := env.clipboard.CopyItem(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.errOut, "已复制 %s\n", args[0])
+	if holdsSelection {
+		fmt.Fprintln(env.errOut, "保持运行以提供剪切板内容，复制其他内容后自动退出（Ctrl+C 结束）")
+		<-changed
+	}
+	return nil
+}
+
+// runAdd 从标准输入读取内容并保存为新条目，经过与剪切板监听相同的分类和敏感内容检测
+func runAdd(env *cliEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	data, err := io.ReadAll(env.in)
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if strings.TrimSpace(content) == "" {
+		return errors.New("标准输入为空")
+	}
+	item, err := env.clipboard.CreateItem(content)
+	if err != nil {
+		return err
+	}
+	if env.format == formatJSON {
+		return env.printJSON(item)
+	}
+	_, err = fmt.Fprintln(env.out, item.ID)
+	return err
+}
+
+// runTag 为条目添加标签，标签不存在时自动创建
+func runTag(env *cliEnv, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	if _, err := env.lookup(args[0]); err != nil {
+		return err
+	}
+	for _, name := range args[1:] {
+		if _, err := env.tags.ImportTag(models.Tag{Name: name}); err != nil {
+			return err
+		}
+	}
+	return env.tags.AddTagsToItem(args[0], args[1:])
+}
+
+// runUntag 移除条目的标签
+func runUntag(env *cliEnv, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	if _, err := env.lookup(args[0]); err != nil {
+		return err
+	}
+	return env.tags.RemoveTagsFromItem(args[0], args[1:])
+}
+
+// runTrash 将条目移到回收站
+func runTrash(env *cliEnv, args []string) error {
+	return env.eachItem(args, env.clipboard.DeleteItem)
+}
+
+// runRestore 从回收站恢复条目
+func runRestore(env *cliEnv, args []string) error {
+	return env.eachItem(args, env.clipboard.RestoreItem)
+}
+
+// eachItem 对每个条目执行操作，条目不存在时停止
+func (e *cliEnv) eachItem(ids []string, fn func(id string) error) error {
+	if len(ids) == 0 {
+		return errUsage
+	}
+	for _, id := range ids {
+		if _, err := e.lookup(id); err != nil {
+			return err
+		}
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup 获取条目，不存在时返回可读的错误
+func (e *cliEnv) lookup(id string) (*models.ClipboardItem, error) {
+	item, err := e.clipboard.GetItem(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("条目不存在: %s", id)
+	}
+	return item, err
+}
+
+// parseTime 解析日期（本地时间）或 RFC3339 时间，空字符串表示不限制
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无法解析时间 %s", errUsage, value)
+	}
+	return &t, nil
+}
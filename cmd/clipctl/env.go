@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"Sid/internal/config"
+	"Sid/internal/repository"
+	"Sid/internal/service"
+)
+
+// passphraseEnv 口令加密数据库的解锁口令
+const passphraseEnv = "CLIPCTL_PASSPHRASE"
+
+// cliEnv 子命令的运行环境
+type cliEnv struct {
+	db        *repository.Database
+	clipboard service.ClipboardService
+	tags      service.TagService
+	gui       *guiClient // 使用 GUI 的数据库且 GUI 开启了本地 API 时不为 nil
+
+	in     io.Reader
+	out    io.Writer
+	errOut io.Writer
+	format string
+}
+
+// openEnv 打开数据库并创建与 GUI 相同的服务层，不启动剪切板监听和后台任务
+// 已初始化的数据库可能正被 GUI 使用，只打开而不执行迁移或全文索引变更
+func openEnv(dbPath string) (*cliEnv, error) {
+	configManager := config.NewManager()
+	shared := dbPath == "" || dbPath == configManager.GetDatabasePath()
+	if dbPath == "" {
+		dbPath = configManager.GetDatabasePath()
+	}
+
+	db, err := repository.OpenExistingDatabase(dbPath)
+	if errors.Is(err, repository.ErrDatabaseNotInitialized) {
+		db, err = repository.NewDatabase(dbPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法打开数据库: %w", err)
+	}
+	if err := unlock(db, configManager); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	settings, err := configManager.Load()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("无法加载配置: %w", err)
+	}
+
+	clipboardRepo := repository.NewClipboardRepository(db.DB, db.Cipher())
+	chatRepo := repository.NewChatRepository(db.DB, db.Cipher())
+	tagRepo := repository.NewTagRepository(db.DB)
+	taggingRepo := repository.NewTaggingRepository(db.DB)
+
+	// 新条目写入打标签队列表，由运行中的 GUI 处理
+	chatModels := service.NewChatModelProvider(configManager)
+	chatService := service.NewChatService(chatRepo, chatModels)
+	tagService := service.NewTagService(tagRepo, clipboardRepo, chatModels)
+	taggingService := service.NewTaggingService(taggingRepo, clipboardRepo, service.DefaultTaggingOptions())
+	clipboardService := service.NewClipboardService(clipboardRepo, settings, chatService, tagService, taggingService)
+
+	env := &cliEnv{db: db, clipboard: clipboardService, tags: tagService}
+	if shared {
+		token, _ := configManager.Secrets().Get(config.SecretAPIToken)
+		env.gui = newGUIClient(settings.API, token)
+	}
+	return env, nil
+}
+
+// unlock 解锁加密的数据库：密钥在系统密钥环中时自动解锁，口令加密时读取环境变量
+func unlock(db *repository.Database, configManager config.Manager) error {
+	encryption := service.NewEncryptionService(db, configManager.Keyring(), configManager.Secrets())
+	if err := encryption.AutoUnlock(); err != nil {
+		return fmt.Errorf("自动解锁数据库失败: %w", err)
+	}
+
+	status, err := encryption.GetStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Locked {
+		return nil
+	}
+	passphrase := os.Getenv(passphraseEnv)
+	if passphrase == "" {
+		return fmt.Errorf("数据库已加密，请通过环境变量 %s 提供口令", passphraseEnv)
+	}
+	return encryption.Unlock(passphrase)
+}
+
+// Close 关闭数据库连接
+func (e *cliEnv) Close() error {
+	return e.db.Close()
+}
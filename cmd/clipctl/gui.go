@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"Sid/internal/api"
+	"Sid/internal/models"
+)
+
+// errGUIUnavailable GUI 未运行、未开启本地 API 或令牌已失效
+var errGUIUnavailable = errors.New("GUI 本地 API 不可用")
+
+// guiClient 通过运行中 GUI 的本地 API 执行需要由 GUI 进程完成的操作
+type guiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newGUIClient 根据 GUI 的本地 API 配置创建客户端，未开启 API 或没有令牌时返回 nil
+func newGUIClient(settings models.APISettings, token string) *guiClient {
+	if !settings.Enabled || token == "" {
+		return nil
+	}
+	return &guiClient{
+		baseURL: fmt.Sprintf("http://127.0.0.1:%d/api/v1", settings.Port),
+		token:   token,
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// useItem 请求 GUI 将条目复制到系统剪切板并记录使用
+func (c *guiClient) useItem(id string) error {
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/items/"+url.PathEscape(id)+"/use", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGUIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: 访问令牌无效", errGUIUnavailable)
+	}
+	var body struct {
+		Error *api.Error `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+		return fmt.Errorf("GUI 复制失败: %s", resp.Status)
+	}
+	return errors.New(body.Error.Message)
+}
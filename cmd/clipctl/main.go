@@ -0,0 +1,163 @@
+// clipctl 剪切板历史命令行工具
+//
+// 与 GUI 使用同一个数据库（WAL 模式），GUI 运行时也可以同时使用。
+// 需要使用 -tags sqlite_fts5 编译，已有数据库只打开而不执行迁移或全文索引变更。
+// 口令加密的数据库通过环境变量 CLIPCTL_PASSPHRASE 解锁。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 退出码
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage 参数错误，打印子命令用法
+var errUsage = errors.New("参数错误")
+
+// command 子命令
+type command struct {
+	usage   string
+	summary string
+	run     func(env *cliEnv, args []string) error
+}
+
+// commands 全部子命令
+var commands = map[string]command{
+	"list":    {"list [--limit N] [--offset N] [--trash]", "列出最近的条目", runList},
+	"search":  {"search [--category C] [--tag T]... [--tag-mode all|any|none] [--untagged] [--after DATE] [--before DATE] [--app APP] [--language LANG] [--limit N] [--offset N] [QUERY]", "搜索条目", runSearch},
+	"get":     {"get ID", "显示条目的完整内容", runGet},
+	"copy":    {"copy ID", "将条目复制到系统剪切板并记录使用", runCopy},
+	"add":     {"add < FILE", "从标准输入添加条目", runAdd},
+	"tag":     {"tag ID TAG...", "为条目添加标签", runTag},
+	"untag":   {"untag ID TAG...", "移除条目的标签", runUntag},
+	"trash":   {"trash ID...", "将条目移到回收站", runTrash},
+	"restore": {"restore ID...", "从回收站恢复条目", runRestore},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 解析全局参数并执行子命令，返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("clipctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dbPath := flags.String("db", "", "数据库路径（默认与 GUI 相同）")
+	format := flags.String("format", formatTable, "输出格式：table 或 json")
+	verbose := flags.Bool("v", false, "输出运行日志")
+	flags.Usage = func() { printUsage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		printUsage(stderr, flags)
+		return exitUsage
+	}
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(stderr, "clipctl: 不支持的输出格式: %s\n", *format)
+		return exitUsage
+	}
+	name := flags.Arg(0)
+	if name == "help" {
+		printUsage(stdout, flags)
+		return exitOK
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "clipctl: 未知命令: %s\n", name)
+		printUsage(stderr, flags)
+		return exitUsage
+	}
+
+	// 服务层的运行日志默认不输出，避免干扰命令结果
+	if !*verbose {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	env, err := openEnv(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "clipctl: %v\n", err)
+		return exitError
+	}
+	defer env.Close()
+	env.in, env.out, env.errOut, env.format = stdin, stdout, stderr, *format
+
+	if err := cmd.run(env, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			if err != errUsage {
+				fmt.Fprintf(stderr, "clipctl %s: %v\n", name, err)
+			}
+			fmt.Fprintf(stderr, "用法: clipctl %s\n", cmd.usage)
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "clipctl %s: %v\n", name, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// printUsage 打印总体用法
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "用法: clipctl [全局参数] <命令> [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "全局参数:")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
+
+// parseArgs 解析子命令参数，参数和位置参数可以交替出现
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// stringList 可重复出现的字符串参数，也支持逗号分隔
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*l = append(*l, part)
+		}
+	}
+	return nil
+}
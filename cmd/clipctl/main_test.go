@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Sid/internal/models"
+)
+
+// clipctl 在临时目录的数据库上执行命令，返回退出码和标准输出
+func clipctl(t *testing.T, dbPath, stdin string, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--db", dbPath}, args...), strings.NewReader(stdin), &stdout, &stderr)
+	if code != exitOK {
+		t.Logf("clipctl %v: %s", args, stderr.String())
+	}
+	return code, stdout.String()
+}
+
+// listJSON 以 JSON 格式列出条目
+func listJSON(t *testing.T, dbPath string, args ...string) []models.ClipboardItem {
+	t.Helper()
+	code, out := clipctl(t, dbPath, "", append([]string{"--format", "json", "list"}, args...)...)
+	if code != exitOK {
+		t.Fatalf("list failed with code %d", code)
+	}
+	var items []models.ClipboardItem
+	if err := json.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("invalid json %q: %v", out, err)
+	}
+	return items
+}
+
+func TestClipctl_Workflow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	if code, _ := clipctl(t, dbPath, "docker compose up -d\n", "add"); code != exitOK {
+		t.Fatalf("add failed with code %d", code)
+	}
+	items := listJSON(t, dbPath)
+	if len(items) != 1 || items[0].Content != "docker compose up -d" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+	id := items[0].ID
+
+	if code, _ := clipctl(t, dbPath, "", "tag", id, "运维", "docker"); code != exitOK {
+		t.Fatalf("tag failed with code %d", code)
+	}
+
+	// 参数可以出现在关键词之后
+	code, out := clipctl(t, dbPath, "", "--format", "json", "search", "compose", "--tag", "运维,docker", "--tag-mode", "all")
+	if code != exitOK {
+		t.Fatalf("search failed with code %d", code)
+	}
+	var result models.SearchResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || len(result.Items[0].Tags) != 2 {
+		t.Errorf("unexpected search result %+v", result)
+	}
+
+	if code, _ := clipctl(t, dbPath, "", "untag", id, "docker"); code != exitOK {
+		t.Fatalf("untag failed with code %d", code)
+	}
+	code, out = clipctl(t, dbPath, "", "get", id)
+	if code != exitOK || !strings.Contains(out, "运维") || strings.Contains(out, "docker,") || !strings.HasSuffix(out, "\ndocker compose up -d\n") {
+		t.Errorf("unexpected get output (code %d):\n%s", code, out)
+	}
+
+	if code, _ := clipctl(t, dbPath, "", "trash", id); code != exitOK {
+		t.Fatalf("trash failed with code %d", code)
+	}
+	if items := listJSON(t, dbPath); len(items) != 0 {
+		t.Errorf("expected trashed item to be hidden, got %d items", len(items))
+	}
+	if items := listJSON(t, dbPath, "--trash"); len(items) != 1 {
+		t.Errorf("expected trashed item in trash, got %d items", len(items))
+	}
+	if code, _ := clipctl(t, dbPath, "", "restore", id); code != exitOK {
+		t.Fatalf("restore failed with code %d", code)
+	}
+	if items := listJSON(t, dbPath); len(items) != 1 {
+		t.Errorf("expected restored item, got %d items", len(items))
+	}
+}
+
+func TestClipctl_Errors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		code  int
+	}{
+		{"no command", "", nil, exitUsage},
+		{"unknown command", "", []string{"frobnicate"}, exitUsage},
+		{"unknown format", "", []string{"--format", "xml", "list"}, exitUsage},
+		{"missing id", "", []string{"get"}, exitUsage},
+		{"unknown id", "", []string{"get", "missing"}, exitError},
+		{"bad tag mode", "", []string{"search", "--tag-mode", "some"}, exitUsage},
+		{"bad date", "", []string{"search", "--after", "yesterday"}, exitUsage},
+		{"empty stdin", "  \n", []string{"add"}, exitError},
+		{"trash unknown id", "", []string{"trash", "missing"}, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := clipctl(t, dbPath, tt.stdin, tt.args...); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestClipctl_CopyViaGUI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	if code, _ := clipctl(t, dbPath, "hello", "add"); code != exitOK {
+		t.Fatalf("add failed with code %d", code)
+	}
+	id := listJSON(t, dbPath)[0].ID
+
+	var used []string
+	gui := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		used = append(used, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer gui.Close()
+
+	env, err := openEnv(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	var stderr bytes.Buffer
+	env.errOut = &stderr
+
+	// X11 下剪切板内容由写入的进程持有，交给 GUI 写入后 clipctl 无需保持运行
+	env.gui = &guiClient{baseURL: gui.URL + "/api/v1", token: "token", http: gui.Client()}
+	if err := runCopy(env, []string{id}); err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 1 || used[0] != "POST /api/v1/items/"+id+"/use" {
+		t.Errorf("unexpected GUI requests %v", used)
+	}
+
+	env.gui.token = "stale"
+	if err := env.gui.useItem(id); !errors.Is(err, errGUIUnavailable) {
+		t.Errorf("expected errGUIUnavailable for invalid token, got %v", err)
+	}
+	gui.Close()
+	if err := env.gui.useItem(id); !errors.Is(err, errGUIUnavailable) {
+		t.Errorf("expected errGUIUnavailable when GUI is not running, got %v", err)
+	}
+
+	if newGUIClient(models.APISettings{Enabled: false, Port: 27182}, "token") != nil {
+		t.Error("expected no GUI client when the API is disabled")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"Sid/internal/models"
+)
+
+// 输出格式
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+const (
+	// previewLength 表格中内容预览的最大字符数
+	previewLength = 60
+	// timeLayout 表格中的时间格式
+	timeLayout = "2006-01-02 15:04"
+)
+
+// printJSON 输出缩进的 JSON
+func (e *cliEnv) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(e.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printItems 输出条目列表
+func (e *cliEnv) printItems(items []models.ClipboardItem) error {
+	if e.format == formatJSON {
+		return e.printJSON(items)
+	}
+
+	w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t分类\t标签\t使用\t最后使用\t内容")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			item.ID, item.Category, strings.Join(item.GetTagNames(), ","),
+			item.UseCount, item.LastUsedAt.Local().Format(timeLayout), preview(item.Content))
+	}
+	return w.Flush()
+}
+
+// printItem 输出单个条目的元数据和完整内容
+func (e *cliEnv) printItem(item *models.ClipboardItem) error {
+	if e.format == formatJSON {
+		return e.printJSON(item)
+	}
+
+	w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", item.ID)
+	fmt.Fprintf(w, "标题:\t%s\n", item.Title)
+	fmt.Fprintf(w, "分类:\t%s\n", item.Category)
+	if item.CodeLanguage != "" {
+		fmt.Fprintf(w, "类型:\t%s（%s）\n", item.ContentType, item.CodeLanguage)
+	} else {
+		fmt.Fprintf(w, "类型:\t%s\n", item.ContentType)
+	}
+	if item.SourceApp != "" {
+		fmt.Fprintf(w, "来源:\t%s（%s）\n", item.SourceApp, item.SourceTitle)
+	}
+	fmt.Fprintf(w, "大小:\t%d 字节，%d 行\n", item.ContentLength, item.LineCount)
+	fmt.Fprintf(w, "标签:\t%s\n", strings.Join(item.GetTagNames(), ", "))
+	fmt.Fprintf(w, "收藏:\t%t\n", item.IsFavorite)
+	fmt.Fprintf(w, "使用次数:\t%d\n", item.UseCount)
+	fmt.Fprintf(w, "创建时间:\t%s\n", item.CreatedAt.Local().Format(timeLayout))
+	fmt.Fprintf(w, "最后使用:\t%s\n", item.LastUsedAt.Local().Format(timeLayout))
+	if item.IsDeleted {
+		fmt.Fprintf(w, "状态:\t回收站\n")
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(e.out, "\n%s\n", item.Content)
+	return err
+}
+
+// preview 将内容压缩为单行预览
+func preview(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(content) <= previewLength {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:previewLength]) + "…"
+}
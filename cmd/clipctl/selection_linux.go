@@ -0,0 +1,6 @@
+//go:build linux
+
+package main
+
+// holdsSelection X11 下剪切板内容由写入的进程提供，进程退出后内容随之消失
+const holdsSelection = true
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package main
+
+// holdsSelection 其他平台由系统保存剪切板内容，写入后即可退出
+const holdsSelection = false
@@ -157,6 +157,24 @@ const SearchBar = ({ onSearch, categories, contentTypes, onAddItem, searchResult
 
             {/* 小选择框筛选行 */}
             <div className="mt-3 flex items-center gap-2 flex-wrap">
+                {/* 搜索模式：语义和混合搜索需要在设置中启用语义搜索，未启用时按关键词搜索 */}
+                <div className="flex items-center gap-1">
+                    <span className="text-xs text-gray-500 whitespace-nowrap">模式:</span>
+                    <Select 
+                        value={currentSearchForm.mode || 'keyword'} 
+                        onValueChange={(value) => handleFilterChange('mode', value)}
+                    >
+                        <SelectTrigger className="h-7 w-20 text-xs border rounded-md">
+                            <SelectValue placeholder="关键词" />
+                        </SelectTrigger>
+                        <SelectContent>
+                            <SelectItem value="keyword">关键词</SelectItem>
+                            <SelectItem value="semantic">语义</SelectItem>
+                            <SelectItem value="hybrid">混合</SelectItem>
+                        </SelectContent>
+                    </Select>
+                </div>
+
                 {/* 分类选择 */}
                 <div className="flex items-center gap-1">
                     <span className="text-xs text-gray-500 whitespace-nowrap">分类:</span>
@@ -1,24 +1,38 @@
 import {
     AlertTriangle,
     Bot,
+    Check,
     ChevronDown,
     Copy,
     Edit3,
+    Loader2,
     MessageCircle,
+    Paperclip,
     Plus,
+    RefreshCw,
     Send,
+    Square,
     Trash2,
-    User
+    User,
+    Wrench,
+    X
 } from 'lucide-react';
 import React, { useEffect, useRef, useState } from 'react';
 import {
+    CancelChatStream,
+    ConfirmChatToolCall,
     CreateChatSession,
     DeleteChatSession,
+    EditChatMessage,
     GetChatMessages,
     GetChatSessions,
+    RegenerateChatMessage,
     SendChatMessage,
-    UpdateChatSession
+    SendChatMessageStream,
+    UpdateChatSession,
+    UseClipboardItem
 } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
 import { StreamingMarkdown } from '../components';
 import { AlertDialog, AlertDialogAction, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from '../components/ui/alert-dialog';
 import { Button } from '../components/ui/button';
@@ -52,6 +66,92 @@ const MessageContent = ({ content, isUser = false, isStreaming = false }) => {
     );
 };
 
+// 回答参考的剪切板条目，回答中实际引用的条目高亮显示，点击复制到剪切板
+const MessageReferences = ({ metadata, onUse }) => {
+    const references = metadata?.references || [];
+    if (references.length === 0) {
+        return null;
+    }
+    const cited = new Set(metadata.cited_item_ids || []);
+
+    return (
+        <div className="flex flex-wrap items-center gap-1.5 mt-2 pt-2 border-t border-border/60">
+            <Paperclip className="w-3 h-3 text-muted-foreground" />
+            {references.map(ref => (
+                <button
+                    key={ref.item_id}
+                    type="button"
+                    title={`复制条目：${ref.title}`}
+                    onClick={() => onUse(ref.item_id)}
+                    className={`max-w-[12rem] truncate rounded px-1.5 py-0.5 text-xs border transition-colors ${
+                        cited.has(ref.item_id)
+                            ? 'bg-primary/10 border-primary/30 text-primary hover:bg-primary/20'
+                            : 'bg-background border-border text-muted-foreground hover:bg-muted'
+                    }`}
+                >
+                    [{ref.index}] {ref.title}
+                </button>
+            ))}
+        </div>
+    );
+};
+
+// 工具名称对应的中文说明
+const TOOL_LABELS = {
+    search_clipboard_items: '搜索剪切板',
+    get_clipboard_item: '查看条目',
+    tag_clipboard_item: '添加标签',
+    favorite_clipboard_item: '收藏条目',
+    create_clipboard_item: '新建条目',
+    copy_clipboard_item: '复制到剪切板',
+    delete_clipboard_item: '删除条目'
+};
+
+// 助手调用的工具及其状态，生成中显示实时进度，完成后来自 metadata.tool_calls
+const MessageToolCalls = ({ toolCalls }) => {
+    if (!toolCalls || toolCalls.length === 0) {
+        return null;
+    }
+
+    const statusIcon = (status) => {
+        switch (status) {
+            case 'success':
+                return <Check className="w-3 h-3 text-green-500" />;
+            case 'error':
+                return <AlertTriangle className="w-3 h-3 text-destructive" />;
+            case 'rejected':
+                return <X className="w-3 h-3 text-muted-foreground" />;
+            default:
+                return <Loader2 className="w-3 h-3 animate-spin text-primary" />;
+        }
+    };
+
+    return (
+        <div className="space-y-1 mb-2 pb-2 border-b border-border/60">
+            {toolCalls.map(call => (
+                <div
+                    key={call.id}
+                    title={call.error || call.arguments}
+                    className="flex items-center gap-1.5 text-xs text-muted-foreground"
+                >
+                    <Wrench className="w-3 h-3" />
+                    <span>{TOOL_LABELS[call.name] || call.name}</span>
+                    {statusIcon(call.status)}
+                    {call.status === 'rejected' && <span>已拒绝</span>}
+                </div>
+            ))}
+        </div>
+    );
+};
+
+// 合并工具调用状态：同一调用用最新状态替换，新调用追加到末尾
+const mergeToolCall = (toolCalls = [], call) => {
+    if (toolCalls.some(item => item.id === call.id)) {
+        return toolCalls.map(item => (item.id === call.id ? call : item));
+    }
+    return [...toolCalls, call];
+};
+
 // 历史会话弹出组件
 const SessionDropdown = ({ sessions, currentSession, onSelectSession, onCreateSession, onEditSession, onDeleteSession, isOpen, onClose }) => {
     const [editingSession, setEditingSession] = useState(null);
@@ -215,6 +315,14 @@ const ChatPage = () => {
         sessionId: null,
         sessionTitle: ''
     });
+    const [toolConfirm, setToolConfirm] = useState(null);
+    // 正在生成的助手消息 ID（Wails 环境中用于停止生成）和正在编辑的用户消息
+    const [streamingMessageId, setStreamingMessageId] = useState(null);
+    const [editingMessage, setEditingMessage] = useState(null);
+    // 浏览器环境中停止生成时中断 SSE 请求
+    const abortControllerRef = useRef(null);
+    // 确认按钮和对话框关闭会先后触发，用 ref 保证每个工具调用只回复一次
+    const toolConfirmRef = useRef(null);
     const messagesEndRef = useRef(null);
 
     // 监听messages变化
@@ -453,7 +561,7 @@ const ChatPage = () => {
                 console.log('Fallback API响应:', response);
                 setMessages(prev => prev.map(msg => 
                     msg.id === aiMsg.id 
-                        ? { ...msg, content: response.content, isStreaming: false }
+                        ? { ...msg, content: response.content, metadata: response.metadata, isStreaming: false }
                         : msg
                 ));
                 console.log('Fallback API完成，设置isStreaming=false');
@@ -493,7 +601,9 @@ const ChatPage = () => {
             // 尝试使用流式API
             const processStream = async () => {
                 console.log('尝试使用流式API');
+                abortControllerRef.current = new AbortController();
                 const response = await fetch('/api/chat/stream', {
+                    signal: abortControllerRef.current.signal,
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json',
@@ -590,6 +700,39 @@ const ChatPage = () => {
                         setIsStreaming(false);
                         setIsLoading(false);
                         break;
+                    case 'cancelled':
+                        try {
+                            const cancelled = JSON.parse(data);
+                            setMessages(prev => prev.map(msg =>
+                                msg.id === aiMsg.id
+                                    ? { ...msg, content: cancelled.content, metadata: cancelled.metadata, isStreaming: false }
+                                    : msg
+                            ));
+                        } catch (e) {
+                            console.error('Error parsing cancelled event:', e);
+                        }
+                        setIsStreaming(false);
+                        setIsLoading(false);
+                        break;
+                    case 'metadata':
+                        try {
+                            const metadata = JSON.parse(data);
+                            setMessages(prev => prev.map(msg =>
+                                msg.id === aiMsg.id ? { ...msg, metadata } : msg
+                            ));
+                        } catch (e) {
+                            console.error('Error parsing metadata:', e);
+                        }
+                        break;
+                    case 'tool_call':
+                    case 'tool_confirm':
+                    case 'tool_result':
+                        try {
+                            handleToolEvent(eventType, JSON.parse(data));
+                        } catch (e) {
+                            console.error('Error parsing tool event:', e);
+                        }
+                        break;
                     case 'done':
                         // 流式输出完成后，切换到ReactMarkdown渲染
                         console.log('流式输出完成，设置isStreaming=false');
@@ -607,17 +750,103 @@ const ChatPage = () => {
             // 检查是否在 Wails 环境中
             const isWails = window.wails && window.wails.go;
             if (isWails) {
-                console.log("在 Wails 环境中，直接使用普通API");
-                await fallbackToNormalAPI();
+                // 回复片段通过 chat:stream 事件推送
+                console.log("在 Wails 环境中，使用事件推送的流式API");
+                await runWailsStream(sessionToUse.id, () => SendChatMessageStream(sessionToUse.id, userMessage));
             } else {
                 await processStream();
             }
         } catch (error) {
+            if (error.name === 'AbortError') {
+                // 停止生成：后端保存已生成的部分并标记为中断
+                console.log('已停止生成');
+                setMessages(prev => prev.map(msg =>
+                    msg.id === aiMsg.id
+                        ? { ...msg, isStreaming: false, metadata: { ...msg.metadata, interrupted: true } }
+                        : msg
+                ));
+                setIsStreaming(false);
+                setIsLoading(false);
+                return;
+            }
             console.error('Streaming failed, falling back to normal API:', error);
             await fallbackToNormalAPI();
+        } finally {
+            abortControllerRef.current = null;
+        }
+    };
+
+    // Wails 环境中运行一次流式生成，片段由 chat:stream 事件更新到界面，完成后从后端重新加载消息
+    const runWailsStream = async (sessionId, run) => {
+        try {
+            await run();
+        } catch (error) {
+            console.error('Failed to stream message:', error);
+            setMessages(prev => prev.map(msg =>
+                msg.role === 'assistant' && msg.isStreaming
+                    ? { ...msg, content: msg.content || `抱歉，AI服务出现了问题：${error}`, isStreaming: false }
+                    : msg
+            ));
+            toast.error('生成回复失败');
+        } finally {
+            setIsLoading(false);
+            setIsStreaming(false);
+            setStreamingMessageId(null);
+            await loadMessages(sessionId);
         }
     };
 
+    // 停止生成
+    const stopGenerating = async () => {
+        if (abortControllerRef.current) {
+            abortControllerRef.current.abort();
+            return;
+        }
+        if (!streamingMessageId) {
+            return;
+        }
+        try {
+            await CancelChatStream(streamingMessageId);
+        } catch (error) {
+            console.error('Failed to cancel stream:', error);
+        }
+    };
+
+    // 从 index 处的用户消息开始重新生成：本地截断消息并添加占位符，后端截断后重新发送
+    const replayFrom = async (index, content, run) => {
+        if (isLoading || !currentSession) return;
+        const now = new Date().toISOString();
+        setMessages(prev => [
+            ...prev.slice(0, index),
+            { id: Date.now(), content, role: 'user', created_at: now },
+            { id: Date.now() + 1, content: '', role: 'assistant', created_at: now, isStreaming: true }
+        ]);
+        setIsLoading(true);
+        setIsStreaming(true);
+        await runWailsStream(currentSession.id, run);
+    };
+
+    // 重新生成助手回复
+    const regenerateMessage = (message) => {
+        const index = messages.findIndex(msg => msg.id === message.id);
+        let userIndex = index - 1;
+        while (userIndex >= 0 && messages[userIndex].role !== 'user') {
+            userIndex--;
+        }
+        if (userIndex < 0) return;
+        replayFrom(userIndex, messages[userIndex].content, () => RegenerateChatMessage(message.id));
+    };
+
+    // 保存编辑后的用户消息并重新生成之后的回复
+    const submitEditMessage = () => {
+        const { id, content } = editingMessage;
+        setEditingMessage(null);
+        if (!content.trim()) return;
+        const index = messages.findIndex(msg => msg.id === id);
+        if (index < 0) return;
+        replayFrom(index, content.trim(), () => EditChatMessage(id, content.trim()));
+    };
+
     // 滚动到底部
     const scrollToBottom = () => {
         messagesEndRef.current?.scrollIntoView({ behavior: 'smooth' });
@@ -648,12 +877,52 @@ const ChatPage = () => {
         }
     };
 
+    // 工具调用事件：更新正在生成的助手消息，需要确认的工具弹出确认对话框
+    const handleToolEvent = (type, call) => {
+        setMessages(prev => prev.map(msg =>
+            msg.role === 'assistant' && msg.isStreaming
+                ? { ...msg, toolCalls: mergeToolCall(msg.toolCalls, call) }
+                : msg
+        ));
+        if (type === 'tool_confirm') {
+            toolConfirmRef.current = call;
+            setToolConfirm(call);
+        }
+    };
+
+    // 回复工具调用确认，超时或已处理时后端返回错误
+    const answerToolConfirm = async (approved) => {
+        const call = toolConfirmRef.current;
+        toolConfirmRef.current = null;
+        setToolConfirm(null);
+        if (!call) {
+            return;
+        }
+        try {
+            await ConfirmChatToolCall(call.id, approved);
+        } catch (error) {
+            console.error('Failed to confirm tool call:', error);
+            toast.error('操作已超时或已被处理');
+        }
+    };
+
     // 复制消息
     const copyMessage = (content) => {
         navigator.clipboard.writeText(content);
         toast.success('已复制');
     };
 
+    // 复制回答参考的剪切板条目
+    const handleUseReference = async (itemId) => {
+        try {
+            await UseClipboardItem(itemId);
+            toast.success('已复制剪切板条目');
+        } catch (error) {
+            console.error('Failed to use clipboard item:', error);
+            toast.error('条目不存在或已被删除');
+        }
+    };
+
     // 初始化
     useEffect(() => {
         loadSessions();
@@ -666,6 +935,39 @@ const ChatPage = () => {
         }
     }, [currentSession]);
 
+    // Wails 环境中回复片段和工具调用进度通过 chat:stream 事件推送，更新正在生成的助手消息
+    useEffect(() => {
+        if (!(window.wails && window.wails.go) || !currentSession) {
+            return;
+        }
+        const updateStreaming = (update) => setMessages(prev => prev.map(msg =>
+            msg.role === 'assistant' && msg.isStreaming ? { ...msg, ...update(msg) } : msg
+        ));
+        return EventsOn('chat:stream', (event) => {
+            if (event.session_id !== currentSession.id) {
+                return;
+            }
+            switch (event.type) {
+                case 'started':
+                    setStreamingMessageId(event.message_id);
+                    break;
+                case 'message':
+                    updateStreaming(msg => ({ content: msg.content + event.data.content }));
+                    break;
+                case 'complete':
+                case 'cancelled':
+                    updateStreaming(() => ({ content: event.data.content, metadata: event.data.metadata, isStreaming: false }));
+                    setStreamingMessageId(null);
+                    break;
+                case 'tool_call':
+                case 'tool_confirm':
+                case 'tool_result':
+                    handleToolEvent(event.type, event.data);
+                    break;
+            }
+        });
+    }, [currentSession]);
+
     // 监听消息变化，自动滚动
     useEffect(() => {
         scrollToBottom();
@@ -760,11 +1062,43 @@ const ChatPage = () => {
                                                         ? 'bg-primary text-primary-foreground border-primary/20'
                                                         : 'bg-muted/50 border-border'
                                                 }`}>
-                                                    <MessageContent 
-                                                        content={message.content} 
-                                                        isUser={message.role === 'user'} 
-                                                        isStreaming={message.isStreaming} 
-                                                    />
+                                                    {message.role === 'assistant' && (
+                                                        <MessageToolCalls toolCalls={message.metadata?.tool_calls || message.toolCalls} />
+                                                    )}
+                                                    {editingMessage?.id === message.id ? (
+                                                        <div className="flex flex-col gap-2 min-w-[240px]">
+                                                            <Input
+                                                                value={editingMessage.content}
+                                                                onChange={(e) => setEditingMessage({ ...editingMessage, content: e.target.value })}
+                                                                onKeyDown={(e) => {
+                                                                    if (e.key === 'Enter') submitEditMessage();
+                                                                    if (e.key === 'Escape') setEditingMessage(null);
+                                                                }}
+                                                                className="h-8 text-sm text-foreground"
+                                                                autoFocus
+                                                            />
+                                                            <div className="flex justify-end gap-1">
+                                                                <Button size="sm" variant="secondary" className="h-6 px-2 text-xs" onClick={() => setEditingMessage(null)}>
+                                                                    取消
+                                                                </Button>
+                                                                <Button size="sm" variant="secondary" className="h-6 px-2 text-xs" onClick={submitEditMessage}>
+                                                                    发送
+                                                                </Button>
+                                                            </div>
+                                                        </div>
+                                                    ) : (
+                                                        <MessageContent 
+                                                            content={message.content} 
+                                                            isUser={message.role === 'user'} 
+                                                            isStreaming={message.isStreaming} 
+                                                        />
+                                                    )}
+                                                    {message.role === 'assistant' && message.metadata?.interrupted && (
+                                                        <div className="mt-2 text-xs text-muted-foreground">已停止生成</div>
+                                                    )}
+                                                    {message.role === 'assistant' && !message.isStreaming && (
+                                                        <MessageReferences metadata={message.metadata} onUse={handleUseReference} />
+                                                    )}
                                                 </div>
                                                 
                                                 <div className={`flex items-center gap-2 mt-1.5 text-xs text-muted-foreground ${
@@ -781,6 +1115,30 @@ const ChatPage = () => {
                                                     >
                                                         <Copy className="w-3 h-3" />
                                                     </Button>
+                                                    {/* 重新生成和编辑只对已保存的消息可用（Wails 环境） */}
+                                                    {window.wails && typeof message.id === 'string' && !isLoading && (
+                                                        message.role === 'assistant' ? (
+                                                            <Button
+                                                                variant="ghost"
+                                                                size="sm"
+                                                                className="h-5 w-5 p-0 opacity-0 group-hover:opacity-100 transition-opacity"
+                                                                title="重新生成"
+                                                                onClick={() => regenerateMessage(message)}
+                                                            >
+                                                                <RefreshCw className="w-3 h-3" />
+                                                            </Button>
+                                                        ) : (
+                                                            <Button
+                                                                variant="ghost"
+                                                                size="sm"
+                                                                className="h-5 w-5 p-0 opacity-0 group-hover:opacity-100 transition-opacity"
+                                                                title="编辑并重新发送"
+                                                                onClick={() => setEditingMessage({ id: message.id, content: message.content })}
+                                                            >
+                                                                <Edit3 className="w-3 h-3" />
+                                                            </Button>
+                                                        )
+                                                    )}
                                                 </div>
                                             </div>
 
@@ -827,14 +1185,26 @@ const ChatPage = () => {
                                 disabled={isLoading}
                                 className="pr-12 text-sm h-10"
                             />
-                            <Button
-                                onClick={sendMessage}
-                                disabled={!inputMessage.trim() || isLoading}
-                                size="sm"
-                                className="absolute right-1.5 top-1/2 -translate-y-1/2 h-7 w-7 p-0"
-                            >
-                                <Send className="w-3.5 h-3.5" />
-                            </Button>
+                            {isStreaming && (streamingMessageId || abortControllerRef.current) ? (
+                                <Button
+                                    onClick={stopGenerating}
+                                    size="sm"
+                                    variant="destructive"
+                                    title="停止生成"
+                                    className="absolute right-1.5 top-1/2 -translate-y-1/2 h-7 w-7 p-0"
+                                >
+                                    <Square className="w-3.5 h-3.5" />
+                                </Button>
+                            ) : (
+                                <Button
+                                    onClick={sendMessage}
+                                    disabled={!inputMessage.trim() || isLoading}
+                                    size="sm"
+                                    className="absolute right-1.5 top-1/2 -translate-y-1/2 h-7 w-7 p-0"
+                                >
+                                    <Send className="w-3.5 h-3.5" />
+                                </Button>
+                            )}
                         </div>
                     </div>
                     
@@ -875,6 +1245,32 @@ const ChatPage = () => {
                     </AlertDialogFooter>
                 </AlertDialogContent>
             </AlertDialog>
+
+            {/* 工具调用确认对话框 */}
+            <AlertDialog open={!!toolConfirm} onOpenChange={(open) => !open && answerToolConfirm(false)}>
+                <AlertDialogContent>
+                    <AlertDialogHeader>
+                        <AlertDialogTitle className="flex items-center gap-2">
+                            <AlertTriangle className="w-5 h-5 text-destructive" />
+                            确认执行操作
+                        </AlertDialogTitle>
+                        <AlertDialogDescription>
+                            AI 请求执行 "<span className="font-medium text-foreground">{TOOL_LABELS[toolConfirm?.name] || toolConfirm?.name}</span>"，是否允许？
+                            <br />
+                            <code className="block mt-2 p-2 rounded bg-muted text-xs break-all">{toolConfirm?.arguments}</code>
+                        </AlertDialogDescription>
+                    </AlertDialogHeader>
+                    <AlertDialogFooter>
+                        <AlertDialogCancel onClick={() => answerToolConfirm(false)}>
+                            拒绝
+                        </AlertDialogCancel>
+                        <AlertDialogAction onClick={() => answerToolConfirm(true)}>
+                            <Check className="w-4 h-4 mr-2" />
+                            允许
+                        </AlertDialogAction>
+                    </AlertDialogFooter>
+                </AlertDialogContent>
+            </AlertDialog>
         </div>
     );
 };
@@ -1,7 +1,9 @@
 import {
     MessageSquare, Globe, Mail,
     Phone, Folder, Shield,
-    Code, Image
+    Code, Image, Braces, FileText,
+    Database, Terminal, Bug, Palette,
+    Network, Fingerprint, Calendar, MapPin
 } from 'lucide-react';
 
 /**
@@ -22,7 +24,20 @@ export const getContentTypeIcon = (type) => {
         file: <Folder className="h-4 w-4 text-cyan-500" />,
         password: <Shield className="h-4 w-4 text-red-500" />,
         code: <Code className="h-4 w-4 text-pink-500" />,
-        image: <Image className="h-4 w-4 text-orange-500" />
+        image: <Image className="h-4 w-4 text-orange-500" />,
+        json: <Braces className="h-4 w-4 text-amber-500" />,
+        yaml: <Braces className="h-4 w-4 text-amber-500" />,
+        xml: <Code className="h-4 w-4 text-amber-500" />,
+        html: <Code className="h-4 w-4 text-orange-500" />,
+        markdown: <FileText className="h-4 w-4 text-slate-500" />,
+        sql: <Database className="h-4 w-4 text-indigo-500" />,
+        shell: <Terminal className="h-4 w-4 text-gray-700" />,
+        stacktrace: <Bug className="h-4 w-4 text-red-500" />,
+        color: <Palette className="h-4 w-4 text-fuchsia-500" />,
+        ip: <Network className="h-4 w-4 text-teal-500" />,
+        uuid: <Fingerprint className="h-4 w-4 text-gray-500" />,
+        date: <Calendar className="h-4 w-4 text-sky-500" />,
+        coordinates: <MapPin className="h-4 w-4 text-emerald-500" />
     };
     return iconMap[type] || <MessageSquare className="h-4 w-4 text-blue-500" />;
 };
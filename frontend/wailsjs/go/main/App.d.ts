@@ -6,10 +6,24 @@ export function AddTagsToItem(arg1:string,arg2:Array<string>):Promise<void>;
 
 export function AutoGenerateTags(arg1:string,arg2:string):Promise<Array<string>>;
 
+export function BackupNow():Promise<models.BackupInfo>;
+
 export function BatchPermanentDelete(arg1:Array<string>):Promise<void>;
 
+export function CancelChatStream(arg1:string):Promise<void>;
+
+export function CancelRetag():Promise<void>;
+
+export function ChooseExportFile(arg1:string):Promise<string>;
+
+export function ChooseImportFile():Promise<string>;
+
 export function CleanupUnusedTags():Promise<void>;
 
+export function ConfirmChatToolCall(arg1:string,arg2:boolean):Promise<void>;
+
+export function CreateCaptureRule(arg1:models.CaptureRule):Promise<models.CaptureRule>;
+
 export function CreateChatSession(arg1:string):Promise<models.ChatSession>;
 
 export function CreateClipboardItem(arg1:string):Promise<void>;
@@ -18,6 +32,8 @@ export function CreateTag(arg1:string,arg2:string,arg3:string,arg4:string):Promi
 
 export function CreateTagGroup(arg1:string,arg2:string,arg3:string,arg4:number):Promise<models.TagGroup>;
 
+export function DeleteCaptureRule(arg1:string):Promise<void>;
+
 export function DeleteChatSession(arg1:string):Promise<void>;
 
 export function DeleteClipboardItem(arg1:string):Promise<void>;
@@ -26,14 +42,26 @@ export function DeleteTag(arg1:string):Promise<void>;
 
 export function DeleteTagGroup(arg1:string):Promise<void>;
 
+export function DisableEncryption():Promise<void>;
+
+export function EditChatMessage(arg1:string,arg2:string):Promise<void>;
+
 export function EmptyTrash():Promise<void>;
 
+export function EnableEncryption(arg1:string,arg2:string):Promise<void>;
+
+export function ExportToFile(arg1:string,arg2:models.ExportOptions):Promise<models.ExportReport>;
+
 export function GenerateChatTags(arg1:string):Promise<Array<string>>;
 
 export function GenerateChatTitle(arg1:string):Promise<string>;
 
 export function GenerateTagsForClipboardItem(arg1:string):Promise<Array<string>>;
 
+export function GetAPIToken():Promise<string>;
+
+export function GetCaptureRules():Promise<Array<models.CaptureRule>>;
+
 export function GetCategoriesAndTags():Promise<models.CategoryTagsResponse>;
 
 export function GetChatMessages(arg1:string,arg2:number,arg3:number):Promise<models.ChatMessageListResponse>;
@@ -42,12 +70,24 @@ export function GetChatSession(arg1:string):Promise<models.ChatSession>;
 
 export function GetChatSessions():Promise<models.ChatSessionListResponse>;
 
+export function GetClipboardItemImage(arg1:string):Promise<string>;
+
 export function GetClipboardItems(arg1:number,arg2:number):Promise<Array<models.ClipboardItem>>;
 
+export function GetEmbeddingStatus():Promise<models.EmbeddingStatus>;
+
+export function GetEncryptionStatus():Promise<models.EncryptionStatus>;
+
+export function GetLLMProviderStatus():Promise<Array<models.LLMProviderStatus>>;
+
+export function GetLLMSettings():Promise<models.LLMSettings>;
+
 export function GetMostUsedTags(arg1:number):Promise<Array<models.TagWithStats>>;
 
 export function GetRecentTags(arg1:number):Promise<Array<models.TagWithStats>>;
 
+export function GetRetagStatus():Promise<models.RetagSummary>;
+
 export function GetSettings():Promise<models.Settings>;
 
 export function GetSimilarTags(arg1:string,arg2:number):Promise<Array<models.Tag>>;
@@ -58,30 +98,60 @@ export function GetTagGroups():Promise<Array<models.TagGroup>>;
 
 export function GetTagStatistics():Promise<models.TagStatistics>;
 
+export function GetTaggingQueueStatus():Promise<models.TaggingQueueStatus>;
+
 export function GetTags():Promise<Array<models.Tag>>;
 
 export function GetTagsByGroup(arg1:string):Promise<Array<models.Tag>>;
 
 export function GetTagsForItem(arg1:string):Promise<Array<models.Tag>>;
 
+export function GetTransforms():Promise<Array<models.TransformInfo>>;
+
 export function GetTrashItems(arg1:number,arg2:number):Promise<Array<models.ClipboardItem>>;
 
 export function GetWindowState():Promise<models.WindowState>;
 
 export function HideWindow():Promise<void>;
 
+export function ImportFromFile(arg1:string,arg2:models.ImportOptions):Promise<models.ImportReport>;
+
+export function ListBackups():Promise<Array<models.BackupInfo>>;
+
+export function ListLocalModels(arg1:string):Promise<Array<string>>;
+
 export function MergeTags(arg1:string,arg2:string):Promise<void>;
 
+export function PauseRetag():Promise<void>;
+
 export function PermanentDeleteClipboardItem(arg1:string):Promise<void>;
 
+export function PreviewClipboardItemTransform(arg1:string,arg2:string):Promise<string>;
+
+export function RegenerateAPIToken():Promise<string>;
+
+export function RegenerateChatMessage(arg1:string):Promise<void>;
+
 export function RemoveTagsFromItem(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function ReorderCaptureRules(arg1:Array<string>):Promise<void>;
+
+export function RestoreBackup(arg1:string):Promise<void>;
+
 export function RestoreClipboardItem(arg1:string):Promise<void>;
 
+export function ResumeRetag():Promise<void>;
+
+export function RunRetentionNow():Promise<models.RetentionReport>;
+
+export function SaveClipboardItemTransformed(arg1:string,arg2:string):Promise<models.ClipboardItem>;
+
 export function SearchClipboardItems(arg1:models.SearchQuery):Promise<models.SearchResult>;
 
 export function SearchTags(arg1:models.TagSearchQuery):Promise<Array<models.TagWithStats>>;
 
+export function SemanticSearch(arg1:string,arg2:number):Promise<models.SearchResult>;
+
 export function SendChatMessage(arg1:string,arg2:string):Promise<models.ChatMessage>;
 
 export function SendChatMessageStream(arg1:string,arg2:string):Promise<void>;
@@ -90,16 +160,28 @@ export function SetScreenSize(arg1:number,arg2:number):Promise<void>;
 
 export function ShowWindow():Promise<void>;
 
+export function StartRetag(arg1:models.RetagOptions):Promise<void>;
+
 export function SuggestTags(arg1:string,arg2:number):Promise<Array<string>>;
 
+export function TestCaptureRules(arg1:models.RuleTestRequest):Promise<models.RuleTestResult>;
+
+export function TestLLMConnection(arg1:models.LLMSettings):Promise<void>;
+
 export function ToggleWindow():Promise<void>;
 
+export function UnlockDatabase(arg1:string):Promise<void>;
+
+export function UpdateCaptureRule(arg1:models.CaptureRule):Promise<void>;
+
 export function UpdateChatSession(arg1:string,arg2:string):Promise<void>;
 
 export function UpdateClipboardItem(arg1:models.ClipboardItem):Promise<void>;
 
 export function UpdateItemTags(arg1:string,arg2:Array<string>,arg3:string):Promise<void>;
 
+export function UpdateLLMSettings(arg1:models.LLMSettings):Promise<void>;
+
 export function UpdateSettings(arg1:models.Settings):Promise<void>;
 
 export function UpdateTag(arg1:models.Tag):Promise<void>;
@@ -107,3 +189,5 @@ export function UpdateTag(arg1:models.Tag):Promise<void>;
 export function UpdateTagGroup(arg1:models.TagGroup):Promise<void>;
 
 export function UseClipboardItem(arg1:string):Promise<void>;
+
+export function UseClipboardItemTransformed(arg1:string,arg2:string):Promise<string>;
@@ -10,14 +10,42 @@ export function AutoGenerateTags(arg1, arg2) {
   return window['go']['main']['App']['AutoGenerateTags'](arg1, arg2);
 }
 
+export function BackupNow() {
+  return window['go']['main']['App']['BackupNow']();
+}
+
 export function BatchPermanentDelete(arg1) {
   return window['go']['main']['App']['BatchPermanentDelete'](arg1);
 }
 
+export function CancelChatStream(arg1) {
+  return window['go']['main']['App']['CancelChatStream'](arg1);
+}
+
+export function CancelRetag() {
+  return window['go']['main']['App']['CancelRetag']();
+}
+
+export function ChooseExportFile(arg1) {
+  return window['go']['main']['App']['ChooseExportFile'](arg1);
+}
+
+export function ChooseImportFile() {
+  return window['go']['main']['App']['ChooseImportFile']();
+}
+
 export function CleanupUnusedTags() {
   return window['go']['main']['App']['CleanupUnusedTags']();
 }
 
+export function ConfirmChatToolCall(arg1, arg2) {
+  return window['go']['main']['App']['ConfirmChatToolCall'](arg1, arg2);
+}
+
+export function CreateCaptureRule(arg1) {
+  return window['go']['main']['App']['CreateCaptureRule'](arg1);
+}
+
 export function CreateChatSession(arg1) {
   return window['go']['main']['App']['CreateChatSession'](arg1);
 }
@@ -34,6 +62,10 @@ export function CreateTagGroup(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['CreateTagGroup'](arg1, arg2, arg3, arg4);
 }
 
+export function DeleteCaptureRule(arg1) {
+  return window['go']['main']['App']['DeleteCaptureRule'](arg1);
+}
+
 export function DeleteChatSession(arg1) {
   return window['go']['main']['App']['DeleteChatSession'](arg1);
 }
@@ -50,10 +82,26 @@ export function DeleteTagGroup(arg1) {
   return window['go']['main']['App']['DeleteTagGroup'](arg1);
 }
 
+export function DisableEncryption() {
+  return window['go']['main']['App']['DisableEncryption']();
+}
+
+export function EditChatMessage(arg1, arg2) {
+  return window['go']['main']['App']['EditChatMessage'](arg1, arg2);
+}
+
 export function EmptyTrash() {
   return window['go']['main']['App']['EmptyTrash']();
 }
 
+export function EnableEncryption(arg1, arg2) {
+  return window['go']['main']['App']['EnableEncryption'](arg1, arg2);
+}
+
+export function ExportToFile(arg1, arg2) {
+  return window['go']['main']['App']['ExportToFile'](arg1, arg2);
+}
+
 export function GenerateChatTags(arg1) {
   return window['go']['main']['App']['GenerateChatTags'](arg1);
 }
@@ -66,6 +114,14 @@ export function GenerateTagsForClipboardItem(arg1) {
   return window['go']['main']['App']['GenerateTagsForClipboardItem'](arg1);
 }
 
+export function GetAPIToken() {
+  return window['go']['main']['App']['GetAPIToken']();
+}
+
+export function GetCaptureRules() {
+  return window['go']['main']['App']['GetCaptureRules']();
+}
+
 export function GetCategoriesAndTags() {
   return window['go']['main']['App']['GetCategoriesAndTags']();
 }
@@ -82,10 +138,30 @@ export function GetChatSessions() {
   return window['go']['main']['App']['GetChatSessions']();
 }
 
+export function GetClipboardItemImage(arg1) {
+  return window['go']['main']['App']['GetClipboardItemImage'](arg1);
+}
+
 export function GetClipboardItems(arg1, arg2) {
   return window['go']['main']['App']['GetClipboardItems'](arg1, arg2);
 }
 
+export function GetEmbeddingStatus() {
+  return window['go']['main']['App']['GetEmbeddingStatus']();
+}
+
+export function GetEncryptionStatus() {
+  return window['go']['main']['App']['GetEncryptionStatus']();
+}
+
+export function GetLLMProviderStatus() {
+  return window['go']['main']['App']['GetLLMProviderStatus']();
+}
+
+export function GetLLMSettings() {
+  return window['go']['main']['App']['GetLLMSettings']();
+}
+
 export function GetMostUsedTags(arg1) {
   return window['go']['main']['App']['GetMostUsedTags'](arg1);
 }
@@ -94,6 +170,10 @@ export function GetRecentTags(arg1) {
   return window['go']['main']['App']['GetRecentTags'](arg1);
 }
 
+export function GetRetagStatus() {
+  return window['go']['main']['App']['GetRetagStatus']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
@@ -114,6 +194,10 @@ export function GetTagStatistics() {
   return window['go']['main']['App']['GetTagStatistics']();
 }
 
+export function GetTaggingQueueStatus() {
+  return window['go']['main']['App']['GetTaggingQueueStatus']();
+}
+
 export function GetTags() {
   return window['go']['main']['App']['GetTags']();
 }
@@ -126,6 +210,10 @@ export function GetTagsForItem(arg1) {
   return window['go']['main']['App']['GetTagsForItem'](arg1);
 }
 
+export function GetTransforms() {
+  return window['go']['main']['App']['GetTransforms']();
+}
+
 export function GetTrashItems(arg1, arg2) {
   return window['go']['main']['App']['GetTrashItems'](arg1, arg2);
 }
@@ -138,22 +226,70 @@ export function HideWindow() {
   return window['go']['main']['App']['HideWindow']();
 }
 
+export function ImportFromFile(arg1, arg2) {
+  return window['go']['main']['App']['ImportFromFile'](arg1, arg2);
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
+export function ListLocalModels(arg1) {
+  return window['go']['main']['App']['ListLocalModels'](arg1);
+}
+
 export function MergeTags(arg1, arg2) {
   return window['go']['main']['App']['MergeTags'](arg1, arg2);
 }
 
+export function PauseRetag() {
+  return window['go']['main']['App']['PauseRetag']();
+}
+
 export function PermanentDeleteClipboardItem(arg1) {
   return window['go']['main']['App']['PermanentDeleteClipboardItem'](arg1);
 }
 
+export function PreviewClipboardItemTransform(arg1, arg2) {
+  return window['go']['main']['App']['PreviewClipboardItemTransform'](arg1, arg2);
+}
+
+export function RegenerateAPIToken() {
+  return window['go']['main']['App']['RegenerateAPIToken']();
+}
+
+export function RegenerateChatMessage(arg1) {
+  return window['go']['main']['App']['RegenerateChatMessage'](arg1);
+}
+
 export function RemoveTagsFromItem(arg1, arg2) {
   return window['go']['main']['App']['RemoveTagsFromItem'](arg1, arg2);
 }
 
+export function ReorderCaptureRules(arg1) {
+  return window['go']['main']['App']['ReorderCaptureRules'](arg1);
+}
+
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
 export function RestoreClipboardItem(arg1) {
   return window['go']['main']['App']['RestoreClipboardItem'](arg1);
 }
 
+export function ResumeRetag() {
+  return window['go']['main']['App']['ResumeRetag']();
+}
+
+export function RunRetentionNow() {
+  return window['go']['main']['App']['RunRetentionNow']();
+}
+
+export function SaveClipboardItemTransformed(arg1, arg2) {
+  return window['go']['main']['App']['SaveClipboardItemTransformed'](arg1, arg2);
+}
+
 export function SearchClipboardItems(arg1) {
   return window['go']['main']['App']['SearchClipboardItems'](arg1);
 }
@@ -162,6 +298,10 @@ export function SearchTags(arg1) {
   return window['go']['main']['App']['SearchTags'](arg1);
 }
 
+export function SemanticSearch(arg1, arg2) {
+  return window['go']['main']['App']['SemanticSearch'](arg1, arg2);
+}
+
 export function SendChatMessage(arg1, arg2) {
   return window['go']['main']['App']['SendChatMessage'](arg1, arg2);
 }
@@ -178,14 +318,34 @@ export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
 
+export function StartRetag(arg1) {
+  return window['go']['main']['App']['StartRetag'](arg1);
+}
+
 export function SuggestTags(arg1, arg2) {
   return window['go']['main']['App']['SuggestTags'](arg1, arg2);
 }
 
+export function TestCaptureRules(arg1) {
+  return window['go']['main']['App']['TestCaptureRules'](arg1);
+}
+
+export function TestLLMConnection(arg1) {
+  return window['go']['main']['App']['TestLLMConnection'](arg1);
+}
+
 export function ToggleWindow() {
   return window['go']['main']['App']['ToggleWindow']();
 }
 
+export function UnlockDatabase(arg1) {
+  return window['go']['main']['App']['UnlockDatabase'](arg1);
+}
+
+export function UpdateCaptureRule(arg1) {
+  return window['go']['main']['App']['UpdateCaptureRule'](arg1);
+}
+
 export function UpdateChatSession(arg1, arg2) {
   return window['go']['main']['App']['UpdateChatSession'](arg1, arg2);
 }
@@ -198,6 +358,10 @@ export function UpdateItemTags(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateItemTags'](arg1, arg2, arg3);
 }
 
+export function UpdateLLMSettings(arg1) {
+  return window['go']['main']['App']['UpdateLLMSettings'](arg1);
+}
+
 export function UpdateSettings(arg1) {
   return window['go']['main']['App']['UpdateSettings'](arg1);
 }
@@ -213,3 +377,7 @@ export function UpdateTagGroup(arg1) {
 export function UseClipboardItem(arg1) {
   return window['go']['main']['App']['UseClipboardItem'](arg1);
 }
+
+export function UseClipboardItemTransformed(arg1, arg2) {
+  return window['go']['main']['App']['UseClipboardItemTransformed'](arg1, arg2);
+}
@@ -1,5 +1,162 @@
 export namespace models {
 	
+	export class APISettings {
+	    enabled: boolean;
+	    port: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new APISettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.port = source["port"];
+	    }
+	}
+	export class AgentSettings {
+	    enabled: boolean;
+	    max_steps: number;
+	    confirm_timeout_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgentSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.max_steps = source["max_steps"];
+	        this.confirm_timeout_seconds = source["confirm_timeout_seconds"];
+	    }
+	}
+	export class BackupInfo {
+	    path: string;
+	    name: string;
+	    size: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupSettings {
+	    enabled: boolean;
+	    directory: string;
+	    interval_hours: number;
+	    keep_daily: number;
+	    keep_weekly: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.directory = source["directory"];
+	        this.interval_hours = source["interval_hours"];
+	        this.keep_daily = source["keep_daily"];
+	        this.keep_weekly = source["keep_weekly"];
+	    }
+	}
+	export class RuleAction {
+	    type: string;
+	    value?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.value = source["value"];
+	    }
+	}
+	export class CaptureRule {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    sort_order: number;
+	    stop: boolean;
+	    source_app: string;
+	    pattern: string;
+	    min_length: number;
+	    max_length: number;
+	    category: string;
+	    actions: RuleAction[];
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CaptureRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.sort_order = source["sort_order"];
+	        this.stop = source["stop"];
+	        this.source_app = source["source_app"];
+	        this.pattern = source["pattern"];
+	        this.min_length = source["min_length"];
+	        this.max_length = source["max_length"];
+	        this.category = source["category"];
+	        this.actions = this.convertValues(source["actions"], RuleAction);
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class CategoryTagsResponse {
 	    categories: string[];
 	    tags: string[];
@@ -111,6 +268,9 @@ export namespace models {
 	    updated_at: any;
 	    // Go type: time
 	    last_active_at: any;
+	    summary: string;
+	    // Go type: time
+	    summary_until?: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new ChatSession(source);
@@ -127,6 +287,8 @@ export namespace models {
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	        this.last_active_at = this.convertValues(source["last_active_at"], null);
+	        this.summary = source["summary"];
+	        this.summary_until = this.convertValues(source["summary_until"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -228,11 +390,28 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class HighlightRange {
+	    field: string;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HighlightRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
 	export class ClipboardItem {
 	    id: string;
 	    content: string;
 	    content_type: string;
 	    title: string;
+	    thumbnail?: string;
 	    tags?: Tag[];
 	    category: string;
 	    is_favorite: boolean;
@@ -246,6 +425,18 @@ export namespace models {
 	    updated_at: any;
 	    // Go type: time
 	    last_used_at: any;
+	    is_sensitive: boolean;
+	    // Go type: time
+	    expires_at?: any;
+	    source_app: string;
+	    source_title: string;
+	    content_length: number;
+	    line_count: number;
+	    language: string;
+	    code_language: string;
+	    snippet?: string;
+	    highlights?: HighlightRange[];
+	    score?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ClipboardItem(source);
@@ -257,6 +448,7 @@ export namespace models {
 	        this.content = source["content"];
 	        this.content_type = source["content_type"];
 	        this.title = source["title"];
+	        this.thumbnail = source["thumbnail"];
 	        this.tags = this.convertValues(source["tags"], Tag);
 	        this.category = source["category"];
 	        this.is_favorite = source["is_favorite"];
@@ -266,6 +458,17 @@ export namespace models {
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	        this.last_used_at = this.convertValues(source["last_used_at"], null);
+	        this.is_sensitive = source["is_sensitive"];
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	        this.source_app = source["source_app"];
+	        this.source_title = source["source_title"];
+	        this.content_length = source["content_length"];
+	        this.line_count = source["line_count"];
+	        this.language = source["language"];
+	        this.code_language = source["code_language"];
+	        this.snippet = source["snippet"];
+	        this.highlights = this.convertValues(source["highlights"], HighlightRange);
+	        this.score = source["score"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -286,13 +489,79 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class EmbeddingSettings {
+	    enabled: boolean;
+	    base_url: string;
+	    model: string;
+	    hybrid_weight: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbeddingSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.base_url = source["base_url"];
+	        this.model = source["model"];
+	        this.hybrid_weight = source["hybrid_weight"];
+	    }
+	}
+	export class EmbeddingStatus {
+	    enabled: boolean;
+	    model: string;
+	    indexed: number;
+	    pending: number;
+	    running: boolean;
+	    last_error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbeddingStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.model = source["model"];
+	        this.indexed = source["indexed"];
+	        this.pending = source["pending"];
+	        this.running = source["running"];
+	        this.last_error = source["last_error"];
+	    }
+	}
+	export class EncryptionStatus {
+	    enabled: boolean;
+	    locked: boolean;
+	    key_source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.locked = source["locked"];
+	        this.key_source = source["key_source"];
+	    }
+	}
 	export class SearchQuery {
 	    query: string;
 	    category: string;
 	    tags: string[];
 	    tag_mode: string;
+	    untagged: boolean;
 	    limit: number;
 	    offset: number;
+	    // Go type: time
+	    created_after?: any;
+	    // Go type: time
+	    created_before?: any;
+	    source_app?: string;
+	    language?: string;
+	    min_length?: number;
+	    max_length?: number;
+	    mode?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SearchQuery(source);
@@ -304,10 +573,451 @@ export namespace models {
 	        this.category = source["category"];
 	        this.tags = source["tags"];
 	        this.tag_mode = source["tag_mode"];
+	        this.untagged = source["untagged"];
 	        this.limit = source["limit"];
 	        this.offset = source["offset"];
+	        this.created_after = this.convertValues(source["created_after"], null);
+	        this.created_before = this.convertValues(source["created_before"], null);
+	        this.source_app = source["source_app"];
+	        this.language = source["language"];
+	        this.min_length = source["min_length"];
+	        this.max_length = source["max_length"];
+	        this.mode = source["mode"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportOptions {
+	    format: string;
+	    query: SearchQuery;
+	    include_chats: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.query = this.convertValues(source["query"], SearchQuery);
+	        this.include_chats = source["include_chats"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportReport {
+	    items: number;
+	    tag_groups: number;
+	    tags: number;
+	    chat_sessions: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = source["items"];
+	        this.tag_groups = source["tag_groups"];
+	        this.tags = source["tags"];
+	        this.chat_sessions = source["chat_sessions"];
+	    }
+	}
+	export class ImportOptions {
+	    conflict: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.conflict = source["conflict"];
+	    }
+	}
+	export class ImportReport {
+	    items_created: number;
+	    items_updated: number;
+	    items_skipped: number;
+	    tag_groups_created: number;
+	    tags_created: number;
+	    chat_sessions_created: number;
+	    chat_sessions_updated: number;
+	    chat_sessions_skipped: number;
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items_created = source["items_created"];
+	        this.items_updated = source["items_updated"];
+	        this.items_skipped = source["items_skipped"];
+	        this.tag_groups_created = source["tag_groups_created"];
+	        this.tags_created = source["tags_created"];
+	        this.chat_sessions_created = source["chat_sessions_created"];
+	        this.chat_sessions_updated = source["chat_sessions_updated"];
+	        this.chat_sessions_skipped = source["chat_sessions_skipped"];
+	        this.errors = source["errors"];
+	    }
+	}
+	export class LLMProviderStatus {
+	    name: string;
+	    base_url: string;
+	    model: string;
+	    healthy: boolean;
+	    models: string[];
+	    latency_ms: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LLMProviderStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.base_url = source["base_url"];
+	        this.model = source["model"];
+	        this.healthy = source["healthy"];
+	        this.models = source["models"];
+	        this.latency_ms = source["latency_ms"];
+	        this.error = source["error"];
+	    }
+	}
+	export class RetrievalSettings {
+	    enabled: boolean;
+	    max_items: number;
+	    max_chars: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetrievalSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.max_items = source["max_items"];
+	        this.max_chars = source["max_chars"];
 	    }
 	}
+	export class LocalLLMSettings {
+	    enabled: boolean;
+	    base_url: string;
+	    model: string;
+	    prefer_local: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LocalLLMSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.base_url = source["base_url"];
+	        this.model = source["model"];
+	        this.prefer_local = source["prefer_local"];
+	    }
+	}
+	export class LLMSettings {
+	    provider: string;
+	    base_url: string;
+	    model: string;
+	    api_key?: string;
+	    has_api_key: boolean;
+	    temperature: number;
+	    max_tokens: number;
+	    timeout_seconds: number;
+	    context_tokens: number;
+	    retrieval: RetrievalSettings;
+	    agent: AgentSettings;
+	    embedding: EmbeddingSettings;
+	    local: LocalLLMSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new LLMSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider = source["provider"];
+	        this.base_url = source["base_url"];
+	        this.model = source["model"];
+	        this.api_key = source["api_key"];
+	        this.has_api_key = source["has_api_key"];
+	        this.temperature = source["temperature"];
+	        this.max_tokens = source["max_tokens"];
+	        this.timeout_seconds = source["timeout_seconds"];
+	        this.context_tokens = source["context_tokens"];
+	        this.retrieval = this.convertValues(source["retrieval"], RetrievalSettings);
+	        this.agent = this.convertValues(source["agent"], AgentSettings);
+	        this.embedding = this.convertValues(source["embedding"], EmbeddingSettings);
+	        this.local = this.convertValues(source["local"], LocalLLMSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetagOptions {
+	    query: SearchQuery;
+	    concurrency: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetagOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = this.convertValues(source["query"], SearchQuery);
+	        this.concurrency = source["concurrency"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetagSummary {
+	    state: string;
+	    total: number;
+	    processed: number;
+	    tagged: number;
+	    skipped: number;
+	    failed: number;
+	    tags_created: string[];
+	    tags_merged: string[];
+	    last_error?: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    finished_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetagSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.state = source["state"];
+	        this.total = source["total"];
+	        this.processed = source["processed"];
+	        this.tagged = source["tagged"];
+	        this.skipped = source["skipped"];
+	        this.failed = source["failed"];
+	        this.tags_created = source["tags_created"];
+	        this.tags_merged = source["tags_merged"];
+	        this.last_error = source["last_error"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetentionReport {
+	    expired: Record<string, number>;
+	    overflow: number;
+	    purged: number;
+	    timeout: number;
+	    // Go type: time
+	    ran_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetentionReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.expired = source["expired"];
+	        this.overflow = source["overflow"];
+	        this.purged = source["purged"];
+	        this.timeout = source["timeout"];
+	        this.ran_at = this.convertValues(source["ran_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetentionSettings {
+	    trash_days: number;
+	    category_ttl_hours: Record<string, number>;
+	    interval_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetentionSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trash_days = source["trash_days"];
+	        this.category_ttl_hours = source["category_ttl_hours"];
+	        this.interval_minutes = source["interval_minutes"];
+	    }
+	}
+	export class RuleTestRequest {
+	    content: string;
+	    source_app: string;
+	    rules?: CaptureRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleTestRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.content = source["content"];
+	        this.source_app = source["source_app"];
+	        this.rules = this.convertValues(source["rules"], CaptureRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleTestResult {
+	    matched: string[];
+	    ignored: boolean;
+	    reason?: string;
+	    tags: string[];
+	    item: ClipboardItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleTestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matched = source["matched"];
+	        this.ignored = source["ignored"];
+	        this.reason = source["reason"];
+	        this.tags = source["tags"];
+	        this.item = this.convertValues(source["item"], ClipboardItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class SearchResult {
 	    items: ClipboardItem[];
 	    total: number;
@@ -346,6 +1056,56 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class SensitiveRule {
+	    detector: string;
+	    enabled: boolean;
+	    action: string;
+	    expire_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SensitiveRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.detector = source["detector"];
+	        this.enabled = source["enabled"];
+	        this.action = source["action"];
+	        this.expire_minutes = source["expire_minutes"];
+	    }
+	}
+	export class SensitiveSettings {
+	    rules: SensitiveRule[];
+	    entropy_threshold: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SensitiveSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rules = this.convertValues(source["rules"], SensitiveRule);
+	        this.entropy_threshold = source["entropy_threshold"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Settings {
 	    main_hotkey: string[];
 	    escape_hotkey: string[];
@@ -356,6 +1116,14 @@ export namespace models {
 	    ignore_images: boolean;
 	    default_category: string;
 	    auto_categorize: boolean;
+	    auto_tag: boolean;
+	    poll_interval_ms: number;
+	    ignore_apps: string[];
+	    llm: LLMSettings;
+	    retention: RetentionSettings;
+	    sensitive: SensitiveSettings;
+	    backup: BackupSettings;
+	    api: APISettings;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -372,7 +1140,33 @@ export namespace models {
 	        this.ignore_images = source["ignore_images"];
 	        this.default_category = source["default_category"];
 	        this.auto_categorize = source["auto_categorize"];
+	        this.auto_tag = source["auto_tag"];
+	        this.poll_interval_ms = source["poll_interval_ms"];
+	        this.ignore_apps = source["ignore_apps"];
+	        this.llm = this.convertValues(source["llm"], LLMSettings);
+	        this.retention = this.convertValues(source["retention"], RetentionSettings);
+	        this.sensitive = this.convertValues(source["sensitive"], SensitiveSettings);
+	        this.backup = this.convertValues(source["backup"], BackupSettings);
+	        this.api = this.convertValues(source["api"], APISettings);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class TagStat {
 	    tag: string;
@@ -587,6 +1381,46 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class TaggingQueueStatus {
+	    running: boolean;
+	    workers: number;
+	    pending: number;
+	    in_flight: number;
+	    completed: number;
+	    failed: number;
+	    skipped: number;
+	    last_error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaggingQueueStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.workers = source["workers"];
+	        this.pending = source["pending"];
+	        this.in_flight = source["in_flight"];
+	        this.completed = source["completed"];
+	        this.failed = source["failed"];
+	        this.skipped = source["skipped"];
+	        this.last_error = source["last_error"];
+	    }
+	}
+	export class TransformInfo {
+	    name: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransformInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	    }
+	}
 	
 	export class WindowState {
 	    visible: boolean;
@@ -2,12 +2,17 @@ package model
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
 )
 
 type ChatModelImpl struct {
@@ -15,58 +20,115 @@ type ChatModelImpl struct {
 	model  *openai.ChatModel
 }
 
+// ChatModelConfig 聊天模型配置（OpenAI 兼容接口）
 type ChatModelConfig struct {
-	BaseURL string
-	APIKey  string
-	Model   string
+	Name        string // 服务名称，用于日志和状态展示
+	BaseURL     string
+	APIKey      string
+	Model       string // 本地服务留空时自动发现
+	Temperature float32
+	MaxTokens   int // 0 表示使用服务端默认值
+	Timeout     time.Duration
+	Local       bool // 本地服务不要求 API 密钥
 }
 
-// getModelConfig 获取模型配置，支持环境变量
-func getModelConfig() *ChatModelConfig {
-	// 尝试从环境变量获取配置
-	baseURL := os.Getenv("CHAT_MODEL_BASE_URL")
-	apiKey := os.Getenv("CHAT_MODEL_API_KEY")
-	modelName := os.Getenv("CHAT_MODEL_NAME")
+// ConfigFromSettings 根据应用设置和密钥构建远程服务配置
+func ConfigFromSettings(settings models.LLMSettings, apiKey string) *ChatModelConfig {
+	return &ChatModelConfig{
+		Name:        ProviderRemote,
+		BaseURL:     strings.TrimSpace(settings.BaseURL),
+		APIKey:      strings.TrimSpace(apiKey),
+		Model:       strings.TrimSpace(settings.Model),
+		Temperature: settings.Temperature,
+		MaxTokens:   settings.MaxTokens,
+		Timeout:     time.Duration(settings.TimeoutSeconds) * time.Second,
+	}
+}
 
-	// 如果环境变量不存在，使用默认配置
-	if baseURL == "" {
-		baseURL = "https://ark.cn-beijing.volces.com/api/v3"
+// LocalConfigFromSettings 根据应用设置构建本地服务配置
+func LocalConfigFromSettings(settings models.LLMSettings) *ChatModelConfig {
+	return &ChatModelConfig{
+		Name:        ProviderLocal,
+		BaseURL:     strings.TrimSpace(settings.Local.BaseURL),
+		Model:       strings.TrimSpace(settings.Local.Model),
+		Temperature: settings.Temperature,
+		MaxTokens:   settings.MaxTokens,
+		Timeout:     time.Duration(settings.TimeoutSeconds) * time.Second,
+		Local:       true,
 	}
-	if apiKey == "" {
-		apiKey = "9567f3a1-7e2e-4fa7-a8db-5a7ee0926d79"
+}
+
+// ConfigsFromSettings 按优先级返回所有已配置的服务，未填写密钥的远程服务不参与
+func ConfigsFromSettings(settings models.LLMSettings, apiKey string) []*ChatModelConfig {
+	var configs []*ChatModelConfig
+	if remote := ConfigFromSettings(settings, apiKey); remote.APIKey != "" {
+		configs = append(configs, remote)
 	}
-	if modelName == "" {
-		modelName = "doubao-1-5-pro-32k-250115"
+	if settings.Local.Enabled {
+		local := LocalConfigFromSettings(settings)
+		if settings.Local.PreferLocal {
+			configs = append([]*ChatModelConfig{local}, configs...)
+		} else {
+			configs = append(configs, local)
+		}
 	}
+	return configs
+}
 
-	return &ChatModelConfig{
-		BaseURL: baseURL,
-		APIKey:  apiKey,
-		Model:   modelName,
+// Validate 校验配置是否完整
+func (c *ChatModelConfig) Validate() error {
+	switch {
+	case c.BaseURL == "":
+		return errors.New("未配置大模型服务地址")
+	case c.Model == "" && !c.Local:
+		return errors.New("未配置模型名称")
+	case c.APIKey == "" && !c.Local:
+		return errors.New("未配置大模型 API 密钥，请在设置中填写")
 	}
+	return nil
 }
 
-// newChatModel component initialization function of node 'CustomChatModel1' in graph 'dev'
-func NewChatModel(ctx context.Context) (cm model.ToolCallingChatModel, err error) {
-	config := getModelConfig()
+// NewChatModel 根据配置创建聊天模型
+func NewChatModel(ctx context.Context, config *ChatModelConfig) (cm model.ToolCallingChatModel, err error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	modelName := config.Model
+	if modelName == "" {
+		discovered, err := ListModels(ctx, config.BaseURL, config.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(discovered) == 0 {
+			return nil, fmt.Errorf("本地服务 %s 未提供任何模型", config.BaseURL)
+		}
+		modelName = discovered[0]
+		log.Printf("🔍 自动选择本地模型: %s", modelName)
+	}
 
 	log.Printf("🤖 正在初始化聊天模型...")
 	log.Printf("🔧 BaseURL: %s", config.BaseURL)
-	log.Printf("🔧 Model: %s", config.Model)
+	log.Printf("🔧 Model: %s", modelName)
 	log.Printf("🔧 APIKey: %s...", maskAPIKey(config.APIKey))
 
-	opcm, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		BaseURL: config.BaseURL,
-		APIKey:  config.APIKey,
-		Model:   config.Model,
-	})
+	temperature := config.Temperature
+	openaiConfig := &openai.ChatModelConfig{
+		BaseURL:     config.BaseURL,
+		APIKey:      config.APIKey,
+		Model:       modelName,
+		Temperature: &temperature,
+		Timeout:     config.Timeout,
+	}
+	if config.MaxTokens > 0 {
+		maxTokens := config.MaxTokens
+		openaiConfig.MaxTokens = &maxTokens
+	}
+
+	opcm, err := openai.NewChatModel(ctx, openaiConfig)
 	if err != nil {
 		log.Printf("❌ 初始化ChatModel失败: %v", err)
-		log.Printf("💡 请检查网络连接和API密钥是否正确")
-		log.Printf("💡 您可以通过以下环境变量自定义配置:")
-		log.Printf("   - CHAT_MODEL_BASE_URL: API基础URL")
-		log.Printf("   - CHAT_MODEL_API_KEY: API密钥")
-		log.Printf("   - CHAT_MODEL_NAME: 模型名称")
+		log.Printf("💡 请在设置中检查大模型服务地址、模型名称和API密钥")
 		return nil, err
 	}
 
@@ -75,6 +137,30 @@ func NewChatModel(ctx context.Context) (cm model.ToolCallingChatModel, err error
 	return cm, nil
 }
 
+// TestConnection 依次尝试给定的服务并发送一条简短消息，任一服务可用即视为成功
+func TestConnection(ctx context.Context, configs []*ChatModelConfig) error {
+	cm, err := NewFailoverChatModel(configs)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(0)
+	for _, config := range configs {
+		timeout += config.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err = cm.Generate(ctx, []*schema.Message{schema.UserMessage("ping")}, model.WithMaxTokens(8))
+	if err != nil {
+		return fmt.Errorf("连接大模型服务失败: %w", err)
+	}
+	return nil
+}
+
 // maskAPIKey 掩码API密钥用于日志显示
 func maskAPIKey(apiKey string) string {
 	if len(apiKey) <= 10 {
@@ -4,14 +4,34 @@ import (
 	"context"
 	"io"
 	"log"
+	"os"
 	"testing"
 
 	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
 )
 
+// testChatModelConfig 从环境变量读取测试用模型配置，未配置密钥时跳过测试
+func testChatModelConfig(t *testing.T) *ChatModelConfig {
+	t.Helper()
+	settings := models.DefaultLLMSettings()
+	if baseURL := os.Getenv("CHAT_MODEL_BASE_URL"); baseURL != "" {
+		settings.BaseURL = baseURL
+	}
+	if name := os.Getenv("CHAT_MODEL_NAME"); name != "" {
+		settings.Model = name
+	}
+	apiKey := os.Getenv("CHAT_MODEL_API_KEY")
+	if apiKey == "" {
+		t.Skip("CHAT_MODEL_API_KEY not set")
+	}
+	return ConfigFromSettings(settings, apiKey)
+}
+
 func TestChatModelImpl_Stream(t *testing.T) {
 	var ctx = context.Background()
-	cm, err := NewChatModel(ctx)
+	cm, err := NewChatModel(ctx, testChatModelConfig(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,3 +60,18 @@ func TestChatModelImpl_Stream(t *testing.T) {
 	}
 	resultStream.Close()
 }
+
+func TestChatModelConfig_Validate(t *testing.T) {
+	config := ConfigFromSettings(models.DefaultLLMSettings(), "")
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for missing API key")
+	}
+
+	config = ConfigFromSettings(models.DefaultLLMSettings(), " key ")
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if config.APIKey != "key" {
+		t.Errorf("expected trimmed key, got %q", config.APIKey)
+	}
+}
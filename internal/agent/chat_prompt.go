@@ -2,25 +2,36 @@ package model
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
 )
 
-func ChatPromptBase(ctx context.Context, input string, history []*schema.Message) ([]*schema.Message, error) {
+// referencesPrompt 剪切板参考资料的说明，条目按 [编号] 列在其后
+const referencesPrompt = `以下是从用户剪切板历史中检索到的条目，可能与用户的问题有关。回答时只使用相关的条目，并在引用处用 [编号] 标注来源；条目都不相关时正常回答，不要编造剪切板中不存在的内容。`
+
+// ChatPromptBase 聊天提示词：参考资料（可选）、历史消息和本次用户输入
+func ChatPromptBase(ctx context.Context, input string, history []*schema.Message, references []models.ClipboardItem) ([]*schema.Message, error) {
 
-	var inputMsg = []*schema.Message{
-		{Role: "user", Content: input},
+	var referenceMsgs []*schema.Message
+	if len(references) > 0 {
+		referenceMsgs = append(referenceMsgs, ReferencesMessage(references))
 	}
 
 	chatTpl := prompt.FromMessages(schema.FString,
+		schema.MessagesPlaceholder("references", true),
 		schema.MessagesPlaceholder("message_histories", true),
 		schema.UserMessage("{user_input}"),
 	)
 
 	msgList, err := chatTpl.Format(ctx, map[string]any{
-		"user_input":        inputMsg,
+		"user_input":        input,
+		"references":        referenceMsgs,
 		"message_histories": history,
 	})
 	if err != nil {
@@ -30,6 +41,21 @@ func ChatPromptBase(ctx context.Context, input string, history []*schema.Message
 	return msgList, nil
 }
 
+// ReferencesMessage 将剪切板条目格式化为参考资料系统消息，编号从 1 开始
+func ReferencesMessage(items []models.ClipboardItem) *schema.Message {
+	var b strings.Builder
+	b.WriteString(referencesPrompt)
+	for i, item := range items {
+		fmt.Fprintf(&b, "\n\n[%d] %s（复制于 %s", i+1, item.Title, item.CreatedAt.Format("2006-01-02 15:04"))
+		if item.SourceApp != "" {
+			fmt.Fprintf(&b, "，来自 %s", item.SourceApp)
+		}
+		b.WriteString("）\n")
+		b.WriteString(item.Content)
+	}
+	return schema.SystemMessage(b.String())
+}
+
 func ChatPromptSummarize(ctx context.Context, input []*schema.Message, history []*schema.Message) ([]*schema.Message, error) {
 
 	systemTpl := `你是一个专业的内容分析助手，你的任务是根据用户的输入和输入的历史消息，生成一段总结,原来精确概括全文内容，不要遗漏任何细节。用户输入：{user_input}`
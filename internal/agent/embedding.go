@@ -0,0 +1,269 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"Sid/internal/models"
+)
+
+// defaultEmbeddingTimeout 未配置超时时每次生成向量的超时时间
+const defaultEmbeddingTimeout = 60 * time.Second
+
+// Embedder 文本向量模型
+type Embedder interface {
+	embedding.Embedder
+	// Name 模型标识，不同模型生成的向量不能混用
+	Name() string
+}
+
+// EmbedderConfig 向量模型配置（OpenAI 兼容 /embeddings 接口）
+type EmbedderConfig struct {
+	BaseURL string
+	APIKey  string // 本地服务可以为空
+	Model   string
+	Timeout time.Duration
+}
+
+// EmbedderConfigFromSettings 根据应用设置构建向量模型配置
+// embedding.base_url 为空时使用大模型服务地址和密钥；单独填写地址时不发送大模型密钥，避免泄露给其他服务
+func EmbedderConfigFromSettings(settings models.LLMSettings, apiKey string) *EmbedderConfig {
+	config := &EmbedderConfig{
+		BaseURL: strings.TrimSpace(settings.Embedding.BaseURL),
+		Model:   strings.TrimSpace(settings.Embedding.Model),
+		Timeout: time.Duration(settings.TimeoutSeconds) * time.Second,
+	}
+	if config.BaseURL == "" || config.BaseURL == strings.TrimSpace(settings.BaseURL) {
+		config.BaseURL = strings.TrimSpace(settings.BaseURL)
+		config.APIKey = strings.TrimSpace(apiKey)
+	}
+	return config
+}
+
+// Validate 校验配置是否完整
+func (c *EmbedderConfig) Validate() error {
+	switch {
+	case c.BaseURL == "":
+		return errors.New("未配置向量模型服务地址")
+	case c.Model == "":
+		return errors.New("未配置向量模型名称")
+	}
+	return nil
+}
+
+// openAIEmbedder 通过 OpenAI 兼容接口 POST {baseURL}/embeddings 生成向量
+type openAIEmbedder struct {
+	config *EmbedderConfig
+	client *http.Client
+}
+
+// NewEmbedder 根据配置创建向量模型
+func NewEmbedder(config *EmbedderConfig) (Embedder, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultEmbeddingTimeout
+	}
+	return &openAIEmbedder{config: config, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Name 模型名称
+func (e *openAIEmbedder) Name() string {
+	return e.config.Model
+}
+
+// EmbedStrings 批量生成向量，结果顺序与 texts 一致
+func (e *openAIEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"model": e.config.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimRight(e.config.BaseURL, "/") + "/embeddings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("无法连接向量模型服务 %s: %w", e.config.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		if decodeErr == nil && result.Error != nil && result.Error.Message != "" {
+			return nil, fmt.Errorf("生成向量失败: %s 返回 %s: %s", url, resp.Status, result.Error.Message)
+		}
+		return nil, fmt.Errorf("生成向量失败: %s 返回 %s", url, resp.Status)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("解析向量结果失败: %w", decodeErr)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("向量数量不匹配: 请求 %d 条，返回 %d 条", len(texts), len(result.Data))
+	}
+
+	sort.Slice(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+	vectors := make([][]float64, len(result.Data))
+	for i, data := range result.Data {
+		vectors[i] = data.Embedding
+	}
+	return vectors, nil
+}
+
+// hashEmbedder 本地特征哈希向量：英文单词和中文单字、双字映射到固定维度，
+// 不需要模型服务，只能反映字面重合，用于测试和离线环境
+type hashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder 创建本地特征哈希向量模型
+func NewHashEmbedder(dimensions int) Embedder {
+	return &hashEmbedder{dimensions: dimensions}
+}
+
+// Name 模型名称，包含维度
+func (e *hashEmbedder) Name() string {
+	return fmt.Sprintf("local-hash-%d", e.dimensions)
+}
+
+// EmbedStrings 为每个文本生成归一化的哈希向量
+func (e *hashEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector := make([]float64, e.dimensions)
+		for _, feature := range hashFeatures(text) {
+			h := fnv.New64a()
+			h.Write([]byte(feature))
+			sum := h.Sum64()
+			sign := 1.0
+			if sum&(1<<63) != 0 {
+				sign = -1
+			}
+			vector[sum%uint64(e.dimensions)] += sign
+		}
+		var norm float64
+		for _, v := range vector {
+			norm += v * v
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for j := range vector {
+				vector[j] /= norm
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// hashFeatures 提取小写的字母数字单词，以及汉字的单字和相邻双字
+func hashFeatures(text string) []string {
+	var features []string
+	var word []rune
+	var prevHan rune
+	flush := func() {
+		if len(word) > 0 {
+			features = append(features, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			features = append(features, string(r))
+			if prevHan != 0 {
+				features = append(features, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevHan = 0
+	}
+	flush()
+	return features
+}
+
+// EmbedderLoader 按当前配置创建向量模型，未启用语义搜索时返回 ErrEmbeddingDisabled
+type EmbedderLoader func() (Embedder, error)
+
+// ErrEmbeddingDisabled 未启用语义搜索
+var ErrEmbeddingDisabled = errors.New("未启用语义搜索")
+
+// EmbedderProvider 向量模型提供者，首次使用时创建并复用，配置变更后调用 Reload 重新创建
+type EmbedderProvider interface {
+	Embedder() (Embedder, error)
+	Reload()
+}
+
+// embedderProvider 向量模型提供者实现
+type embedderProvider struct {
+	mu       sync.Mutex
+	loader   EmbedderLoader
+	embedder Embedder
+}
+
+// NewEmbedderProvider 创建新的向量模型提供者
+func NewEmbedderProvider(loader EmbedderLoader) EmbedderProvider {
+	return &embedderProvider{loader: loader}
+}
+
+// Embedder 获取向量模型，未创建时按当前配置创建
+func (p *embedderProvider) Embedder() (Embedder, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.embedder != nil {
+		return p.embedder, nil
+	}
+	embedder, err := p.loader()
+	if err != nil {
+		return nil, err
+	}
+	p.embedder = embedder
+	return embedder, nil
+}
+
+// Reload 丢弃已创建的向量模型，下次使用时按最新配置重新创建
+func (p *embedderProvider) Reload() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.embedder = nil
+}
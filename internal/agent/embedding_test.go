@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Sid/internal/models"
+)
+
+func TestOpenAIEmbedder_EmbedStrings(t *testing.T) {
+	var request struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&request)
+		// 故意乱序返回，结果应按 index 排列
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(&EmbedderConfig{BaseURL: server.URL + "/v1/", APIKey: "key", Model: "embed-small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := embedder.EmbedStrings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Model != "embed-small" || len(request.Input) != 2 || auth != "Bearer key" {
+		t.Errorf("request = %+v, auth %q", request, auth)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v", vectors)
+	}
+}
+
+func TestOpenAIEmbedder_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"model not found"}}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(&EmbedderConfig{BaseURL: server.URL, Model: "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := embedder.EmbedStrings(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestEmbedderConfigFromSettings(t *testing.T) {
+	settings := models.DefaultLLMSettings()
+	config := EmbedderConfigFromSettings(settings, "secret")
+	if config.BaseURL != settings.BaseURL || config.APIKey != "secret" {
+		t.Errorf("default config = %+v", config)
+	}
+
+	// 单独配置的服务地址不发送大模型密钥
+	settings.Embedding.BaseURL = "http://localhost:11434/v1"
+	config = EmbedderConfigFromSettings(settings, "secret")
+	if config.BaseURL != "http://localhost:11434/v1" || config.APIKey != "" {
+		t.Errorf("custom config = %+v", config)
+	}
+}
+
+func TestHashEmbedder(t *testing.T) {
+	embedder := NewHashEmbedder(256)
+	vectors, err := embedder.EmbedStrings(context.Background(), []string{
+		"SELECT * FROM orders JOIN users ON users.id = orders.user_id",
+		"orders joined with users",
+		"今天的购物清单",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := func(a, b []float64) float64 {
+		var sum float64
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	if self := dot(vectors[0], vectors[0]); self < 0.999 || self > 1.001 {
+		t.Errorf("vector not normalized: %v", self)
+	}
+	if dot(vectors[0], vectors[1]) <= dot(vectors[0], vectors[2]) {
+		t.Error("related texts should be closer than unrelated ones")
+	}
+}
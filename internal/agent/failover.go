@@ -0,0 +1,189 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// failoverCooldown 服务调用失败后暂时降级的时长，期间优先尝试其他服务
+const failoverCooldown = 30 * time.Second
+
+// failoverChatModel 按优先级依次尝试多个服务的聊天模型
+// 模型在首次使用时创建，避免本地服务未启动时影响远程服务的使用
+// WithTools 返回的实例与原实例共享服务健康状态和已创建的模型，只各自保存工具绑定
+type failoverChatModel struct {
+	state *failoverState
+	tools []*schema.ToolInfo
+
+	mu    sync.Mutex
+	bound map[*failoverCandidate]toolBoundModel
+}
+
+// failoverState 候选服务的健康状态和已创建的模型
+type failoverState struct {
+	mu         sync.Mutex
+	candidates []*failoverCandidate
+}
+
+// failoverCandidate 候选服务，model 为未绑定工具的模型
+type failoverCandidate struct {
+	config   *ChatModelConfig
+	model    model.ToolCallingChatModel
+	failedAt time.Time
+}
+
+// toolBoundModel 由 base 绑定工具得到的模型，base 被重新创建后失效
+type toolBoundModel struct {
+	base  model.ToolCallingChatModel
+	model model.ToolCallingChatModel
+}
+
+// NewFailoverChatModel 创建支持自动故障切换的聊天模型，configs 按优先级排列
+func NewFailoverChatModel(configs []*ChatModelConfig) (model.ToolCallingChatModel, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("未配置可用的大模型服务，请在设置中填写 API 密钥或启用本地模型")
+	}
+
+	candidates := make([]*failoverCandidate, 0, len(configs))
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Name, err)
+		}
+		candidates = append(candidates, &failoverCandidate{config: config})
+	}
+	return &failoverChatModel{state: &failoverState{candidates: candidates}}, nil
+}
+
+// Generate 生成回复，当前服务失败时切换到下一个
+func (f *failoverChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var errs []error
+	for _, c := range f.ordered() {
+		cm, err := f.modelFor(ctx, c)
+		if err == nil {
+			var resp *schema.Message
+			if resp, err = cm.Generate(ctx, input, opts...); err == nil {
+				f.markHealthy(c)
+				return resp, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		f.markFailed(c, err)
+		errs = append(errs, fmt.Errorf("%s: %w", c.config.Name, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Stream 流式生成回复，仅在建立流之前进行故障切换
+func (f *failoverChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var errs []error
+	for _, c := range f.ordered() {
+		cm, err := f.modelFor(ctx, c)
+		if err == nil {
+			var stream *schema.StreamReader[*schema.Message]
+			if stream, err = cm.Stream(ctx, input, opts...); err == nil {
+				f.markHealthy(c)
+				return stream, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		f.markFailed(c, err)
+		errs = append(errs, fmt.Errorf("%s: %w", c.config.Name, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// WithTools 返回绑定工具的新实例，与原实例共享服务健康状态和已创建的模型
+func (f *failoverChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return &failoverChatModel{state: f.state, tools: tools}, nil
+}
+
+// ordered 返回尝试顺序：正常的服务在前，冷却中的服务在后
+func (f *failoverChatModel) ordered() []*failoverCandidate {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+
+	var healthy, cooling []*failoverCandidate
+	for _, c := range f.state.candidates {
+		if !c.failedAt.IsZero() && time.Since(c.failedAt) < failoverCooldown {
+			cooling = append(cooling, c)
+		} else {
+			healthy = append(healthy, c)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+// modelFor 获取候选服务的模型，未创建时创建，有工具时返回绑定工具后的模型
+func (f *failoverChatModel) modelFor(ctx context.Context, c *failoverCandidate) (model.ToolCallingChatModel, error) {
+	base, err := f.state.baseModel(ctx, c)
+	if err != nil || len(f.tools) == 0 {
+		return base, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if cached, ok := f.bound[c]; ok && cached.base == base {
+		return cached.model, nil
+	}
+	cm, err := base.WithTools(f.tools)
+	if err != nil {
+		return nil, err
+	}
+	if f.bound == nil {
+		f.bound = make(map[*failoverCandidate]toolBoundModel)
+	}
+	f.bound[c] = toolBoundModel{base: base, model: cm}
+	return cm, nil
+}
+
+// baseModel 获取候选服务未绑定工具的模型，未创建时创建
+func (s *failoverState) baseModel(ctx context.Context, c *failoverCandidate) (model.ToolCallingChatModel, error) {
+	s.mu.Lock()
+	cm := c.model
+	s.mu.Unlock()
+	if cm != nil {
+		return cm, nil
+	}
+
+	cm, err := NewChatModel(ctx, c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 并发创建时保留先创建的模型
+	if c.model == nil {
+		c.model = cm
+	}
+	return c.model, nil
+}
+
+// markHealthy 标记服务可用
+func (f *failoverChatModel) markHealthy(c *failoverCandidate) {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	c.failedAt = time.Time{}
+}
+
+// markFailed 标记服务失败，本地服务的模型可能已变化，下次使用时重新创建
+func (f *failoverChatModel) markFailed(c *failoverCandidate, err error) {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	c.failedAt = time.Now()
+	if c.config.Local {
+		c.model = nil
+	}
+	log.Printf("⚠️  大模型服务 %s (%s) 不可用: %v", c.config.Name, c.config.BaseURL, err)
+}
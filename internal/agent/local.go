@@ -0,0 +1,109 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Sid/internal/models"
+)
+
+const (
+	// ProviderRemote 远程大模型服务名称
+	ProviderRemote = "remote"
+	// ProviderLocal 本地大模型服务名称
+	ProviderLocal = "local"
+)
+
+// healthCheckTimeout 健康检查超时时间
+const healthCheckTimeout = 3 * time.Second
+
+// ListModels 通过 OpenAI 兼容接口 GET {baseURL}/models 获取服务端可用模型
+func ListModels(ctx context.Context, baseURL, apiKey string) ([]string, error) {
+	url := strings.TrimRight(baseURL, "/") + "/models"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("无法连接模型服务 %s: %w", baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取模型列表失败: %s 返回 %s", url, resp.Status)
+	}
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析模型列表失败: %w", err)
+	}
+
+	names := make([]string, 0, len(result.Data))
+	for _, m := range result.Data {
+		if m.ID != "" {
+			names = append(names, m.ID)
+		}
+	}
+	return names, nil
+}
+
+// CheckHealth 检查服务是否可用，本地服务还会确认配置的模型存在
+func CheckHealth(ctx context.Context, config *ChatModelConfig) models.LLMProviderStatus {
+	status := models.LLMProviderStatus{
+		Name:    config.Name,
+		BaseURL: config.BaseURL,
+		Model:   config.Model,
+	}
+	if err := config.Validate(); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	names, err := ListModels(ctx, config.BaseURL, config.APIKey)
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Models = names
+
+	switch {
+	case config.Model == "" && len(names) == 0:
+		status.Error = "服务端未提供任何模型"
+	case config.Model == "":
+		status.Model = names[0]
+		status.Healthy = true
+	case config.Local && !containsModel(names, config.Model):
+		status.Error = fmt.Sprintf("服务端不存在模型 %s", config.Model)
+	default:
+		status.Healthy = true
+	}
+	return status
+}
+
+// containsModel 检查模型列表中是否包含指定模型
+func containsModel(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
+)
+
+// newFakeOpenAIServer 创建模拟 OpenAI 兼容接口的测试服务
+func newFakeOpenAIServer(t *testing.T, modelNames []string, reply string, fail bool) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		data := make([]map[string]string, len(modelNames))
+		for i, name := range modelNames {
+			data[i] = map[string]string{"id": name, "object": "model"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data})
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			http.Error(w, `{"error":{"message":"unavailable"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   req.Model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]string{"role": "assistant", "content": reply + "@" + req.Model},
+				"finish_reason": "stop",
+			}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestListModels(t *testing.T) {
+	server, _ := newFakeOpenAIServer(t, []string{"qwen2.5:7b", "llama3"}, "", false)
+
+	names, err := ListModels(context.Background(), server.URL+"/v1/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "qwen2.5:7b" {
+		t.Errorf("unexpected models %v", names)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	server, _ := newFakeOpenAIServer(t, []string{"qwen2.5:7b"}, "", false)
+
+	status := CheckHealth(context.Background(), &ChatModelConfig{Name: ProviderLocal, BaseURL: server.URL + "/v1", Local: true})
+	if !status.Healthy || status.Model != "qwen2.5:7b" {
+		t.Errorf("expected healthy with discovered model, got %+v", status)
+	}
+
+	status = CheckHealth(context.Background(), &ChatModelConfig{Name: ProviderLocal, BaseURL: server.URL + "/v1", Model: "missing", Local: true})
+	if status.Healthy || status.Error == "" {
+		t.Errorf("expected unhealthy for missing model, got %+v", status)
+	}
+
+	server.Close()
+	status = CheckHealth(context.Background(), &ChatModelConfig{Name: ProviderLocal, BaseURL: server.URL + "/v1", Local: true})
+	if status.Healthy {
+		t.Error("expected unhealthy for stopped server")
+	}
+}
+
+func TestFailoverChatModel_Generate(t *testing.T) {
+	remote, remoteCalls := newFakeOpenAIServer(t, []string{"remote-model"}, "remote", true)
+	local, localCalls := newFakeOpenAIServer(t, []string{"qwen2.5:7b"}, "local", false)
+
+	settings := models.DefaultLLMSettings()
+	settings.BaseURL = remote.URL + "/v1"
+	settings.Model = "remote-model"
+	settings.Local = models.LocalLLMSettings{Enabled: true, BaseURL: local.URL + "/v1"}
+
+	configs := ConfigsFromSettings(settings, "sk-test")
+	if len(configs) != 2 || configs[0].Name != ProviderRemote || configs[1].Name != ProviderLocal {
+		t.Fatalf("unexpected config order %+v", configs)
+	}
+
+	cm, err := NewFailoverChatModel(configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []*schema.Message{schema.UserMessage("hello")}
+	resp, err := cm.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "local@qwen2.5:7b" {
+		t.Errorf("expected local reply with discovered model, got %q", resp.Content)
+	}
+	if *remoteCalls != 1 || *localCalls != 1 {
+		t.Errorf("expected one call each, got remote=%d local=%d", *remoteCalls, *localCalls)
+	}
+
+	// 远程服务处于冷却期，直接使用本地服务
+	if _, err := cm.Generate(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if *remoteCalls != 1 || *localCalls != 2 {
+		t.Errorf("expected remote to be skipped during cooldown, got remote=%d local=%d", *remoteCalls, *localCalls)
+	}
+}
+
+func TestFailoverChatModel_WithToolsSharesState(t *testing.T) {
+	remote, remoteCalls := newFakeOpenAIServer(t, []string{"remote-model"}, "remote", true)
+	local, localCalls := newFakeOpenAIServer(t, []string{"qwen2.5:7b"}, "local", false)
+
+	settings := models.DefaultLLMSettings()
+	settings.BaseURL = remote.URL + "/v1"
+	settings.Model = "remote-model"
+	settings.Local = models.LocalLLMSettings{Enabled: true, BaseURL: local.URL + "/v1"}
+	cm, err := NewFailoverChatModel(ConfigsFromSettings(settings, "sk-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := cm.(*failoverChatModel)
+	tools := []*schema.ToolInfo{{Name: "search_clipboard", Desc: "搜索剪切板历史"}}
+
+	// 绑定工具的实例中记录的失败对原实例可见
+	input := []*schema.Message{schema.UserMessage("hello")}
+	bound, err := cm.WithTools(tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bound.Generate(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if order := parent.ordered(); order[0].config.Name != ProviderLocal {
+		t.Errorf("expected local provider first after remote failure, got %s", order[0].config.Name)
+	}
+	created := parent.state.candidates[1].model
+	if created == nil {
+		t.Fatal("expected local model to be cached in shared state")
+	}
+
+	// 下一轮对话重新绑定工具时跳过冷却中的服务，并复用已创建的模型
+	bound, err = cm.WithTools(tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bound.Generate(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if *remoteCalls != 1 || *localCalls != 2 {
+		t.Errorf("expected remote to be skipped during cooldown, got remote=%d local=%d", *remoteCalls, *localCalls)
+	}
+	if parent.state.candidates[1].model != created {
+		t.Error("expected the created model to be reused across tool bindings")
+	}
+}
+
+func TestConfigsFromSettings(t *testing.T) {
+	settings := models.DefaultLLMSettings()
+	if configs := ConfigsFromSettings(settings, ""); len(configs) != 0 {
+		t.Errorf("expected no providers without key or local server, got %d", len(configs))
+	}
+
+	settings.Local.Enabled = true
+	settings.Local.PreferLocal = true
+	configs := ConfigsFromSettings(settings, "sk-test")
+	if len(configs) != 2 || configs[0].Name != ProviderLocal {
+		t.Errorf("expected local provider first, got %+v", configs)
+	}
+	if _, err := NewFailoverChatModel(nil); err == nil {
+		t.Error("expected error without providers")
+	}
+}
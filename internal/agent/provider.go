@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// ConfigLoader 读取当前模型配置，按优先级排列
+type ConfigLoader func() ([]*ChatModelConfig, error)
+
+// Provider 聊天模型提供者，首次使用时创建模型并复用，配置变更后调用 Reload 重新创建
+type Provider interface {
+	ChatModel(ctx context.Context) (model.ToolCallingChatModel, error)
+	Reload()
+}
+
+// provider 聊天模型提供者实现
+type provider struct {
+	mu     sync.Mutex
+	loader ConfigLoader
+	model  model.ToolCallingChatModel
+}
+
+// NewProvider 创建新的聊天模型提供者
+func NewProvider(loader ConfigLoader) Provider {
+	return &provider{loader: loader}
+}
+
+// ChatModel 获取聊天模型，未创建时按当前配置创建
+func (p *provider) ChatModel(ctx context.Context) (model.ToolCallingChatModel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.model != nil {
+		return p.model, nil
+	}
+
+	configs, err := p.loader()
+	if err != nil {
+		return nil, err
+	}
+
+	var cm model.ToolCallingChatModel
+	if len(configs) == 1 {
+		cm, err = NewChatModel(ctx, configs[0])
+	} else {
+		cm, err = NewFailoverChatModel(configs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.model = cm
+	return cm, nil
+}
+
+// Reload 丢弃已创建的模型，下次使用时按最新配置重新创建
+func (p *provider) Reload() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.model = nil
+}
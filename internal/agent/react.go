@@ -0,0 +1,207 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	"Sid/internal/models"
+)
+
+// DefaultMaxSteps ReAct 循环默认的最大轮数（每轮一次模型调用）
+const DefaultMaxSteps = 6
+
+// maxStepsPrompt 达到最大轮数后要求模型直接回答的提示
+const maxStepsPrompt = "工具调用次数已达上限，请根据以上工具结果直接回答用户，不要再调用工具。"
+
+// AgentTool 智能体可调用的工具，Destructive 的工具执行前需要用户确认
+type AgentTool struct {
+	Tool        tool.InvokableTool
+	Destructive bool
+}
+
+// AgentOptions ReAct 循环的选项
+type AgentOptions struct {
+	MaxSteps int // 小于等于 0 时使用 DefaultMaxSteps
+
+	// Confirm 请求用户确认 Destructive 工具，返回 false 表示拒绝；为 nil 时一律拒绝
+	Confirm func(ctx context.Context, call models.ChatToolCall) bool
+	// OnToolCall 工具调用状态变化时回调：请求调用（pending）和执行结束（success/error/rejected）
+	OnToolCall func(call models.ChatToolCall)
+	// OnContent 不为 nil 时以流式方式调用模型，并逐段回调回答内容
+	OnContent func(chunk string)
+}
+
+// AgentResult ReAct 循环的结果
+type AgentResult struct {
+	Content   string
+	ToolCalls []models.ChatToolCall
+}
+
+// RunAgent 以 ReAct 方式运行对话：模型请求调用工具时执行工具并把结果交回模型，
+// 直到模型给出不含工具调用的回答或达到最大轮数；没有工具时等同于一次普通调用
+func RunAgent(ctx context.Context, chatModel model.ToolCallingChatModel, messages []*schema.Message, tools []AgentTool, options AgentOptions) (*AgentResult, error) {
+	maxSteps := options.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+
+	byName := make(map[string]AgentTool, len(tools))
+	infos := make([]*schema.ToolInfo, 0, len(tools))
+	for _, t := range tools {
+		info, err := t.Tool.Info(ctx)
+		if err != nil {
+			return nil, err
+		}
+		byName[info.Name] = t
+		infos = append(infos, info)
+	}
+	bound := chatModel
+	if len(infos) > 0 {
+		var err error
+		if bound, err = chatModel.WithTools(infos); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &AgentResult{}
+	messages = append([]*schema.Message(nil), messages...)
+	for step := 0; ; step++ {
+		current := bound
+		if step == maxSteps {
+			// 达到上限后不再提供工具，要求模型根据已有结果回答
+			current = chatModel
+			messages = append(messages, schema.SystemMessage(maxStepsPrompt))
+			log.Printf("⚠️  工具调用达到最大轮数 %d，要求模型直接回答", maxSteps)
+		}
+
+		response, err := generate(ctx, current, messages, result, options.OnContent)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.ToolCalls) == 0 || step == maxSteps {
+			return result, nil
+		}
+
+		messages = append(messages, response)
+		for _, toolCall := range response.ToolCalls {
+			call := runTool(ctx, byName, toolCall, options)
+			result.ToolCalls = append(result.ToolCalls, call)
+			output := call.Result
+			if call.Status != models.ToolCallStatusSuccess {
+				output = "错误: " + call.Error
+			}
+			messages = append(messages, schema.ToolMessage(output, toolCall.ID))
+		}
+	}
+}
+
+// generate 调用一次模型，回答内容追加到 result.Content；流式调用时逐段回调并合并工具调用片段
+func generate(ctx context.Context, chatModel model.ToolCallingChatModel, messages []*schema.Message, result *AgentResult, onContent func(string)) (*schema.Message, error) {
+	// 多轮都有文字内容时用空行分隔
+	separated := result.Content == ""
+	appendContent := func(chunk string) {
+		if chunk == "" {
+			return
+		}
+		if !separated {
+			separated = true
+			chunk = "\n\n" + chunk
+		}
+		result.Content += chunk
+		if onContent != nil {
+			onContent(chunk)
+		}
+	}
+
+	if onContent == nil {
+		response, err := chatModel.Generate(ctx, messages)
+		if err != nil {
+			return nil, err
+		}
+		appendContent(response.Content)
+		return response, nil
+	}
+
+	stream, err := chatModel.Stream(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+		appendContent(chunk.Content)
+		// 停止生成后不再等待模型的后续片段
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(chunks) == 0 {
+		return &schema.Message{Role: schema.Assistant}, nil
+	}
+	return schema.ConcatMessages(chunks)
+}
+
+// runTool 执行一次工具调用，需要确认的工具先请求用户确认
+func runTool(ctx context.Context, tools map[string]AgentTool, toolCall schema.ToolCall, options AgentOptions) models.ChatToolCall {
+	call := models.ChatToolCall{
+		ID:        toolCall.ID,
+		Name:      toolCall.Function.Name,
+		Arguments: toolCall.Function.Arguments,
+		Status:    models.ToolCallStatusPending,
+	}
+	if call.ID == "" {
+		// 部分模型不返回调用 ID，生成一个用于前端展示和确认
+		call.ID = uuid.New().String()
+	}
+	finish := func(status, output string) models.ChatToolCall {
+		call.Status = status
+		if status == models.ToolCallStatusSuccess {
+			call.Result = output
+		} else {
+			call.Error = output
+		}
+		if options.OnToolCall != nil {
+			options.OnToolCall(call)
+		}
+		return call
+	}
+
+	t, ok := tools[call.Name]
+	if !ok {
+		return finish(models.ToolCallStatusError, fmt.Sprintf("未知的工具: %s", call.Name))
+	}
+	call.Destructive = t.Destructive
+	if options.OnToolCall != nil {
+		options.OnToolCall(call)
+	}
+
+	if t.Destructive && (options.Confirm == nil || !options.Confirm(ctx, call)) {
+		log.Printf("🚫 用户拒绝了工具调用: %s", call.Name)
+		return finish(models.ToolCallStatusRejected, "用户拒绝了此操作")
+	}
+
+	log.Printf("🔧 调用工具: %s %s", call.Name, call.Arguments)
+	output, err := t.Tool.InvokableRun(ctx, call.Arguments)
+	if err != nil {
+		log.Printf("❌ 工具执行失败: %s: %v", call.Name, err)
+		return finish(models.ToolCallStatusError, err.Error())
+	}
+	return finish(models.ToolCallStatusSuccess, output)
+}
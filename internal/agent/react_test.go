@@ -0,0 +1,201 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
+)
+
+// scriptedModel 按顺序返回预设的回复，记录每次调用的输入以及是否绑定了工具
+type scriptedModel struct {
+	replies []*schema.Message
+	calls   [][]*schema.Message
+	bound   []bool
+	tools   bool
+}
+
+func (m *scriptedModel) Generate(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.Message, error) {
+	m.calls = append(m.calls, input)
+	m.bound = append(m.bound, m.tools)
+	if len(m.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := m.replies[0]
+	m.replies = m.replies[1:]
+	return reply, nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	reply, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// 拆成两段返回，验证流式片段的合并
+	half := len(reply.Content) / 2
+	first := &schema.Message{Role: schema.Assistant, Content: reply.Content[:half]}
+	second := &schema.Message{Role: schema.Assistant, Content: reply.Content[half:], ToolCalls: reply.ToolCalls}
+	return schema.StreamReaderFromArray([]*schema.Message{first, second}), nil
+}
+
+func (m *scriptedModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return &boundModel{parent: m}, nil
+}
+
+// boundModel 绑定工具后的模型，与 scriptedModel 共享回复和调用记录
+type boundModel struct {
+	parent *scriptedModel
+}
+
+func (b *boundModel) Generate(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.Message, error) {
+	b.parent.tools = true
+	defer func() { b.parent.tools = false }()
+	return b.parent.Generate(ctx, input, opts...)
+}
+
+func (b *boundModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	b.parent.tools = true
+	defer func() { b.parent.tools = false }()
+	return b.parent.Stream(ctx, input, opts...)
+}
+
+func (b *boundModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return b, nil
+}
+
+func toolCallMessage(id, name, arguments string) *schema.Message {
+	return schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: name, Arguments: arguments}}})
+}
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+// testTools 返回一个普通的 echo 工具和一个需要确认的 remove 工具，invoked 记录实际执行的工具
+func testTools(t *testing.T, invoked *[]string) []AgentTool {
+	t.Helper()
+	echo, err := utils.InferTool("echo", "echo", func(ctx context.Context, input echoInput) (string, error) {
+		*invoked = append(*invoked, "echo")
+		return "echo: " + input.Text, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove, err := utils.InferTool("remove", "remove", func(ctx context.Context, input echoInput) (string, error) {
+		*invoked = append(*invoked, "remove")
+		return "removed", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []AgentTool{{Tool: echo}, {Tool: remove, Destructive: true}}
+}
+
+func TestRunAgent_ToolCallThenAnswer(t *testing.T) {
+	chatModel := &scriptedModel{replies: []*schema.Message{
+		toolCallMessage("call-1", "echo", `{"text":"hi"}`),
+		schema.AssistantMessage("done", nil),
+	}}
+	var invoked []string
+	var events []string
+	result, err := RunAgent(context.Background(), chatModel, []*schema.Message{schema.UserMessage("say hi")}, testTools(t, &invoked), AgentOptions{
+		OnToolCall: func(call models.ChatToolCall) { events = append(events, call.Status) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "done" || len(result.ToolCalls) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	call := result.ToolCalls[0]
+	if call.ID != "call-1" || call.Status != models.ToolCallStatusSuccess || !strings.Contains(call.Result, "echo: hi") {
+		t.Errorf("tool call = %+v", call)
+	}
+	if strings.Join(events, ",") != "pending,success" {
+		t.Errorf("events = %v", events)
+	}
+
+	// 第二次调用带上了工具调用和工具结果
+	second := chatModel.calls[1]
+	if len(second) != 3 || second[2].Role != schema.Tool || second[2].ToolCallID != "call-1" {
+		t.Errorf("second call input = %v", second)
+	}
+}
+
+func TestRunAgent_DestructiveToolRequiresConfirmation(t *testing.T) {
+	for _, approved := range []bool{false, true} {
+		chatModel := &scriptedModel{replies: []*schema.Message{
+			toolCallMessage("", "remove", `{"text":"x"}`),
+			schema.AssistantMessage("ok", nil),
+		}}
+		var invoked []string
+		var confirmed models.ChatToolCall
+		result, err := RunAgent(context.Background(), chatModel, []*schema.Message{schema.UserMessage("remove x")}, testTools(t, &invoked), AgentOptions{
+			Confirm: func(ctx context.Context, call models.ChatToolCall) bool {
+				confirmed = call
+				return approved
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !confirmed.Destructive || confirmed.ID == "" {
+			t.Errorf("confirm request = %+v", confirmed)
+		}
+		call := result.ToolCalls[0]
+		if approved && (call.Status != models.ToolCallStatusSuccess || len(invoked) != 1) {
+			t.Errorf("approved call = %+v, invoked %v", call, invoked)
+		}
+		if !approved && (call.Status != models.ToolCallStatusRejected || len(invoked) != 0) {
+			t.Errorf("rejected call = %+v, invoked %v", call, invoked)
+		}
+	}
+}
+
+func TestRunAgent_MaxSteps(t *testing.T) {
+	chatModel := &scriptedModel{replies: []*schema.Message{
+		toolCallMessage("1", "echo", `{"text":"a"}`),
+		toolCallMessage("2", "echo", `{"text":"b"}`),
+		schema.AssistantMessage("final", nil),
+	}}
+	var invoked []string
+	var chunks []string
+	result, err := RunAgent(context.Background(), chatModel, []*schema.Message{schema.UserMessage("loop")}, testTools(t, &invoked), AgentOptions{
+		MaxSteps:  2,
+		OnContent: func(chunk string) { chunks = append(chunks, chunk) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "final" || strings.Join(chunks, "") != "final" || len(invoked) != 2 {
+		t.Fatalf("result = %+v, chunks %v, invoked %v", result, chunks, invoked)
+	}
+	// 最后一轮不再绑定工具，并提示模型直接回答
+	if len(chatModel.bound) != 3 || !chatModel.bound[0] || chatModel.bound[2] {
+		t.Errorf("bound = %v", chatModel.bound)
+	}
+	last := chatModel.calls[2]
+	if last[len(last)-1].Content != maxStepsPrompt {
+		t.Errorf("last input = %v", last[len(last)-1])
+	}
+}
+
+func TestRunAgent_UnknownTool(t *testing.T) {
+	chatModel := &scriptedModel{replies: []*schema.Message{
+		toolCallMessage("1", "missing", `{}`),
+		schema.AssistantMessage("sorry", nil),
+	}}
+	result, err := RunAgent(context.Background(), chatModel, []*schema.Message{schema.UserMessage("x")}, nil, AgentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ToolCalls[0].Status != models.ToolCallStatusError || !strings.HasPrefix(chatModel.calls[1][2].Content, "错误: ") {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"unicode"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// messageOverheadTokens 每条消息的角色和分隔符大约占用的 token 数
+const messageOverheadTokens = 4
+
+// EstimateTokens 估算文本的 token 数，不依赖具体模型的分词器
+// 中日韩字符按每个字 1 个 token 计算，其他字符按每 4 个字节 1 个 token 计算
+func EstimateTokens(text string) int {
+	tokens, other := 0, 0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			tokens++
+			continue
+		}
+		if r < 0x80 {
+			other++
+		} else {
+			other += 2
+		}
+	}
+	return tokens + (other+3)/4
+}
+
+// MessageTokens 估算一条消息的 token 数（含固定开销）
+func MessageTokens(message *schema.Message) int {
+	return EstimateTokens(message.Content) + messageOverheadTokens
+}
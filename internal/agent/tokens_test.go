@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"hello world", 3},
+		{"你好世界", 4},
+		{"剪切板 clipboard", 3 + 3},
+		{"é", 1},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+
+	if got := MessageTokens(schema.UserMessage(strings.Repeat("a", 400))); got != 100+messageOverheadTokens {
+		t.Errorf("MessageTokens = %d", got)
+	}
+}
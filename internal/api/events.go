@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// eventBuffer 每个 SSE 连接的事件缓冲区大小，写满后丢弃新事件
+	eventBuffer = 256
+	// heartbeatInterval SSE 心跳间隔，防止空闲连接被代理或客户端断开
+	heartbeatInterval = 30 * time.Second
+)
+
+// events GET /events?events=item:created,tags:changed
+// 以 Server-Sent Events 推送事件总线上的事件，未指定 events 时推送全部事件
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	if s.services.Events == nil {
+		writeError(w, &Error{Status: http.StatusServiceUnavailable, Code: "unavailable", Message: "事件推送未启用"})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, &Error{Status: http.StatusInternalServerError, Code: "internal_error", Message: "连接不支持流式响应"})
+		return
+	}
+
+	filter := make(map[string]bool)
+	for _, name := range strings.Split(r.URL.Query().Get("events"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter[name] = true
+		}
+	}
+
+	events, unsubscribe := s.services.Events.Subscribe(eventBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	ctx := s.streamContext(r)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if len(filter) > 0 && !filter[event.Name] {
+				continue
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				log.Printf("❌ 序列化事件失败: %s: %v", event.Name, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// streamContext 请求断开或服务停止时结束的上下文，保证 Stop 不会被长连接阻塞
+func (s *server) streamContext(r *http.Request) context.Context {
+	s.mu.Lock()
+	stopping := s.stopping
+	s.mu.Unlock()
+	if stopping == nil {
+		return r.Context()
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	go func() {
+		defer cancel()
+		select {
+		case <-stopping:
+		case <-ctx.Done():
+		}
+	}()
+	return ctx
+}
@@ -0,0 +1,796 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/service"
+)
+
+// defaultLimit 列表接口默认返回的条目数
+const defaultLimit = 50
+
+// routes 注册 /api/v1 路由
+func (s *server) routes() {
+	s.mux.HandleFunc("GET /api/v1/items", s.listItems)
+	s.mux.HandleFunc("POST /api/v1/items", s.createItem)
+	s.mux.HandleFunc("GET /api/v1/items/{id}", s.getItem)
+	s.mux.HandleFunc("PATCH /api/v1/items/{id}", s.updateItem)
+	s.mux.HandleFunc("DELETE /api/v1/items/{id}", s.deleteItem)
+	s.mux.HandleFunc("POST /api/v1/items/{id}/restore", s.restoreItem)
+	s.mux.HandleFunc("POST /api/v1/items/{id}/use", s.useItem)
+	s.mux.HandleFunc("GET /api/v1/items/{id}/tags", s.getItemTags)
+	s.mux.HandleFunc("POST /api/v1/items/{id}/tags", s.addItemTags)
+	s.mux.HandleFunc("DELETE /api/v1/items/{id}/tags/{name}", s.removeItemTag)
+
+	s.mux.HandleFunc("GET /api/v1/search", s.search)
+	s.mux.HandleFunc("POST /api/v1/search", s.search)
+
+	s.mux.HandleFunc("GET /api/v1/tags", s.listTags)
+	s.mux.HandleFunc("POST /api/v1/tags", s.createTag)
+	s.mux.HandleFunc("PUT /api/v1/tags/{id}", s.updateTag)
+	s.mux.HandleFunc("DELETE /api/v1/tags/{id}", s.deleteTag)
+
+	s.mux.HandleFunc("GET /api/v1/tag-groups", s.listTagGroups)
+	s.mux.HandleFunc("POST /api/v1/tag-groups", s.createTagGroup)
+	s.mux.HandleFunc("PUT /api/v1/tag-groups/{id}", s.updateTagGroup)
+	s.mux.HandleFunc("DELETE /api/v1/tag-groups/{id}", s.deleteTagGroup)
+
+	s.mux.HandleFunc("GET /api/v1/sessions", s.listSessions)
+	s.mux.HandleFunc("POST /api/v1/sessions", s.createSession)
+	s.mux.HandleFunc("GET /api/v1/sessions/{id}", s.getSession)
+	s.mux.HandleFunc("PATCH /api/v1/sessions/{id}", s.updateSession)
+	s.mux.HandleFunc("DELETE /api/v1/sessions/{id}", s.deleteSession)
+	s.mux.HandleFunc("GET /api/v1/sessions/{id}/messages", s.listMessages)
+	s.mux.HandleFunc("POST /api/v1/sessions/{id}/messages", s.sendMessage)
+	s.mux.HandleFunc("POST /api/v1/tool-calls/{id}/confirm", s.confirmToolCall)
+
+	s.mux.HandleFunc("GET /api/v1/stats", s.stats)
+	s.mux.HandleFunc("GET /api/v1/events", s.events)
+
+	s.mux.HandleFunc("/", s.fallback)
+}
+
+// fallback 未匹配的请求：路径存在但方法不支持时返回 405，否则返回 404
+func (s *server) fallback(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := s.mux.Handler(probe); pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) == 0 {
+		writeError(w, notFound("未知的接口: %s", r.URL.Path))
+		return
+	}
+	for _, method := range allowed {
+		w.Header().Add("Allow", method)
+	}
+	writeError(w, &Error{Status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Message: "不支持的请求方法: " + r.Method})
+}
+
+// === 条目 ===
+
+// listItems GET /items?limit=&offset=&trash=true
+func (s *server) listItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset, err := pagination(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	trash, err := boolParam(query, "trash")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var items []models.ClipboardItem
+	if trash {
+		items, err = s.services.Clipboard.GetTrashItems(limit, offset)
+	} else {
+		items, err = s.services.Clipboard.GetItems(limit, offset)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNil(items))
+}
+
+// createItem POST /items {"content": "..."}，经过与剪切板监听相同的分类和敏感内容检测
+func (s *server) createItem(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Content string `json:"content"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Content == "" {
+		writeError(w, badRequest("content 不能为空"))
+		return
+	}
+	item, err := s.services.Clipboard.CreateItem(body.Content)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// getItem GET /items/{id}
+func (s *server) getItem(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// updateItem PATCH /items/{id}，只更新请求中出现的字段
+func (s *server) updateItem(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Title      *string `json:"title"`
+		Category   *string `json:"category"`
+		IsFavorite *bool   `json:"is_favorite"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Title != nil {
+		item.Title = *body.Title
+	}
+	if body.Category != nil {
+		item.Category = *body.Category
+	}
+	if body.IsFavorite != nil {
+		item.IsFavorite = *body.IsFavorite
+	}
+	item.UpdatedAt = time.Now()
+	if err := s.services.Clipboard.UpdateItem(*item); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// deleteItem DELETE /items/{id}，默认移到回收站，?permanent=true 时永久删除
+func (s *server) deleteItem(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	permanent, err := boolParam(r.URL.Query(), "permanent")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if permanent {
+		err = s.services.Clipboard.PermanentDeleteItem(item.ID)
+	} else {
+		err = s.services.Clipboard.DeleteItem(item.ID)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// restoreItem POST /items/{id}/restore 从回收站恢复
+func (s *server) restoreItem(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Clipboard.RestoreItem(item.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.getItem(w, r)
+}
+
+// useItem POST /items/{id}/use 复制到系统剪切板并记录使用
+func (s *server) useItem(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Clipboard.UseItem(item.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getItemTags GET /items/{id}/tags
+func (s *server) getItemTags(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNil(item.Tags))
+}
+
+// addItemTags POST /items/{id}/tags {"tags": ["..."]}，标签不存在时自动创建，返回条目的全部标签
+func (s *server) addItemTags(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(body.Tags) == 0 {
+		writeError(w, badRequest("tags 不能为空"))
+		return
+	}
+	for _, name := range body.Tags {
+		if err := s.services.Tags.ValidateTagName(name); err != nil {
+			writeError(w, badRequest("%v", err))
+			return
+		}
+		if _, err := s.services.Tags.ImportTag(models.Tag{Name: name}); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	if err := s.services.Tags.AddTagsToItem(item.ID, body.Tags); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.getItemTags(w, r)
+}
+
+// removeItemTag DELETE /items/{id}/tags/{name}
+func (s *server) removeItemTag(w http.ResponseWriter, r *http.Request) {
+	item, err := s.item(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Tags.RemoveTagsFromItem(item.ID, []string{r.PathValue("name")}); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// item 读取路径中的条目
+func (s *server) item(r *http.Request) (*models.ClipboardItem, error) {
+	id := r.PathValue("id")
+	item, err := s.services.Clipboard.GetItem(id)
+	if err != nil {
+		if apiErr := toAPIError(err); apiErr.Status == http.StatusNotFound {
+			return nil, notFound("条目不存在: %s", id)
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// === 搜索 ===
+
+// search GET /search?q=&mode=&category=&tag=&tag_mode=&untagged=&after=&before=&limit=&offset=
+// 或 POST /search，请求体为 SearchQuery
+func (s *server) search(w http.ResponseWriter, r *http.Request) {
+	var query models.SearchQuery
+	if r.Method == http.MethodPost {
+		if err := decodeJSON(r, &query); err != nil {
+			writeError(w, err)
+			return
+		}
+	} else {
+		var err error
+		if query, err = searchQuery(r.URL.Query()); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	switch query.TagMode {
+	case "", "all", "any", "none":
+	default:
+		writeError(w, badRequest("不支持的标签匹配方式: %s", query.TagMode))
+		return
+	}
+	switch query.Mode {
+	case "", models.SearchModeKeyword, models.SearchModeSemantic, models.SearchModeHybrid:
+	default:
+		writeError(w, badRequest("不支持的搜索模式: %s", query.Mode))
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+
+	result, err := s.services.Clipboard.SearchItems(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	result.Items = nonNil(result.Items)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// searchQuery 将查询参数转换为 SearchQuery
+func searchQuery(values url.Values) (models.SearchQuery, error) {
+	query := models.SearchQuery{
+		Query:    values.Get("q"),
+		Category: values.Get("category"),
+		Tags:     values["tag"],
+		TagMode:  values.Get("tag_mode"),
+		Mode:     values.Get("mode"),
+
+		SourceApp: values.Get("app"),
+		Language:  values.Get("language"),
+	}
+	var err error
+	if query.Limit, query.Offset, err = pagination(values); err != nil {
+		return query, err
+	}
+	if query.Untagged, err = boolParam(values, "untagged"); err != nil {
+		return query, err
+	}
+	if query.CreatedAfter, err = timeParam(values, "after"); err != nil {
+		return query, err
+	}
+	if query.CreatedBefore, err = timeParam(values, "before"); err != nil {
+		return query, err
+	}
+	if query.MinLength, err = intParam(values, "min_length", 0); err != nil {
+		return query, err
+	}
+	if query.MaxLength, err = intParam(values, "max_length", 0); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// === 标签 ===
+
+// listTags GET /tags?group=
+func (s *server) listTags(w http.ResponseWriter, r *http.Request) {
+	var tags []models.Tag
+	var err error
+	if group := r.URL.Query().Get("group"); group != "" {
+		tags, err = s.services.Tags.GetTagsByGroup(group)
+	} else {
+		tags, err = s.services.Tags.GetTags()
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNil(tags))
+}
+
+// createTag POST /tags
+func (s *server) createTag(w http.ResponseWriter, r *http.Request) {
+	var body models.Tag
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Tags.ValidateTagName(body.Name); err != nil {
+		writeError(w, badRequest("%v", err))
+		return
+	}
+	if body.GroupID == "" {
+		writeError(w, badRequest("group_id 不能为空"))
+		return
+	}
+	tag, err := s.services.Tags.CreateTag(body.Name, body.Description, body.Color, body.GroupID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+// updateTag PUT /tags/{id}，更新名称、描述、颜色和分组
+func (s *server) updateTag(w http.ResponseWriter, r *http.Request) {
+	tag, err := s.tag(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body models.Tag
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Tags.ValidateTagName(body.Name); err != nil {
+		writeError(w, badRequest("%v", err))
+		return
+	}
+	body.ID = tag.ID
+	if err := s.services.Tags.UpdateTag(body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if tag, err = s.tag(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tag)
+}
+
+// deleteTag DELETE /tags/{id}
+func (s *server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	tag, err := s.tag(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Tags.DeleteTag(tag.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tag 读取路径中的标签
+func (s *server) tag(r *http.Request) (*models.Tag, error) {
+	id := r.PathValue("id")
+	tags, err := s.services.Tags.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tags {
+		if tags[i].ID == id {
+			return &tags[i], nil
+		}
+	}
+	return nil, notFound("标签不存在: %s", id)
+}
+
+// === 标签分组 ===
+
+// listTagGroups GET /tag-groups
+func (s *server) listTagGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := s.services.Tags.GetTagGroups()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNil(groups))
+}
+
+// createTagGroup POST /tag-groups
+func (s *server) createTagGroup(w http.ResponseWriter, r *http.Request) {
+	var body models.TagGroup
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Name == "" {
+		writeError(w, badRequest("name 不能为空"))
+		return
+	}
+	group, err := s.services.Tags.CreateTagGroup(body.Name, body.Description, body.Color, body.SortOrder)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, group)
+}
+
+// updateTagGroup PUT /tag-groups/{id}，更新名称、描述、颜色和排序
+func (s *server) updateTagGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := s.tagGroup(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body models.TagGroup
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Name == "" {
+		writeError(w, badRequest("name 不能为空"))
+		return
+	}
+	body.ID = group.ID
+	if err := s.services.Tags.UpdateTagGroup(body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if group, err = s.tagGroup(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+// deleteTagGroup DELETE /tag-groups/{id}
+func (s *server) deleteTagGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := s.tagGroup(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Tags.DeleteTagGroup(group.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tagGroup 读取路径中的标签分组
+func (s *server) tagGroup(r *http.Request) (*models.TagGroup, error) {
+	id := r.PathValue("id")
+	groups, err := s.services.Tags.GetTagGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].ID == id {
+			return &groups[i], nil
+		}
+	}
+	return nil, notFound("标签分组不存在: %s", id)
+}
+
+// === 聊天会话 ===
+
+// listSessions GET /sessions
+func (s *server) listSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.services.Chat.ListSessions(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sessions.Sessions = nonNil(sessions.Sessions)
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// createSession POST /sessions {"title": "..."}
+func (s *server) createSession(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title string `json:"title"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	session, err := s.services.Chat.CreateSession(r.Context(), body.Title)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// getSession GET /sessions/{id}
+func (s *server) getSession(w http.ResponseWriter, r *http.Request) {
+	session, err := s.session(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// updateSession PATCH /sessions/{id} {"title": "..."}
+func (s *server) updateSession(w http.ResponseWriter, r *http.Request) {
+	session, err := s.session(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Title string `json:"title"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Title == "" {
+		writeError(w, badRequest("title 不能为空"))
+		return
+	}
+	if err := s.services.Chat.UpdateSession(r.Context(), session.ID, body.Title); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.getSession(w, r)
+}
+
+// deleteSession DELETE /sessions/{id}
+func (s *server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	session, err := s.session(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.services.Chat.DeleteSession(r.Context(), session.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listMessages GET /sessions/{id}/messages?limit=&offset=
+func (s *server) listMessages(w http.ResponseWriter, r *http.Request) {
+	session, err := s.session(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	limit, offset, err := pagination(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	messages, err := s.services.Chat.GetMessages(r.Context(), session.ID, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	messages.Messages = nonNil(messages.Messages)
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// sendMessage POST /sessions/{id}/messages {"message": "..."}，等待大模型回复后返回助手消息
+func (s *server) sendMessage(w http.ResponseWriter, r *http.Request) {
+	session, err := s.session(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.Message == "" {
+		writeError(w, badRequest("message 不能为空"))
+		return
+	}
+	reply, err := s.services.Chat.SendMessage(r.Context(), session.ID, body.Message)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, reply)
+}
+
+// confirmToolCall POST /tool-calls/{id}/confirm {"approved": true}，确认或拒绝聊天中等待确认的工具调用
+// id 为 tool_confirm 事件中的 confirmation_id
+func (s *server) confirmToolCall(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Approved bool `json:"approved"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	id := r.PathValue("id")
+	if err := s.services.Chat.ConfirmToolCall(id, body.Approved); err != nil {
+		if errors.Is(err, service.ErrToolCallNotPending) {
+			err = notFound("工具调用不存在或已不再等待确认: %s", id)
+		}
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// session 读取路径中的聊天会话
+func (s *server) session(r *http.Request) (*models.ChatSession, error) {
+	id := r.PathValue("id")
+	session, err := s.services.Chat.GetSession(r.Context(), id)
+	if err != nil {
+		if apiErr := toAPIError(err); apiErr.Status == http.StatusNotFound {
+			return nil, notFound("会话不存在: %s", id)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// === 统计 ===
+
+// stats GET /stats
+func (s *server) stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.services.Clipboard.GetStatistics()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// === 参数解析 ===
+
+// pagination 解析 limit 和 offset
+func pagination(values url.Values) (int, int, error) {
+	limit, err := intParam(values, "limit", defaultLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := intParam(values, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit <= 0 || offset < 0 {
+		return 0, 0, badRequest("limit 必须大于 0，offset 不能为负数")
+	}
+	return limit, offset, nil
+}
+
+// intParam 解析整数参数
+func intParam(values url.Values, name string, fallback int) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badRequest("%s 必须是整数", name)
+	}
+	return n, nil
+}
+
+// boolParam 解析布尔参数
+func boolParam(values url.Values, name string) (bool, error) {
+	value := values.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, badRequest("%s 必须是布尔值", name)
+	}
+	return b, nil
+}
+
+// timeParam 解析 RFC3339 时间或日期（本地时间）
+func timeParam(values url.Values, name string) (*time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, badRequest("%s 必须是 RFC3339 时间或 2006-01-02 格式的日期", name)
+	}
+	return &t, nil
+}
+
+// nonNil 空列表序列化为 [] 而不是 null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+	"Sid/internal/service"
+)
+
+// maxBodyBytes 请求体大小上限
+const maxBodyBytes = 10 << 20
+
+// Services API 使用的服务，与 GUI 共用同一组实例
+type Services struct {
+	Clipboard service.ClipboardService
+	Tags      service.TagService
+	Chat      service.ChatService
+	Events    service.EventBus
+}
+
+// Server 本地 HTTP/JSON API，只监听回环地址，所有请求都需要 Bearer 令牌
+type Server interface {
+	Handler() http.Handler
+	Apply(settings models.APISettings) error
+	Addr() string
+	SetToken(token string)
+	Stop(ctx context.Context) error
+}
+
+// server API 服务实现
+type server struct {
+	services Services
+	mux      *http.ServeMux
+
+	mu       sync.Mutex
+	token    string
+	http     *http.Server
+	listener net.Listener
+	port     int
+	stopping chan struct{} // Stop 时关闭，用于结束 SSE 长连接
+}
+
+// NewServer 创建新的 API 服务，调用 Apply 后才开始监听
+func NewServer(services Services, token string) Server {
+	s := &server{services: services, token: token, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+// Handler 带令牌校验的请求处理器
+func (s *server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sid"`)
+			writeError(w, &Error{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "缺少或无效的访问令牌"})
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		}
+		s.mux.ServeHTTP(w, r)
+	})
+}
+
+// authorized 校验 Authorization: Bearer 令牌
+func (s *server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// SetToken 更换访问令牌，旧令牌立即失效
+func (s *server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// Apply 按配置启动、停止或在端口变化时重启监听
+func (s *server) Apply(settings models.APISettings) error {
+	s.mu.Lock()
+	running, port := s.http != nil, s.port
+	s.mu.Unlock()
+
+	if running && (!settings.Enabled || settings.Port != port) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.Stop(ctx); err != nil {
+			return err
+		}
+		running = false
+	}
+	if !settings.Enabled || running {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", settings.Port))
+	if err != nil {
+		return fmt.Errorf("本地 API 监听失败: %w", err)
+	}
+	httpServer := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	s.mu.Lock()
+	s.http, s.listener, s.port = httpServer, listener, settings.Port
+	s.stopping = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ 本地 API 服务异常退出: %v", err)
+		}
+	}()
+	log.Printf("✅ 本地 API 已启动: http://%s/api/v1", listener.Addr())
+	return nil
+}
+
+// Addr 当前监听地址，未启动时返回空字符串
+func (s *server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop 停止监听并等待进行中的请求结束
+func (s *server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	httpServer, stopping := s.http, s.stopping
+	s.http, s.listener, s.port, s.stopping = nil, nil, 0, nil
+	s.mu.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
+	close(stopping)
+	log.Println("🛑 本地 API 已停止")
+	return httpServer.Shutdown(ctx)
+}
+
+// Error API 错误，序列化为 {"error": {"code": ..., "message": ...}}
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// badRequest 请求参数错误
+func badRequest(format string, args ...interface{}) error {
+	return &Error{Status: http.StatusBadRequest, Code: "invalid_request", Message: fmt.Sprintf(format, args...)}
+}
+
+// notFound 资源不存在
+func notFound(format string, args ...interface{}) error {
+	return &Error{Status: http.StatusNotFound, Code: "not_found", Message: fmt.Sprintf(format, args...)}
+}
+
+// toAPIError 将服务层错误转换为带状态码的 API 错误
+func toAPIError(err error) *Error {
+	var apiErr *Error
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{Status: http.StatusNotFound, Code: "not_found", Message: "资源不存在"}
+	case errors.Is(err, repository.ErrDatabaseLocked):
+		return &Error{Status: http.StatusLocked, Code: "database_locked", Message: err.Error()}
+	case errors.As(err, &maxBytes):
+		return &Error{Status: http.StatusRequestEntityTooLarge, Code: "too_large", Message: "请求体过大"}
+	default:
+		return &Error{Status: http.StatusInternalServerError, Code: "internal_error", Message: err.Error()}
+	}
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("❌ 写入 API 响应失败: %v", err)
+	}
+}
+
+// writeError 输出 JSON 错误
+func writeError(w http.ResponseWriter, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("❌ API 请求失败: %v", err)
+	}
+	writeJSON(w, apiErr.Status, map[string]*Error{"error": apiErr})
+}
+
+// decodeJSON 解析请求体，未知字段视为错误
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			return err
+		}
+		return badRequest("无效的请求体: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+	"Sid/internal/service"
+)
+
+const testToken = "test-token"
+
+// newTestServer 创建使用真实数据库和服务层的 API
+func newTestServer(t *testing.T) (Server, *httptest.Server) {
+	t.Helper()
+	db, err := repository.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	settings := models.DefaultSettings()
+	settings.AutoTag = false
+	clipboardRepo := repository.NewClipboardRepository(db.DB, db.Cipher())
+	chatService := service.NewChatService(repository.NewChatRepository(db.DB, db.Cipher()), nil)
+	tagService := service.NewTagService(repository.NewTagRepository(db.DB), clipboardRepo, nil)
+	tagging := service.NewTaggingService(repository.NewTaggingRepository(db.DB), clipboardRepo, service.DefaultTaggingOptions())
+	clipboardService := service.NewClipboardService(clipboardRepo, &settings, chatService, tagService, tagging)
+	events := service.NewEventBus()
+	clipboardService.SetEventEmitter(events.Publish)
+	tagService.SetEventEmitter(events.Publish)
+
+	server := NewServer(Services{Clipboard: clipboardService, Tags: tagService, Chat: chatService, Events: events}, testToken)
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+// call 发送带令牌的请求并解析 JSON 响应
+func call(t *testing.T, ts *httptest.Server, method, path, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: invalid json %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// errorBody API 错误响应
+type errorBody struct {
+	Error Error `json:"error"`
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	server, ts := newTestServer(t)
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/items", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body errorBody
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || body.Error.Code != "unauthorized" {
+			t.Errorf("header %q: expected 401 json error, got %d %+v", header, resp.StatusCode, body)
+		}
+	}
+
+	// 更换令牌后旧令牌失效
+	server.SetToken("rotated")
+	if status := call(t, ts, http.MethodGet, "/api/v1/items", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("expected old token to be rejected, got %d", status)
+	}
+}
+
+func TestServer_Items(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var item models.ClipboardItem
+	if status := call(t, ts, http.MethodPost, "/api/v1/items", `{"content":"kubectl get pods -A"}`, &item); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	path := "/api/v1/items/" + item.ID
+
+	var updated models.ClipboardItem
+	if status := call(t, ts, http.MethodPatch, path, `{"is_favorite":true,"title":"pods"}`, &updated); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if !updated.IsFavorite || updated.Title != "pods" || updated.Content != "kubectl get pods -A" {
+		t.Errorf("unexpected updated item %+v", updated)
+	}
+
+	var tags []models.Tag
+	if status := call(t, ts, http.MethodPost, path+"/tags", `{"tags":["k8s","运维"]}`, &tags); status != http.StatusOK || len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %d %+v", status, tags)
+	}
+
+	var result models.SearchResult
+	if status := call(t, ts, http.MethodGet, "/api/v1/search?q=kubectl&tag=k8s&tag=%E8%BF%90%E7%BB%B4&tag_mode=all", "", &result); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if result.Total != 1 || result.Items[0].ID != item.ID {
+		t.Errorf("unexpected search result %+v", result)
+	}
+	if status := call(t, ts, http.MethodPost, "/api/v1/search", `{"tags":["k8s"],"tag_mode":"none"}`, &result); status != http.StatusOK || result.Total != 0 {
+		t.Errorf("expected no results for tag_mode none, got %d %+v", status, result)
+	}
+
+	if status := call(t, ts, http.MethodDelete, path+"/tags/k8s", "", nil); status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	if status := call(t, ts, http.MethodDelete, path, "", nil); status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	var items []models.ClipboardItem
+	call(t, ts, http.MethodGet, "/api/v1/items", "", &items)
+	if len(items) != 0 {
+		t.Errorf("expected trashed item to be hidden, got %d items", len(items))
+	}
+	call(t, ts, http.MethodGet, "/api/v1/items?trash=true", "", &items)
+	if len(items) != 1 || len(items[0].Tags) != 1 {
+		t.Errorf("expected trashed item with one tag, got %+v", items)
+	}
+
+	var restored models.ClipboardItem
+	if status := call(t, ts, http.MethodPost, path+"/restore", "", &restored); status != http.StatusOK || restored.IsDeleted {
+		t.Errorf("expected restored item, got %d %+v", status, restored)
+	}
+
+	var stats models.Statistics
+	if status := call(t, ts, http.MethodGet, "/api/v1/stats", "", &stats); status != http.StatusOK || stats.TotalItems != 1 {
+		t.Errorf("unexpected stats %d %+v", status, stats)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{http.MethodGet, "/api/v1/items/missing", "", http.StatusNotFound, "not_found"},
+		{http.MethodGet, "/api/v1/nothing", "", http.StatusNotFound, "not_found"},
+		{http.MethodPut, "/api/v1/items", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{http.MethodPost, "/api/v1/items", `{"content":`, http.StatusBadRequest, "invalid_request"},
+		{http.MethodPost, "/api/v1/items", `{"text":"x"}`, http.StatusBadRequest, "invalid_request"},
+		{http.MethodPost, "/api/v1/items", `{"content":""}`, http.StatusBadRequest, "invalid_request"},
+		{http.MethodGet, "/api/v1/items?limit=abc", "", http.StatusBadRequest, "invalid_request"},
+		{http.MethodGet, "/api/v1/search?after=yesterday", "", http.StatusBadRequest, "invalid_request"},
+		{http.MethodGet, "/api/v1/search?tag_mode=some", "", http.StatusBadRequest, "invalid_request"},
+		{http.MethodGet, "/api/v1/search?mode=fuzzy", "", http.StatusBadRequest, "invalid_request"},
+		{http.MethodGet, "/api/v1/sessions/missing/messages", "", http.StatusNotFound, "not_found"},
+		{http.MethodPut, "/api/v1/tags/missing", `{"name":"x"}`, http.StatusNotFound, "not_found"},
+	}
+	for _, tt := range tests {
+		var body errorBody
+		status := call(t, ts, tt.method, tt.path, tt.body, &body)
+		if status != tt.status || body.Error.Code != tt.code || body.Error.Message == "" {
+			t.Errorf("%s %s: expected %d %s, got %d %+v", tt.method, tt.path, tt.status, tt.code, status, body)
+		}
+	}
+}
+
+func TestServer_TagsAndSessions(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var group models.TagGroup
+	if status := call(t, ts, http.MethodPost, "/api/v1/tag-groups", `{"name":"语言","color":"#123456"}`, &group); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	var tag models.Tag
+	body := `{"name":"go","color":"#00add8","group_id":"` + group.ID + `"}`
+	if status := call(t, ts, http.MethodPost, "/api/v1/tags", body, &tag); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	var renamed models.Tag
+	body = `{"name":"golang","color":"#00add8","group_id":"` + group.ID + `"}`
+	if status := call(t, ts, http.MethodPut, "/api/v1/tags/"+tag.ID, body, &renamed); status != http.StatusOK || renamed.Name != "golang" {
+		t.Errorf("unexpected renamed tag %d %+v", status, renamed)
+	}
+	var tags []models.Tag
+	call(t, ts, http.MethodGet, "/api/v1/tags?group="+group.ID, "", &tags)
+	if len(tags) != 1 || tags[0].Name != "golang" {
+		t.Errorf("unexpected tags in group %+v", tags)
+	}
+
+	var session models.ChatSession
+	if status := call(t, ts, http.MethodPost, "/api/v1/sessions", `{"title":"会话"}`, &session); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	var messages models.ChatMessageListResponse
+	if status := call(t, ts, http.MethodGet, "/api/v1/sessions/"+session.ID+"/messages", "", &messages); status != http.StatusOK || messages.Messages == nil {
+		t.Errorf("expected empty message list, got %d %+v", status, messages)
+	}
+	var sessions models.ChatSessionListResponse
+	call(t, ts, http.MethodGet, "/api/v1/sessions", "", &sessions)
+	if sessions.Total != 1 {
+		t.Errorf("expected one session, got %+v", sessions)
+	}
+	if status := call(t, ts, http.MethodDelete, "/api/v1/sessions/"+session.ID, "", nil); status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+}
+
+func TestServer_Apply(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Apply(models.APISettings{})
+
+	if err := server.Apply(models.APISettings{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	addr := server.Addr()
+	if !strings.HasPrefix(addr, "127.0.0.1:") {
+		t.Fatalf("expected loopback listener, got %q", addr)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/api/v1/stats", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(buf.String(), "total_items") {
+		t.Errorf("unexpected response %d %s", resp.StatusCode, buf.String())
+	}
+
+	if err := server.Apply(models.APISettings{Enabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	if server.Addr() != "" {
+		t.Error("expected listener to be closed")
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/events?events=item:created,tags:changed", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content-type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// 按 SSE 格式逐条读取事件
+	type sseEvent struct{ name, data string }
+	received := make(chan sseEvent, 10)
+	go func() {
+		var current sseEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				current.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				current.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && current.name != "":
+				received <- current
+				current = sseEvent{}
+			}
+		}
+	}()
+	next := func() sseEvent {
+		t.Helper()
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return sseEvent{}
+		}
+	}
+
+	var item models.ClipboardItem
+	if status := call(t, ts, http.MethodPost, "/api/v1/items", `{"content":"live update"}`, &item); status != http.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	// 删除事件不在订阅列表中，不应推送
+	if status := call(t, ts, http.MethodDelete, "/api/v1/items/"+item.ID, "", nil); status != http.StatusNoContent {
+		t.Fatalf("delete status = %d", status)
+	}
+	call(t, ts, http.MethodPost, "/api/v1/items/"+item.ID+"/restore", "", nil)
+	if status := call(t, ts, http.MethodPost, "/api/v1/tags", `{"name":"实时","group_id":"ai-generated"}`, nil); status != http.StatusCreated {
+		t.Fatalf("create tag status = %d", status)
+	}
+
+	event := next()
+	var created models.ClipboardItem
+	if err := json.Unmarshal([]byte(event.data), &created); err != nil || event.name != "item:created" || created.ID != item.ID {
+		t.Fatalf("first event = %+v (%v)", event, err)
+	}
+	if event = next(); event.name != "tags:changed" {
+		t.Fatalf("second event = %+v", event)
+	}
+}
@@ -0,0 +1,504 @@
+package clipboard
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// detectSampleSize 按行统计特征时最多检查的字节数，超长内容只看开头部分
+const detectSampleSize = 64 * 1024
+
+// ContentDetection 内容类型检测结果
+type ContentDetection struct {
+	Type         string // models.ContentType*
+	CodeLanguage string // Type 为 code 时猜测的编程语言，无法判断时为空
+}
+
+// contentDetector 按顺序尝试的内容类型检测器，先匹配的优先
+type contentDetector struct {
+	contentType string
+	match       func(d *detectInput) bool
+}
+
+// detectInput 检测用的预处理内容
+type detectInput struct {
+	text   string   // 去除首尾空白后的完整内容
+	sample string   // text 的前 detectSampleSize 字节
+	lines  []string // sample 中的非空行
+}
+
+// singleLine 是否为较短的单行内容，值类型（颜色、UUID 等）只在这种情况下检测
+func (d *detectInput) singleLine() bool {
+	return len(d.lines) == 1 && len(d.text) <= 200
+}
+
+// contentDetectors 结构化格式在前，代码和 Markdown 这类按特征打分的检测在后
+var contentDetectors = []contentDetector{
+	{models.ContentTypeUUID, isUUID},
+	{models.ContentTypeColor, isHexColor},
+	{models.ContentTypeIP, isIP},
+	{models.ContentTypeURL, isURL},
+	{models.ContentTypeEmail, isEmail},
+	{models.ContentTypeCoordinates, isCoordinates},
+	{models.ContentTypeDate, isDate},
+	{models.ContentTypePhone, isPhone},
+	{models.ContentTypeJSON, isJSON},
+	{models.ContentTypeHTML, isHTML},
+	{models.ContentTypeXML, isXML},
+	{models.ContentTypeStackTrace, isStackTrace},
+	{models.ContentTypeSQL, isSQL},
+	{models.ContentTypeShell, isShell},
+	{models.ContentTypeYAML, isYAML},
+	{models.ContentTypeMarkdown, isMarkdown},
+}
+
+// DetectContentType 检测文本内容的类型，无法识别时返回 text
+func DetectContentType(content string) ContentDetection {
+	text := strings.TrimSpace(content)
+	if text == "" {
+		return ContentDetection{Type: models.ContentTypeText}
+	}
+
+	sample := text
+	if len(sample) > detectSampleSize {
+		sample = sample[:detectSampleSize]
+	}
+	d := &detectInput{text: text, sample: sample}
+	for _, line := range strings.Split(sample, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			d.lines = append(d.lines, line)
+		}
+	}
+
+	for _, detector := range contentDetectors {
+		if detector.match(d) {
+			return ContentDetection{Type: detector.contentType}
+		}
+	}
+	if language, ok := detectCode(d); ok {
+		return ContentDetection{Type: models.ContentTypeCode, CodeLanguage: language}
+	}
+	return ContentDetection{Type: models.ContentTypeText}
+}
+
+// FillContentType 检测文本条目的内容类型，图片条目不做处理
+func FillContentType(item *models.ClipboardItem) {
+	if item.IsImage() {
+		return
+	}
+	detection := DetectContentType(item.Content)
+	item.ContentType = detection.Type
+	item.CodeLanguage = detection.CodeLanguage
+}
+
+// CategoryForContentType 根据内容类型推导分类，普通文本返回空字符串，由分析器按原有规则分类
+func CategoryForContentType(contentType string) string {
+	switch contentType {
+	case models.ContentTypeURL:
+		return models.CategoryURL
+	case models.ContentTypeEmail:
+		return models.CategoryEmail
+	case models.ContentTypeJSON, models.ContentTypeYAML, models.ContentTypeXML, models.ContentTypeUUID:
+		return models.CategoryData
+	case models.ContentTypeHTML, models.ContentTypeSQL, models.ContentTypeShell, models.ContentTypeStackTrace, models.ContentTypeCode:
+		return models.CategoryCode
+	case models.ContentTypeColor:
+		return models.CategoryColor
+	case models.ContentTypeIP, models.ContentTypePhone, models.ContentTypeDate, models.ContentTypeCoordinates:
+		return models.CategoryNumber
+	case models.ContentTypeMarkdown:
+		return models.CategoryText
+	case models.ContentTypeImage:
+		return models.CategoryImage
+	default:
+		return ""
+	}
+}
+
+var (
+	uuidPattern        = regexp.MustCompile(`^\{?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\}?$`)
+	hexColorPattern    = regexp.MustCompile(`^#(?:[0-9a-fA-F]{6}|[0-9a-fA-F]{8}|[0-9a-fA-F]{3,4})$`)
+	urlPattern         = regexp.MustCompile(`^(?:https?://|www\.)\S+$`)
+	emailPattern       = regexp.MustCompile(`^[\w.%+-]+@[\w-]+(?:\.[\w-]+)*\.[a-zA-Z]{2,}$`)
+	coordinatesPattern = regexp.MustCompile(`^\(?([-+]?\d{1,2}\.\d+)°?\s*[,，]\s*([-+]?\d{1,3}\.\d+)°?\)?$`)
+	phonePattern       = regexp.MustCompile(`^[+(\d][\d\s().-]{6,19}$`)
+	chinaMobilePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+)
+
+func isUUID(d *detectInput) bool {
+	return d.singleLine() && uuidPattern.MatchString(d.text)
+}
+
+// isHexColor 三位和四位的简写必须包含字母，避免把 #123 这样的编号识别为颜色
+func isHexColor(d *detectInput) bool {
+	if !d.singleLine() || !hexColorPattern.MatchString(d.text) {
+		return false
+	}
+	return len(d.text) > 5 || strings.ContainsAny(strings.ToLower(d.text), "abcdef")
+}
+
+func isIP(d *detectInput) bool {
+	if !d.singleLine() {
+		return false
+	}
+	if _, _, err := net.ParseCIDR(d.text); err == nil {
+		return true
+	}
+	return net.ParseIP(d.text) != nil
+}
+
+func isURL(d *detectInput) bool {
+	return len(d.lines) == 1 && urlPattern.MatchString(d.text)
+}
+
+func isEmail(d *detectInput) bool {
+	return d.singleLine() && emailPattern.MatchString(d.text)
+}
+
+func isCoordinates(d *detectInput) bool {
+	if !d.singleLine() {
+		return false
+	}
+	match := coordinatesPattern.FindStringSubmatch(d.text)
+	if match == nil {
+		return false
+	}
+	lat, _ := strconv.ParseFloat(match[1], 64)
+	lon, _ := strconv.ParseFloat(match[2], 64)
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// dateLayouts 识别的日期和时间格式
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"2006/01/02 15:04:05",
+	"2006.01.02",
+	"01/02/2006",
+	"02.01.2006",
+	"2006年1月2日",
+	"2006年01月02日",
+	"2006年1月2日 15:04",
+	"2006年1月2日 15:04:05",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+func isDate(d *detectInput) bool {
+	if !d.singleLine() {
+		return false
+	}
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, d.text); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isPhone 识别带国际区号或分隔符的电话号码和中国大陆手机号，纯数字的验证码等不算电话
+func isPhone(d *detectInput) bool {
+	if !d.singleLine() || !phonePattern.MatchString(d.text) {
+		return false
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, d.text)
+	if len(digits) < 7 || len(digits) > 15 {
+		return false
+	}
+	if chinaMobilePattern.MatchString(digits) && (digits == d.text || strings.HasPrefix(d.text, "+86")) {
+		return true
+	}
+	return strings.HasPrefix(d.text, "+") || strings.ContainsAny(d.text, " ()-")
+}
+
+func isJSON(d *detectInput) bool {
+	if first := d.text[0]; first != '{' && first != '[' {
+		return false
+	}
+	return json.Valid([]byte(d.text))
+}
+
+var htmlPattern = regexp.MustCompile(`(?i)<!doctype html|<html[\s>]|<(?:head|body|div|span|p|a|ul|ol|li|table|tr|td|br|img|h[1-6]|script|style|form|input|button|section|article|nav)(?:\s[^>]*)?/?>`)
+
+func isHTML(d *detectInput) bool {
+	if d.text[0] != '<' {
+		return false
+	}
+	return htmlPattern.MatchString(d.sample)
+}
+
+// isXML 以标签开头且能完整解析的内容
+func isXML(d *detectInput) bool {
+	if d.text[0] != '<' || d.text[len(d.text)-1] != '>' {
+		return false
+	}
+	decoder := xml.NewDecoder(strings.NewReader(d.text))
+	elements := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return elements > 0
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			elements++
+		}
+	}
+}
+
+// stackFramePatterns 常见语言的堆栈帧
+var stackFramePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s+at [\w$.<>/]+\(.*\)$`),                    // Java、C#
+	regexp.MustCompile(`^\s+at (?:.+ \()?(?:\S+):\d+:\d+\)?$`),        // JavaScript
+	regexp.MustCompile(`^\s+File ".+", line \d+`),                     // Python
+	regexp.MustCompile(`^\s+\S+\.go:\d+(?: \+0x[0-9a-f]+)?$`),         // Go
+	regexp.MustCompile(`^\s*#\d+\s+(?:0x[0-9a-f]+ in )?\S+.* at \S+`), // gdb、Rust backtrace
+	regexp.MustCompile(`^\s+from \S+:\d+:in `),                        // Ruby
+}
+
+var stackHeaderPattern = regexp.MustCompile(`^(?:Traceback \(most recent call last\):|panic: |goroutine \d+ \[|Exception in thread |(?:[\w$.]+\.)?\w*(?:Exception|Error)\b|thread '.+' panicked at )`)
+
+// isStackTrace 至少包含两个堆栈帧，且帧或异常标题占大部分内容
+func isStackTrace(d *detectInput) bool {
+	frames, headers := 0, 0
+	for _, line := range d.lines {
+		if stackHeaderPattern.MatchString(line) {
+			headers++
+			continue
+		}
+		for _, pattern := range stackFramePatterns {
+			if pattern.MatchString(line) {
+				frames++
+				break
+			}
+		}
+	}
+	return frames >= 2 && (headers > 0 || frames*2 >= len(d.lines))
+}
+
+var (
+	sqlPattern        = regexp.MustCompile(`(?is)^(?:select\s.+\sfrom\s|insert\s+into\s|update\s+\S+\s+set\s|delete\s+from\s|create\s+(?:unique\s+)?(?:table|index|view|database|schema|trigger|function|procedure)\s|alter\s+table\s|drop\s+(?:table|index|view|database|schema)\s|with\s+(?:recursive\s+)?\w+\s+as\s*\(|truncate\s+(?:table\s+)?\w)`)
+	sqlKeywordPattern = regexp.MustCompile(`^(?:SELECT|INSERT|UPDATE|DELETE|CREATE|ALTER|DROP|WITH|TRUNCATE)\b`)
+	sqlWherePattern   = regexp.MustCompile(`(?i)\swhere\s`)
+)
+
+// isSQL 以 SQL 语句开头；关键字为小写时还需要包含分号、星号、等号或 WHERE，避免把英文句子识别为 SQL
+func isSQL(d *detectInput) bool {
+	statement := d.sample
+	if !sqlPattern.MatchString(statement) {
+		return false
+	}
+	return sqlKeywordPattern.MatchString(statement) || strings.ContainsAny(statement, ";*=") || sqlWherePattern.MatchString(statement)
+}
+
+// shellCommands 常见命令，遇到这些命令时只要求一条命令行
+var shellCommands = map[string]bool{
+	"git": true, "docker": true, "docker-compose": true, "kubectl": true, "helm": true, "terraform": true,
+	"npm": true, "npx": true, "yarn": true, "pnpm": true, "pip": true, "pip3": true, "cargo": true, "brew": true,
+	"apt": true, "apt-get": true, "yum": true, "dnf": true, "pacman": true, "sudo": true, "systemctl": true,
+	"journalctl": true, "curl": true, "wget": true, "ssh": true, "scp": true, "rsync": true, "chmod": true,
+	"chown": true, "mkdir": true, "tar": true, "grep": true, "ls": true, "cd": true, "rm": true, "cp": true,
+	"mv": true, "export": true, "source": true, "ps": true, "kill": true, "pkill": true, "sed": true, "awk": true,
+	"go": true, "make": true, "python": true, "python3": true, "node": true, "cat": true, "echo": true, "find": true,
+	"ping": true, "dig": true, "nslookup": true, "openssl": true, "ffmpeg": true, "psql": true, "mysql": true,
+}
+
+// ambiguousCommands 也是常见英文单词的命令，需要带参数或路径等特征才算命令
+var ambiguousCommands = map[string]bool{
+	"go": true, "make": true, "cat": true, "echo": true, "find": true, "source": true, "export": true,
+	"ps": true, "kill": true, "cd": true, "ls": true, "cp": true, "mv": true, "rm": true, "ping": true,
+}
+
+var shellSyntaxPattern = regexp.MustCompile(`\s-{1,2}\w|\s\|\s|&&|\|\||[<>]\s*\S|\$\(|\$\{?\w|[~.]?/\S|\s\w+=\S|\\$`)
+
+// isShell 以 shebang 开头的脚本，或每一行都是命令行（可带 $ 提示符），最多 20 行
+func isShell(d *detectInput) bool {
+	if strings.HasPrefix(d.lines[0], "#!") {
+		return strings.Contains(d.lines[0], "sh")
+	}
+	if len(d.lines) > 20 {
+		return false
+	}
+
+	commands := 0
+	for i, line := range d.lines {
+		line = strings.TrimSpace(line)
+		prompted := strings.HasPrefix(line, "$ ")
+		line = strings.TrimPrefix(line, "$ ")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 上一行以反斜杠结尾时为续行
+		if i > 0 && strings.HasSuffix(strings.TrimSpace(d.lines[i-1]), "\\") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		name := fields[0]
+		if name == "sudo" && len(fields) > 1 {
+			name = fields[1]
+		}
+		switch {
+		case prompted:
+		case shellCommands[name] && !ambiguousCommands[name]:
+		case shellCommands[name] && shellSyntaxPattern.MatchString(line):
+		default:
+			return false
+		}
+		commands++
+	}
+	return commands > 0
+}
+
+var (
+	yamlKeyPattern  = regexp.MustCompile(`^\s*(?:- )?[\w.\-"'/]+:(?:\s|$)`)
+	yamlItemPattern = regexp.MustCompile(`^\s*- \S`)
+)
+
+// isYAML 至少两行，除注释外绝大多数行是键值对或列表项，且有顶层键
+func isYAML(d *detectInput) bool {
+	if len(d.lines) < 2 {
+		return false
+	}
+	if d.lines[0] == "---" {
+		return true
+	}
+
+	keys, items, total, topLevel := 0, 0, 0, false
+	for _, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		total++
+		switch {
+		case strings.HasSuffix(trimmed, ";") || strings.HasSuffix(trimmed, "{"):
+			return false
+		case yamlKeyPattern.MatchString(line):
+			keys++
+			if line[0] != ' ' && line[0] != '\t' {
+				topLevel = true
+			}
+		case yamlItemPattern.MatchString(line):
+			items++
+		}
+	}
+	return topLevel && keys >= 2 && (keys+items)*10 >= total*8
+}
+
+var markdownPatterns = map[string]*regexp.Regexp{
+	"heading":   regexp.MustCompile(`(?m)^#{1,6} \S`),
+	"fence":     regexp.MustCompile("(?m)^```"),
+	"link":      regexp.MustCompile(`\[[^\]\n]+\]\([^)\s]+\)`),
+	"emphasis":  regexp.MustCompile(`\*\*[^*\n]+\*\*|__[^_\n]+__`),
+	"list":      regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+\.) \S`),
+	"quote":     regexp.MustCompile(`(?m)^> `),
+	"table":     regexp.MustCompile(`(?m)^\|.+\|\s*$\n^\|\s*:?-{3,}`),
+	"checklist": regexp.MustCompile(`(?m)^\s*[-*] \[[ xX]\] `),
+}
+
+// isMarkdown 包含代码围栏或表格，或至少两种 Markdown 语法特征
+func isMarkdown(d *detectInput) bool {
+	features := 0
+	for name, pattern := range markdownPatterns {
+		if !pattern.MatchString(d.sample) {
+			continue
+		}
+		if name == "fence" || name == "table" {
+			return true
+		}
+		features++
+	}
+	return features >= 2
+}
+
+// codeLanguage 编程语言的特征，命中的特征越多越可能是该语言
+type codeLanguage struct {
+	name    string
+	signals []*regexp.Regexp
+}
+
+// codeLanguages 按优先级排列，得分相同时取靠前的语言
+var codeLanguages = []codeLanguage{
+	{"go", compileSignals(`(?m)^package \w+$`, `\bfunc (?:\([^)]*\) )?\w+\(`, `:=`, `\bfmt\.\w+\(`, `\berr != nil\b`, `(?m)^import \($`, `\bchan\b|\bgo func\(`)},
+	{"rust", compileSignals(`\bfn \w+(?:<[^>]*>)?\(`, `\blet mut\b`, `\w+!\(`, `(?m)^use \w+(?:::\w+)+`, `(?m)^\s*impl\b`, `&str\b|&mut\b`, `\bpub (?:fn|struct|enum)\b`)},
+	{"python", compileSignals(`(?m)^\s*def \w+\(.*\)(?:\s*->\s*[\w\[\], ]+)?:$`, `(?m)^(?:import \w+|from [\w.]+ import\b)`, `\bself\.`, `(?m)^\s*class \w+(?:\(.*\))?:$`, `(?m)^\s*(?:elif\b.*|except\b.*|try|finally):$`, `__name__|__init__`, `(?m)^\s*print\(`)},
+	{"typescript", compileSignals(`\binterface \w+\s*\{`, `(?m)^\s*(?:export )?type \w+\s*=`, `:\s*(?:string|number|boolean|any|void)\b`, `\bimport .+ from ['"]`, `<\w+>\(`, `\bas const\b`)},
+	{"javascript", compileSignals(`\b(?:const|let|var) \w+\s*=`, `=>`, `\bfunction\s*\w*\(`, `console\.\w+\(`, `\brequire\(['"]`, `\bexport (?:default|const|function)\b`, `\bdocument\.|\bwindow\.`)},
+	{"java", compileSignals(`\bpublic (?:static |final )*(?:class|void|interface)\b`, `System\.out\.print`, `(?m)^import java\.`, `@Override`, `(?m)^\s*private (?:final )?\w+(?:<.*>)? \w+;`, `\bnew \w+\(.*\);`)},
+	{"csharp", compileSignals(`(?m)^using System`, `(?m)^\s*namespace [\w.]+`, `Console\.Write`, `\{ get; (?:private )?set; \}`, `\bpublic (?:async )?Task\b`, `\bvar \w+ = new\b`)},
+	{"cpp", compileSignals(`#include\s*<(?:iostream|vector|string|map|memory)>`, `\bstd::`, `\bcout\s*<<`, `\bnullptr\b`, `\btemplate\s*<`, `\bclass \w+\s*(?::\s*public \w+)?\s*\{`)},
+	{"c", compileSignals(`#include\s*<\w+\.h>`, `\bint main\(`, `\bprintf\(`, `\bmalloc\(|\bfree\(`, `\bstruct \w+\s*\{`, `(?m)^#define \w+`)},
+	{"php", compileSignals(`<\?php`, `\$\w+\s*=`, `\$this->`, `\becho\b`, `\bfunction \w+\(.*\$`, `(?m)^namespace [\w\\]+;`)},
+	{"ruby", compileSignals(`(?m)^\s*def \w+[?!]?(?:\(.*\))?$`, `(?m)^\s*end$`, `\bputs\b`, `\.each do\b|\bdo \|\w+\|`, `\battr_(?:accessor|reader)\b`, `(?m)^require ['"]`)},
+	{"swift", compileSignals(`(?m)^import (?:UIKit|SwiftUI|Foundation)$`, `\bfunc \w+\(.*\)(?: -> \w+)? \{`, `\bguard let\b|\bif let\b`, `\bvar \w+: \w+`, `\bstruct \w+: View\b`, `\blet \w+ = `)},
+	{"kotlin", compileSignals(`\bfun \w+\(`, `(?m)^\s*val \w+`, `\bprintln\(`, `\bdata class\b`, `(?m)^package [\w.]+$`, `\bwhen \(`)},
+	{"css", compileSignals(`(?m)^[.#]?[\w-]+(?:\s*[,>+~ ]\s*[.#:]?[\w-]+)*\s*\{$`, `(?m)^\s*[\w-]+\s*:\s*[^;{}]+;$`, `(?m)^@media\b`, `\b\d+(?:px|rem|em|vh|vw)\b`)},
+}
+
+func compileSignals(patterns ...string) []*regexp.Regexp {
+	signals := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		signals[i] = regexp.MustCompile(pattern)
+	}
+	return signals
+}
+
+// detectCode 按语言特征打分，至少命中两个特征时认为是该语言的代码
+// 没有语言达到要求但大多数行以分号或括号结尾时认为是未知语言的代码
+func detectCode(d *detectInput) (string, bool) {
+	best, bestScore := "", 0
+	for _, language := range codeLanguages {
+		score := 0
+		for _, signal := range language.signals {
+			if signal.MatchString(d.sample) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = language.name, score
+		}
+	}
+	if bestScore >= 2 {
+		return best, true
+	}
+
+	if len(d.lines) < 3 {
+		return "", false
+	}
+	structural := 0
+	for _, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasSuffix(trimmed, ";") || strings.HasSuffix(trimmed, "{") || trimmed == "}" || trimmed == "};" {
+			structural++
+		}
+	}
+	return "", structural*2 >= len(d.lines)
+}
@@ -0,0 +1,88 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Sid/internal/models"
+)
+
+// testdata/contenttype/<内容类型>/<名称>.txt，代码样例的文件名以语言开头：code/<语言>_<名称>.txt
+func TestDetectContentType_Fixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "contenttype", "*", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, file := range files {
+		wantType := filepath.Base(filepath.Dir(file))
+		name := strings.TrimSuffix(filepath.Base(file), ".txt")
+		wantLanguage := ""
+		if wantType == models.ContentTypeCode {
+			wantLanguage, _, _ = strings.Cut(name, "_")
+		}
+
+		t.Run(wantType+"/"+name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := DetectContentType(string(data))
+			if got.Type != wantType || got.CodeLanguage != wantLanguage {
+				t.Errorf("got %s/%q, want %s/%q", got.Type, got.CodeLanguage, wantType, wantLanguage)
+			}
+		})
+	}
+}
+
+func TestDetectContentType_LongContent(t *testing.T) {
+	// 超过采样大小的 JSON 仍按完整内容校验
+	content := `{"items":[` + strings.Repeat(`{"id":1,"title":"剪切板条目"},`, 5000) + `{"id":2}]}`
+	if len(content) <= detectSampleSize {
+		t.Fatalf("content too short: %d", len(content))
+	}
+	if got := DetectContentType(content); got.Type != models.ContentTypeJSON {
+		t.Errorf("long JSON detected as %s", got.Type)
+	}
+
+	code := strings.Repeat("func add(a, b int) int {\n\tsum := a + b\n\treturn sum\n}\n\n", 2000)
+	if got := DetectContentType("package main\n\n" + code); got.Type != models.ContentTypeCode || got.CodeLanguage != "go" {
+		t.Errorf("long Go source detected as %s/%s", got.Type, got.CodeLanguage)
+	}
+}
+
+func TestItemBuilder_CategoryFromContentType(t *testing.T) {
+	settings := models.DefaultSettings()
+	builder := NewItemBuilder(NewAnalyzer(), &settings)
+
+	tests := []struct {
+		content, contentType, category string
+	}{
+		{"SELECT id, title FROM clipboard_items WHERE is_favorite = 1 ORDER BY created_at DESC LIMIT 20;", models.ContentTypeSQL, models.CategoryCode},
+		{`{"name": "sid", "description": "a clipboard manager with a long description field"}`, models.ContentTypeJSON, models.CategoryData},
+		{"#ff8800", models.ContentTypeColor, models.CategoryColor},
+		{"2024-03-15", models.ContentTypeDate, models.CategoryNumber},
+		{"https://example.com/a/very/long/path/that/is/longer/than/fifty/bytes/in/total", models.ContentTypeURL, models.CategoryURL},
+		{"今天天气不错", models.ContentTypeText, models.CategoryText},
+		{"/usr/local/bin", models.ContentTypeText, models.CategoryPath},
+	}
+	for _, tt := range tests {
+		item, _ := builder.BuildItem(tt.content)
+		if item.ContentType != tt.contentType || item.Category != tt.category {
+			t.Errorf("%q: got %s/%s, want %s/%s", tt.content, item.ContentType, item.Category, tt.contentType, tt.category)
+		}
+	}
+
+	// 关闭自动分类时使用默认分类，内容类型仍然检测
+	settings.AutoCategorize = false
+	settings.DefaultCategory = models.CategoryText
+	item, _ := NewItemBuilder(NewAnalyzer(), &settings).BuildItem("#ff8800")
+	if item.ContentType != models.ContentTypeColor || item.Category != models.CategoryText {
+		t.Errorf("got %s/%s with auto categorize disabled", item.ContentType, item.Category)
+	}
+}
@@ -0,0 +1,80 @@
+package clipboard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// thumbnailMaxSize 缩略图最长边像素
+const thumbnailMaxSize = 240
+
+// ImageInfo 剪切板图片解析结果
+type ImageInfo struct {
+	Hash      string
+	Data      []byte
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+// HashBytes 计算数据的 SHA-256 十六进制摘要
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseImage 解析剪切板中的 PNG 数据并生成缩略图
+func ParseImage(data []byte) (*ImageInfo, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析PNG失败: %w", err)
+	}
+
+	bounds := img.Bounds()
+	thumbnail, err := makeThumbnail(img, thumbnailMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageInfo{
+		Hash:      HashBytes(data),
+		Data:      data,
+		Thumbnail: thumbnail,
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+	}, nil
+}
+
+// makeThumbnail 等比缩放图片，最长边不超过 maxSize
+func makeThumbnail(img image.Image, maxSize int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("图片尺寸无效: %dx%d", width, height)
+	}
+
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = max(1, height*maxSize/width)
+			width = maxSize
+		} else {
+			width = max(1, width*maxSize/height)
+			height = maxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, fmt.Errorf("生成缩略图失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package clipboard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encodeTestPNG 生成指定尺寸的纯色 PNG
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		thumbW, thumbH int
+	}{
+		{"small", 100, 50, 100, 50},
+		{"exact", thumbnailMaxSize, thumbnailMaxSize, thumbnailMaxSize, thumbnailMaxSize},
+		{"wide", 960, 480, thumbnailMaxSize, 120},
+		{"tall", 300, 1200, 60, thumbnailMaxSize},
+		{"thin", 2400, 1, thumbnailMaxSize, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodeTestPNG(t, tt.width, tt.height)
+			info, err := ParseImage(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Width != tt.width || info.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", info.Width, info.Height, tt.width, tt.height)
+			}
+			if info.Hash != HashBytes(data) || !bytes.Equal(info.Data, data) {
+				t.Error("hash or data does not match the source PNG")
+			}
+
+			thumb, err := png.Decode(bytes.NewReader(info.Thumbnail))
+			if err != nil {
+				t.Fatalf("thumbnail is not a valid PNG: %v", err)
+			}
+			bounds := thumb.Bounds()
+			if bounds.Dx() != tt.thumbW || bounds.Dy() != tt.thumbH {
+				t.Errorf("thumbnail = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.thumbW, tt.thumbH)
+			}
+		})
+	}
+}
+
+func TestParseImage_Invalid(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not a png"), encodeTestPNG(t, 10, 10)[:20]} {
+		if _, err := ParseImage(data); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	a := encodeTestPNG(t, 8, 8)
+	b := encodeTestPNG(t, 8, 9)
+	if HashBytes(a) != HashBytes(append([]byte(nil), a...)) {
+		t.Error("identical data should have the same hash")
+	}
+	if HashBytes(a) == HashBytes(b) {
+		t.Error("different data should have different hashes")
+	}
+	if len(HashBytes(a)) != 64 {
+		t.Errorf("unexpected hash length %d", len(HashBytes(a)))
+	}
+}
@@ -1,13 +1,16 @@
 package clipboard
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
-	clipboardLib "golang.design/x/clipboard"
 	"github.com/google/uuid"
 	"github.com/jbrukh/bayesian"
+	clipboardLib "golang.design/x/clipboard"
 
 	"Sid/internal/models"
 )
@@ -18,49 +21,82 @@ type Monitor interface {
 	Stop()
 	IsRunning() bool
 	SetProcessor(processor ContentProcessor)
+	UpdateSettings(settings *models.Settings)
 }
 
 // ContentProcessor 内容处理器接口
 type ContentProcessor interface {
-	ProcessContent(content string) error
+	ProcessContent(content string, source Source) error
+	ProcessImage(data []byte, source Source) error
 }
 
 // Analyzer 内容分析器接口
 type Analyzer interface {
 	GenerateTitle(content string) string
 	AutoDetectCategory(content string) string
-	IsLikelyPassword(content string) bool
+}
+
+// captureQueueSize 待处理剪切板变化的缓冲大小，避免处理较慢时丢失连续复制
+const captureQueueSize = 64
+
+// capture 一次剪切板变化
+type capture struct {
+	format clipboardLib.Format
+	data   []byte
+	source Source // 变化发生时的活动应用
 }
 
 // monitor 剪切板监听器实现
 type monitor struct {
-	isRunning      bool
-	stopChan       chan bool
-	lastClipboard  string
-	processor      ContentProcessor
-	analyzer       Analyzer
-	settings       *models.Settings
-	tickerInterval time.Duration
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	processor ContentProcessor
+	settings  *models.Settings
+	source    SourceDetector
+
+	// 以下字段仅在监听协程中访问
+	lastTextHash  string
+	lastImageHash string
 }
 
 // NewMonitor 创建新的剪切板监听器
 func NewMonitor(settings *models.Settings) Monitor {
 	return &monitor{
-		stopChan:       make(chan bool),
-		analyzer:       NewAnalyzer(),
-		settings:       settings,
-		tickerInterval: 500 * time.Millisecond,
+		settings: settings,
+		source:   NewSourceDetector(),
 	}
 }
 
 // SetProcessor 设置内容处理器
 func (m *monitor) SetProcessor(processor ContentProcessor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.processor = processor
 }
 
+// UpdateSettings 更新设置，正在运行时会重启监听以应用新的监听格式和轮询间隔
+func (m *monitor) UpdateSettings(settings *models.Settings) {
+	running := m.IsRunning()
+	if running {
+		m.Stop()
+	}
+
+	m.mu.Lock()
+	m.settings = settings
+	m.mu.Unlock()
+
+	if running {
+		m.Start()
+	}
+}
+
 // Start 开始监听剪切板
 func (m *monitor) Start() error {
-	if m.isRunning {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
 		return nil
 	}
 
@@ -69,63 +105,149 @@ func (m *monitor) Start() error {
 		return nil
 	}
 
-	m.isRunning = true
-	go m.monitorLoop()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.monitorLoop(ctx, m.done)
 	log.Println("🎯 开始监听剪切板...")
 	return nil
 }
 
-// Stop 停止监听剪切板
+// Stop 停止监听剪切板，可重复调用
 func (m *monitor) Stop() {
-	if !m.isRunning {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
 		return
 	}
 
-	m.stopChan <- true
-	m.isRunning = false
+	cancel()
+	<-done
 	log.Println("🛑 停止监听剪切板")
 }
 
 // IsRunning 检查是否正在运行
 func (m *monitor) IsRunning() bool {
-	return m.isRunning
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cancel != nil
 }
 
-// monitorLoop 监听循环
-func (m *monitor) monitorLoop() {
-	ticker := time.NewTicker(m.tickerInterval)
-	defer ticker.Stop()
+// monitorLoop 监听循环：订阅剪切板变化事件，并以可配置的间隔兜底轮询
+func (m *monitor) monitorLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+
+	textCh := clipboardLib.Watch(ctx, clipboardLib.FmtText)
+	var imageCh <-chan []byte
+	if !settings.IgnoreImages {
+		imageCh = clipboardLib.Watch(ctx, clipboardLib.FmtImage)
+	}
+
+	var pollCh <-chan time.Time
+	if settings.PollIntervalMs > 0 {
+		ticker := time.NewTicker(time.Duration(settings.PollIntervalMs) * time.Millisecond)
+		defer ticker.Stop()
+		pollCh = ticker.C
+	}
+
+	// 处理协程与监听分离，内容处理较慢时不会阻塞事件接收
+	queue := make(chan capture, captureQueueSize)
+	workerDone := make(chan struct{})
+	go m.processLoop(queue, workerDone)
+	defer func() {
+		close(queue)
+		<-workerDone
+	}()
 
 	for {
 		select {
-		case <-m.stopChan:
+		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			if data := clipboardLib.Read(clipboardLib.FmtText); len(data) > 0 {
-				content := string(data)
-				if content != "" && content != m.lastClipboard {
-					m.lastClipboard = content
-					m.processClipboardContent(content)
-				}
+		case data, ok := <-textCh:
+			if !ok {
+				textCh = nil
+				continue
+			}
+			m.enqueue(queue, clipboardLib.FmtText, data)
+		case data, ok := <-imageCh:
+			if !ok {
+				imageCh = nil
+				continue
+			}
+			m.enqueue(queue, clipboardLib.FmtImage, data)
+		case <-pollCh:
+			m.enqueue(queue, clipboardLib.FmtText, clipboardLib.Read(clipboardLib.FmtText))
+			if imageCh != nil {
+				m.enqueue(queue, clipboardLib.FmtImage, clipboardLib.Read(clipboardLib.FmtImage))
 			}
 		}
 	}
 }
 
-// processClipboardContent 处理剪切板内容
-func (m *monitor) processClipboardContent(content string) {
-	// 过滤敏感内容
-	if m.settings.IgnorePasswords && m.analyzer.IsLikelyPassword(content) {
-		log.Println("🚫 跳过疑似密码内容")
+// enqueue 按内容哈希去重后放入处理队列，同时记录当前的活动应用
+func (m *monitor) enqueue(queue chan<- capture, format clipboardLib.Format, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	hash := HashBytes(data)
+	last := &m.lastTextHash
+	if format == clipboardLib.FmtImage {
+		last = &m.lastImageHash
+	}
+	if hash == *last {
 		return
 	}
+	*last = hash
+
+	select {
+	case queue <- capture{format: format, data: data, source: m.source.ActiveSource()}:
+	default:
+		log.Println("⚠️  剪切板处理队列已满，丢弃本次变化")
+	}
+}
+
+// processLoop 依次处理队列中的剪切板变化
+func (m *monitor) processLoop(queue <-chan capture, done chan struct{}) {
+	defer close(done)
+
+	for c := range queue {
+		if c.format == clipboardLib.FmtImage {
+			m.processClipboardImage(c.data, c.source)
+		} else {
+			m.processClipboardContent(string(c.data), c.source)
+		}
+	}
+}
 
-	// 处理内容
-	if err := m.processor.ProcessContent(content); err != nil {
+// processClipboardContent 处理剪切板内容（敏感内容检测在构建条目时进行）
+func (m *monitor) processClipboardContent(content string, source Source) {
+	if err := m.processor.ProcessContent(content, source); err != nil {
 		log.Printf("❌ 处理剪切板内容失败: %v", err)
 	}
 }
 
+// processClipboardImage 处理剪切板图片
+func (m *monitor) processClipboardImage(data []byte, source Source) {
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+	if settings.IgnoreImages {
+		return
+	}
+
+	if err := m.processor.ProcessImage(data, source); err != nil {
+		log.Printf("❌ 处理剪切板图片失败: %v", err)
+	}
+}
+
 // analyzer 内容分析器实现
 type analyzer struct {
 	classifier *bayesian.Classifier
@@ -236,40 +358,10 @@ func (a *analyzer) fallbackClassification(content string) string {
 	return models.CategoryText
 }
 
-// IsLikelyPassword 检查是否像密码
-func (a *analyzer) IsLikelyPassword(content string) bool {
-	// 简单的密码检测逻辑
-	if len(content) < 4 || len(content) > 100 {
-		return false
-	}
-
-	// 包含常见密码特征
-	hasUpper := strings.ContainsAny(content, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
-	hasLower := strings.ContainsAny(content, "abcdefghijklmnopqrstuvwxyz")
-	hasNumber := strings.ContainsAny(content, "0123456789")
-	hasSpecial := strings.ContainsAny(content, "!@#$%^&*()_+-=[]{}|;':\",./<>?")
-
-	// 如果包含3种以上字符类型且长度适中，可能是密码
-	charTypes := 0
-	if hasUpper {
-		charTypes++
-	}
-	if hasLower {
-		charTypes++
-	}
-	if hasNumber {
-		charTypes++
-	}
-	if hasSpecial {
-		charTypes++
-	}
-
-	return charTypes >= 3 && len(content) >= 8 && len(content) <= 50
-}
-
 // ItemBuilder 剪切板条目构建器
 type ItemBuilder struct {
 	analyzer Analyzer
+	detector *SensitiveDetector
 	settings *models.Settings
 }
 
@@ -277,32 +369,103 @@ type ItemBuilder struct {
 func NewItemBuilder(analyzer Analyzer, settings *models.Settings) *ItemBuilder {
 	return &ItemBuilder{
 		analyzer: analyzer,
+		detector: NewSensitiveDetector(settings.Sensitive),
 		settings: settings,
 	}
 }
 
-// BuildItem 构建剪切板条目
-func (b *ItemBuilder) BuildItem(content string) models.ClipboardItem {
+// BuildItem 构建剪切板条目，开启敏感内容过滤时按规则打码或设置过期时间
+// 返回的检测结果 Skip 为 true 时调用方不应保存该条目
+func (b *ItemBuilder) BuildItem(content string) (models.ClipboardItem, SensitiveResult) {
+	result := SensitiveResult{Redacted: content}
+	if b.settings.IgnorePasswords {
+		result = b.detector.Scan(content)
+	}
+	content = result.Redacted
+
+	detection := DetectContentType(content)
 	category := models.CategoryText
 
 	if b.settings.AutoCategorize {
-		category = b.analyzer.AutoDetectCategory(content)
+		// 优先按检测出的内容类型分类，普通文本再交给分析器
+		category = CategoryForContentType(detection.Type)
+		if category == "" {
+			category = b.analyzer.AutoDetectCategory(content)
+		}
 	} else {
 		category = b.settings.DefaultCategory
 	}
 
+	item := models.ClipboardItem{
+		ID:           uuid.New().String(),
+		Content:      content,
+		ContentType:  detection.Type,
+		CodeLanguage: detection.CodeLanguage,
+		Title:        b.analyzer.GenerateTitle(content),
+		Tags:         []models.Tag{}, // 标签在创建后通过关联表添加
+		Category:     category,
+		IsFavorite:   false,
+		UseCount:     0,
+		IsDeleted:    false,
+		DeletedAt:    nil,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		LastUsedAt:   time.Now(),
+		IsSensitive:  result.Sensitive(),
+	}
+	FillContentStats(&item)
+	if result.ExpireAfter > 0 {
+		expiresAt := item.CreatedAt.Add(result.ExpireAfter)
+		item.ExpiresAt = &expiresAt
+	}
+	return item, result
+}
+
+// RescanItem 对已构建条目的当前内容重新做敏感内容检测（如捕获规则转换之后），
+// 按结果打码、标记敏感并取更早的过期时间；返回结果的 Skip 为 true 时调用方不应保存该条目
+func (b *ItemBuilder) RescanItem(item *models.ClipboardItem) SensitiveResult {
+	result := SensitiveResult{Redacted: item.Content}
+	if !b.settings.IgnorePasswords {
+		return result
+	}
+	result = b.detector.Scan(item.Content)
+	if !result.Sensitive() || result.Skip {
+		return result
+	}
+
+	item.IsSensitive = true
+	if result.Redacted != item.Content {
+		item.Content = result.Redacted
+		item.Title = b.analyzer.GenerateTitle(item.Content)
+		FillContentStats(item)
+	}
+	if result.ExpireAfter > 0 {
+		expiresAt := item.CreatedAt.Add(result.ExpireAfter)
+		if item.ExpiresAt == nil || expiresAt.Before(*item.ExpiresAt) {
+			item.ExpiresAt = &expiresAt
+		}
+	}
+	return result
+}
+
+// BuildImageItem 构建图片条目
+func (b *ItemBuilder) BuildImageItem(info *ImageInfo) models.ClipboardItem {
+	now := time.Now()
 	return models.ClipboardItem{
-		ID:         uuid.New().String(),
-		Content:    content,
-		Title:      b.analyzer.GenerateTitle(content),
-		Tags:       []models.Tag{}, // 标签在创建后通过关联表添加
-		Category:   category,
-		IsFavorite: false,
-		UseCount:   0,
-		IsDeleted:  false,
-		DeletedAt:  nil,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-		LastUsedAt: time.Now(),
+		ID:          uuid.New().String(),
+		Content:     "image:" + info.Hash,
+		ContentType: models.ContentTypeImage,
+		Title:       fmt.Sprintf("图片 %d×%d", info.Width, info.Height),
+		Tags:        []models.Tag{},
+		Category:    models.CategoryImage,
+		IsFavorite:  false,
+		UseCount:    0,
+		IsDeleted:   false,
+		DeletedAt:   nil,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LastUsedAt:  now,
+
+		ContentLength: len(info.Data),
 	}
 }
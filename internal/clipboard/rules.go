@@ -0,0 +1,192 @@
+package clipboard
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// RuleOutcome 捕获规则的执行结果
+type RuleOutcome struct {
+	Matched []string // 命中的规则名称
+	Ignored bool     // 命中 ignore 动作，不应保存
+	Tags    []string // 保存后需要添加的标签
+}
+
+// compiledRule 编译后的规则
+type compiledRule struct {
+	rule    models.CaptureRule
+	pattern *regexp.Regexp
+}
+
+// RuleEngine 捕获规则引擎，创建时编译并排序规则，之后只读，可并发使用
+type RuleEngine struct {
+	rules    []compiledRule
+	analyzer Analyzer
+}
+
+// ValidateRule 校验规则的条件和动作
+func ValidateRule(rule models.CaptureRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("规则名称不能为空")
+	}
+	if rule.Pattern != "" {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("规则 %s 的正则表达式无效: %v", rule.Name, err)
+		}
+	}
+	if rule.MinLength < 0 || rule.MaxLength < 0 || (rule.MaxLength > 0 && rule.MinLength > rule.MaxLength) {
+		return fmt.Errorf("规则 %s 的长度范围无效", rule.Name)
+	}
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("规则 %s 没有设置动作", rule.Name)
+	}
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case models.RuleActionIgnore, models.RuleActionFavorite:
+		case models.RuleActionTag:
+			if len(splitTags(action.Value)) == 0 {
+				return fmt.Errorf("规则 %s 的 tag 动作缺少标签", rule.Name)
+			}
+		case models.RuleActionCategory:
+			if strings.TrimSpace(action.Value) == "" {
+				return fmt.Errorf("规则 %s 的 category 动作缺少分类", rule.Name)
+			}
+		case models.RuleActionExpire:
+			if minutes, err := strconv.Atoi(action.Value); err != nil || minutes <= 0 {
+				return fmt.Errorf("规则 %s 的 expire 动作需要正整数分钟数", rule.Name)
+			}
+		case models.RuleActionTransform:
+			if !HasTransform(action.Value) {
+				return fmt.Errorf("规则 %s 使用了未知的转换: %s", rule.Name, action.Value)
+			}
+		default:
+			return fmt.Errorf("规则 %s 使用了未知的动作: %s", rule.Name, action.Type)
+		}
+	}
+	return nil
+}
+
+// NewRuleEngine 创建规则引擎，跳过未启用的规则，按 SortOrder 排序
+func NewRuleEngine(rules []models.CaptureRule) (*RuleEngine, error) {
+	engine := &RuleEngine{analyzer: NewAnalyzer()}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if err := ValidateRule(rule); err != nil {
+			return nil, err
+		}
+		compiled := compiledRule{rule: rule}
+		if rule.Pattern != "" {
+			compiled.pattern = regexp.MustCompile(rule.Pattern)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	sort.SliceStable(engine.rules, func(i, j int) bool {
+		return engine.rules[i].rule.SortOrder < engine.rules[j].rule.SortOrder
+	})
+	return engine, nil
+}
+
+// Apply 依次匹配规则并就地修改条目，命中 ignore 或设置了 Stop 的规则后不再继续
+func (e *RuleEngine) Apply(item *models.ClipboardItem, source Source) RuleOutcome {
+	var outcome RuleOutcome
+	if e == nil {
+		return outcome
+	}
+	for _, compiled := range e.rules {
+		if !compiled.matches(item, source) {
+			continue
+		}
+		outcome.Matched = append(outcome.Matched, compiled.rule.Name)
+		for _, action := range compiled.rule.Actions {
+			if e.applyAction(item, action, &outcome) {
+				return outcome
+			}
+		}
+		if compiled.rule.Stop {
+			break
+		}
+	}
+	return outcome
+}
+
+// applyAction 执行单个动作，返回 true 表示条目被忽略
+func (e *RuleEngine) applyAction(item *models.ClipboardItem, action models.RuleAction, outcome *RuleOutcome) bool {
+	switch action.Type {
+	case models.RuleActionIgnore:
+		outcome.Ignored = true
+		return true
+	case models.RuleActionTag:
+		for _, tag := range splitTags(action.Value) {
+			if !containsString(outcome.Tags, tag) {
+				outcome.Tags = append(outcome.Tags, tag)
+			}
+		}
+	case models.RuleActionFavorite:
+		item.IsFavorite = true
+	case models.RuleActionCategory:
+		item.Category = strings.TrimSpace(action.Value)
+	case models.RuleActionExpire:
+		minutes, _ := strconv.Atoi(action.Value)
+		expiresAt := item.CreatedAt.Add(time.Duration(minutes) * time.Minute)
+		if item.ExpiresAt == nil || expiresAt.Before(*item.ExpiresAt) {
+			item.ExpiresAt = &expiresAt
+		}
+	case models.RuleActionTransform:
+		content, err := ApplyTransform(action.Value, item.Content)
+		if err != nil {
+			log.Printf("⚠️  规则转换 %s 失败，保留原内容: %v", action.Value, err)
+			return false
+		}
+		item.Content = content
+		item.Title = e.analyzer.GenerateTitle(content)
+		FillContentStats(item)
+		FillContentType(item)
+	}
+	return false
+}
+
+// matches 判断条目是否满足规则的全部条件
+func (c compiledRule) matches(item *models.ClipboardItem, source Source) bool {
+	rule := c.rule
+	if rule.SourceApp != "" && !strings.EqualFold(rule.SourceApp, source.App) {
+		return false
+	}
+	if rule.Category != "" && rule.Category != item.Category {
+		return false
+	}
+	length := len(item.Content)
+	if length < rule.MinLength || (rule.MaxLength > 0 && length > rule.MaxLength) {
+		return false
+	}
+	return c.pattern == nil || c.pattern.MatchString(item.Content)
+}
+
+// splitTags 解析逗号分隔的标签列表
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
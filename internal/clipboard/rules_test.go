@@ -0,0 +1,101 @@
+package clipboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func newRuleTestItem(content, category string) *models.ClipboardItem {
+	item := &models.ClipboardItem{Content: content, Category: category, CreatedAt: time.Now()}
+	FillContentStats(item)
+	return item
+}
+
+func TestRuleEngine_Apply(t *testing.T) {
+	rules := []models.CaptureRule{
+		{Name: "终端命令", Enabled: true, SortOrder: 2, SourceApp: "gnome-terminal", Pattern: `^\$ `,
+			Actions: []models.RuleAction{{Type: models.RuleActionTag, Value: "命令, shell"}, {Type: models.RuleActionTransform, Value: "trim"}}},
+		{Name: "工单号", Enabled: true, SortOrder: 1, Pattern: `JIRA-\d+`,
+			Actions: []models.RuleAction{{Type: models.RuleActionFavorite}, {Type: models.RuleActionCategory, Value: "工单"}, {Type: models.RuleActionTag, Value: "shell"}}},
+		{Name: "验证码", Enabled: true, SortOrder: 0, MaxLength: 8, Category: models.CategoryNumber, Stop: true,
+			Actions: []models.RuleAction{{Type: models.RuleActionExpire, Value: "5"}}},
+		{Name: "停用", Enabled: false, SortOrder: 3, Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}},
+		{Name: "长文本", Enabled: true, SortOrder: 4, MinLength: 1000, Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}},
+	}
+	engine, err := NewRuleEngine(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 按 SortOrder 依次执行，标签去重，转换后重新计算标题和统计
+	item := newRuleTestItem("$ git log JIRA-42  \n", models.CategoryText)
+	outcome := engine.Apply(item, Source{App: "Gnome-Terminal"})
+	if strings.Join(outcome.Matched, ",") != "工单号,终端命令" {
+		t.Errorf("matched = %v", outcome.Matched)
+	}
+	if strings.Join(outcome.Tags, ",") != "shell,命令" || outcome.Ignored {
+		t.Errorf("outcome = %+v", outcome)
+	}
+	if !item.IsFavorite || item.Category != "工单" || item.Content != "$ git log JIRA-42" || item.ContentLength != 17 || item.Title == "" {
+		t.Errorf("item = %+v", item)
+	}
+
+	// 来源应用不匹配
+	item = newRuleTestItem("$ ls", models.CategoryText)
+	if outcome := engine.Apply(item, Source{App: "Firefox"}); len(outcome.Matched) != 0 {
+		t.Errorf("unexpected match: %v", outcome.Matched)
+	}
+
+	// Stop 规则之后不再匹配
+	item = newRuleTestItem("123456", models.CategoryNumber)
+	outcome = engine.Apply(item, Source{})
+	if len(outcome.Matched) != 1 || item.ExpiresAt == nil || item.ExpiresAt.Sub(item.CreatedAt) != 5*time.Minute {
+		t.Errorf("outcome = %+v, expires = %v", outcome, item.ExpiresAt)
+	}
+
+	// ignore 动作
+	item = newRuleTestItem(strings.Repeat("a", 1000), models.CategoryText)
+	if outcome := engine.Apply(item, Source{}); !outcome.Ignored {
+		t.Errorf("expected long text to be ignored: %+v", outcome)
+	}
+
+	// 没有规则时不做任何处理
+	var empty *RuleEngine
+	if outcome := empty.Apply(item, Source{}); len(outcome.Matched) != 0 {
+		t.Errorf("nil engine matched: %+v", outcome)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := models.CaptureRule{Name: "ok", Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}}
+	if err := ValidateRule(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]func(*models.CaptureRule){
+		"empty name":     func(r *models.CaptureRule) { r.Name = " " },
+		"bad pattern":    func(r *models.CaptureRule) { r.Pattern = "(" },
+		"bad length":     func(r *models.CaptureRule) { r.MinLength, r.MaxLength = 10, 5 },
+		"no actions":     func(r *models.CaptureRule) { r.Actions = nil },
+		"unknown action": func(r *models.CaptureRule) { r.Actions = []models.RuleAction{{Type: "paste"}} },
+		"empty tag": func(r *models.CaptureRule) {
+			r.Actions = []models.RuleAction{{Type: models.RuleActionTag, Value: " , "}}
+		},
+		"bad expire": func(r *models.CaptureRule) {
+			r.Actions = []models.RuleAction{{Type: models.RuleActionExpire, Value: "0"}}
+		},
+		"unknown transform": func(r *models.CaptureRule) {
+			r.Actions = []models.RuleAction{{Type: models.RuleActionTransform, Value: "rot13"}}
+		},
+	}
+	for name, mutate := range invalid {
+		rule := valid
+		mutate(&rule)
+		if err := ValidateRule(rule); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package clipboard
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"Sid/internal/models"
+)
+
+// Detector 敏感内容检测器，返回命中范围（字节偏移，左闭右开）
+type Detector interface {
+	Name() string
+	Detect(content string) [][2]int
+}
+
+// SensitiveMatch 敏感内容命中
+type SensitiveMatch struct {
+	Detector string
+	Action   string
+	Start    int
+	End      int
+}
+
+// SensitiveResult 检测结果
+type SensitiveResult struct {
+	Matches     []SensitiveMatch
+	Skip        bool          // 命中了动作为 skip 的规则，不应保存
+	Redacted    string        // 打码后的内容，没有需要打码的命中时与原文相同
+	ExpireAfter time.Duration // 命中 expire 规则时原文保留的最短时长，0 表示不过期
+}
+
+// Sensitive 是否命中任意规则
+func (r SensitiveResult) Sensitive() bool {
+	return len(r.Matches) > 0
+}
+
+// Detectors 命中的检测器名称（去重）
+func (r SensitiveResult) Detectors() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range r.Matches {
+		if !seen[m.Detector] {
+			seen[m.Detector] = true
+			names = append(names, m.Detector)
+		}
+	}
+	return names
+}
+
+// sensitiveRule 启用的检测器及其处理方式
+type sensitiveRule struct {
+	detector Detector
+	action   string
+	expire   time.Duration
+}
+
+// SensitiveDetector 按配置依次运行检测器
+type SensitiveDetector struct {
+	rules []sensitiveRule
+}
+
+// defaultExpireAfter expire 规则未设置时长时使用的默认值
+const defaultExpireAfter = time.Hour
+
+// NewSensitiveDetector 根据设置创建检测器链，未知的检测器名称会被忽略
+func NewSensitiveDetector(settings models.SensitiveSettings) *SensitiveDetector {
+	builtin := BuiltinDetectors(settings.EntropyThreshold)
+
+	d := &SensitiveDetector{}
+	for _, rule := range settings.Rules {
+		detector, ok := builtin[rule.Detector]
+		if !rule.Enabled || !ok {
+			continue
+		}
+		expire := time.Duration(rule.ExpireMinutes) * time.Minute
+		if rule.Action == models.SensitiveActionExpire && expire <= 0 {
+			expire = defaultExpireAfter
+		}
+		d.rules = append(d.rules, sensitiveRule{detector: detector, action: rule.Action, expire: expire})
+	}
+	return d
+}
+
+// Scan 检测内容并计算处理结果：skip 优先，其次对 redact 命中打码，expire 取最短时长
+// 规则按配置顺序匹配，同一片段只由第一个命中的规则处理
+func (d *SensitiveDetector) Scan(content string) SensitiveResult {
+	result := SensitiveResult{Redacted: content}
+
+	var redact []SensitiveMatch
+	for _, rule := range d.rules {
+		for _, span := range rule.detector.Detect(content) {
+			if overlaps(result.Matches, span) {
+				continue
+			}
+			match := SensitiveMatch{Detector: rule.detector.Name(), Action: rule.action, Start: span[0], End: span[1]}
+			result.Matches = append(result.Matches, match)
+
+			switch rule.action {
+			case models.SensitiveActionSkip:
+				result.Skip = true
+			case models.SensitiveActionRedact:
+				redact = append(redact, match)
+			case models.SensitiveActionExpire:
+				if result.ExpireAfter == 0 || rule.expire < result.ExpireAfter {
+					result.ExpireAfter = rule.expire
+				}
+			}
+		}
+	}
+
+	if !result.Skip && len(redact) > 0 {
+		result.Redacted = redactMatches(content, redact)
+	}
+	return result
+}
+
+// overlaps 检查范围是否与已有命中重叠
+func overlaps(matches []SensitiveMatch, span [2]int) bool {
+	for _, m := range matches {
+		if span[0] < m.End && m.Start < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMatches 将互不重叠的命中范围替换为打码文本
+func redactMatches(content string, matches []SensitiveMatch) string {
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(content[last:m.Start])
+		b.WriteString(mask(content[m.Start:m.End], m.Detector))
+		last = m.End
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// mask 打码：较短的值保留首尾字符便于辨认，较长的块整体替换
+func mask(value, detector string) string {
+	runes := []rune(value)
+	switch {
+	case len(runes) > 64:
+		return "[已隐藏:" + detector + "]"
+	case len(runes) >= 11:
+		return string(runes[:3]) + strings.Repeat("*", len(runes)-7) + string(runes[len(runes)-4:])
+	default:
+		return strings.Repeat("*", len(runes))
+	}
+}
+
+// BuiltinDetectors 返回内置检测器，按名称索引
+func BuiltinDetectors(entropyThreshold float64) map[string]Detector {
+	detectors := []Detector{
+		&regexDetector{name: models.SensitiveDetectorAWSKey, pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		&regexDetector{name: models.SensitiveDetectorGitHub, pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+		&regexDetector{name: models.SensitiveDetectorOpenAI, pattern: regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}`)},
+		&regexDetector{name: models.SensitiveDetectorJWT, pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+		&regexDetector{name: models.SensitiveDetectorPrivateKey, pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----(?s:.*?)(?:-----END (?:[A-Z]+ )*PRIVATE KEY-----|$)`)},
+		&regexDetector{name: models.SensitiveDetectorCreditCard, pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), validate: isValidCardNumber},
+		&regexDetector{name: models.SensitiveDetectorChineseID, pattern: regexp.MustCompile(`\b\d{17}[\dXx]\b`), validate: isValidChineseID},
+		&regexDetector{name: models.SensitiveDetectorPhone, pattern: regexp.MustCompile(`(?:\+86[- ]?|\b)1[3-9]\d{9}\b`)},
+		&entropyDetector{threshold: entropyThreshold},
+	}
+
+	byName := make(map[string]Detector, len(detectors))
+	for _, d := range detectors {
+		byName[d.Name()] = d
+	}
+	return byName
+}
+
+// regexDetector 基于正则的检测器，validate 用于排除格式匹配但校验失败的值
+type regexDetector struct {
+	name     string
+	pattern  *regexp.Regexp
+	validate func(string) bool
+}
+
+// Name 检测器名称
+func (d *regexDetector) Name() string {
+	return d.name
+}
+
+// Detect 返回所有通过校验的匹配
+func (d *regexDetector) Detect(content string) [][2]int {
+	var spans [][2]int
+	for _, loc := range d.pattern.FindAllStringIndex(content, -1) {
+		if d.validate == nil || d.validate(content[loc[0]:loc[1]]) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	return spans
+}
+
+// isValidCardNumber 使用 Luhn 算法校验银行卡号
+func isValidCardNumber(value string) bool {
+	var digits []int
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	// 排除全部相同的数字（如 0000000000000）
+	same := true
+	for _, d := range digits[1:] {
+		if d != digits[0] {
+			same = false
+			break
+		}
+	}
+	if same {
+		return false
+	}
+
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// isValidChineseID 校验18位居民身份证号的出生日期和校验码
+func isValidChineseID(value string) bool {
+	if len(value) != 18 {
+		return false
+	}
+	if _, err := time.Parse("20060102", value[6:14]); err != nil {
+		return false
+	}
+
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(value[i]-'0') * w
+	}
+	check := "10X98765432"[sum%11]
+	return check == strings.ToUpper(value[17:])[0]
+}
+
+// entropyDetector 高熵字符串检测，用于识别随机密码和未知格式的密钥
+type entropyDetector struct {
+	threshold float64
+}
+
+// Name 检测器名称
+func (d *entropyDetector) Name() string {
+	return models.SensitiveDetectorEntropy
+}
+
+// assignmentPattern key=value / key: value 赋值，第 1 组为值；值不能以 = 开头，避免把 base64 的填充当成赋值
+var assignmentPattern = regexp.MustCompile(`(?:^|[\s{(,;])["']?[A-Za-z_][A-Za-z0-9_.-]*["']?[ \t]*[:=][ \t]*["'` + "`" + `]?([^\s"'` + "`" + `,;=][^\s"'` + "`" + `,;]*)`)
+
+// Detect 只检查赋值语句（key=value、key: value）的值，以及不含空白也没有赋值的整段内容；
+// 排除标识符、路径、文件名、版本号和摘要等常见格式后，较短的片段需要同时包含大小写字母、数字和符号，
+// 较长的片段只需字母和数字混合，两者都要求信息熵达到阈值（短片段的阈值按长度折算）
+func (d *entropyDetector) Detect(content string) [][2]int {
+	threshold := d.threshold
+	if threshold <= 0 {
+		threshold = models.DefaultSensitiveSettings().EntropyThreshold
+	}
+
+	var spans [][2]int
+	for _, loc := range entropyCandidates(content) {
+		if isHighEntropyToken(content[loc[0]:loc[1]], threshold) {
+			spans = append(spans, loc)
+		}
+	}
+	return spans
+}
+
+// entropyCandidates 返回需要评分的片段范围：所有赋值语句的值；没有赋值且整段内容不含空白时为整段内容
+func entropyCandidates(content string) [][2]int {
+	var spans [][2]int
+	for _, m := range assignmentPattern.FindAllStringSubmatchIndex(content, -1) {
+		spans = append(spans, [2]int{m[2], m[3]})
+	}
+	if len(spans) > 0 {
+		return spans
+	}
+
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || strings.IndexFunc(trimmed, unicode.IsSpace) >= 0 {
+		return nil
+	}
+	start := strings.Index(content, trimmed)
+	return [][2]int{{start, start + len(trimmed)}}
+}
+
+// isHighEntropyToken 判断片段是否像随机生成的密码或密钥
+func isHighEntropyToken(token string, threshold float64) bool {
+	if len(token) < 8 || len(token) > 128 || looksLikeAddress(token) || looksLikeStructuredToken(token) {
+		return false
+	}
+
+	upper, lower, digit, symbol := charClasses(token)
+	if !digit || !(upper || lower) || isHexToken(token) {
+		return false
+	}
+	if len(token) < 16 && !(upper && lower && symbol) {
+		return false
+	}
+
+	required := math.Min(threshold, 0.8*math.Log2(float64(len(token))))
+	return shannonEntropy(token) >= required
+}
+
+// looksLikeAddress 排除网址、邮箱和路径等常见的非密钥片段
+func looksLikeAddress(token string) bool {
+	return strings.Contains(token, "://") ||
+		strings.HasPrefix(token, "/") || strings.HasPrefix(token, "~") || strings.HasPrefix(token, ".") ||
+		strings.Contains(token, `\`) || windowsDrivePattern.MatchString(token) ||
+		(strings.Contains(token, "@") && strings.Contains(token[strings.Index(token, "@"):], "."))
+}
+
+var (
+	windowsDrivePattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+	versionPattern      = regexp.MustCompile(`^[vV]?\d+(?:\.\d+)+(?:[-+._][0-9A-Za-z.+_-]*)?$`)
+	filenamePattern     = regexp.MustCompile(`^[^/\\]+\.[A-Za-z][A-Za-z0-9]{0,4}$`)
+	digestPattern       = regexp.MustCompile(`^(?:[\w./-]+@)?[A-Za-z][A-Za-z0-9]*[:=-][0-9a-fA-F]{16,}$`)
+)
+
+// looksLikeStructuredToken 排除版本号、文件名、带算法前缀的摘要以及由单词拼接的标识符
+func looksLikeStructuredToken(token string) bool {
+	return versionPattern.MatchString(token) ||
+		filenamePattern.MatchString(token) ||
+		digestPattern.MatchString(token) ||
+		looksLikeIdentifier(token)
+}
+
+// looksLikeIdentifier 按分隔符、字母数字交替和小写转大写处切分片段，
+// 平均分段长度不小于 3 时视为 feature/JIRA-1234-fix、user1.Name 这类由单词拼接的标识符；
+// 随机字符串的大小写和数字频繁交替，分段通常只有一两个字符
+func looksLikeIdentifier(token string) bool {
+	segments, letters := 0, 0
+	var prev rune
+	for _, r := range token {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			prev = 0
+			continue
+		}
+		if prev == 0 || unicode.IsDigit(prev) != unicode.IsDigit(r) || (unicode.IsLower(prev) && unicode.IsUpper(r)) {
+			segments++
+		}
+		letters++
+		prev = r
+	}
+	return segments > 0 && float64(letters)/float64(segments) >= 3
+}
+
+// isHexToken 纯十六进制片段（哈希、UUID 等）不视为密钥
+func isHexToken(token string) bool {
+	for _, r := range token {
+		if !strings.ContainsRune("0123456789abcdefABCDEF-", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// charClasses 统计片段包含的字符类型，包含非 ASCII 字符时视为普通文本
+func charClasses(token string) (upper, lower, digit, symbol bool) {
+	for _, r := range token {
+		switch {
+		case r > unicode.MaxASCII:
+			return false, false, false, false
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	return
+}
+
+// shannonEntropy 计算每个字符的香农熵（比特）
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
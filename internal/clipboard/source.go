@@ -0,0 +1,92 @@
+package clipboard
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Source 复制发生时的活动应用和窗口标题，无法获取时为空
+type Source struct {
+	App   string
+	Title string
+}
+
+// SourceDetector 来源检测器接口，获取当前活动窗口所属的应用
+type SourceDetector interface {
+	ActiveSource() Source
+}
+
+// noSourceDetector 不支持的平台或会话（如纯 Wayland）使用的空实现
+type noSourceDetector struct{}
+
+// ActiveSource 始终返回空来源
+func (noSourceDetector) ActiveSource() Source {
+	return Source{}
+}
+
+// activeWindowPattern 匹配 `xprop -root _NET_ACTIVE_WINDOW` 输出中的窗口ID
+var activeWindowPattern = regexp.MustCompile(`window id # (0x[0-9a-fA-F]+)`)
+
+// parseActiveWindow 解析活动窗口ID，没有活动窗口时返回 false
+func parseActiveWindow(output string) (string, bool) {
+	match := activeWindowPattern.FindStringSubmatch(output)
+	if match == nil || strings.TrimLeft(match[1][2:], "0") == "" {
+		return "", false
+	}
+	return match[1], true
+}
+
+// parseWindowProperties 解析 `xprop -id <window> WM_CLASS _NET_WM_NAME WM_NAME` 的输出
+// 应用名称取 WM_CLASS 的类名部分（如 "Firefox"），标题优先使用 UTF-8 的 _NET_WM_NAME
+func parseWindowProperties(output string) Source {
+	var source Source
+	var wmName string
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "(")
+		values := parseQuoted(value)
+		if len(values) == 0 {
+			continue
+		}
+		switch name {
+		case "WM_CLASS":
+			source.App = values[len(values)-1]
+		case "_NET_WM_NAME":
+			source.Title = values[0]
+		case "WM_NAME":
+			wmName = values[0]
+		}
+	}
+	if source.Title == "" {
+		source.Title = wmName
+	}
+	return source
+}
+
+// parseQuoted 解析 xprop 输出的带引号字符串列表，如 `"firefox", "Firefox"`
+func parseQuoted(value string) []string {
+	var values []string
+	var current strings.Builder
+	inQuote, escaped := false, false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			if inQuote {
+				values = append(values, current.String())
+				current.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			current.WriteRune(r)
+		}
+	}
+	return values
+}
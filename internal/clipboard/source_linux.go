@@ -0,0 +1,57 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// xpropTimeout 单次 xprop 调用的超时，避免拖慢剪切板事件处理
+const xpropTimeout = 300 * time.Millisecond
+
+// xpropDetector 通过 xprop 读取 X11 的 _NET_ACTIVE_WINDOW 获取活动窗口
+type xpropDetector struct {
+	path string
+}
+
+// NewSourceDetector 创建来源检测器，没有 X11 显示或未安装 xprop 时返回空实现
+func NewSourceDetector() SourceDetector {
+	if os.Getenv("DISPLAY") == "" {
+		return noSourceDetector{}
+	}
+	path, err := exec.LookPath("xprop")
+	if err != nil {
+		log.Println("⚠️  未找到 xprop，不记录复制来源应用")
+		return noSourceDetector{}
+	}
+	return &xpropDetector{path: path}
+}
+
+// ActiveSource 获取当前活动窗口的应用名称和标题
+func (d *xpropDetector) ActiveSource() Source {
+	output, err := d.run("-root", "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return Source{}
+	}
+	window, ok := parseActiveWindow(output)
+	if !ok {
+		return Source{}
+	}
+	output, err = d.run("-id", window, "WM_CLASS", "_NET_WM_NAME", "WM_NAME")
+	if err != nil {
+		return Source{}
+	}
+	return parseWindowProperties(output)
+}
+
+// run 执行 xprop 并返回输出
+func (d *xpropDetector) run(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), xpropTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, d.path, args...).Output()
+	return string(output), err
+}
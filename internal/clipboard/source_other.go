@@ -0,0 +1,8 @@
+//go:build !linux
+
+package clipboard
+
+// NewSourceDetector 创建来源检测器，当前仅支持 Linux X11，其他平台不记录来源
+func NewSourceDetector() SourceDetector {
+	return noSourceDetector{}
+}
@@ -0,0 +1,62 @@
+package clipboard
+
+import "testing"
+
+func TestParseActiveWindow(t *testing.T) {
+	cases := []struct {
+		output string
+		want   string
+		ok     bool
+	}{
+		{"_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007\n", "0x3a00007", true},
+		{"_NET_ACTIVE_WINDOW(WINDOW): window id # 0x0\n", "", false},
+		{"_NET_ACTIVE_WINDOW:  not found.\n", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := parseActiveWindow(tc.output)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parseActiveWindow(%q) = %q, %v", tc.output, got, ok)
+		}
+	}
+}
+
+func TestParseWindowProperties(t *testing.T) {
+	output := `WM_CLASS(STRING) = "keepassxc", "KeePassXC"
+_NET_WM_NAME(UTF8_STRING) = "Passwords.kdbx [锁定] - \"KeePassXC\""
+WM_NAME(STRING) = "Passwords.kdbx"
+`
+	source := parseWindowProperties(output)
+	if source.App != "KeePassXC" || source.Title != `Passwords.kdbx [锁定] - "KeePassXC"` {
+		t.Fatalf("source = %+v", source)
+	}
+
+	// 没有 _NET_WM_NAME 时使用 WM_NAME
+	source = parseWindowProperties("WM_CLASS(STRING) = \"xterm\", \"XTerm\"\n_NET_WM_NAME:  not found.\nWM_NAME(STRING) = \"bash\"\n")
+	if source.App != "XTerm" || source.Title != "bash" {
+		t.Fatalf("source = %+v", source)
+	}
+}
+
+func TestContentStats(t *testing.T) {
+	lines := map[string]int{"": 0, "one": 1, "one\n": 1, "one\ntwo": 2, "one\ntwo\n\n": 2}
+	for content, want := range lines {
+		if got := CountLines(content); got != want {
+			t.Errorf("CountLines(%q) = %d, want %d", content, got, want)
+		}
+	}
+
+	languages := map[string]string{
+		"今天的会议改到下午三点":              "zh",
+		"The meeting moved to 3pm": "en",
+		"会議は午後三時に変更されました":          "ja",
+		"회의가 오후 3시로 변경되었습니다":       "ko",
+		"Встреча перенесена":       "ru",
+		"12345":                    "",
+		"ok":                       "",
+	}
+	for content, want := range languages {
+		if got := DetectLanguage(content); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package clipboard
+
+import (
+	"strings"
+	"unicode"
+
+	"Sid/internal/models"
+)
+
+// minLanguageLetters 判断语言所需的最少字母数，过短的内容不判断
+const minLanguageLetters = 3
+
+// FillContentStats 计算文本条目的字节数、行数和语言
+func FillContentStats(item *models.ClipboardItem) {
+	if item.IsImage() {
+		return
+	}
+	item.ContentLength = len(item.Content)
+	item.LineCount = CountLines(item.Content)
+	item.Language = DetectLanguage(item.Content)
+}
+
+// CountLines 统计行数，末尾的换行不计为新的一行
+func CountLines(content string) int {
+	if content == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimRight(content, "\n"), "\n") + 1
+}
+
+// DetectLanguage 按文字系统粗略判断内容的自然语言
+// 假名优先判为日文，其余取字母最多的文字系统，拉丁字母统一视为 en
+func DetectLanguage(content string) string {
+	counts := make(map[string]int)
+	total := 0
+	for _, r := range content {
+		var language string
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			language = "ja"
+		case unicode.Is(unicode.Hangul, r):
+			language = "ko"
+		case unicode.Is(unicode.Han, r):
+			language = "zh"
+		case unicode.Is(unicode.Cyrillic, r):
+			language = "ru"
+		case unicode.Is(unicode.Arabic, r):
+			language = "ar"
+		case unicode.Is(unicode.Latin, r):
+			language = "en"
+		default:
+			continue
+		}
+		counts[language]++
+		total++
+	}
+	if total < minLanguageLetters {
+		return ""
+	}
+
+	// 日文中汉字通常多于假名，出现一定比例的假名即判为日文
+	if counts["ja"] > 0 && counts["ja"]*5 >= counts["ja"]+counts["zh"] {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for _, language := range []string{"zh", "en", "ko", "ru", "ar", "ja"} {
+		if counts[language] > bestCount {
+			best, bestCount = language, counts[language]
+		}
+	}
+	return best
+}
@@ -0,0 +1,236 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"Sid/internal/models"
+)
+
+// transform 已注册的文本转换
+type transform struct {
+	info  models.TransformInfo
+	apply func(text string) (string, error)
+}
+
+// transforms 已注册的转换，用于捕获规则和粘贴时的格式调整
+var transforms = map[string]transform{}
+
+// registerTransform 注册转换，名称重复时 panic
+func registerTransform(name, description string, apply func(string) (string, error)) {
+	if _, exists := transforms[name]; exists {
+		panic("duplicate transform: " + name)
+	}
+	transforms[name] = transform{info: models.TransformInfo{Name: name, Description: description}, apply: apply}
+}
+
+// infallible 将不会失败的转换包装为注册所需的签名
+func infallible(fn func(string) string) func(string) (string, error) {
+	return func(text string) (string, error) {
+		return fn(text), nil
+	}
+}
+
+func init() {
+	registerTransform("trim", "去除首尾空白", infallible(strings.TrimSpace))
+	registerTransform("collapse_whitespace", "合并连续空白为单个空格", infallible(func(text string) string {
+		return strings.Join(strings.Fields(text), " ")
+	}))
+	registerTransform("upper", "转为大写", infallible(strings.ToUpper))
+	registerTransform("lower", "转为小写", infallible(strings.ToLower))
+	registerTransform("title", "单词首字母大写", infallible(titleCase))
+	registerTransform("strip_formatting", "去除 HTML 标签、不可见字符和排版引号", infallible(stripFormatting))
+	registerTransform("json_pretty", "格式化 JSON", jsonPretty)
+	registerTransform("json_minify", "压缩 JSON", jsonMinify)
+	registerTransform("url_encode", "URL 编码", infallible(url.QueryEscape))
+	registerTransform("url_decode", "URL 解码", url.QueryUnescape)
+	registerTransform("base64_encode", "Base64 编码", infallible(func(text string) string {
+		return base64.StdEncoding.EncodeToString([]byte(text))
+	}))
+	registerTransform("base64_decode", "Base64 解码", base64Decode)
+	registerTransform("strip_tracking", "移除链接中的跟踪参数（utm_*、fbclid 等）", infallible(stripTracking))
+	registerTransform("tabs_to_csv", "将制表符分隔的表格转为 CSV", tabsToCSV)
+}
+
+// Transforms 返回全部转换，按名称排序
+func Transforms() []models.TransformInfo {
+	list := make([]models.TransformInfo, 0, len(transforms))
+	for _, t := range transforms {
+		list = append(list, t.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// HasTransform 判断转换是否存在
+func HasTransform(name string) bool {
+	_, ok := transforms[name]
+	return ok
+}
+
+// ApplyTransform 按名称执行转换
+func ApplyTransform(name, text string) (string, error) {
+	t, ok := transforms[name]
+	if !ok {
+		return "", fmt.Errorf("未知的转换: %s", name)
+	}
+	return t.apply(text)
+}
+
+// titleCase 将每个单词的首字母转为大写，其余字母转为小写
+func titleCase(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	start := true
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' {
+			if start {
+				b.WriteRune(unicode.ToUpper(r))
+			} else {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			start = false
+			continue
+		}
+		b.WriteRune(r)
+		start = true
+	}
+	return b.String()
+}
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[a-zA-Z/!][^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+
+	// formattingReplacer 排版字符替换为普通字符，不可见字符直接删除
+	formattingReplacer = strings.NewReplacer(
+		"\u00a0", " ", "\u2002", " ", "\u2003", " ", "\u2009", " ", "\u3000", " ",
+		"\u200b", "", "\u200c", "", "\u200d", "", "\u2060", "", "\ufeff", "", "\u00ad", "",
+		"\u2018", "'", "\u2019", "'", "\u201c", `"`, "\u201d", `"`,
+		"\u2013", "-", "\u2014", "-", "\u2026", "...",
+		"\r\n", "\n", "\r", "\n",
+	)
+)
+
+// stripFormatting 去除富文本残留的格式，得到纯文本
+func stripFormatting(text string) string {
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = formattingReplacer.Replace(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text = blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// jsonPretty 以两个空格缩进格式化 JSON
+func jsonPretty(text string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(text)), "", "  "); err != nil {
+		return "", fmt.Errorf("不是有效的 JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// jsonMinify 去除 JSON 中的空白
+func jsonMinify(text string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(strings.TrimSpace(text))); err != nil {
+		return "", fmt.Errorf("不是有效的 JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// base64Decode 解码标准或 URL 安全的 Base64（填充可省略），结果必须是 UTF-8 文本
+func base64Decode(text string) (string, error) {
+	text = strings.Join(strings.Fields(text), "")
+	encodings := []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding}
+	for _, encoding := range encodings {
+		data, err := encoding.DecodeString(text)
+		if err != nil {
+			continue
+		}
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("解码结果不是文本")
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("不是有效的 Base64")
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// trackingParams 常见的跟踪参数，utm_ 开头的参数另外按前缀匹配
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true, "msclkid": true,
+	"yclid": true, "igshid": true, "mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true,
+	"mkt_tok": true, "ref_src": true, "spm": true, "si": true,
+}
+
+// stripTracking 移除文本中所有链接的跟踪参数，其余参数保持原有顺序
+func stripTracking(text string) string {
+	return linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		base, query, found := strings.Cut(link, "?")
+		if !found {
+			return link
+		}
+		query, fragment, hasFragment := strings.Cut(query, "#")
+
+		var kept []string
+		for _, param := range strings.Split(query, "&") {
+			key, _, _ := strings.Cut(param, "=")
+			if key, err := url.QueryUnescape(key); err == nil {
+				key = strings.ToLower(key)
+				if strings.HasPrefix(key, "utm_") || trackingParams[key] {
+					continue
+				}
+			}
+			if param != "" {
+				kept = append(kept, param)
+			}
+		}
+
+		result := base
+		if len(kept) > 0 {
+			result += "?" + strings.Join(kept, "&")
+		}
+		if hasFragment {
+			result += "#" + fragment
+		}
+		return result
+	})
+}
+
+// tabsToCSV 将制表符分隔的表格（如从表格软件复制的内容）转为 CSV
+func tabsToCSV(text string) (string, error) {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if !strings.Contains(text, "\t") {
+		return "", fmt.Errorf("内容中没有制表符")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	for _, line := range strings.Split(text, "\n") {
+		if err := writer.Write(strings.Split(line, "\t")); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
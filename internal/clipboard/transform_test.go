@@ -0,0 +1,67 @@
+package clipboard
+
+import "testing"
+
+func TestApplyTransform(t *testing.T) {
+	tests := []struct {
+		transform string
+		input     string
+		want      string
+		wantErr   bool
+	}{
+		{transform: "trim", input: "  hello \n", want: "hello"},
+		{transform: "collapse_whitespace", input: "a \t b\n\nc", want: "a b c"},
+		{transform: "upper", input: "Hello, 世界", want: "HELLO, 世界"},
+		{transform: "lower", input: "Hello WORLD", want: "hello world"},
+		{transform: "title", input: "hello wORLD, it's 2nd-hand", want: "Hello World, It's 2nd-Hand"},
+		{transform: "strip_formatting", input: "<p>Tom&amp;Jerry say \u201chi\u201d\u200b</p>  \n\n\n\n<b>bye</b>", want: "Tom&Jerry say \"hi\"\n\nbye"},
+		{transform: "json_pretty", input: `{"a":1,"b":[true,null]}`, want: "{\n  \"a\": 1,\n  \"b\": [\n    true,\n    null\n  ]\n}"},
+		{transform: "json_pretty", input: `{"a":`, wantErr: true},
+		{transform: "json_minify", input: "{\n  \"a\": 1,\n  \"b\": \"x y\"\n}\n", want: `{"a":1,"b":"x y"}`},
+		{transform: "json_minify", input: "not json", wantErr: true},
+		{transform: "url_encode", input: "a b&c=中", want: "a+b%26c%3D%E4%B8%AD"},
+		{transform: "url_decode", input: "a+b%26c%3D%E4%B8%AD", want: "a b&c=中"},
+		{transform: "url_decode", input: "%zz", wantErr: true},
+		{transform: "base64_encode", input: "hello 世界", want: "aGVsbG8g5LiW55WM"},
+		{transform: "base64_decode", input: "aGVsbG8g5LiW55WM\n", want: "hello 世界"},
+		{transform: "base64_decode", input: "aGk", want: "hi"},
+		{transform: "base64_decode", input: "not base64!", wantErr: true},
+		{transform: "base64_decode", input: "/w==", wantErr: true},
+		{
+			transform: "strip_tracking",
+			input:     "看看 https://example.com/a?id=1&utm_source=x&UTM_Medium=y&fbclid=z#top 和 https://example.com/b?gclid=1",
+			want:      "看看 https://example.com/a?id=1#top 和 https://example.com/b",
+		},
+		{transform: "strip_tracking", input: "https://example.com/?q=go", want: "https://example.com/?q=go"},
+		{transform: "tabs_to_csv", input: "name\tnote\nAlice\tsays \"hi\", bye\n", want: "name,note\nAlice,\"says \"\"hi\"\", bye\""},
+		{transform: "tabs_to_csv", input: "no tabs here", wantErr: true},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		covered[tt.transform] = true
+		got, err := ApplyTransform(tt.transform, tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s(%q): expected error, got %q", tt.transform, tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s(%q): %v", tt.transform, tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.transform, tt.input, got, tt.want)
+		}
+	}
+
+	for _, info := range Transforms() {
+		if !covered[info.Name] {
+			t.Errorf("transform %s has no test case", info.Name)
+		}
+	}
+	if _, err := ApplyTransform("rot13", "x"); err == nil {
+		t.Error("expected unknown transform to fail")
+	}
+}
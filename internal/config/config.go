@@ -14,12 +14,16 @@ type Manager interface {
 	Save(settings *models.Settings) error
 	GetConfigPath() string
 	GetDatabasePath() string
+	Secrets() SecretStore
+	Keyring() SecretStore
 }
 
 // configManager 配置管理器实现
 type configManager struct {
 	configPath string
 	dbPath     string
+	secrets    SecretStore
+	keyring    SecretStore
 }
 
 // NewManager 创建新的配置管理器
@@ -27,10 +31,14 @@ func NewManager() Manager {
 	homeDir, _ := os.UserHomeDir()
 	configPath := filepath.Join(homeDir, ".clipboard-manager-config.json")
 	dbPath := filepath.Join(homeDir, ".clipboard-manager.db")
+	secretsPath := filepath.Join(homeDir, ".clipboard-manager-secrets.json")
+	keyPath := filepath.Join(homeDir, ".clipboard-manager.key")
 
 	return &configManager{
 		configPath: configPath,
 		dbPath:     dbPath,
+		secrets:    NewFileSecretStore(secretsPath, keyPath),
+		keyring:    NewKeyringSecretStore(KeyringService),
 	}
 }
 
@@ -47,11 +55,23 @@ func (c *configManager) Load() (*models.Settings, error) {
 		}
 	}
 
+	// 密钥不保存在配置文件中
+	settings.LLM.APIKey = ""
+	settings.LLM.HasAPIKey = c.secrets.Has(SecretLLMAPIKey)
+
 	return &settings, nil
 }
 
-// Save 保存配置
+// Save 保存配置，大模型 API 密钥单独加密保存
 func (c *configManager) Save(settings *models.Settings) error {
+	if settings.LLM.APIKey != "" {
+		if err := c.secrets.Set(SecretLLMAPIKey, settings.LLM.APIKey); err != nil {
+			return err
+		}
+	}
+	settings.LLM.APIKey = ""
+	settings.LLM.HasAPIKey = c.secrets.Has(SecretLLMAPIKey)
+
 	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return err
@@ -68,3 +88,13 @@ func (c *configManager) GetConfigPath() string {
 func (c *configManager) GetDatabasePath() string {
 	return c.dbPath
 }
+
+// Secrets 获取敏感信息存储
+func (c *configManager) Secrets() SecretStore {
+	return c.secrets
+}
+
+// Keyring 获取系统密钥环存储，用于保存数据库密钥
+func (c *configManager) Keyring() SecretStore {
+	return c.keyring
+}
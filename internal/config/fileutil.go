@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// lockFile 对 path 加进程间排他锁，GUI 和 clipctl 修改同一文件时互斥，返回的函数用于释放锁
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFileHandle(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFileHandle(f)
+		f.Close()
+	}, nil
+}
+
+// writeFileAtomic 先写入同目录的临时文件并同步到磁盘，再重命名替换目标文件
+// 写入中途崩溃时原文件保持不变
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// 同步目录项，确保重命名本身也已落盘（Windows 不支持打开目录同步，忽略错误）
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
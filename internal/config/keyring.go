@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService 系统密钥环中条目的服务名
+const KeyringService = "clipboard-manager"
+
+// ErrKeyringUnavailable 系统密钥环不可用（例如 Linux 上没有运行 Secret Service）
+var ErrKeyringUnavailable = errors.New("系统密钥环不可用")
+
+// keyringSecretStore 基于系统密钥环的敏感信息存储
+// Linux 使用 Secret Service，macOS 使用钥匙串，Windows 使用凭据管理器（DPAPI）
+type keyringSecretStore struct {
+	service string
+}
+
+// NewKeyringSecretStore 创建新的系统密钥环存储
+func NewKeyringSecretStore(service string) SecretStore {
+	return &keyringSecretStore{service: service}
+}
+
+// Get 读取指定名称的值，不存在时返回空字符串
+func (s *keyringSecretStore) Get(name string) (string, error) {
+	value, err := keyring.Get(s.service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+	return value, nil
+}
+
+// Set 保存指定名称的值
+func (s *keyringSecretStore) Set(name, value string) error {
+	if err := keyring.Set(s.service, name, value); err != nil {
+		return fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+	return nil
+}
+
+// Delete 删除指定名称的值
+func (s *keyringSecretStore) Delete(name string) error {
+	err := keyring.Delete(s.service, name)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+	return nil
+}
+
+// Has 检查是否保存了指定名称的值
+func (s *keyringSecretStore) Has(name string) bool {
+	value, err := s.Get(name)
+	return err == nil && value != ""
+}
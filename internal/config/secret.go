@@ -0,0 +1,251 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// SecretLLMAPIKey 大模型 API 密钥的存储名称
+const SecretLLMAPIKey = "llm_api_key"
+
+// SecretDatabaseKey 数据库加密密钥的存储名称（密钥来源为 keyring 时使用）
+const SecretDatabaseKey = "database_key"
+
+// SecretRetiredDatabaseKeys 关闭加密或更换密钥后保留的旧数据库密钥（JSON 数组），用于打开旧的加密备份
+const SecretRetiredDatabaseKeys = "retired_database_keys"
+
+// SecretAPIToken 本地 HTTP API 访问令牌的存储名称
+const SecretAPIToken = "api_token"
+
+// secretKeySize AES-256 密钥长度
+const secretKeySize = 32
+
+// apiTokenSize 随机生成的 API 访问令牌字节数
+const apiTokenSize = 32
+
+// SecretStore 敏感信息存储接口
+type SecretStore interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	Has(name string) bool
+}
+
+// ErrMasterKeyMissing 密钥文件中已有条目但主密钥文件丢失，已保存的值无法再解密
+var ErrMasterKeyMissing = errors.New("主密钥文件丢失，无法解密已保存的密钥")
+
+// fileSecretStore 基于 AES-GCM 加密文件的敏感信息存储
+// 随机生成的密钥单独保存在仅当前用户可读的文件中。主密钥与数据位于同一用户目录，
+// 只能防止密钥以明文出现在配置中，不能防御能读取用户目录的攻击者
+// GUI 与 clipctl 可能同时修改，读写都持有进程间文件锁，写入使用临时文件加重命名
+type fileSecretStore struct {
+	mu        sync.Mutex
+	path      string
+	keyPath   string
+	cachedKey []byte
+}
+
+// NewFileSecretStore 创建新的加密文件存储
+func NewFileSecretStore(path, keyPath string) SecretStore {
+	return &fileSecretStore{
+		path:    path,
+		keyPath: keyPath,
+	}
+}
+
+// Get 读取并解密指定名称的值，不存在时返回空字符串
+func (s *fileSecretStore) Get(name string) (string, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := secrets[name]
+	if !ok {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("密钥数据已损坏: %w", err)
+	}
+	gcm, err := s.cipher(secrets)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("密钥数据已损坏")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("解密失败: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Set 加密并保存指定名称的值
+func (s *fileSecretStore) Set(name, value string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	gcm, err := s.cipher(secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	secrets[name] = base64.StdEncoding.EncodeToString(sealed)
+	return s.save(secrets)
+}
+
+// Delete 删除指定名称的值
+func (s *fileSecretStore) Delete(name string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return nil
+	}
+	delete(secrets, name)
+	return s.save(secrets)
+}
+
+// Has 检查是否保存了指定名称的值
+func (s *fileSecretStore) Has(name string) bool {
+	unlock, err := s.lock()
+	if err != nil {
+		return false
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return false
+	}
+	_, ok := secrets[name]
+	return ok
+}
+
+// lock 获取进程内和进程间的互斥锁
+func (s *fileSecretStore) lock() (func(), error) {
+	s.mu.Lock()
+	release, err := lockFile(s.path + ".lock")
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("锁定密钥文件失败: %w", err)
+	}
+	return func() {
+		release()
+		s.mu.Unlock()
+	}, nil
+}
+
+// load 读取加密文件中的全部条目
+func (s *fileSecretStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	return secrets, nil
+}
+
+// save 写入加密文件
+func (s *fileSecretStore) save(secrets map[string]string) error {
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0600)
+}
+
+// cipher 加载主密钥并创建 AES-GCM，仅在还没有保存任何条目时生成新的主密钥
+func (s *fileSecretStore) cipher(secrets map[string]string) (cipher.AEAD, error) {
+	if s.cachedKey == nil {
+		key, err := os.ReadFile(s.keyPath)
+		if errors.Is(err, os.ErrNotExist) {
+			// 已有条目时生成新密钥会让它们永远无法解密
+			if len(secrets) > 0 {
+				return nil, ErrMasterKeyMissing
+			}
+			key = make([]byte, secretKeySize)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			if err := writeFileAtomic(s.keyPath, key, 0600); err != nil {
+				return nil, fmt.Errorf("保存主密钥失败: %w", err)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("读取主密钥失败: %w", err)
+		}
+		if len(key) != secretKeySize {
+			return nil, errors.New("主密钥长度无效")
+		}
+		s.cachedKey = key
+	}
+
+	block, err := aes.NewCipher(s.cachedKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EnsureAPIToken 读取本地 HTTP API 访问令牌，尚未生成时随机生成并保存
+func EnsureAPIToken(store SecretStore) (string, error) {
+	token, err := store.Get(SecretAPIToken)
+	if err != nil || token != "" {
+		return token, err
+	}
+	return RegenerateAPIToken(store)
+}
+
+// RegenerateAPIToken 生成并保存新的访问令牌，旧令牌随即失效
+func RegenerateAPIToken(store SecretStore) (string, error) {
+	buf := make([]byte, apiTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := store.Set(SecretAPIToken, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.json")
+	keyPath := filepath.Join(dir, "secrets.key")
+
+	store := NewFileSecretStore(path, keyPath)
+	if store.Has(SecretLLMAPIKey) {
+		t.Fatal("expected empty store")
+	}
+	if err := store.Set(SecretLLMAPIKey, "sk-test-123"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件中不应出现明文
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-test-123") {
+		t.Error("secret stored in plaintext")
+	}
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected key file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// 重新打开后可以解密
+	reopened := NewFileSecretStore(path, keyPath)
+	value, err := reopened.Get(SecretLLMAPIKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "sk-test-123" {
+		t.Errorf("unexpected value %q", value)
+	}
+
+	// 换用其他主密钥无法解密
+	other := NewFileSecretStore(path, filepath.Join(dir, "other.key"))
+	if _, err := other.Get(SecretLLMAPIKey); err == nil {
+		t.Error("expected decryption to fail with a different key")
+	}
+
+	if err := reopened.Delete(SecretLLMAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Has(SecretLLMAPIKey) {
+		t.Error("expected secret to be deleted")
+	}
+}
+
+func TestFileSecretStore_MissingMasterKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.json")
+	keyPath := filepath.Join(dir, "secrets.key")
+	if err := NewFileSecretStore(path, keyPath).Set(SecretLLMAPIKey, "sk-test-123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已有条目时不能生成新的主密钥，否则旧条目永远无法解密
+	store := NewFileSecretStore(path, keyPath)
+	if err := store.Set(SecretAPIToken, "token"); !errors.Is(err, ErrMasterKeyMissing) {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := os.Stat(keyPath); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected no new master key to be created")
+	}
+}
+
+func TestFileSecretStore_ConcurrentProcesses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.json")
+	keyPath := filepath.Join(dir, "secrets.key")
+
+	// 每个存储实例模拟一个独立进程（GUI 和 clipctl），只通过文件锁互斥
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store := NewFileSecretStore(path, keyPath)
+			if err := store.Set(fmt.Sprintf("secret-%d", i), "value"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	store := NewFileSecretStore(path, keyPath)
+	for i := 0; i < 8; i++ {
+		if value, err := store.Get(fmt.Sprintf("secret-%d", i)); err != nil || value != "value" {
+			t.Errorf("secret-%d = %q, %v", i, value, err)
+		}
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestEnsureAPIToken(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileSecretStore(filepath.Join(dir, "secrets.json"), filepath.Join(dir, "secrets.key"))
+
+	token, err := EnsureAPIToken(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) != 2*apiTokenSize {
+		t.Errorf("unexpected token %q", token)
+	}
+	again, err := EnsureAPIToken(store)
+	if err != nil || again != token {
+		t.Errorf("expected existing token to be reused, got %q %v", again, err)
+	}
+
+	regenerated, err := RegenerateAPIToken(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regenerated == token {
+		t.Error("expected a new token")
+	}
+	if current, _ := EnsureAPIToken(store); current != regenerated {
+		t.Errorf("expected regenerated token to be stored, got %q", current)
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// BackupInfo 备份文件信息
+type BackupInfo struct {
+	Path      string    `json:"path"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
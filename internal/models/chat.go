@@ -15,6 +15,10 @@ type ChatSession struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 	LastActiveAt time.Time `json:"last_active_at" db:"last_active_at"`
+
+	// 滚动摘要：超出上下文预算的早期消息被压缩为摘要，SummaryUntil 为已压缩的最后一条消息的创建时间
+	Summary      string     `json:"summary" db:"summary"`
+	SummaryUntil *time.Time `json:"summary_until,omitempty" db:"summary_until"`
 }
 
 // ChatMessage 聊天消息模型
@@ -31,6 +35,43 @@ type ChatMessage struct {
 	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
 }
 
+// ChatReference 聊天回答参考的剪切板条目，Index 对应回答中的引用编号 [n]
+type ChatReference struct {
+	Index  int    `json:"index"`
+	ItemID string `json:"item_id"`
+	Title  string `json:"title"`
+}
+
+// 助手消息 Metadata（以及流式完成响应的 ChatResponse.Metadata）中的字段
+const (
+	ChatMetadataReferences   = "references"     // []ChatReference，发送给模型的全部参考条目
+	ChatMetadataCitedItemIDs = "cited_item_ids" // []string，回答中实际引用的条目 ID
+	ChatMetadataToolCalls    = "tool_calls"     // []ChatToolCall，生成回答过程中的工具调用
+	ChatMetadataInterrupted  = "interrupted"    // bool，生成被停止或应用退出时中断，内容只是已生成的部分
+	ChatMetadataError        = "error"          // string，模型请求出错导致中断时的错误信息
+)
+
+// ChatToolCall 智能体的一次工具调用及其结果
+type ChatToolCall struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Arguments   string `json:"arguments"` // JSON 格式的参数
+	Destructive bool   `json:"destructive"`
+	Status      string `json:"status"`
+	Result      string `json:"result,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// ConfirmationID 只出现在 tool_confirm 事件中，由服务端生成，提交确认时使用；模型返回的 ID 可能在不同会话间重复
+	ConfirmationID string `json:"confirmation_id,omitempty"`
+}
+
+// ToolCallStatus 工具调用状态
+const (
+	ToolCallStatusPending  = "pending" // 等待执行或等待用户确认
+	ToolCallStatusSuccess  = "success"
+	ToolCallStatusError    = "error"
+	ToolCallStatusRejected = "rejected" // 用户拒绝或确认超时
+)
+
 // ChatRequest 聊天请求模型
 type ChatRequest struct {
 	SessionID string `json:"session_id"`
@@ -65,7 +106,7 @@ type ChatMessageListResponse struct {
 
 // StreamResponse 流式响应
 type StreamResponse struct {
-	Type      string      `json:"type"` // message, error, complete
+	Type      string      `json:"type"` // started, message, error, complete, cancelled, tool_call, tool_confirm, tool_result
 	Data      interface{} `json:"data"` // 响应数据
 	MessageID string      `json:"message_id,omitempty"`
 	Error     string      `json:"error,omitempty"`
@@ -87,7 +128,14 @@ const (
 
 // StreamResponseType 流式响应类型常量
 const (
-	StreamTypeMessage  = "message"
-	StreamTypeError    = "error"
-	StreamTypeComplete = "complete"
+	StreamTypeStarted   = "started" // 助手消息已创建，MessageID 可用于 CancelStream
+	StreamTypeMessage   = "message"
+	StreamTypeError     = "error"
+	StreamTypeComplete  = "complete"
+	StreamTypeCancelled = "cancelled" // 用户停止生成，Data 为已生成部分的 ChatResponse
+
+	// 工具调用事件，Data 为 ChatToolCall
+	StreamTypeToolCall    = "tool_call"    // 模型请求调用工具
+	StreamTypeToolConfirm = "tool_confirm" // 工具需要用户确认，通过 ConfirmToolCall 回复
+	StreamTypeToolResult  = "tool_result"  // 工具执行完成（成功、失败或被拒绝）
 )
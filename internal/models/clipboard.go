@@ -8,7 +8,8 @@ type ClipboardItem struct {
 	Content     string     `json:"content" db:"content"`
 	ContentType string     `json:"content_type" db:"content_type"`
 	Title       string     `json:"title" db:"title"`
-	Tags        []Tag      `json:"tags,omitempty"` // 通过关联查询获取的标签
+	Thumbnail   string     `json:"thumbnail,omitempty"` // 图片条目的缩略图（data URL）
+	Tags        []Tag      `json:"tags,omitempty"`      // 通过关联查询获取的标签
 	Category    string     `json:"category" db:"category"`
 	IsFavorite  bool       `json:"is_favorite" db:"is_favorite"`
 	UseCount    int        `json:"use_count" db:"use_count"`
@@ -17,6 +18,45 @@ type ClipboardItem struct {
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	LastUsedAt  time.Time  `json:"last_used_at" db:"last_used_at"`
+	IsSensitive bool       `json:"is_sensitive" db:"is_sensitive"` // 疑似密码等敏感内容，不会发送给AI
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`     // 到期后自动永久删除，为空表示不过期
+
+	// 复制时采集的元数据
+	SourceApp     string `json:"source_app" db:"source_app"`         // 复制时的活动应用，无法获取时为空
+	SourceTitle   string `json:"source_title" db:"source_title"`     // 复制时的活动窗口标题
+	ContentLength int    `json:"content_length" db:"content_length"` // 内容字节数，图片为 PNG 数据大小
+	LineCount     int    `json:"line_count" db:"line_count"`
+	Language      string `json:"language" db:"language"`           // 检测到的自然语言（zh、en、ja 等），无法判断时为空
+	CodeLanguage  string `json:"code_language" db:"code_language"` // ContentType 为 code 时猜测的编程语言（go、python 等）
+
+	// 以下字段仅在搜索结果中填充
+	Snippet    string           `json:"snippet,omitempty"`    // 命中位置附近的摘要（HTML 转义，命中部分以 <mark> 标记）
+	Highlights []HighlightRange `json:"highlights,omitempty"` // 命中位置
+	Score      float64          `json:"score,omitempty"`      // 语义和混合搜索的相关度（0-1）
+}
+
+// HighlightRange 搜索命中范围，Start/End 为按 Unicode 字符（rune）计算的偏移，左闭右开
+type HighlightRange struct {
+	Field string `json:"field"` // title 或 content
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// IsImage 判断是否为图片条目
+func (c *ClipboardItem) IsImage() bool {
+	return c.ContentType == ContentTypeImage
+}
+
+// ClipboardImage 剪切板图片数据（存储在 clipboard_images 表中）
+type ClipboardImage struct {
+	ItemID    string    `json:"item_id" db:"item_id"`
+	Hash      string    `json:"hash" db:"hash"`   // PNG 数据的 SHA-256
+	Data      []byte    `json:"-" db:"data"`      // 原始 PNG 数据
+	Thumbnail []byte    `json:"-" db:"thumbnail"` // PNG 缩略图
+	Width     int       `json:"width" db:"width"`
+	Height    int       `json:"height" db:"height"`
+	Size      int       `json:"size" db:"size"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 // GetTagNames 获取标签名称列表
@@ -24,7 +64,7 @@ func (c *ClipboardItem) GetTagNames() []string {
 	if c.Tags == nil {
 		return []string{}
 	}
-	
+
 	names := make([]string, len(c.Tags))
 	for i, tag := range c.Tags {
 		names[i] = tag.Name
@@ -38,8 +78,24 @@ type SearchQuery struct {
 	Category string   `json:"category"`
 	Tags     []string `json:"tags"`     // 标签名称列表
 	TagMode  string   `json:"tag_mode"` // all, any, none
+	Untagged bool     `json:"untagged"` // 仅返回没有任何标签的条目
 	Limit    int      `json:"limit"`
 	Offset   int      `json:"offset"`
+
+	// 创建时间范围，为空表示不限制
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+
+	// 元数据过滤，零值表示不限制
+	SourceApp string `json:"source_app,omitempty"` // 来源应用，不区分大小写
+	Language  string `json:"language,omitempty"`
+	MinLength int    `json:"min_length,omitempty"` // 内容字节数下限
+	MaxLength int    `json:"max_length,omitempty"` // 内容字节数上限
+
+	// 搜索模式：空或 keyword、semantic、hybrid，未启用语义搜索时按关键词搜索
+	Mode string `json:"mode,omitempty"`
+	// 限定在这些条目内搜索（语义检索的候选条目），不对外公开
+	IDs []string `json:"-"`
 }
 
 // SearchResult 搜索结果
@@ -110,11 +166,11 @@ type TagSearchQuery struct {
 
 // TagStatistics 标签统计信息
 type TagStatistics struct {
-	TotalTags     int            `json:"total_tags"`
-	MostUsedTags  []TagWithStats `json:"most_used_tags"`
-	RecentTags    []TagWithStats `json:"recent_tags"`
-	TagGroups     []TagGroup     `json:"tag_groups"`
-	UnusedTags    []Tag          `json:"unused_tags"`
+	TotalTags    int            `json:"total_tags"`
+	MostUsedTags []TagWithStats `json:"most_used_tags"`
+	RecentTags   []TagWithStats `json:"recent_tags"`
+	TagGroups    []TagGroup     `json:"tag_groups"`
+	UnusedTags   []Tag          `json:"unused_tags"`
 }
 
 // CategoryTagsResponse 分类和标签响应
@@ -123,6 +179,31 @@ type CategoryTagsResponse struct {
 	Tags       []string `json:"tags"`
 }
 
+// ContentType 内容类型常量
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+
+	// 以下由内容检测得出，均为文本条目
+	ContentTypeURL         = "url"
+	ContentTypeEmail       = "email"
+	ContentTypeJSON        = "json"
+	ContentTypeYAML        = "yaml"
+	ContentTypeXML         = "xml"
+	ContentTypeHTML        = "html"
+	ContentTypeMarkdown    = "markdown"
+	ContentTypeSQL         = "sql"
+	ContentTypeShell       = "shell"      // 命令行命令或脚本
+	ContentTypeStackTrace  = "stacktrace" // 异常堆栈
+	ContentTypeCode        = "code"       // 编程语言见 CodeLanguage
+	ContentTypeColor       = "color"      // 十六进制颜色值
+	ContentTypeIP          = "ip"         // IPv4/IPv6 地址或 CIDR 网段
+	ContentTypeUUID        = "uuid"
+	ContentTypePhone       = "phone"
+	ContentTypeDate        = "date"
+	ContentTypeCoordinates = "coordinates" // 纬度,经度
+)
+
 // Category 分类常量
 const (
 	CategoryText   = "文本"
@@ -131,6 +212,10 @@ const (
 	CategoryPath   = "路径"
 	CategoryEmail  = "邮箱"
 	CategoryNumber = "数字"
+	CategoryImage  = "图片"
+	CategoryCode   = "代码"
+	CategoryData   = "数据"
+	CategoryColor  = "颜色"
 )
 
 // GetAllCategories 获取所有分类
@@ -142,5 +227,9 @@ func GetAllCategories() []string {
 		CategoryPath,
 		CategoryEmail,
 		CategoryNumber,
+		CategoryImage,
+		CategoryCode,
+		CategoryData,
+		CategoryColor,
 	}
-}
\ No newline at end of file
+}
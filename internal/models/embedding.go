@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ItemEmbedding 剪切板条目的文本向量（clipboard_embeddings 表）
+type ItemEmbedding struct {
+	ItemID    string    `json:"item_id"`
+	Model     string    `json:"model"`  // 生成向量的模型，不同模型的向量不能混用
+	Vector    []float32 `json:"vector"` // 已归一化为单位向量
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SemanticMatch 语义检索命中的条目及余弦相似度
+type SemanticMatch struct {
+	ItemID string  `json:"item_id"`
+	Score  float64 `json:"score"`
+}
+
+// EmbeddingStatus 语义索引状态
+type EmbeddingStatus struct {
+	Enabled   bool   `json:"enabled"`
+	Model     string `json:"model"`
+	Indexed   int    `json:"indexed"`    // 已生成向量的条目数
+	Pending   int    `json:"pending"`    // 等待生成向量的条目数
+	Running   bool   `json:"running"`    // 后台索引是否在运行
+	LastError string `json:"last_error"` // 最近一次生成向量失败的原因
+}
+
+// 搜索模式
+const (
+	SearchModeKeyword  = "keyword"  // 关键词（FTS / LIKE），默认
+	SearchModeSemantic = "semantic" // 仅按向量相似度
+	SearchModeHybrid   = "hybrid"   // 关键词排名与向量相似度加权混合
+)
@@ -0,0 +1,14 @@
+package models
+
+// 数据库加密密钥来源
+const (
+	EncryptionKeySourcePassphrase = "passphrase" // 由用户口令派生的密钥保护，每次启动需要解锁
+	EncryptionKeySourceKeyring    = "keyring"    // 密钥保存在系统密钥环中，启动时自动解锁
+)
+
+// EncryptionStatus 数据库加密状态
+type EncryptionStatus struct {
+	Enabled   bool   `json:"enabled"`
+	Locked    bool   `json:"locked"` // 已启用但尚未解锁，此时无法读写加密内容
+	KeySource string `json:"key_source"`
+}
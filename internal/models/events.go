@@ -0,0 +1,24 @@
+package models
+
+// ItemDeletedEvent 条目删除事件（item:deleted）
+type ItemDeletedEvent struct {
+	IDs          []string `json:"ids,omitempty"`
+	Permanent    bool     `json:"permanent"`     // 永久删除，否则为移入回收站
+	EmptiedTrash bool     `json:"emptied_trash"` // 清空回收站，此时不列出条目ID
+}
+
+// TagsChangedEvent 标签变化事件（tags:changed）
+type TagsChangedEvent struct {
+	ItemID string `json:"item_id,omitempty"` // 条目标签变化时为条目ID，标签或分组本身变化时为空
+}
+
+// MonitorStateEvent 剪切板监听状态事件（monitor:state）
+type MonitorStateEvent struct {
+	Monitoring bool `json:"monitoring"`
+}
+
+// ChatStreamEvent 聊天流式响应片段（chat:stream）
+type ChatStreamEvent struct {
+	SessionID string `json:"session_id"`
+	StreamResponse
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RetentionReport 一次自动清理的结果
+type RetentionReport struct {
+	Expired  map[string]int `json:"expired"`  // 按分类统计到期移入回收站的条目数
+	Overflow int            `json:"overflow"` // 超出最大条目数移入回收站的条目数
+	Purged   int            `json:"purged"`   // 从回收站永久删除的条目数
+	Timeout  int            `json:"timeout"`  // 到达自动过期时间被永久删除的条目数（敏感内容规则设置）
+	RanAt    time.Time      `json:"ran_at"`
+}
+
+// Removed 本次清理移除的条目总数
+func (r RetentionReport) Removed() int {
+	total := r.Overflow + r.Purged + r.Timeout
+	for _, n := range r.Expired {
+		total += n
+	}
+	return total
+}
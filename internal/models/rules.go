@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// CaptureRule 捕获规则：按顺序匹配新复制的文本，全部条件满足时执行动作
+type CaptureRule struct {
+	ID        string `json:"id" db:"id"`
+	Name      string `json:"name" db:"name"`
+	Enabled   bool   `json:"enabled" db:"enabled"`
+	SortOrder int    `json:"sort_order" db:"sort_order"` // 越小越先匹配
+	Stop      bool   `json:"stop" db:"stop"`             // 匹配后不再检查后续规则
+
+	// 匹配条件，零值表示不限制
+	SourceApp string `json:"source_app" db:"source_app"` // 来源应用，不区分大小写
+	Pattern   string `json:"pattern" db:"pattern"`       // 匹配内容的正则表达式
+	MinLength int    `json:"min_length" db:"min_length"` // 内容字节数下限
+	MaxLength int    `json:"max_length" db:"max_length"` // 内容字节数上限
+	Category  string `json:"category" db:"category"`     // 自动检测出的分类
+
+	Actions   []RuleAction `json:"actions" db:"actions"` // 以 JSON 保存
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// RuleAction 规则动作
+type RuleAction struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"` // 标签（逗号分隔）、分类、过期分钟数或转换名称
+}
+
+// 规则动作类型
+const (
+	RuleActionIgnore    = "ignore"    // 不保存
+	RuleActionTag       = "tag"       // 添加标签
+	RuleActionFavorite  = "favorite"  // 标记收藏
+	RuleActionCategory  = "category"  // 设置分类
+	RuleActionExpire    = "expire"    // 保存后按分钟数自动过期
+	RuleActionTransform = "transform" // 保存前转换内容
+)
+
+// RuleTestRequest 规则试运行请求，Rules 为空时使用已保存的规则
+type RuleTestRequest struct {
+	Content   string        `json:"content"`
+	SourceApp string        `json:"source_app"`
+	Rules     []CaptureRule `json:"rules,omitempty"`
+}
+
+// RuleTestResult 规则试运行结果，不会保存任何数据
+type RuleTestResult struct {
+	Matched []string      `json:"matched"` // 命中的规则名称，按执行顺序
+	Ignored bool          `json:"ignored"`
+	Reason  string        `json:"reason,omitempty"` // 不会保存的原因
+	Tags    []string      `json:"tags"`
+	Item    ClipboardItem `json:"item"` // 执行动作后将要保存的条目
+}
+
+// TransformInfo 可用的文本转换
+type TransformInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
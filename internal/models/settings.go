@@ -1,16 +1,132 @@
 package models
 
+import "strings"
+
 // Settings 应用程序配置
 type Settings struct {
-	MainHotkey      []string `json:"main_hotkey"`
-	EscapeHotkey    []string `json:"escape_hotkey"`
-	Position        string   `json:"position"` // "left" or "right"
-	AutoCapture     bool     `json:"auto_capture"`
-	MaxItems        int      `json:"max_items"`
-	IgnorePasswords bool     `json:"ignore_passwords"`
-	IgnoreImages    bool     `json:"ignore_images"`
-	DefaultCategory string   `json:"default_category"`
-	AutoCategorize  bool     `json:"auto_categorize"`
+	MainHotkey      []string          `json:"main_hotkey"`
+	EscapeHotkey    []string          `json:"escape_hotkey"`
+	Position        string            `json:"position"` // "left" or "right"
+	AutoCapture     bool              `json:"auto_capture"`
+	MaxItems        int               `json:"max_items"`
+	IgnorePasswords bool              `json:"ignore_passwords"`
+	IgnoreImages    bool              `json:"ignore_images"`
+	DefaultCategory string            `json:"default_category"`
+	AutoCategorize  bool              `json:"auto_categorize"`
+	AutoTag         bool              `json:"auto_tag"`         // 新条目自动加入后台AI打标签队列
+	PollIntervalMs  int               `json:"poll_interval_ms"` // 兜底轮询间隔（毫秒），0 表示仅依赖变化事件
+	IgnoreApps      []string          `json:"ignore_apps"`      // 不记录来自这些应用的复制（按应用名称匹配，不区分大小写）
+	LLM             LLMSettings       `json:"llm"`
+	Retention       RetentionSettings `json:"retention"`
+	Sensitive       SensitiveSettings `json:"sensitive"` // IgnorePasswords 开启时生效
+	Backup          BackupSettings    `json:"backup"`
+	API             APISettings       `json:"api"`
+}
+
+// SensitiveSettings 敏感内容检测规则
+type SensitiveSettings struct {
+	Rules            []SensitiveRule `json:"rules"`
+	EntropyThreshold float64         `json:"entropy_threshold"` // 高熵字符串检测阈值（比特/字符）
+}
+
+// SensitiveRule 单个检测器的开关和命中后的处理方式
+type SensitiveRule struct {
+	Detector      string `json:"detector"`
+	Enabled       bool   `json:"enabled"`
+	Action        string `json:"action"`         // skip、redact 或 expire
+	ExpireMinutes int    `json:"expire_minutes"` // action 为 expire 时原文保留的分钟数
+}
+
+// 敏感内容检测器名称
+const (
+	SensitiveDetectorAWSKey     = "aws_access_key"
+	SensitiveDetectorGitHub     = "github_token"
+	SensitiveDetectorOpenAI     = "openai_key"
+	SensitiveDetectorJWT        = "jwt"
+	SensitiveDetectorPrivateKey = "private_key"
+	SensitiveDetectorCreditCard = "credit_card"
+	SensitiveDetectorChineseID  = "cn_id_card"
+	SensitiveDetectorPhone      = "phone"
+	SensitiveDetectorEntropy    = "entropy"
+)
+
+// 敏感内容处理方式
+const (
+	SensitiveActionSkip   = "skip"   // 不保存
+	SensitiveActionRedact = "redact" // 打码后保存
+	SensitiveActionExpire = "expire" // 保存原文，到期后自动删除
+)
+
+// RetentionSettings 自动清理策略，收藏的条目不受影响
+type RetentionSettings struct {
+	TrashDays        int            `json:"trash_days"`         // 回收站条目保留天数，0 表示不自动清理
+	CategoryTTLHours map[string]int `json:"category_ttl_hours"` // 各分类条目的保留时长（小时），到期后移入回收站，0 表示不过期
+	IntervalMinutes  int            `json:"interval_minutes"`   // 自动清理间隔（分钟），0 表示不自动执行
+}
+
+// BackupSettings 自动备份策略
+type BackupSettings struct {
+	Enabled       bool   `json:"enabled"`
+	Directory     string `json:"directory"`      // 备份目录，为空时使用数据库所在目录下的 backups
+	IntervalHours int    `json:"interval_hours"` // 自动备份间隔（小时）
+	KeepDaily     int    `json:"keep_daily"`     // 保留最近 N 天每天最新的一份
+	KeepWeekly    int    `json:"keep_weekly"`    // 保留最近 N 周每周最新的一份
+}
+
+// APISettings 本地 HTTP API 配置，访问令牌单独加密保存
+type APISettings struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"` // 仅监听 127.0.0.1
+}
+
+// LLMSettings 大模型服务配置
+type LLMSettings struct {
+	Provider       string            `json:"provider"` // OpenAI 兼容服务标识，如 "ark"、"openai"
+	BaseURL        string            `json:"base_url"`
+	Model          string            `json:"model"`
+	APIKey         string            `json:"api_key,omitempty"` // 仅用于提交新密钥，不会写入配置文件，留空表示保持不变
+	HasAPIKey      bool              `json:"has_api_key"`       // 是否已保存密钥
+	Temperature    float32           `json:"temperature"`
+	MaxTokens      int               `json:"max_tokens"` // 0 表示使用服务端默认值
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	ContextTokens  int               `json:"context_tokens"` // 聊天历史（含摘要）的 token 预算，超出时早期消息压缩为摘要
+	Retrieval      RetrievalSettings `json:"retrieval"`      // 聊天时检索剪切板历史作为参考资料
+	Agent          AgentSettings     `json:"agent"`          // 聊天时允许模型调用工具操作剪切板
+	Embedding      EmbeddingSettings `json:"embedding"`      // 语义搜索使用的文本向量模型
+	Local          LocalLLMSettings  `json:"local"`          // 本地 OpenAI 兼容服务（Ollama / llama.cpp server）
+}
+
+// RetrievalSettings 聊天检索剪切板历史的配置，敏感条目和图片不会被检索
+type RetrievalSettings struct {
+	Enabled  bool `json:"enabled"`
+	MaxItems int  `json:"max_items"` // 每条消息最多引用的条目数
+	MaxChars int  `json:"max_chars"` // 每个条目发送给模型的最大字符数，超出部分截断
+}
+
+// AgentSettings 聊天智能体（工具调用）配置
+type AgentSettings struct {
+	Enabled               bool `json:"enabled"`
+	MaxSteps              int  `json:"max_steps"`               // 每条消息最多调用模型的轮数
+	ConfirmTimeoutSeconds int  `json:"confirm_timeout_seconds"` // 需要确认的操作等待用户确认的时间，超时视为拒绝
+}
+
+// LocalLLMSettings 本地大模型服务配置
+type LocalLLMSettings struct {
+	Enabled     bool   `json:"enabled"`
+	BaseURL     string `json:"base_url"`
+	Model       string `json:"model"`        // 留空时自动使用服务端列出的第一个模型
+	PreferLocal bool   `json:"prefer_local"` // 优先使用本地服务，远程服务作为备用
+}
+
+// LLMProviderStatus 大模型服务健康状态
+type LLMProviderStatus struct {
+	Name      string   `json:"name"`
+	BaseURL   string   `json:"base_url"`
+	Model     string   `json:"model"`
+	Healthy   bool     `json:"healthy"`
+	Models    []string `json:"models"`
+	LatencyMs int64    `json:"latency_ms"`
+	Error     string   `json:"error,omitempty"`
 }
 
 // DefaultSettings 返回默认设置
@@ -25,6 +141,96 @@ func DefaultSettings() Settings {
 		IgnoreImages:    false,
 		DefaultCategory: CategoryText,
 		AutoCategorize:  true,
+		AutoTag:         true,
+		PollIntervalMs:  2000,
+		IgnoreApps:      []string{"KeePassXC", "KeePass", "1Password", "Bitwarden", "Enpass", "Seahorse"},
+		LLM:             DefaultLLMSettings(),
+		Retention: RetentionSettings{
+			TrashDays:        30,
+			CategoryTTLHours: map[string]int{CategoryNumber: 24},
+			IntervalMinutes:  60,
+		},
+		Sensitive: DefaultSensitiveSettings(),
+		Backup: BackupSettings{
+			Enabled:       true,
+			IntervalHours: 24,
+			KeepDaily:     7,
+			KeepWeekly:    4,
+		},
+		API: APISettings{
+			Port: 27182,
+		},
+	}
+}
+
+// IgnoresApp 判断是否不记录来自该应用的复制
+func (s *Settings) IgnoresApp(app string) bool {
+	if app == "" {
+		return false
+	}
+	for _, ignored := range s.IgnoreApps {
+		if strings.EqualFold(strings.TrimSpace(ignored), app) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSensitiveSettings 返回默认敏感内容检测规则
+func DefaultSensitiveSettings() SensitiveSettings {
+	return SensitiveSettings{
+		Rules: []SensitiveRule{
+			{Detector: SensitiveDetectorAWSKey, Enabled: true, Action: SensitiveActionSkip},
+			{Detector: SensitiveDetectorGitHub, Enabled: true, Action: SensitiveActionSkip},
+			{Detector: SensitiveDetectorOpenAI, Enabled: true, Action: SensitiveActionSkip},
+			{Detector: SensitiveDetectorPrivateKey, Enabled: true, Action: SensitiveActionSkip},
+			{Detector: SensitiveDetectorJWT, Enabled: true, Action: SensitiveActionExpire, ExpireMinutes: 60},
+			{Detector: SensitiveDetectorChineseID, Enabled: true, Action: SensitiveActionRedact},
+			{Detector: SensitiveDetectorCreditCard, Enabled: true, Action: SensitiveActionRedact},
+			{Detector: SensitiveDetectorPhone, Enabled: false, Action: SensitiveActionRedact},
+			{Detector: SensitiveDetectorEntropy, Enabled: true, Action: SensitiveActionRedact},
+		},
+		EntropyThreshold: 3.5,
+	}
+}
+
+// EmbeddingSettings 语义搜索配置，开启后在后台为条目生成文本向量，敏感条目和图片不会发送
+type EmbeddingSettings struct {
+	Enabled      bool    `json:"enabled"`
+	BaseURL      string  `json:"base_url"`      // OpenAI 兼容 /embeddings 接口地址，为空时使用 llm.base_url 和已保存的密钥
+	Model        string  `json:"model"`         // 更换模型后所有条目会重新生成向量
+	HybridWeight float64 `json:"hybrid_weight"` // 混合搜索中向量相似度的权重（0-1），其余为关键词排名
+}
+
+// DefaultContextTokens 默认的聊天历史 token 预算
+const DefaultContextTokens = 4000
+
+// DefaultLLMSettings 返回默认大模型配置（不含密钥）
+func DefaultLLMSettings() LLMSettings {
+	return LLMSettings{
+		Provider:       "ark",
+		BaseURL:        "https://ark.cn-beijing.volces.com/api/v3",
+		Model:          "doubao-1-5-pro-32k-250115",
+		Temperature:    0.7,
+		TimeoutSeconds: 60,
+		ContextTokens:  DefaultContextTokens,
+		Retrieval: RetrievalSettings{
+			Enabled:  true,
+			MaxItems: 5,
+			MaxChars: 1000,
+		},
+		Agent: AgentSettings{
+			Enabled:               true,
+			MaxSteps:              6,
+			ConfirmTimeoutSeconds: 120,
+		},
+		Embedding: EmbeddingSettings{
+			Model:        "doubao-embedding-text-240715",
+			HybridWeight: 0.5,
+		},
+		Local: LocalLLMSettings{
+			BaseURL: "http://localhost:11434/v1",
+		},
 	}
 }
 
@@ -36,4 +242,4 @@ type WindowState struct {
 	ScreenSize   string `json:"screenSize"`
 	Position     string `json:"position"`
 	IsMonitoring bool   `json:"isMonitoring"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// TaggingJob 后台AI打标签任务
+type TaggingJob struct {
+	ID        string    `json:"id" db:"id"`
+	ItemID    string    `json:"item_id" db:"item_id"`
+	Status    string    `json:"status" db:"status"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	LastError string    `json:"last_error" db:"last_error"`
+	NextRunAt time.Time `json:"next_run_at" db:"next_run_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TaggingQueueStatus 打标签队列状态
+type TaggingQueueStatus struct {
+	Running   bool   `json:"running"`
+	Workers   int    `json:"workers"`
+	Pending   int    `json:"pending"`
+	InFlight  int    `json:"in_flight"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// TaggingProgress 单个任务完成时推送的进度事件
+type TaggingProgress struct {
+	ItemID string             `json:"item_id"`
+	Status string             `json:"status"`
+	Tags   []string           `json:"tags,omitempty"`
+	Error  string             `json:"error,omitempty"`
+	Queue  TaggingQueueStatus `json:"queue"`
+}
+
+// 打标签任务状态常量
+const (
+	TaggingStatusPending = "pending"
+	TaggingStatusRunning = "running"
+	TaggingStatusDone    = "done"
+	TaggingStatusFailed  = "failed"
+	TaggingStatusSkipped = "skipped"
+)
+
+// RetagOptions 批量重新打标签参数
+type RetagOptions struct {
+	Query       SearchQuery `json:"query"`       // 筛选需要处理的条目，Limit/Offset 会被忽略
+	Concurrency int         `json:"concurrency"` // 并发数，默认 2
+}
+
+// RetagSummary 批量重新打标签的进度与结果汇总
+type RetagSummary struct {
+	State       string     `json:"state"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Tagged      int        `json:"tagged"`
+	Skipped     int        `json:"skipped"`
+	Failed      int        `json:"failed"`
+	TagsCreated []string   `json:"tags_created"` // 新建的标签
+	TagsMerged  []string   `json:"tags_merged"`  // 关联到已有标签的标签
+	LastError   string     `json:"last_error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// RetagProgress 批量重新打标签时每处理一个条目推送的进度事件
+type RetagProgress struct {
+	ItemID  string       `json:"item_id,omitempty"`
+	Status  string       `json:"status,omitempty"`
+	Tags    []string     `json:"tags,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Summary RetagSummary `json:"summary"`
+}
+
+// 批量重新打标签任务状态常量
+const (
+	RetagStateIdle      = "idle"
+	RetagStateRunning   = "running"
+	RetagStatePaused    = "paused"
+	RetagStateCompleted = "completed"
+	RetagStateCancelled = "cancelled"
+)
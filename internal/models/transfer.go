@@ -0,0 +1,165 @@
+package models
+
+import "time"
+
+// ExportFormatVersion 导出文件格式版本，格式说明见 README 的导入导出一节
+const ExportFormatVersion = 1
+
+// 导出格式
+const (
+	ExportFormatJSONL    = "jsonl"    // 完整数据：标签分组、标签、条目和聊天会话，可导入
+	ExportFormatCSV      = "csv"      // 仅条目，便于在表格软件中查看
+	ExportFormatMarkdown = "markdown" // 仅条目，便于阅读
+)
+
+// JSON Lines 记录类型
+const (
+	ExportRecordHeader      = "header"
+	ExportRecordTagGroup    = "tag_group"
+	ExportRecordTag         = "tag"
+	ExportRecordItem        = "item"
+	ExportRecordChatSession = "chat_session"
+)
+
+// 导入冲突处理方式（按 ID 判断冲突）
+const (
+	ImportConflictSkip      = "skip"      // 保留已有数据
+	ImportConflictOverwrite = "overwrite" // 用导入的数据替换已有数据
+	ImportConflictMerge     = "merge"     // 保留已有数据，按名称合并标签，聊天会话补充缺少的消息
+)
+
+// 单条记录的导入结果
+const (
+	ImportOutcomeCreated = "created"
+	ImportOutcomeUpdated = "updated"
+	ImportOutcomeSkipped = "skipped"
+)
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	Format       string      `json:"format"`
+	Query        SearchQuery `json:"query"`         // 条目过滤条件，Limit/Offset 会被忽略
+	IncludeChats bool        `json:"include_chats"` // 是否导出聊天会话（仅 jsonl）
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Conflict string `json:"conflict"`
+}
+
+// ExportRecord JSON Lines 中的一行，Type 决定哪个字段有值
+type ExportRecord struct {
+	Type        string             `json:"type"`
+	Header      *ExportHeader      `json:"header,omitempty"`
+	TagGroup    *TagGroup          `json:"tag_group,omitempty"`
+	Tag         *Tag               `json:"tag,omitempty"`
+	Item        *ExportItem        `json:"item,omitempty"`
+	ChatSession *ExportChatSession `json:"chat_session,omitempty"`
+}
+
+// ExportHeader 导出文件头，必须是第一行
+type ExportHeader struct {
+	Format     string    `json:"format"` // 固定为 sid-export
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// ExportItem 导出的剪切板条目，标签按名称引用
+type ExportItem struct {
+	ID           string       `json:"id"`
+	Content      string       `json:"content"`
+	ContentType  string       `json:"content_type"`
+	CodeLanguage string       `json:"code_language,omitempty"`
+	Title        string       `json:"title"`
+	Category     string       `json:"category"`
+	Tags         []string     `json:"tags"`
+	IsFavorite   bool         `json:"is_favorite"`
+	UseCount     int          `json:"use_count"`
+	IsDeleted    bool         `json:"is_deleted"`
+	DeletedAt    *time.Time   `json:"deleted_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	LastUsedAt   time.Time    `json:"last_used_at"`
+	IsSensitive  bool         `json:"is_sensitive"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty"`
+	Image        *ExportImage `json:"image,omitempty"`
+}
+
+// ExportImage 图片条目的原始数据（base64）
+type ExportImage struct {
+	Hash      string `json:"hash"`
+	Data      []byte `json:"data"`
+	Thumbnail []byte `json:"thumbnail,omitempty"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// ExportChatSession 导出的聊天会话及其全部消息
+type ExportChatSession struct {
+	Session  ChatSession   `json:"session"`
+	Messages []ChatMessage `json:"messages"`
+}
+
+// ExportReport 导出结果
+type ExportReport struct {
+	Items        int `json:"items"`
+	TagGroups    int `json:"tag_groups"`
+	Tags         int `json:"tags"`
+	ChatSessions int `json:"chat_sessions"`
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	ItemsCreated        int      `json:"items_created"`
+	ItemsUpdated        int      `json:"items_updated"`
+	ItemsSkipped        int      `json:"items_skipped"`
+	TagGroupsCreated    int      `json:"tag_groups_created"`
+	TagsCreated         int      `json:"tags_created"`
+	ChatSessionsCreated int      `json:"chat_sessions_created"`
+	ChatSessionsUpdated int      `json:"chat_sessions_updated"`
+	ChatSessionsSkipped int      `json:"chat_sessions_skipped"`
+	Errors              []string `json:"errors"` // 单条记录的错误，不会中断导入
+}
+
+// NewExportItem 将条目转换为导出格式
+func NewExportItem(item ClipboardItem) ExportItem {
+	return ExportItem{
+		ID:           item.ID,
+		Content:      item.Content,
+		ContentType:  item.ContentType,
+		CodeLanguage: item.CodeLanguage,
+		Title:        item.Title,
+		Category:     item.Category,
+		Tags:         item.GetTagNames(),
+		IsFavorite:   item.IsFavorite,
+		UseCount:     item.UseCount,
+		IsDeleted:    item.IsDeleted,
+		DeletedAt:    item.DeletedAt,
+		CreatedAt:    item.CreatedAt,
+		UpdatedAt:    item.UpdatedAt,
+		LastUsedAt:   item.LastUsedAt,
+		IsSensitive:  item.IsSensitive,
+		ExpiresAt:    item.ExpiresAt,
+	}
+}
+
+// ClipboardItem 转换为剪切板条目（不含标签）
+func (e ExportItem) ClipboardItem() ClipboardItem {
+	return ClipboardItem{
+		ID:           e.ID,
+		Content:      e.Content,
+		ContentType:  e.ContentType,
+		CodeLanguage: e.CodeLanguage,
+		Title:        e.Title,
+		Category:     e.Category,
+		IsFavorite:   e.IsFavorite,
+		UseCount:     e.UseCount,
+		IsDeleted:    e.IsDeleted,
+		DeletedAt:    e.DeletedAt,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+		LastUsedAt:   e.LastUsedAt,
+		IsSensitive:  e.IsSensitive,
+		ExpiresAt:    e.ExpiresAt,
+	}
+}
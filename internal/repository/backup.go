@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Path 获取数据库文件路径
+func (db *Database) Path() string {
+	return db.path
+}
+
+// BackupTo 使用 VACUUM INTO 生成一致的数据库快照，WAL 中尚未检查点的写入同样包含在内
+func (db *Database) BackupTo(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file already exists: %s", path)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to backup database: %v", err)
+	}
+	return nil
+}
+
+// VerifyBackup 以只读方式打开备份文件并执行完整性检查
+func VerifyBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	conn, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	var tables int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'clipboard_items'").Scan(&tables); err != nil {
+		return err
+	}
+	if tables == 0 {
+		return errors.New("not a clipboard database")
+	}
+	return nil
+}
+
+// RestoreFrom 使用 SQLite 在线备份 API 将备份文件的内容整体写入当前数据库
+// 写入在一个事务中完成，其他连接不会看到中间状态；完成后重新执行迁移并按备份的加密配置重新锁定
+func (db *Database) RestoreFrom(path string) error {
+	if err := VerifyBackup(path); err != nil {
+		return err
+	}
+
+	src, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	ctx := context.Background()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	dstConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	err = dstConn.Raw(func(dstDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			dst, ok := dstDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("unexpected sqlite driver connection")
+			}
+			source, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("unexpected sqlite driver connection")
+			}
+
+			backup, err := dst.Backup("main", source, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore database: %v", err)
+	}
+
+	log.Printf("♻️  已从备份恢复数据库: %s", path)
+	return db.migrate(MigrateOptions{})
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func TestDatabase_BackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDatabase(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+	now := time.Now()
+	if err := repo.Create(newTestItem("1", "备份前", "备份前的内容", now)); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := filepath.Join(dir, "backup.db")
+	if err := db.BackupTo(backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyBackup(backup); err != nil {
+		t.Fatalf("expected fresh backup to verify, got %v", err)
+	}
+	if err := db.BackupTo(backup); err == nil {
+		t.Error("expected existing backup file not to be overwritten")
+	}
+
+	// 备份后的修改在恢复后应当消失
+	if err := repo.Create(newTestItem("2", "备份后", "备份后的内容", now)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PermanentDelete("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.RestoreFrom(backup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID("1"); err != nil {
+		t.Errorf("expected item from backup to be restored, got %v", err)
+	}
+	if _, err := repo.GetByID("2"); err == nil {
+		t.Error("expected item created after backup to be gone")
+	}
+	results, err := repo.Search(models.SearchQuery{Query: "备份前", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Items) != 1 {
+		t.Errorf("expected restored item to be searchable, got %d results", len(results.Items))
+	}
+}
+
+func TestVerifyBackup_RejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyBackup(garbage); err == nil {
+		t.Error("expected garbage file to fail verification")
+	}
+	if err := VerifyBackup(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected missing file to fail verification")
+	}
+}
@@ -17,61 +17,97 @@ type ChatRepository interface {
 	UpdateChatSession(sessionID string, title string) error
 	DeleteChatSession(sessionID string) error
 	UpdateChatSessionAfterMessage(sessionID, lastMessage string) error
+	UpdateChatSessionSummary(sessionID, summary string, until time.Time) error
 
 	// 消息操作
 	CreateChatMessage(message *models.ChatMessage) error
+	GetChatMessage(messageID string) (*models.ChatMessage, error)
 	GetChatMessages(sessionID string, limit, offset int) ([]models.ChatMessage, error)
+	GetChatMessagesAfter(sessionID string, after *time.Time) ([]models.ChatMessage, error)
 	GetChatMessageCount(sessionID string) (int, error)
 	UpdateChatMessage(message *models.ChatMessage) error
 	DeleteChatMessage(messageID string) error
+	TruncateChatMessages(sessionID string, from time.Time) (int, error)
+	GetStreamingChatMessages() ([]models.ChatMessage, error)
+}
+
+// sessionColumns 会话查询列，顺序与 scanSession 一致
+const sessionColumns = "id, title, description, last_message, message_count, is_active, created_at, updated_at, last_active_at, summary, summary_until"
+
+// scanSession 按 sessionColumns 的顺序扫描一行并解密
+func (r *chatRepository) scanSession(row rowScanner) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := row.Scan(
+		&session.ID, &session.Title, &session.Description, &session.LastMessage,
+		&session.MessageCount, &session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.LastActiveAt,
+		&session.Summary, &session.SummaryUntil)
+	if err != nil {
+		return nil, err
+	}
+	if session.Title, err = r.cipher.Decrypt(session.Title); err != nil {
+		return nil, err
+	}
+	if session.LastMessage, err = r.cipher.Decrypt(session.LastMessage); err != nil {
+		return nil, err
+	}
+	if session.Summary, err = r.cipher.Decrypt(session.Summary); err != nil {
+		return nil, err
+	}
+	return &session, nil
 }
 
 // chatRepository 聊天数据仓库实现
 type chatRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *ContentCipher
 }
 
-// NewChatRepository 创建新的聊天数据仓库
-func NewChatRepository(db *sql.DB) ChatRepository {
-	return &chatRepository{db: db}
+// NewChatRepository 创建新的聊天数据仓库，cipher 为 nil 时不加密
+func NewChatRepository(db *sql.DB, cipher *ContentCipher) ChatRepository {
+	return &chatRepository{db: db, cipher: cipher}
 }
 
 // CreateChatSession 创建聊天会话
 func (r *chatRepository) CreateChatSession(session *models.ChatSession) error {
 	query := `
-	INSERT INTO chat_sessions (id, title, description, last_message, message_count, is_active, created_at, updated_at, last_active_at)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO chat_sessions (` + sessionColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query, session.ID, session.Title, session.Description, session.LastMessage,
-		session.MessageCount, session.IsActive, session.CreatedAt, session.UpdatedAt, session.LastActiveAt)
+	title, err := r.cipher.Encrypt(session.Title)
+	if err != nil {
+		return err
+	}
+	lastMessage, err := r.cipher.Encrypt(session.LastMessage)
+	if err != nil {
+		return err
+	}
+	summary, err := r.cipher.Encrypt(session.Summary)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, session.ID, title, session.Description, lastMessage,
+		session.MessageCount, session.IsActive, session.CreatedAt, session.UpdatedAt, session.LastActiveAt,
+		summary, session.SummaryUntil)
 	return err
 }
 
 // GetChatSession 获取聊天会话
 func (r *chatRepository) GetChatSession(sessionID string) (*models.ChatSession, error) {
 	query := `
-	SELECT id, title, description, last_message, message_count, is_active, created_at, updated_at, last_active_at
+	SELECT ` + sessionColumns + `
 	FROM chat_sessions
 	WHERE id = ?
 	`
 
-	var session models.ChatSession
-	err := r.db.QueryRow(query, sessionID).Scan(
-		&session.ID, &session.Title, &session.Description, &session.LastMessage,
-		&session.MessageCount, &session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.LastActiveAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &session, nil
+	return r.scanSession(r.db.QueryRow(query, sessionID))
 }
 
 // ListChatSessions 获取所有聊天会话
 func (r *chatRepository) ListChatSessions() ([]models.ChatSession, error) {
 	query := `
-	SELECT id, title, description, last_message, message_count, is_active, created_at, updated_at, last_active_at
+	SELECT ` + sessionColumns + `
 	FROM chat_sessions
 	WHERE is_active = 1
 	ORDER BY last_active_at DESC
@@ -85,14 +121,11 @@ func (r *chatRepository) ListChatSessions() ([]models.ChatSession, error) {
 
 	var sessions []models.ChatSession
 	for rows.Next() {
-		var session models.ChatSession
-		err := rows.Scan(
-			&session.ID, &session.Title, &session.Description, &session.LastMessage,
-			&session.MessageCount, &session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.LastActiveAt)
+		session, err := r.scanSession(rows)
 		if err != nil {
 			return nil, err
 		}
-		sessions = append(sessions, session)
+		sessions = append(sessions, *session)
 	}
 
 	return sessions, nil
@@ -100,8 +133,12 @@ func (r *chatRepository) ListChatSessions() ([]models.ChatSession, error) {
 
 // UpdateChatSession 更新聊天会话
 func (r *chatRepository) UpdateChatSession(sessionID string, title string) error {
+	title, err := r.cipher.Encrypt(title)
+	if err != nil {
+		return err
+	}
 	query := `UPDATE chat_sessions SET title = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.Exec(query, title, time.Now(), sessionID)
+	_, err = r.db.Exec(query, title, time.Now(), sessionID)
 	return err
 }
 
@@ -136,8 +173,24 @@ func (r *chatRepository) UpdateChatSessionAfterMessage(sessionID, lastMessage st
 	SET last_message = ?, message_count = message_count + 1, last_active_at = ?, updated_at = ?
 	WHERE id = ?
 	`
+	lastMessage, err := r.cipher.Encrypt(lastMessage)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	_, err := r.db.Exec(query, lastMessage, now, now, sessionID)
+	_, err = r.db.Exec(query, lastMessage, now, now, sessionID)
+	return err
+}
+
+// UpdateChatSessionSummary 保存会话的滚动摘要及其覆盖到的最后一条消息时间
+func (r *chatRepository) UpdateChatSessionSummary(sessionID, summary string, until time.Time) error {
+	summary, err := r.cipher.Encrypt(summary)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`UPDATE chat_sessions SET summary = ?, summary_until = ?, updated_at = ? WHERE id = ?`,
+		summary, until, time.Now(), sessionID)
 	return err
 }
 
@@ -150,12 +203,46 @@ func (r *chatRepository) CreateChatMessage(message *models.ChatMessage) error {
 	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query, message.ID, message.SessionID, message.Role, message.Content,
-		message.ContentType, string(metadataJSON), message.IsStreaming, message.IsComplete,
+	content, err := r.cipher.Encrypt(message.Content)
+	if err != nil {
+		return err
+	}
+	// metadata 可能包含引用的剪切板条目标题，与内容一样加密
+	metadata, err := r.cipher.Encrypt(string(metadataJSON))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, message.ID, message.SessionID, message.Role, content,
+		message.ContentType, metadata, message.IsStreaming, message.IsComplete,
 		message.CreatedAt, message.UpdatedAt)
 	return err
 }
 
+// GetChatMessage 获取单条聊天消息，不存在时返回 sql.ErrNoRows
+func (r *chatRepository) GetChatMessage(messageID string) (*models.ChatMessage, error) {
+	query := `
+	SELECT id, session_id, role, content, content_type, metadata, is_streaming, is_complete, created_at, updated_at
+	FROM chat_messages
+	WHERE id = ?
+	`
+
+	rows, err := r.db.Query(query, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &messages[0], nil
+}
+
 // GetChatMessages 获取聊天消息
 func (r *chatRepository) GetChatMessages(sessionID string, limit, offset int) ([]models.ChatMessage, error) {
 	query := `
@@ -172,6 +259,47 @@ func (r *chatRepository) GetChatMessages(sessionID string, limit, offset int) ([
 	}
 	defer rows.Close()
 
+	return r.scanMessages(rows)
+}
+
+// GetChatMessagesAfter 获取创建时间晚于 after 的全部消息（按时间正序），after 为 nil 时返回全部消息
+func (r *chatRepository) GetChatMessagesAfter(sessionID string, after *time.Time) ([]models.ChatMessage, error) {
+	query := `
+	SELECT id, session_id, role, content, content_type, metadata, is_streaming, is_complete, created_at, updated_at
+	FROM chat_messages
+	WHERE session_id = ? AND (? IS NULL OR created_at > ?)
+	ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, sessionID, after, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+// GetStreamingChatMessages 获取仍标记为生成中的消息（全部会话）
+func (r *chatRepository) GetStreamingChatMessages() ([]models.ChatMessage, error) {
+	query := `
+	SELECT id, session_id, role, content, content_type, metadata, is_streaming, is_complete, created_at, updated_at
+	FROM chat_messages
+	WHERE is_streaming = 1
+	ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+// scanMessages 扫描并解密消息列表
+func (r *chatRepository) scanMessages(rows *sql.Rows) ([]models.ChatMessage, error) {
 	var messages []models.ChatMessage
 	for rows.Next() {
 		var message models.ChatMessage
@@ -184,6 +312,12 @@ func (r *chatRepository) GetChatMessages(sessionID string, limit, offset int) ([
 		if err != nil {
 			return nil, err
 		}
+		if message.Content, err = r.cipher.Decrypt(message.Content); err != nil {
+			return nil, err
+		}
+		if metadataJSON, err = r.cipher.Decrypt(metadataJSON); err != nil {
+			return nil, err
+		}
 
 		// 解析metadata
 		if metadataJSON != "" {
@@ -193,7 +327,7 @@ func (r *chatRepository) GetChatMessages(sessionID string, limit, offset int) ([
 		messages = append(messages, message)
 	}
 
-	return messages, nil
+	return messages, rows.Err()
 }
 
 // GetChatMessageCount 获取聊天消息总数
@@ -214,7 +348,16 @@ func (r *chatRepository) UpdateChatMessage(message *models.ChatMessage) error {
 	WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query, message.Content, string(metadataJSON), message.IsStreaming,
+	content, err := r.cipher.Encrypt(message.Content)
+	if err != nil {
+		return err
+	}
+	metadata, err := r.cipher.Encrypt(string(metadataJSON))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, content, metadata, message.IsStreaming,
 		message.IsComplete, message.UpdatedAt, message.ID)
 	return err
 }
@@ -225,3 +368,37 @@ func (r *chatRepository) DeleteChatMessage(messageID string) error {
 	_, err := r.db.Exec(query, messageID)
 	return err
 }
+
+// TruncateChatMessages 删除会话中创建时间不早于 from 的消息，返回删除的消息数
+// 会话计数按删除的助手回复扣减；滚动摘要覆盖了被删除的消息时一并清空，之后按剩余消息重新压缩
+func (r *chatRepository) TruncateChatMessages(sessionID string, from time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted, replies int
+	err = tx.QueryRow(`SELECT COUNT(*), COUNT(CASE WHEN role = ? THEN 1 END) FROM chat_messages WHERE session_id = ? AND created_at >= ?`,
+		models.MessageRoleAssistant, sessionID, from).Scan(&deleted, &replies)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM chat_messages WHERE session_id = ? AND created_at >= ?`, sessionID, from); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+	UPDATE chat_sessions
+	SET message_count = MAX(message_count - ?, 0),
+		summary = CASE WHEN summary_until >= ? THEN '' ELSE summary END,
+		summary_until = CASE WHEN summary_until >= ? THEN NULL ELSE summary_until END,
+		updated_at = ?
+	WHERE id = ?
+	`, replies, from, from, time.Now(), sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"Sid/internal/models"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
@@ -26,27 +27,102 @@ type ClipboardRepository interface {
 	UseItem(id string) error
 	GetAllCategories() ([]string, error)
 	GetAllTags() ([]string, error)
+
+	// 保留策略（均不影响收藏的条目），返回受影响的条目数
+	ExpireCategory(category string, before time.Time) (int, error)
+	TrimToLimit(maxItems int) (int, error)
+	PurgeTrash(before time.Time) (int, error)
+	DeleteExpired(now time.Time) (int, error)
+
+	// 图片数据
+	CreateImage(image models.ClipboardImage) error
+	GetImage(itemID string) (*models.ClipboardImage, error)
+	IsDuplicateImage(hash string) (bool, error)
+}
+
+// itemColumns 条目查询列，顺序与 scanItem 一致
+const itemColumns = "id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at, is_sensitive, expires_at, source_app, source_title, content_length, line_count, language, code_language"
+
+// searchColumns 搜索查询使用的条目列（带 ci 别名）
+const searchColumns = "ci.id, ci.content, ci.content_type, ci.title, ci.category, ci.is_favorite, ci.use_count, ci.is_deleted, ci.deleted_at, ci.created_at, ci.updated_at, ci.last_used_at, ci.is_sensitive, ci.expires_at, ci.source_app, ci.source_title, ci.content_length, ci.line_count, ci.language, ci.code_language"
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanItem 按 itemColumns 的顺序扫描一行
+func scanItem(row rowScanner, item *models.ClipboardItem) error {
+	return row.Scan(&item.ID, &item.Content, &item.ContentType, &item.Title,
+		&item.Category, &item.IsFavorite, &item.UseCount, &item.IsDeleted, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt, &item.LastUsedAt,
+		&item.IsSensitive, &item.ExpiresAt, &item.SourceApp, &item.SourceTitle, &item.ContentLength, &item.LineCount, &item.Language, &item.CodeLanguage)
 }
 
 // clipboardRepository 剪切板数据仓库实现
 type clipboardRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *ContentCipher
+}
+
+// NewClipboardRepository 创建新的剪切板数据仓库，cipher 为 nil 时不加密
+func NewClipboardRepository(db *sql.DB, cipher *ContentCipher) ClipboardRepository {
+	return &clipboardRepository{db: db, cipher: cipher}
 }
 
-// NewClipboardRepository 创建新的剪切板数据仓库
-func NewClipboardRepository(db *sql.DB) ClipboardRepository {
-	return &clipboardRepository{db: db}
+// ftsEnabled 检查全文索引当前是否可用
+// 启用、关闭加密和从备份恢复都会删除或重建索引，因此每次搜索时检查
+func (r *clipboardRepository) ftsEnabled() bool {
+	var enabled bool
+	r.db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')
+		AND EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'clipboard_fts')`).Scan(&enabled)
+	return enabled
+}
+
+// encryptItem 加密条目的内容和标题，并计算用于重复检测的内容指纹
+func (r *clipboardRepository) encryptItem(item models.ClipboardItem) (content, title string, hash sql.NullString, err error) {
+	if content, err = r.cipher.Encrypt(item.Content); err != nil {
+		return
+	}
+	if title, err = r.cipher.Encrypt(item.Title); err != nil {
+		return
+	}
+	hash.String, err = r.cipher.Fingerprint(item.Content)
+	hash.Valid = hash.String != ""
+	return
+}
+
+// decryptItem 解密条目的内容、标题和来源窗口标题
+func (r *clipboardRepository) decryptItem(item *models.ClipboardItem) error {
+	var err error
+	if item.Content, err = r.cipher.Decrypt(item.Content); err != nil {
+		return err
+	}
+	if item.SourceTitle, err = r.cipher.Decrypt(item.SourceTitle); err != nil {
+		return err
+	}
+	item.Title, err = r.cipher.Decrypt(item.Title)
+	return err
 }
 
 // Create 创建新的剪切板条目
 func (r *clipboardRepository) Create(item models.ClipboardItem) error {
+	content, title, hash, err := r.encryptItem(item)
+	if err != nil {
+		return err
+	}
+	sourceTitle, err := r.cipher.Encrypt(item.SourceTitle)
+	if err != nil {
+		return err
+	}
+
 	query := `
-	INSERT INTO clipboard_items (id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO clipboard_items (` + itemColumns + `, content_hash)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query, item.ID, item.Content, item.ContentType, item.Title,
-		item.Category, item.IsFavorite, item.UseCount, item.IsDeleted, item.DeletedAt, item.CreatedAt, item.UpdatedAt, item.LastUsedAt)
+	_, err = r.db.Exec(query, item.ID, content, item.ContentType, title,
+		item.Category, item.IsFavorite, item.UseCount, item.IsDeleted, item.DeletedAt, item.CreatedAt, item.UpdatedAt, item.LastUsedAt,
+		item.IsSensitive, item.ExpiresAt, item.SourceApp, sourceTitle, item.ContentLength, item.LineCount, item.Language, item.CodeLanguage, hash)
 
 	return err
 }
@@ -54,29 +130,31 @@ func (r *clipboardRepository) Create(item models.ClipboardItem) error {
 // GetByID 根据ID获取剪切板条目
 func (r *clipboardRepository) GetByID(id string) (*models.ClipboardItem, error) {
 	query := `
-	SELECT id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at
+	SELECT ` + itemColumns + `
 	FROM clipboard_items
 	WHERE id = ?
 	`
 
 	var item models.ClipboardItem
 
-	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.Content, &item.ContentType, &item.Title,
-		&item.Category, &item.IsFavorite, &item.UseCount, &item.IsDeleted, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt, &item.LastUsedAt)
-
+	err := scanItem(r.db.QueryRow(query, id), &item)
 	if err != nil {
 		return nil, err
 	}
+	if err := r.decryptItem(&item); err != nil {
+		return nil, err
+	}
 
 	// 加载标签信息
 	item.Tags, _ = r.loadTagsForItem(item.ID)
+	r.loadThumbnail(&item)
 	return &item, nil
 }
 
 // List 获取剪切板条目列表（仅活跃条目）
 func (r *clipboardRepository) List(limit, offset int) ([]models.ClipboardItem, error) {
 	query := `
-	SELECT id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at
+	SELECT ` + itemColumns + `
 	FROM clipboard_items
 	WHERE is_deleted = 0
 	ORDER BY created_at DESC
@@ -95,7 +173,7 @@ func (r *clipboardRepository) List(limit, offset int) ([]models.ClipboardItem, e
 // GetTrashItems 获取回收站条目
 func (r *clipboardRepository) GetTrashItems(limit, offset int) ([]models.ClipboardItem, error) {
 	query := `
-	SELECT id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at
+	SELECT ` + itemColumns + `
 	FROM clipboard_items
 	WHERE is_deleted = 1
 	ORDER BY deleted_at DESC
@@ -115,14 +193,26 @@ func (r *clipboardRepository) GetTrashItems(limit, offset int) ([]models.Clipboa
 func (r *clipboardRepository) Update(item models.ClipboardItem) error {
 	item.UpdatedAt = time.Now()
 
+	content, title, hash, err := r.encryptItem(item)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE clipboard_items 
-	SET content = ?, content_type = ?, title = ?, category = ?, is_favorite = ?, is_deleted = ?, deleted_at = ?, updated_at = ?
+	SET content = ?, content_type = ?, title = ?, category = ?, is_favorite = ?, is_deleted = ?, deleted_at = ?, updated_at = ?, is_sensitive = ?, content_hash = ?
 	WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query, item.Content, item.ContentType, item.Title,
-		item.Category, item.IsFavorite, item.IsDeleted, item.DeletedAt, item.UpdatedAt, item.ID)
+	_, err = r.db.Exec(query, content, item.ContentType, title,
+		item.Category, item.IsFavorite, item.IsDeleted, item.DeletedAt, item.UpdatedAt, item.IsSensitive, hash, item.ID)
+	if err != nil || item.IsImage() {
+		return err
+	}
+
+	// 文本内容可能被修改，同步更新内容统计和检测出的编程语言（图片的统计在创建时确定）
+	_, err = r.db.Exec(`UPDATE clipboard_items SET content_length = ?, line_count = ?, language = ?, code_language = ? WHERE id = ?`,
+		item.ContentLength, item.LineCount, item.Language, item.CodeLanguage, item.ID)
 
 	return err
 }
@@ -174,29 +264,131 @@ func (r *clipboardRepository) EmptyTrash() error {
 	return err
 }
 
+// ExpireCategory 将分类下创建时间早于 before 的条目移入回收站
+func (r *clipboardRepository) ExpireCategory(category string, before time.Time) (int, error) {
+	now := time.Now()
+	query := `
+	UPDATE clipboard_items SET is_deleted = 1, deleted_at = ?, updated_at = ?
+	WHERE is_deleted = 0 AND is_favorite = 0 AND category = ? AND created_at < ?
+	`
+	return affectedRows(r.db.Exec(query, now, now, category, before))
+}
+
+// TrimToLimit 活跃条目超过 maxItems 时，将最早创建的非收藏条目移入回收站
+func (r *clipboardRepository) TrimToLimit(maxItems int) (int, error) {
+	if maxItems <= 0 {
+		return 0, nil
+	}
+
+	// 收藏条目同样占用名额，但不会被移除
+	var favorites int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM clipboard_items WHERE is_deleted = 0 AND is_favorite = 1`).Scan(&favorites); err != nil {
+		return 0, err
+	}
+	keep := max(maxItems-favorites, 0)
+
+	now := time.Now()
+	query := `
+	UPDATE clipboard_items SET is_deleted = 1, deleted_at = ?, updated_at = ?
+	WHERE id IN (
+		SELECT id FROM clipboard_items
+		WHERE is_deleted = 0 AND is_favorite = 0
+		ORDER BY created_at DESC
+		LIMIT -1 OFFSET ?
+	)
+	`
+	return affectedRows(r.db.Exec(query, now, now, keep))
+}
+
+// PurgeTrash 永久删除在回收站中超过保留期限的条目
+func (r *clipboardRepository) PurgeTrash(before time.Time) (int, error) {
+	query := `DELETE FROM clipboard_items WHERE is_deleted = 1 AND is_favorite = 0 AND deleted_at < ?`
+	return affectedRows(r.db.Exec(query, before))
+}
+
+// DeleteExpired 永久删除已到自动过期时间的条目
+func (r *clipboardRepository) DeleteExpired(now time.Time) (int, error) {
+	query := `DELETE FROM clipboard_items WHERE expires_at IS NOT NULL AND expires_at <= ? AND is_favorite = 0`
+	return affectedRows(r.db.Exec(query, now))
+}
+
+// affectedRows 返回语句影响的行数
+func affectedRows(result sql.Result, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
 // Search 搜索剪切板条目
+// 有全文索引且关键词均不少于3个字符时使用 FTS5 并按 BM25 排序，否则回退到 LIKE 匹配
+// 数据库加密后无法在 SQL 中匹配内容，关键词改为解密后在内存中匹配
 func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchResult, error) {
 	var result models.SearchResult
 
-	sqlQuery := `
-	SELECT id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at
-	FROM clipboard_items
-	WHERE is_deleted = 0
-	`
+	terms := splitSearchTerms(query.Query)
+	decryptMatch := r.cipher.Configured() && len(terms) > 0
+	useFTS := !decryptMatch && len(terms) > 0 && allTermsIndexable(terms) && r.ftsEnabled()
 
+	fromClause := " FROM clipboard_items ci"
+	whereClause := " WHERE ci.is_deleted = 0"
 	var args []interface{}
 
-	if query.Query != "" {
-		sqlQuery += " AND (content LIKE ? OR title LIKE ?)"
-		searchTerm := "%" + query.Query + "%"
-		args = append(args, searchTerm, searchTerm)
+	if useFTS {
+		fromClause += " INNER JOIN clipboard_fts ON clipboard_fts.rowid = ci.rowid"
+		whereClause += " AND clipboard_fts MATCH ?"
+		args = append(args, buildMatchQuery(terms))
+	} else if !decryptMatch {
+		for _, term := range terms {
+			whereClause += " AND (ci.content LIKE ? OR ci.title LIKE ?)"
+			searchTerm := "%" + term + "%"
+			args = append(args, searchTerm, searchTerm)
+		}
+	}
+
+	if len(query.IDs) > 0 {
+		whereClause += " AND ci.id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(query.IDs)), ",") + ")"
+		for _, id := range query.IDs {
+			args = append(args, id)
+		}
 	}
 
 	if query.Category != "" {
-		sqlQuery += " AND category = ?"
+		whereClause += " AND ci.category = ?"
 		args = append(args, query.Category)
 	}
 
+	if query.CreatedAfter != nil {
+		whereClause += " AND ci.created_at >= ?"
+		args = append(args, *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		whereClause += " AND ci.created_at < ?"
+		args = append(args, *query.CreatedBefore)
+	}
+
+	if query.SourceApp != "" {
+		whereClause += " AND ci.source_app = ? COLLATE NOCASE"
+		args = append(args, query.SourceApp)
+	}
+	if query.Language != "" {
+		whereClause += " AND ci.language = ?"
+		args = append(args, query.Language)
+	}
+	if query.MinLength > 0 {
+		whereClause += " AND ci.content_length >= ?"
+		args = append(args, query.MinLength)
+	}
+	if query.MaxLength > 0 {
+		whereClause += " AND ci.content_length <= ?"
+		args = append(args, query.MaxLength)
+	}
+
+	if query.Untagged {
+		whereClause += " AND NOT EXISTS (SELECT 1 FROM clipboard_item_tags cit WHERE cit.item_id = ci.id)"
+	}
+
 	// 标签查询
 	if len(query.Tags) > 0 {
 		tagMode := query.TagMode
@@ -207,7 +399,7 @@ func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchRes
 		switch tagMode {
 		case "all": // 包含所有标签
 			for range query.Tags {
-				sqlQuery += ` AND id IN (
+				whereClause += ` AND ci.id IN (
 					SELECT DISTINCT cit.item_id 
 					FROM clipboard_item_tags cit 
 					INNER JOIN tags t ON cit.tag_id = t.id 
@@ -219,7 +411,7 @@ func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchRes
 			}
 		case "none": // 不包含任何标签
 			for range query.Tags {
-				sqlQuery += ` AND id NOT IN (
+				whereClause += ` AND ci.id NOT IN (
 					SELECT DISTINCT cit.item_id 
 					FROM clipboard_item_tags cit 
 					INNER JOIN tags t ON cit.tag_id = t.id 
@@ -234,7 +426,7 @@ func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchRes
 			for i := range query.Tags {
 				placeholders[i] = "?"
 			}
-			sqlQuery += fmt.Sprintf(` AND id IN (
+			whereClause += fmt.Sprintf(` AND ci.id IN (
 				SELECT DISTINCT cit.item_id 
 				FROM clipboard_item_tags cit 
 				INNER JOIN tags t ON cit.tag_id = t.id 
@@ -246,16 +438,23 @@ func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchRes
 		}
 	}
 
+	if decryptMatch {
+		return r.searchDecrypted(query, terms, fromClause+whereClause, args)
+	}
+
 	// 获取总数
-	countQuery := strings.Replace(sqlQuery, "SELECT id, content, content_type, title, category, is_favorite, use_count, is_deleted, deleted_at, created_at, updated_at, last_used_at", "SELECT COUNT(*)", 1)
 	var total int
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
+	err := r.db.QueryRow("SELECT COUNT(*)"+fromClause+whereClause, args...).Scan(&total)
 	if err != nil {
 		return result, err
 	}
 
-	// 添加排序和分页
-	sqlQuery += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	// 添加排序和分页（title 命中权重高于 content）
+	orderClause := " ORDER BY ci.created_at DESC"
+	if useFTS {
+		orderClause = " ORDER BY bm25(clipboard_fts, 2.0, 1.0), ci.created_at DESC"
+	}
+	sqlQuery := "SELECT " + searchColumns + fromClause + whereClause + orderClause + " LIMIT ? OFFSET ?"
 	args = append(args, query.Limit, query.Offset)
 
 	// 执行查询
@@ -270,6 +469,56 @@ func (r *clipboardRepository) Search(query models.SearchQuery) (models.SearchRes
 		return result, err
 	}
 
+	// 计算高亮范围和摘要
+	for i := range items {
+		applyHighlights(&items[i], terms)
+	}
+
+	result.Items = items
+	result.Total = total
+	result.Page = query.Offset/query.Limit + 1
+	result.PageSize = query.Limit
+	result.TotalPages = (total + query.Limit - 1) / query.Limit
+
+	return result, nil
+}
+
+// searchDecrypted 按关键词以外的条件查询后逐条解密匹配，再在内存中分页
+func (r *clipboardRepository) searchDecrypted(query models.SearchQuery, terms []string, clause string, args []interface{}) (models.SearchResult, error) {
+	var result models.SearchResult
+
+	rows, err := r.db.Query("SELECT "+searchColumns+clause+" ORDER BY ci.created_at DESC", args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	var matched []models.ClipboardItem
+	for rows.Next() {
+		var item models.ClipboardItem
+		if err := scanItem(rows, &item); err != nil {
+			return result, err
+		}
+		if err := r.decryptItem(&item); err != nil {
+			return result, err
+		}
+		if matchesAllTerms(item, terms) {
+			matched = append(matched, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	total := len(matched)
+	start := min(query.Offset, total)
+	items := matched[start:min(start+query.Limit, total)]
+	for i := range items {
+		items[i].Tags, _ = r.loadTagsForItem(items[i].ID)
+		r.loadThumbnail(&items[i])
+		applyHighlights(&items[i], terms)
+	}
+
 	result.Items = items
 	result.Total = total
 	result.Page = query.Offset/query.Limit + 1
@@ -312,11 +561,21 @@ func (r *clipboardRepository) GetStatistics() (models.Statistics, error) {
 	return stats, nil
 }
 
-// IsDuplicateContent 检查是否重复内容
+// IsDuplicateContent 检查是否重复内容，加密后密文各不相同，改为比较内容指纹
 func (r *clipboardRepository) IsDuplicateContent(content string) (bool, error) {
 	query := `SELECT COUNT(*) FROM clipboard_items WHERE content = ? AND is_deleted = 0 LIMIT 1`
+	value := content
+	if r.cipher.Configured() {
+		hash, err := r.cipher.Fingerprint(content)
+		if err != nil {
+			return false, err
+		}
+		query = `SELECT COUNT(*) FROM clipboard_items WHERE content_hash = ? AND is_deleted = 0 LIMIT 1`
+		value = hash
+	}
+
 	var count int
-	err := r.db.QueryRow(query, content).Scan(&count)
+	err := r.db.QueryRow(query, value).Scan(&count)
 	return err == nil && count > 0, err
 }
 
@@ -334,14 +593,16 @@ func (r *clipboardRepository) scanItems(rows *sql.Rows) ([]models.ClipboardItem,
 	for rows.Next() {
 		var item models.ClipboardItem
 
-		err := rows.Scan(&item.ID, &item.Content, &item.ContentType, &item.Title,
-			&item.Category, &item.IsFavorite, &item.UseCount, &item.IsDeleted, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt, &item.LastUsedAt)
-		if err != nil {
+		if err := scanItem(rows, &item); err != nil {
 			continue
 		}
+		if err := r.decryptItem(&item); err != nil {
+			return nil, err
+		}
 
 		// 加载标签信息
 		item.Tags, _ = r.loadTagsForItem(item.ID)
+		r.loadThumbnail(&item)
 		items = append(items, item)
 	}
 
@@ -450,3 +711,85 @@ func (r *clipboardRepository) loadTagsForItem(itemID string) ([]models.Tag, erro
 
 	return tags, nil
 }
+
+// CreateImage 保存图片数据
+func (r *clipboardRepository) CreateImage(image models.ClipboardImage) error {
+	query := `
+	INSERT INTO clipboard_images (item_id, hash, data, thumbnail, width, height, size, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	data, err := r.cipher.EncryptBytes(image.Data)
+	if err != nil {
+		return err
+	}
+	thumbnail, err := r.cipher.EncryptBytes(image.Thumbnail)
+	if err != nil {
+		return err
+	}
+	hash, err := r.cipher.ImageFingerprint(image.Hash)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, image.ItemID, hash, data, thumbnail,
+		image.Width, image.Height, image.Size, image.CreatedAt)
+	return err
+}
+
+// GetImage 获取条目的图片数据
+func (r *clipboardRepository) GetImage(itemID string) (*models.ClipboardImage, error) {
+	query := `
+	SELECT item_id, hash, data, thumbnail, width, height, size, created_at
+	FROM clipboard_images
+	WHERE item_id = ?
+	`
+	var image models.ClipboardImage
+	err := r.db.QueryRow(query, itemID).Scan(&image.ItemID, &image.Hash, &image.Data, &image.Thumbnail,
+		&image.Width, &image.Height, &image.Size, &image.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if image.Data, err = r.cipher.DecryptBytes(image.Data); err != nil {
+		return nil, err
+	}
+	if image.Thumbnail, err = r.cipher.DecryptBytes(image.Thumbnail); err != nil {
+		return nil, err
+	}
+	// 启用加密时保存的是摘要的 HMAC，返回前从图片数据重新计算
+	if r.cipher.Configured() {
+		image.Hash = sha256Hex(image.Data)
+	}
+	return &image, nil
+}
+
+// IsDuplicateImage 检查是否已存在相同的图片（仅活跃条目）
+func (r *clipboardRepository) IsDuplicateImage(hash string) (bool, error) {
+	hash, err := r.cipher.ImageFingerprint(hash)
+	if err != nil {
+		return false, err
+	}
+	query := `
+	SELECT COUNT(*) FROM clipboard_images img
+	INNER JOIN clipboard_items ci ON img.item_id = ci.id
+	WHERE img.hash = ? AND ci.is_deleted = 0
+	`
+	var count int
+	err = r.db.QueryRow(query, hash).Scan(&count)
+	return err == nil && count > 0, err
+}
+
+// loadThumbnail 为图片条目加载缩略图
+func (r *clipboardRepository) loadThumbnail(item *models.ClipboardItem) {
+	if !item.IsImage() {
+		return
+	}
+
+	var thumbnail []byte
+	err := r.db.QueryRow(`SELECT thumbnail FROM clipboard_images WHERE item_id = ?`, item.ID).Scan(&thumbnail)
+	if err != nil || len(thumbnail) == 0 {
+		return
+	}
+	if thumbnail, err = r.cipher.DecryptBytes(thumbnail); err != nil {
+		return
+	}
+	item.Thumbnail = "data:image/png;base64," + base64.StdEncoding.EncodeToString(thumbnail)
+}
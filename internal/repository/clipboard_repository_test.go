@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestItem(id, title, content string, createdAt time.Time) models.ClipboardItem {
+	return models.ClipboardItem{
+		ID:          id,
+		Content:     content,
+		ContentType: models.ContentTypeText,
+		Title:       title,
+		Category:    models.CategoryText,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		LastUsedAt:  createdAt,
+	}
+}
+
+func TestClipboardRepository_Search(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	items := []models.ClipboardItem{
+		newTestItem("1", "数据库配置", "redis://localhost:6379 连接字符串", now.Add(-3*time.Hour)),
+		newTestItem("2", "连接字符串", "postgres://user@localhost/db 数据库连接字符串", now.Add(-2*time.Hour)),
+		newTestItem("3", "购物清单", "牛奶 面包 鸡蛋", now.Add(-1*time.Hour)),
+	}
+	for _, item := range items {
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := repo.Search(models.SearchQuery{Query: "连接字符串", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Items) != 2 {
+		t.Fatalf("expected 2 results, got total=%d items=%d", result.Total, len(result.Items))
+	}
+
+	if db.ftsAvailable() {
+		// 标题命中的权重更高
+		if result.Items[0].ID != "2" {
+			t.Errorf("expected title match ranked first, got %s", result.Items[0].ID)
+		}
+	}
+
+	first := result.Items[0]
+	if first.Snippet == "" {
+		t.Error("expected snippet for content match")
+	}
+	var titleHit bool
+	for _, h := range first.Highlights {
+		if h.Field == "title" && h.Start == 0 && h.End == 5 {
+			titleHit = true
+		}
+	}
+	if !titleHit {
+		t.Errorf("expected title highlight [0,5), got %+v", first.Highlights)
+	}
+
+	// 更新后索引同步
+	updated := items[2]
+	updated.Content = "牛奶 面包 连接字符串"
+	if err := repo.Update(updated); err != nil {
+		t.Fatal(err)
+	}
+	result, err = repo.Search(models.SearchQuery{Query: "连接字符串", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 {
+		t.Errorf("expected 3 results after update, got %d", result.Total)
+	}
+
+	// 删除后索引同步
+	if err := repo.PermanentDelete("1"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = repo.Search(models.SearchQuery{Query: "redis", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 0 {
+		t.Errorf("expected deleted item to be gone from index, got %d", result.Total)
+	}
+}
+
+func TestClipboardRepository_SearchShortQuery(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	if err := repo.Create(newTestItem("1", "牛奶", "买牛奶", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	// 少于3个字符时 trigram 无法匹配，回退到 LIKE
+	result, err := repo.Search(models.SearchQuery{Query: "牛奶", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Fatalf("expected 1 result, got %d", result.Total)
+	}
+	if got := result.Items[0].Snippet; got != "买<mark>牛奶</mark>" {
+		t.Errorf("unexpected snippet %q", got)
+	}
+}
+
+func TestClipboardRepository_SearchUntaggedAndDateRange(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+	tagRepo := NewTagRepository(db.DB)
+
+	now := time.Now()
+	for _, item := range []models.ClipboardItem{
+		newTestItem("old", "旧条目", "old", now.Add(-48*time.Hour)),
+		newTestItem("tagged", "已打标签", "tagged", now.Add(-2*time.Hour)),
+		newTestItem("plain", "未打标签", "plain", now.Add(-1*time.Hour)),
+	} {
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tag, err := tagRepo.GetOrCreateTag("工作", "ai-generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tagRepo.AddTagToItem("tagged", tag.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	after := now.Add(-24 * time.Hour)
+	result, err := repo.Search(models.SearchQuery{Untagged: true, CreatedAfter: &after, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "plain" {
+		t.Fatalf("expected only the recent untagged item, got %+v", result.Items)
+	}
+
+	before := now.Add(-24 * time.Hour)
+	result, err = repo.Search(models.SearchQuery{CreatedBefore: &before, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "old" {
+		t.Fatalf("expected only the old item, got %+v", result.Items)
+	}
+}
+
+func TestDatabase_BackfillFullTextIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.ftsAvailable() {
+		db.Close()
+		t.Skip("SQLite built without FTS5")
+	}
+
+	// 模拟索引建立前已存在的数据
+	if _, err := db.Exec("DROP TABLE clipboard_fts"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP TRIGGER clipboard_items_fts_insert"); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+	if err := repo.Create(newTestItem("1", "旧数据", "backfilled content", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	result, err := NewClipboardRepository(db.DB, db.Cipher()).Search(models.SearchQuery{Query: "backfilled", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("expected backfilled row to be searchable, got %d", result.Total)
+	}
+}
+
+func TestClipboardRepository_Retention(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	number := newTestItem("number", "123", "123", now.Add(-48*time.Hour))
+	number.Category = models.CategoryNumber
+	favorite := newTestItem("favorite", "456", "456", now.Add(-72*time.Hour))
+	favorite.Category = models.CategoryNumber
+	favorite.IsFavorite = true
+	for _, item := range []models.ClipboardItem{
+		number, favorite,
+		newTestItem("old", "旧", "old", now.Add(-3*time.Hour)),
+		newTestItem("mid", "中", "mid", now.Add(-2*time.Hour)),
+		newTestItem("new", "新", "new", now.Add(-1*time.Hour)),
+	} {
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := repo.ExpireCategory(models.CategoryNumber, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 expired item, got %d", n)
+	}
+
+	// 活跃条目: favorite, old, mid, new；收藏占用一个名额
+	n, err = repo.TrimToLimit(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 trimmed item, got %d", n)
+	}
+	items, err := repo.List(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 3 || ids[0] != "new" || ids[1] != "mid" || ids[2] != "favorite" {
+		t.Errorf("unexpected active items %v", ids)
+	}
+
+	n, err = repo.PurgeTrash(now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 purged items, got %d", n)
+	}
+	trash, err := repo.GetTrashItems(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 0 {
+		t.Errorf("expected empty trash, got %d items", len(trash))
+	}
+}
+
+func TestClipboardRepository_DeleteExpired(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	expired := newTestItem("expired", "jwt", "eyJ...", now.Add(-time.Hour))
+	expired.ExpiresAt = &past
+	pending := newTestItem("pending", "jwt", "eyJ...", now)
+	pending.ExpiresAt = &future
+	for _, item := range []models.ClipboardItem{expired, pending, newTestItem("plain", "plain", "plain", now)} {
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := repo.DeleteExpired(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 expired item, got %d", n)
+	}
+	item, err := repo.GetByID("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ExpiresAt == nil || !item.ExpiresAt.Equal(future) {
+		t.Errorf("expected expires_at to round-trip, got %v", item.ExpiresAt)
+	}
+}
+
+func TestClipboardRepository_SearchMetadata(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	items := []models.ClipboardItem{
+		newTestItem("1", "终端", "ls -la", now.Add(-3*time.Hour)),
+		newTestItem("2", "网页", "一段很长的中文内容", now.Add(-2*time.Hour)),
+		newTestItem("3", "编辑器", "func main() {}", now.Add(-1*time.Hour)),
+	}
+	items[0].SourceApp, items[0].SourceTitle, items[0].ContentLength, items[0].Language = "Gnome-terminal", "~/src", 6, "en"
+	items[1].SourceApp, items[1].ContentLength, items[1].Language = "Firefox", 27, "zh"
+	items[2].SourceApp, items[2].ContentLength, items[2].Language = "Code", 14, "en"
+	for _, item := range items {
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item, err := repo.GetByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.SourceApp != "Gnome-terminal" || item.SourceTitle != "~/src" || item.ContentLength != 6 || item.Language != "en" {
+		t.Fatalf("metadata not stored: %+v", item)
+	}
+
+	cases := []struct {
+		name  string
+		query models.SearchQuery
+		want  []string
+	}{
+		{"source app ignores case", models.SearchQuery{SourceApp: "firefox"}, []string{"2"}},
+		{"language", models.SearchQuery{Language: "en"}, []string{"3", "1"}},
+		{"length range", models.SearchQuery{MinLength: 10, MaxLength: 20}, []string{"3"}},
+		{"combined with keyword", models.SearchQuery{Query: "main", Language: "zh"}, nil},
+	}
+	for _, tc := range cases {
+		tc.query.Limit = 10
+		result, err := repo.Search(tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var got []string
+		for _, item := range result.Items {
+			got = append(got, item.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClipboardRepository_Images(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	for _, id := range []string{"img1", "img2"} {
+		item := newTestItem(id, "图片", "[图片 2x2]", now)
+		item.ContentType = models.ContentTypeImage
+		item.Category = models.CategoryImage
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	images := []models.ClipboardImage{
+		{ItemID: "img1", Hash: "hash-a", Data: []byte("png-a"), Thumbnail: []byte("thumb-a"), Width: 2, Height: 2, Size: 5, CreatedAt: now},
+		{ItemID: "img2", Hash: "hash-b", Data: []byte("png-b"), Thumbnail: []byte("thumb-b"), Width: 3, Height: 1, Size: 5, CreatedAt: now},
+	}
+	for _, image := range images {
+		if err := repo.CreateImage(image); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := repo.GetImage("img1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Hash != "hash-a" || string(got.Data) != "png-a" || string(got.Thumbnail) != "thumb-a" || got.Width != 2 || got.Height != 2 {
+		t.Errorf("unexpected image %+v", got)
+	}
+	item, err := repo.GetByID("img1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Thumbnail == "" {
+		t.Error("expected thumbnail to be loaded for image item")
+	}
+
+	if err := repo.SoftDelete("img2"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		hash string
+		want bool
+	}{
+		{"hash-a", true},
+		{"hash-b", false}, // 所属条目已在回收站
+		{"hash-c", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hash, func(t *testing.T) {
+			dup, err := repo.IsDuplicateImage(tt.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dup != tt.want {
+				t.Errorf("IsDuplicateImage(%q) = %v, want %v", tt.hash, dup, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,13 +10,61 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+var (
+	// ErrDatabaseNotInitialized 数据库尚未执行过迁移
+	ErrDatabaseNotInitialized = errors.New("数据库尚未初始化")
+	// ErrSchemaMismatch 数据库结构版本与程序不一致
+	ErrSchemaMismatch = errors.New("数据库结构版本不一致，请先启动最新版本的 GUI 完成迁移")
+	// ErrFullTextSearchUnavailable 数据库已建立全文检索索引，但当前程序未编译 FTS5
+	ErrFullTextSearchUnavailable = errors.New("数据库已启用全文检索，但当前程序未启用 FTS5，请使用 -tags sqlite_fts5 重新编译")
+)
+
 // Database 数据库连接管理器
 type Database struct {
 	*sql.DB
+	path   string
+	cipher *ContentCipher
 }
 
-// NewDatabase 创建新的数据库连接
+// NewDatabase 创建新的数据库连接并执行迁移
 func NewDatabase(dbPath string) (*Database, error) {
+	return NewDatabaseWithOptions(dbPath, MigrateOptions{})
+}
+
+// NewDatabaseWithOptions 使用指定迁移选项创建数据库连接
+func NewDatabaseWithOptions(dbPath string, opts MigrateOptions) (*Database, error) {
+	db, err := openSQLite(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &Database{DB: db, path: dbPath, cipher: &ContentCipher{}}
+	if err := database.migrate(opts); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return database, nil
+}
+
+// OpenExistingDatabase 打开已由 GUI 初始化的数据库，不执行迁移，也不创建或移除全文检索索引，
+// 供与 GUI 同时运行的命令行工具使用，避免对正在使用的数据库执行结构变更
+func OpenExistingDatabase(dbPath string) (*Database, error) {
+	db, err := openSQLite(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &Database{DB: db, path: dbPath, cipher: &ContentCipher{}}
+	if err := database.attach(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+// openSQLite 打开 SQLite 连接并设置连接参数
+func openSQLite(dbPath string) (*sql.DB, error) {
 	// 添加 SQLite 参数确保UTF-8编码支持
 	dsn := fmt.Sprintf("%s?_busy_timeout=10000&_case_sensitive_like=OFF&_encoding=UTF-8&_foreign_keys=ON&_journal_mode=WAL&_synchronous=NORMAL", dbPath)
 	db, err := sql.Open("sqlite3", dsn)
@@ -31,233 +79,165 @@ func NewDatabase(dbPath string) (*Database, error) {
 
 	// 执行 PRAGMA 设置确保 UTF-8 编码
 	if _, err := db.Exec("PRAGMA encoding = 'UTF-8'"); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to set UTF-8 encoding: %v", err)
 	}
-
-	database := &Database{DB: db}
-	if err := database.migrate(); err != nil {
-		return nil, err
-	}
-
-	return database, nil
+	return db, nil
 }
 
-// migrate 执行数据库迁移
-func (db *Database) migrate() error {
-
-	// 创建基础表结构
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS clipboard_items (
-		id TEXT PRIMARY KEY,
-		content TEXT NOT NULL,
-		content_type TEXT DEFAULT 'text',
-		title TEXT NOT NULL,
-		category TEXT DEFAULT '未分类',
-		is_favorite BOOLEAN DEFAULT 0,
-		use_count INTEGER DEFAULT 0,
-		is_deleted BOOLEAN DEFAULT 0,
-		deleted_at DATETIME NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_used_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_created_at ON clipboard_items(created_at);
-	CREATE INDEX IF NOT EXISTS idx_category ON clipboard_items(category);
-	CREATE INDEX IF NOT EXISTS idx_is_favorite ON clipboard_items(is_favorite);
-	CREATE INDEX IF NOT EXISTS idx_use_count ON clipboard_items(use_count);
-	CREATE INDEX IF NOT EXISTS idx_is_deleted ON clipboard_items(is_deleted);
-	
-	-- 聊天会话表
-	CREATE TABLE IF NOT EXISTS chat_sessions (
-		id TEXT PRIMARY KEY,
-		title TEXT NOT NULL,
-		description TEXT DEFAULT '',
-		last_message TEXT DEFAULT '',
-		message_count INTEGER DEFAULT 0,
-		is_active BOOLEAN DEFAULT 1,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_active_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_chat_sessions_created_at ON chat_sessions(created_at);
-	CREATE INDEX IF NOT EXISTS idx_chat_sessions_is_active ON chat_sessions(is_active);
-	CREATE INDEX IF NOT EXISTS idx_chat_sessions_last_active_at ON chat_sessions(last_active_at);
-	
-	-- 聊天消息表
-	CREATE TABLE IF NOT EXISTS chat_messages (
-		id TEXT PRIMARY KEY,
-		session_id TEXT NOT NULL,
-		role TEXT NOT NULL,
-		content TEXT NOT NULL,
-		content_type TEXT DEFAULT 'text',
-		metadata TEXT DEFAULT '{}',
-		is_streaming BOOLEAN DEFAULT 0,
-		is_complete BOOLEAN DEFAULT 1,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (session_id) REFERENCES chat_sessions(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_chat_messages_session_id ON chat_messages(session_id);
-	CREATE INDEX IF NOT EXISTS idx_chat_messages_created_at ON chat_messages(created_at);
-	CREATE INDEX IF NOT EXISTS idx_chat_messages_role ON chat_messages(role);
-
-	-- 标签分组表
-	CREATE TABLE IF NOT EXISTS tag_groups (
-		id TEXT PRIMARY KEY,
-		name TEXT NOT NULL UNIQUE,
-		description TEXT DEFAULT '',
-		color TEXT DEFAULT '#1890ff',
-		sort_order INTEGER DEFAULT 0,
-		is_system BOOLEAN DEFAULT 0,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tag_groups_sort_order ON tag_groups(sort_order);
-	CREATE INDEX IF NOT EXISTS idx_tag_groups_is_system ON tag_groups(is_system);
-
-	-- 标签表
-	CREATE TABLE IF NOT EXISTS tags (
-		id TEXT PRIMARY KEY,
-		name TEXT NOT NULL UNIQUE,
-		description TEXT DEFAULT '',
-		color TEXT DEFAULT '#1890ff',
-		group_id TEXT,
-		use_count INTEGER DEFAULT 0,
-		is_system BOOLEAN DEFAULT 0,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_used_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (group_id) REFERENCES tag_groups(id) ON DELETE SET NULL
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
-	CREATE INDEX IF NOT EXISTS idx_tags_group_id ON tags(group_id);
-	CREATE INDEX IF NOT EXISTS idx_tags_use_count ON tags(use_count);
-	CREATE INDEX IF NOT EXISTS idx_tags_is_system ON tags(is_system);
-	CREATE INDEX IF NOT EXISTS idx_tags_last_used_at ON tags(last_used_at);
-
-	-- 剪切板条目标签关联表
-	CREATE TABLE IF NOT EXISTS clipboard_item_tags (
-		id TEXT PRIMARY KEY,
-		item_id TEXT NOT NULL,
-		tag_id TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (item_id) REFERENCES clipboard_items(id) ON DELETE CASCADE,
-		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
-		UNIQUE(item_id, tag_id)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_item_id ON clipboard_item_tags(item_id);
-	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_tag_id ON clipboard_item_tags(tag_id);
-	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_created_at ON clipboard_item_tags(created_at);
-	`
+// attach 校验已有数据库的结构版本和全文索引，并按加密配置设置加密器状态
+func (db *Database) attach() error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %v", err)
+	}
+	latest := migrations[len(migrations)-1].Version
+	switch {
+	case version == 0:
+		return ErrDatabaseNotInitialized
+	case version != latest:
+		return fmt.Errorf("%w: 数据库版本 v%d，程序支持 v%d", ErrSchemaMismatch, version, latest)
+	}
 
-	_, err := db.Exec(createTableSQL)
+	cfg, err := db.loadEncryptionConfig()
 	if err != nil {
 		return err
 	}
-	// 查询 ai-generated 标签分组是否存在
-	var count int
-	err = db.QueryRow("SELECT COUNT(*) FROM tag_groups WHERE id = ?", "ai-generated").Scan(&count)
-	if err != nil {
+	if err := db.cipher.setKey(cfg != nil, nil); err != nil {
 		return err
 	}
 
-	if count == 0 {
-		// 创建 ai-generated 标签分组
-		_, err = db.Exec(`INSERT OR IGNORE INTO tag_groups (id, name, description, color, sort_order, is_system, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-			"ai-generated", "AI生成", "AI自动生成的标签", "#52c41a", 0, true, "2024-01-01 00:00:00", "2024-01-01 00:00:00")
+	// 未编译 FTS5 时全文索引触发器会导致写入失败，但不能移除其他进程依赖的触发器
+	if !db.ftsAvailable() {
+		triggers, err := db.fullTextTriggerCount()
 		if err != nil {
 			return err
 		}
+		if triggers > 0 {
+			return ErrFullTextSearchUnavailable
+		}
 	}
-	log.Println("数据库表创建成功")
 	return nil
 }
 
-// migrateTagsToRelationships 迁移旧的标签数据到新的关系表
-func (db *Database) migrateTagsToRelationships() error {
-	log.Println("开始迁移标签数据...")
+// fullTextTriggerCount 统计全文检索同步触发器的数量
+func (db *Database) fullTextTriggerCount() (int, error) {
+	var triggers int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'clipboard_items_fts_%'").Scan(&triggers)
+	return triggers, err
+}
 
-	// 查询所有包含标签的条目
-	query := `SELECT id, tags FROM clipboard_items WHERE tags IS NOT NULL AND tags != '[]'`
-	rows, err := db.Query(query)
+// migrate 执行数据库迁移
+func (db *Database) migrate(opts MigrateOptions) error {
+	report, err := db.Migrate(opts)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	// 创建默认标签分组
-	defaultGroupID := "ai-generated"
-	_, err = db.Exec(`INSERT OR IGNORE INTO tag_groups (id, name, description, color, sort_order, is_system, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		defaultGroupID, "AI生成", "AI自动生成的标签", "#52c41a", 0, true, "2024-01-01 00:00:00", "2024-01-01 00:00:00")
-	if err != nil {
-		return err
+	if opts.DryRun {
+		log.Printf("🧪 迁移试运行完成: v%d -> v%d，共 %d 个待执行迁移", report.FromVersion, report.ToVersion, len(report.Pending))
+		return nil
 	}
 
-	userGroupID := "user-custom"
-	_, err = db.Exec(`INSERT OR IGNORE INTO tag_groups (id, name, description, color, sort_order, is_system, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		userGroupID, "用户自定义", "用户手动创建的标签", "#1890ff", 1, false, "2024-01-01 00:00:00", "2024-01-01 00:00:00")
+	// 全文检索索引依赖编译选项，不纳入版本化迁移，每次启动时校验
+	// 已加密的数据库不建立索引，启动时处于锁定状态，解锁后才能读写加密字段
+	cfg, err := db.loadEncryptionConfig()
 	if err != nil {
 		return err
 	}
+	if cfg == nil {
+		if err := db.cipher.setKey(false, nil); err != nil {
+			return err
+		}
+		if err := db.setupFullTextSearch(); err != nil {
+			return err
+		}
+	} else {
+		if err := db.cipher.setKey(true, nil); err != nil {
+			return err
+		}
+		if err := dropFullTextSearch(db); err != nil {
+			return err
+		}
+		log.Printf("🔒 数据库已加密（%s），等待解锁", cfg.keySource)
+	}
 
-	tagMap := make(map[string]string) // tag name -> tag id
+	log.Printf("数据库迁移完成，当前版本 v%d", report.ToVersion)
+	return nil
+}
 
-	for rows.Next() {
-		var itemID, tagsJSON string
-		if err := rows.Scan(&itemID, &tagsJSON); err != nil {
-			continue
-		}
+// ftsAvailable 检查当前 SQLite 是否编译了 FTS5
+func (db *Database) ftsAvailable() bool {
+	var enabled int
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return false
+	}
+	return enabled == 1
+}
 
-		// 解析标签JSON
-		var tags []string
-		if err := json.Unmarshal([]byte(tagsJSON), &tags); err != nil {
-			continue
-		}
+// setupFullTextSearch 创建 FTS5 索引及同步触发器，首次创建时回填已有数据
+func (db *Database) setupFullTextSearch() error {
+	if !db.ftsAvailable() {
+		// 未编译 FTS5 时触发器会导致写入失败，需移除；重新启用时会自动重建索引
+		log.Println("⚠️  SQLite 未启用 FTS5，搜索将回退到 LIKE 匹配")
+		_, err := db.Exec(`
+		DROP TRIGGER IF EXISTS clipboard_items_fts_insert;
+		DROP TRIGGER IF EXISTS clipboard_items_fts_delete;
+		DROP TRIGGER IF EXISTS clipboard_items_fts_update;
+		`)
+		return err
+	}
 
-		// 为每个标签创建关联
-		for _, tagName := range tags {
-			if tagName == "" {
-				continue
-			}
+	triggers, err := db.fullTextTriggerCount()
+	if err != nil {
+		return err
+	}
 
-			// 获取或创建标签
-			tagID, exists := tagMap[tagName]
-			if !exists {
-				tagID = fmt.Sprintf("tag-%d", time.Now().UnixNano())
+	// trigram 分词器对中文等无空格分隔的文本同样有效
+	ftsSQL := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS clipboard_fts USING fts5(
+		title,
+		content,
+		content = 'clipboard_items',
+		content_rowid = 'rowid',
+		tokenize = 'trigram'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS clipboard_items_fts_insert AFTER INSERT ON clipboard_items BEGIN
+		INSERT INTO clipboard_fts (rowid, title, content) VALUES (new.rowid, new.title, new.content);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS clipboard_items_fts_delete AFTER DELETE ON clipboard_items BEGIN
+		INSERT INTO clipboard_fts (clipboard_fts, rowid, title, content) VALUES ('delete', old.rowid, old.title, old.content);
+	END;
 
-				// 创建标签
-				_, err = db.Exec(`INSERT OR IGNORE INTO tags (id, name, description, color, group_id, use_count, is_system, created_at, updated_at, last_used_at) 
-					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-					tagID, tagName, "", "#1890ff", defaultGroupID, 0, true, "2024-01-01 00:00:00", "2024-01-01 00:00:00", "2024-01-01 00:00:00")
-				if err != nil {
-					log.Printf("创建标签失败: %v", err)
-					continue
-				}
-				tagMap[tagName] = tagID
-			}
+	CREATE TRIGGER IF NOT EXISTS clipboard_items_fts_update AFTER UPDATE OF title, content ON clipboard_items BEGIN
+		INSERT INTO clipboard_fts (clipboard_fts, rowid, title, content) VALUES ('delete', old.rowid, old.title, old.content);
+		INSERT INTO clipboard_fts (rowid, title, content) VALUES (new.rowid, new.title, new.content);
+	END;
+	`
+	if _, err := db.Exec(ftsSQL); err != nil {
+		return fmt.Errorf("failed to create full-text index: %v", err)
+	}
 
-			// 创建关联
-			relID := fmt.Sprintf("rel-%d", time.Now().UnixNano())
-			_, err = db.Exec(`INSERT OR IGNORE INTO clipboard_item_tags (id, item_id, tag_id, created_at) 
-				VALUES (?, ?, ?, ?)`,
-				relID, itemID, tagID, "2024-01-01 00:00:00")
-			if err != nil {
-				log.Printf("创建标签关联失败: %v", err)
-				continue
-			}
+	// 索引为新建或曾脱离同步时回填
+	if triggers < 3 {
+		if err := db.RebuildFullTextIndex(); err != nil {
+			return err
 		}
+		log.Println("✅ 全文检索索引已回填")
 	}
+	return nil
+}
 
-	log.Printf("迁移了 %d 个标签", len(tagMap))
+// RebuildFullTextIndex 根据 clipboard_items 重建全文检索索引
+func (db *Database) RebuildFullTextIndex() error {
+	if !db.ftsAvailable() {
+		return nil
+	}
+	if _, err := db.Exec("INSERT INTO clipboard_fts (clipboard_fts) VALUES ('rebuild')"); err != nil {
+		return fmt.Errorf("failed to rebuild full-text index: %v", err)
+	}
 	return nil
 }
 
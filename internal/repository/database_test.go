@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func TestOpenExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	if _, err := OpenExistingDatabase(path); !errors.Is(err, ErrDatabaseNotInitialized) {
+		t.Fatalf("expected ErrDatabaseNotInitialized, got %v", err)
+	}
+
+	owner, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer owner.Close()
+	triggers, err := owner.fullTextTriggerCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := OpenExistingDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewClipboardRepository(shared.DB, shared.Cipher()).Create(newTestItem("1", "共享", "来自命令行的条目", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	shared.Close()
+
+	// 打开已有数据库不修改全文索引，另一个进程写入的条目仍能被全文检索命中
+	after, err := owner.fullTextTriggerCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != triggers {
+		t.Errorf("trigger count changed from %d to %d", triggers, after)
+	}
+	result, err := NewClipboardRepository(owner.DB, owner.Cipher()).Search(models.SearchQuery{Query: "命令行", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("expected shared item to be searchable, got %d", result.Total)
+	}
+
+	if _, err := owner.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', ?)", 999, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenExistingDatabase(path); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestOpenExistingDatabase_WithoutFTS5(t *testing.T) {
+	db := newTestDatabase(t)
+	if db.ftsAvailable() {
+		t.Skip("SQLite compiled with FTS5")
+	}
+
+	// 模拟由启用 FTS5 的 GUI 创建的触发器
+	if _, err := db.Exec(`CREATE TRIGGER clipboard_items_fts_insert AFTER INSERT ON clipboard_items BEGIN SELECT 1; END`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenExistingDatabase(db.path); !errors.Is(err, ErrFullTextSearchUnavailable) {
+		t.Fatalf("expected ErrFullTextSearchUnavailable, got %v", err)
+	}
+	if triggers, err := db.fullTextTriggerCount(); err != nil || triggers != 1 {
+		t.Errorf("trigger must be kept, got %d %v", triggers, err)
+	}
+}
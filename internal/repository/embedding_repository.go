@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// EmbeddingRepository 条目文本向量仓库接口
+type EmbeddingRepository interface {
+	SaveEmbeddings(embeddings []models.ItemEmbedding) error
+	ListEmbeddings(model string) ([]models.ItemEmbedding, error)
+	PendingItemIDs(model string, limit int) ([]string, error)
+	CountEmbeddings(model string) (indexed, pending int, err error)
+	DeleteEmbedding(itemID string) error
+}
+
+// embeddableCondition 需要生成向量的条目：未删除、非敏感、非图片
+const embeddableCondition = "ci.is_deleted = 0 AND ci.is_sensitive = 0 AND ci.content_type != '" + models.ContentTypeImage + "'"
+
+// embeddingRepository 条目文本向量仓库实现，向量按 float32 小端序编码为 base64 保存，加密数据库中同样加密
+type embeddingRepository struct {
+	db     *sql.DB
+	cipher *ContentCipher
+}
+
+// NewEmbeddingRepository 创建新的文本向量仓库
+func NewEmbeddingRepository(db *sql.DB, cipher *ContentCipher) EmbeddingRepository {
+	return &embeddingRepository{db: db, cipher: cipher}
+}
+
+// SaveEmbeddings 保存条目向量，已有向量（包括其他模型生成的）会被替换
+func (r *embeddingRepository) SaveEmbeddings(embeddings []models.ItemEmbedding) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO clipboard_embeddings (item_id, model, dimensions, vector, created_at) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, embedding := range embeddings {
+		vector, err := r.cipher.Encrypt(encodeVector(embedding.Vector))
+		if err != nil {
+			return err
+		}
+		createdAt := embedding.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		if _, err := stmt.Exec(embedding.ItemID, embedding.Model, len(embedding.Vector), vector, createdAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListEmbeddings 返回指定模型生成的全部向量，已删除或标记为敏感的条目不返回
+func (r *embeddingRepository) ListEmbeddings(model string) ([]models.ItemEmbedding, error) {
+	rows, err := r.db.Query(`
+	SELECT e.item_id, e.model, e.vector, e.created_at
+	FROM clipboard_embeddings e
+	INNER JOIN clipboard_items ci ON ci.id = e.item_id
+	WHERE e.model = ? AND `+embeddableCondition, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var embeddings []models.ItemEmbedding
+	for rows.Next() {
+		var embedding models.ItemEmbedding
+		var vector string
+		if err := rows.Scan(&embedding.ItemID, &embedding.Model, &vector, &embedding.CreatedAt); err != nil {
+			return nil, err
+		}
+		if vector, err = r.cipher.Decrypt(vector); err != nil {
+			return nil, err
+		}
+		if embedding.Vector, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("条目 %s 的向量无法解析: %v", embedding.ItemID, err)
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, rows.Err()
+}
+
+// PendingItemIDs 返回还没有指定模型向量的条目 ID，最新的条目优先
+func (r *embeddingRepository) PendingItemIDs(model string, limit int) ([]string, error) {
+	rows, err := r.db.Query(`
+	SELECT ci.id FROM clipboard_items ci
+	LEFT JOIN clipboard_embeddings e ON e.item_id = ci.id AND e.model = ?
+	WHERE e.item_id IS NULL AND `+embeddableCondition+`
+	ORDER BY ci.created_at DESC
+	LIMIT ?`, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CountEmbeddings 统计已有指定模型向量和等待生成向量的条目数
+func (r *embeddingRepository) CountEmbeddings(model string) (indexed, pending int, err error) {
+	err = r.db.QueryRow(`
+	SELECT COUNT(e.item_id), COUNT(*) - COUNT(e.item_id)
+	FROM clipboard_items ci
+	LEFT JOIN clipboard_embeddings e ON e.item_id = ci.id AND e.model = ?
+	WHERE `+embeddableCondition, model).Scan(&indexed, &pending)
+	return
+}
+
+// DeleteEmbedding 删除条目的向量（无论由哪个模型生成），条目随后重新进入待处理列表
+func (r *embeddingRepository) DeleteEmbedding(itemID string) error {
+	_, err := r.db.Exec(`DELETE FROM clipboard_embeddings WHERE item_id = ?`, itemID)
+	return err
+}
+
+// encodeVector 将向量编码为 float32 小端序的 base64 字符串
+func encodeVector(vector []float32) string {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// decodeVector 解码 encodeVector 生成的字符串
+func decodeVector(value string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("长度 %d 不是 4 的倍数", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector, nil
+}
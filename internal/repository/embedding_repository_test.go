@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func TestEmbeddingRepository_SaveAndPending(t *testing.T) {
+	db := newTestDatabase(t)
+	clipboardRepo := NewClipboardRepository(db.DB, db.Cipher())
+	repo := NewEmbeddingRepository(db.DB, db.Cipher())
+
+	now := time.Now()
+	sensitive := newTestItem("secret", "密码", "hunter2", now)
+	sensitive.IsSensitive = true
+	for _, item := range []models.ClipboardItem{
+		newTestItem("old", "旧条目", "old content", now.Add(-time.Hour)),
+		newTestItem("new", "新条目", "new content", now),
+		sensitive,
+	} {
+		if err := clipboardRepo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 敏感条目不生成向量，最新的条目优先
+	ids, err := repo.PendingItemIDs("m1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "new" || ids[1] != "old" {
+		t.Fatalf("pending = %v", ids)
+	}
+
+	vector := []float32{0.6, -0.8, 0}
+	if err := repo.SaveEmbeddings([]models.ItemEmbedding{{ItemID: "new", Model: "m1", Vector: vector}}); err != nil {
+		t.Fatal(err)
+	}
+	indexed, pending, err := repo.CountEmbeddings("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 1 || pending != 1 {
+		t.Errorf("indexed = %d, pending = %d", indexed, pending)
+	}
+
+	embeddings, err := repo.ListEmbeddings("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embeddings) != 1 || embeddings[0].ItemID != "new" || len(embeddings[0].Vector) != 3 || embeddings[0].Vector[1] != -0.8 {
+		t.Fatalf("embeddings = %+v", embeddings)
+	}
+
+	// 换用其他模型后需要重新生成
+	if ids, _ := repo.PendingItemIDs("m2", 10); len(ids) != 2 {
+		t.Errorf("pending for new model = %v", ids)
+	}
+
+	// 移入回收站的条目不再参与检索
+	if err := clipboardRepo.SoftDelete("new"); err != nil {
+		t.Fatal(err)
+	}
+	if embeddings, _ := repo.ListEmbeddings("m1"); len(embeddings) != 0 {
+		t.Errorf("deleted item still listed: %+v", embeddings)
+	}
+}
+
+func TestEncodeVector(t *testing.T) {
+	vector := []float32{1, -0.5, 0.25}
+	decoded, err := decodeVector(encodeVector(vector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range vector {
+		if decoded[i] != vector[i] {
+			t.Fatalf("decoded = %v", decoded)
+		}
+	}
+	if _, err := decodeVector("AAA="); err == nil {
+		t.Error("expected length error")
+	}
+}
@@ -0,0 +1,714 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// encryptedPrefix 加密字段的前缀，没有前缀的值视为明文
+const encryptedPrefix = "enc:v1:"
+
+// encryptionVerifier 用数据密钥加密后保存的固定文本，解锁时用于校验密钥
+const encryptionVerifier = "sid-database-encryption"
+
+// DatabaseKeySize 数据密钥长度（AES-256）
+const DatabaseKeySize = 32
+
+// passphraseIterations 口令派生密钥的 PBKDF2 迭代次数，实际使用的次数随配置保存
+var passphraseIterations = 600000
+
+var (
+	// ErrDatabaseLocked 数据库已加密但尚未解锁
+	ErrDatabaseLocked = errors.New("数据库已加密，请先解锁")
+	// ErrWrongPassphrase 口令或密钥不正确
+	ErrWrongPassphrase = errors.New("口令或密钥不正确")
+	// ErrEncryptionEnabled 数据库已启用加密
+	ErrEncryptionEnabled = errors.New("数据库已启用加密")
+	// ErrEncryptionDisabled 数据库未启用加密
+	ErrEncryptionDisabled = errors.New("数据库未启用加密")
+)
+
+// ContentCipher 内容字段加密器
+// 未启用加密时原样读写；已启用但未解锁时拒绝读写加密内容，避免以明文写入新数据
+// 方法允许在 nil 上调用，等同于未启用加密
+type ContentCipher struct {
+	mu         sync.RWMutex
+	configured bool
+	aead       cipher.AEAD
+	macKey     []byte
+}
+
+// Configured 数据库是否启用了加密（无论是否已解锁）
+func (c *ContentCipher) Configured() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.configured
+}
+
+// Locked 已启用加密但尚未解锁
+func (c *ContentCipher) Locked() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.configured && c.aead == nil
+}
+
+// Encrypt 加密字段值，未启用加密时原样返回，空字符串不加密
+func (c *ContentCipher) Encrypt(value string) (string, error) {
+	if c == nil || value == "" {
+		return value, nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.configured {
+		return value, nil
+	}
+	if c.aead == nil {
+		return "", ErrDatabaseLocked
+	}
+	return sealValue(c.aead, value)
+}
+
+// Decrypt 解密字段值，没有加密前缀的值原样返回
+func (c *ContentCipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrDatabaseLocked
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.aead == nil {
+		return "", ErrDatabaseLocked
+	}
+	return openValue(c.aead, value)
+}
+
+// EncryptBytes 加密二进制字段（如图片数据），规则与 Encrypt 相同，空值不加密
+func (c *ContentCipher) EncryptBytes(value []byte) ([]byte, error) {
+	if c == nil || len(value) == 0 {
+		return value, nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.configured {
+		return value, nil
+	}
+	if c.aead == nil {
+		return nil, ErrDatabaseLocked
+	}
+	return sealBytes(c.aead, value)
+}
+
+// DecryptBytes 解密二进制字段，没有加密前缀的值原样返回
+func (c *ContentCipher) DecryptBytes(value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, []byte(encryptedPrefix)) {
+		return value, nil
+	}
+	if c == nil {
+		return nil, ErrDatabaseLocked
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.aead == nil {
+		return nil, ErrDatabaseLocked
+	}
+	return openBytes(c.aead, value)
+}
+
+// Fingerprint 计算内容指纹（HMAC-SHA256），用于加密后的重复检测；未启用加密时返回空字符串
+func (c *ContentCipher) Fingerprint(value string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.configured {
+		return "", nil
+	}
+	if c.macKey == nil {
+		return "", ErrDatabaseLocked
+	}
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// ImageFingerprint 图片 SHA-256 摘要在数据库中的存储值，用于加密后的重复检测
+// 启用加密时为摘要的 HMAC，明文摘要可以用来确认某张已知图片是否在历史中；未启用加密时原样返回
+func (c *ContentCipher) ImageFingerprint(hash string) (string, error) {
+	fingerprint, err := c.Fingerprint(hash)
+	if err != nil {
+		return "", err
+	}
+	if fingerprint == "" {
+		return hash, nil
+	}
+	return fingerprint, nil
+}
+
+// setKey 设置数据密钥，key 为 nil 时锁定
+func (c *ContentCipher) setKey(configured bool, key []byte) error {
+	var aead cipher.AEAD
+	var macKey []byte
+	if key != nil {
+		var err error
+		if aead, macKey, err = deriveContentKeys(key); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configured = configured
+	c.aead = aead
+	c.macKey = macKey
+	return nil
+}
+
+// deriveContentKeys 从数据密钥派生内容加密密钥和指纹密钥
+func deriveContentKeys(key []byte) (cipher.AEAD, []byte, error) {
+	if len(key) != DatabaseKeySize {
+		return nil, nil, fmt.Errorf("数据密钥长度应为 %d 字节", DatabaseKeySize)
+	}
+	encKey, err := hkdf.Key(sha256.New, key, nil, "sid content encryption", 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	macKey, err := hkdf.Key(sha256.New, key, nil, "sid content fingerprint", 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(encKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, macKey, nil
+}
+
+// newGCM 创建 AES-GCM 加密器
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealValue 加密并编码为带前缀的文本
+func sealValue(aead cipher.AEAD, value string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// openValue 解码并解密带前缀的文本
+func openValue(aead cipher.AEAD, value string) (string, error) {
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", errors.New("加密数据已损坏")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// sealBytes 加密二进制数据，结果为前缀 + nonce + 密文
+func sealBytes(aead cipher.AEAD, value []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append([]byte(encryptedPrefix), nonce...)
+	return aead.Seal(sealed, nonce, value, nil), nil
+}
+
+// openBytes 解密 sealBytes 生成的数据
+func openBytes(aead cipher.AEAD, value []byte) ([]byte, error) {
+	data := value[len(encryptedPrefix):]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("加密数据已损坏")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+// GenerateDatabaseKey 生成随机数据密钥
+func GenerateDatabaseKey() ([]byte, error) {
+	key := make([]byte, DatabaseKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encryptionConfig database_encryption 表中的加密配置
+type encryptionConfig struct {
+	keySource  string
+	salt       []byte
+	iterations int
+	wrappedKey []byte
+	verifier   string
+}
+
+// loadEncryptionConfig 读取加密配置，未启用加密时返回 nil
+func (db *Database) loadEncryptionConfig() (*encryptionConfig, error) {
+	var cfg encryptionConfig
+	err := db.QueryRow(`SELECT key_source, salt, iterations, wrapped_key, verifier FROM database_encryption WHERE id = 1`).
+		Scan(&cfg.keySource, &cfg.salt, &cfg.iterations, &cfg.wrappedKey, &cfg.verifier)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Cipher 获取内容字段加密器，供仓库层读写加密字段
+func (db *Database) Cipher() *ContentCipher {
+	return db.cipher
+}
+
+// EncryptionStatus 获取加密状态
+func (db *Database) EncryptionStatus() (models.EncryptionStatus, error) {
+	cfg, err := db.loadEncryptionConfig()
+	if err != nil || cfg == nil {
+		return models.EncryptionStatus{}, err
+	}
+	return models.EncryptionStatus{
+		Enabled:   true,
+		Locked:    db.cipher.Locked(),
+		KeySource: cfg.keySource,
+	}, nil
+}
+
+// EnablePassphraseEncryption 启用加密并就地加密已有数据，数据密钥由口令派生的密钥包裹后保存
+func (db *Database) EnablePassphraseEncryption(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("口令不能为空")
+	}
+	key, err := GenerateDatabaseKey()
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	wrapped, err := wrapKey(passphrase, salt, passphraseIterations, key)
+	if err != nil {
+		return err
+	}
+	return db.enableEncryption(key, encryptionConfig{
+		keySource:  models.EncryptionKeySourcePassphrase,
+		salt:       salt,
+		iterations: passphraseIterations,
+		wrappedKey: wrapped,
+	})
+}
+
+// EnableKeyEncryption 使用调用方保存的数据密钥（如密钥存储）启用加密并就地加密已有数据
+func (db *Database) EnableKeyEncryption(key []byte) error {
+	if len(key) != DatabaseKeySize {
+		return fmt.Errorf("数据密钥长度应为 %d 字节", DatabaseKeySize)
+	}
+	return db.enableEncryption(key, encryptionConfig{keySource: models.EncryptionKeySourceKeyring})
+}
+
+// UnlockWithPassphrase 使用口令解锁
+func (db *Database) UnlockWithPassphrase(passphrase string) error {
+	cfg, err := db.loadEncryptionConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return ErrEncryptionDisabled
+	}
+	if cfg.keySource != models.EncryptionKeySourcePassphrase {
+		return errors.New("数据库未使用口令加密")
+	}
+
+	key, err := unwrapKey(passphrase, cfg.salt, cfg.iterations, cfg.wrappedKey)
+	if err != nil {
+		return err
+	}
+	return db.unlock(cfg, key)
+}
+
+// UnlockWithKey 使用数据密钥解锁
+func (db *Database) UnlockWithKey(key []byte) error {
+	cfg, err := db.loadEncryptionConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return ErrEncryptionDisabled
+	}
+	return db.unlock(cfg, key)
+}
+
+// unlock 校验数据密钥后解锁
+func (db *Database) unlock(cfg *encryptionConfig, key []byte) error {
+	aead, _, err := deriveContentKeys(key)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+	if plain, err := openValue(aead, cfg.verifier); err != nil || plain != encryptionVerifier {
+		return ErrWrongPassphrase
+	}
+	if err := db.cipher.setKey(true, key); err != nil {
+		return err
+	}
+	log.Println("🔓 数据库已解锁")
+	return nil
+}
+
+// enableEncryption 在一个事务中加密已有数据并保存加密配置，完成后移除全文索引并整理数据库文件
+func (db *Database) enableEncryption(key []byte, cfg encryptionConfig) error {
+	existing, err := db.loadEncryptionConfig()
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrEncryptionEnabled
+	}
+
+	next := &ContentCipher{}
+	if err := next.setKey(true, key); err != nil {
+		return err
+	}
+	if cfg.verifier, err = next.Encrypt(encryptionVerifier); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n, err := rewriteContent(tx, nil, next)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+	INSERT INTO database_encryption (id, key_source, salt, iterations, wrapped_key, verifier, created_at)
+	VALUES (1, ?, ?, ?, ?, ?, ?)
+	`, cfg.keySource, cfg.salt, cfg.iterations, cfg.wrappedKey, cfg.verifier, time.Now()); err != nil {
+		return err
+	}
+	// 密文无法建立有效的全文索引，加密后搜索在解密后匹配
+	if err := dropFullTextSearch(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := db.cipher.setKey(true, key); err != nil {
+		return err
+	}
+	db.compact()
+	log.Printf("🔐 数据库已启用加密（%s），加密了 %d 个字段", cfg.keySource, n)
+	return nil
+}
+
+// DisableEncryption 就地解密全部数据并移除加密配置，需要先解锁
+func (db *Database) DisableEncryption() error {
+	if !db.cipher.Configured() {
+		return ErrEncryptionDisabled
+	}
+	if db.cipher.Locked() {
+		return ErrDatabaseLocked
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n, err := rewriteContent(tx, db.cipher, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM database_encryption`); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := db.cipher.setKey(false, nil); err != nil {
+		return err
+	}
+	if err := db.setupFullTextSearch(); err != nil {
+		return err
+	}
+	db.compact()
+	log.Printf("🔓 数据库已关闭加密，解密了 %d 个字段", n)
+	return nil
+}
+
+// encryptedField 需要加密的字段，fingerprint 不为空时同时维护内容指纹，binary 为 BLOB 字段
+// BLOB 字段的指纹为明文 SHA-256 摘要经 ImageFingerprint 处理后的值
+type encryptedField struct {
+	table       string
+	key         string
+	column      string
+	fingerprint string
+	binary      bool
+}
+
+// encryptedFields 加密存储的字段
+// 不加密的内容：标签名称（包括规则添加的标签）、分类、来源应用名、时间戳、使用次数、图片尺寸和大小，
+// 以及会话描述；这些字段用于筛选和排序，不包含复制的内容本身
+var encryptedFields = []encryptedField{
+	{table: "clipboard_items", key: "id", column: "content", fingerprint: "content_hash"},
+	{table: "clipboard_items", key: "id", column: "title"},
+	{table: "clipboard_items", key: "id", column: "source_title"},
+	{table: "chat_messages", key: "id", column: "content"},
+	{table: "chat_messages", key: "id", column: "metadata"},
+	{table: "chat_sessions", key: "id", column: "title"},
+	{table: "chat_sessions", key: "id", column: "last_message"},
+	{table: "chat_sessions", key: "id", column: "summary"},
+	{table: "clipboard_embeddings", key: "item_id", column: "vector"},
+	{table: "clipboard_images", key: "item_id", column: "data", fingerprint: "hash", binary: true},
+	{table: "clipboard_images", key: "item_id", column: "thumbnail", binary: true},
+}
+
+// rewriteContent 用 from 解密所有加密字段后再用 to 重写，from 或 to 为 nil 表示明文
+func rewriteContent(tx *sql.Tx, from, to *ContentCipher) (int, error) {
+	total := 0
+	for _, field := range encryptedFields {
+		n, err := rewriteField(tx, field, from, to)
+		if err != nil {
+			return total, fmt.Errorf("failed to rewrite %s.%s: %v", field.table, field.column, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// rewriteField 重写单个字段，先读取全部值再更新，避免边读边写
+func rewriteField(tx *sql.Tx, field encryptedField, from, to *ContentCipher) (int, error) {
+	if field.binary {
+		return rewriteBinaryField(tx, field, from, to)
+	}
+
+	rows, err := tx.Query(fmt.Sprintf(`SELECT %s, %s FROM %s WHERE %s IS NOT NULL AND %s != ''`,
+		field.key, field.column, field.table, field.column, field.column))
+	if err != nil {
+		return 0, err
+	}
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		values[key] = value
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, field.table, field.column, field.key)
+	if field.fingerprint != "" {
+		update = fmt.Sprintf(`UPDATE %s SET %s = ?, %s = ? WHERE %s = ?`, field.table, field.column, field.fingerprint, field.key)
+	}
+	stmt, err := tx.Prepare(update)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for key, value := range values {
+		plain, err := from.Decrypt(value)
+		if err != nil {
+			return 0, err
+		}
+		stored, err := to.Encrypt(plain)
+		if err != nil {
+			return 0, err
+		}
+
+		if field.fingerprint == "" {
+			_, err = stmt.Exec(stored, key)
+		} else {
+			var fingerprint sql.NullString
+			if fingerprint.String, err = to.Fingerprint(plain); err != nil {
+				return 0, err
+			}
+			fingerprint.Valid = fingerprint.String != ""
+			_, err = stmt.Exec(stored, fingerprint, key)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(values), nil
+}
+
+// rewriteBinaryField 重写单个 BLOB 字段
+func rewriteBinaryField(tx *sql.Tx, field encryptedField, from, to *ContentCipher) (int, error) {
+	rows, err := tx.Query(fmt.Sprintf(`SELECT %s, %s FROM %s WHERE length(%s) > 0`,
+		field.key, field.column, field.table, field.column))
+	if err != nil {
+		return 0, err
+	}
+	values := make(map[string][]byte)
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		values[key] = value
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, field.table, field.column, field.key)
+	if field.fingerprint != "" {
+		update = fmt.Sprintf(`UPDATE %s SET %s = ?, %s = ? WHERE %s = ?`, field.table, field.column, field.fingerprint, field.key)
+	}
+	stmt, err := tx.Prepare(update)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for key, value := range values {
+		plain, err := from.DecryptBytes(value)
+		if err != nil {
+			return 0, err
+		}
+		stored, err := to.EncryptBytes(plain)
+		if err != nil {
+			return 0, err
+		}
+
+		if field.fingerprint == "" {
+			_, err = stmt.Exec(stored, key)
+		} else {
+			var fingerprint string
+			if fingerprint, err = to.ImageFingerprint(sha256Hex(plain)); err != nil {
+				return 0, err
+			}
+			_, err = stmt.Exec(stored, fingerprint, key)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(values), nil
+}
+
+// sha256Hex 计算数据的 SHA-256 十六进制摘要，与剪切板图片的摘要一致
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// execer 兼容 *sql.DB 和 *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// dropFullTextSearch 移除全文检索索引及触发器
+func dropFullTextSearch(db execer) error {
+	_, err := db.Exec(`
+	DROP TRIGGER IF EXISTS clipboard_items_fts_insert;
+	DROP TRIGGER IF EXISTS clipboard_items_fts_delete;
+	DROP TRIGGER IF EXISTS clipboard_items_fts_update;
+	DROP TABLE IF EXISTS clipboard_fts;
+	`)
+	return err
+}
+
+// compact 整理数据库文件，清除空闲页和 WAL 中残留的明文
+func (db *Database) compact() {
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		log.Printf("⚠️  WAL 检查点失败: %v", err)
+	}
+	if _, err := db.Exec("VACUUM"); err != nil {
+		log.Printf("⚠️  整理数据库文件失败: %v", err)
+	}
+}
+
+// wrapKey 使用口令派生的密钥加密数据密钥
+func wrapKey(passphrase string, salt []byte, iterations int, key []byte) ([]byte, error) {
+	aead, err := passphraseAEAD(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// unwrapKey 使用口令解出数据密钥
+func unwrapKey(passphrase string, salt []byte, iterations int, wrapped []byte) ([]byte, error) {
+	aead, err := passphraseAEAD(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("加密配置已损坏")
+	}
+	key, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+// passphraseAEAD 使用 PBKDF2-SHA256 从口令派生密钥
+func passphraseAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	kek, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	return newGCM(kek)
+}
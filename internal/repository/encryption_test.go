@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// useFastKDF 降低测试中的口令派生迭代次数
+func useFastKDF(t *testing.T) {
+	t.Helper()
+	original := passphraseIterations
+	passphraseIterations = 1000
+	t.Cleanup(func() { passphraseIterations = original })
+}
+
+// rawColumn 直接读取数据库中保存的字段值
+func rawColumn(t *testing.T, db *Database, query, id string) string {
+	t.Helper()
+	var value string
+	if err := db.QueryRow(query, id).Scan(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestDatabase_PassphraseEncryption(t *testing.T) {
+	useFastKDF(t)
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 启用加密前已存在的明文数据
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+	chatRepo := NewChatRepository(db.DB, db.Cipher())
+	now := time.Now()
+	if err := repo.Create(newTestItem("1", "数据库密码", "postgres://admin@localhost 连接字符串", now)); err != nil {
+		t.Fatal(err)
+	}
+	imageItem := newTestItem("img", "图片", "[图片 1x1]", now)
+	imageItem.ContentType = models.ContentTypeImage
+	if err := repo.Create(imageItem); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateImage(models.ClipboardImage{ItemID: "img", Hash: "h", Data: []byte("\x89PNG data"), Thumbnail: []byte("\x89PNG thumb"), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	session := &models.ChatSession{ID: "s1", Title: "会话", IsActive: true, CreatedAt: now, UpdatedAt: now, LastActiveAt: now}
+	if err := chatRepo.CreateChatSession(session); err != nil {
+		t.Fatal(err)
+	}
+	message := &models.ChatMessage{ID: "m1", SessionID: "s1", Role: "user", Content: "帮我总结连接字符串", CreatedAt: now, UpdatedAt: now}
+	if err := chatRepo.CreateChatMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	if err := chatRepo.UpdateChatSessionAfterMessage("s1", message.Content); err != nil {
+		t.Fatal(err)
+	}
+	if err := chatRepo.UpdateChatSessionSummary("s1", "用户在整理数据库连接字符串", now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.EnablePassphraseEncryption("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnablePassphraseEncryption("again"); !errors.Is(err, ErrEncryptionEnabled) {
+		t.Errorf("expected ErrEncryptionEnabled, got %v", err)
+	}
+
+	// 已有数据被就地加密
+	for _, query := range []string{
+		"SELECT content FROM clipboard_items WHERE id = ?",
+		"SELECT title FROM clipboard_items WHERE id = ?",
+		"SELECT content FROM chat_messages WHERE session_id = ?",
+		"SELECT metadata FROM chat_messages WHERE session_id = ?",
+		"SELECT title FROM chat_sessions WHERE id = ?",
+		"SELECT last_message FROM chat_sessions WHERE id = ?",
+		"SELECT summary FROM chat_sessions WHERE id = ?",
+	} {
+		id := "1"
+		if strings.Contains(query, "chat") {
+			id = "s1"
+		}
+		if value := rawColumn(t, db, query, id); !strings.HasPrefix(value, encryptedPrefix) {
+			t.Errorf("%s: expected encrypted value, got %q", query, value)
+		}
+	}
+
+	// 图片数据和缩略图同样加密
+	for _, query := range []string{
+		"SELECT data FROM clipboard_images WHERE item_id = ?",
+		"SELECT thumbnail FROM clipboard_images WHERE item_id = ?",
+	} {
+		if value := rawColumn(t, db, query, "img"); !strings.HasPrefix(value, encryptedPrefix) || strings.Contains(value, "PNG") {
+			t.Errorf("%s: expected encrypted value, got %q", query, value)
+		}
+	}
+	image, err := repo.GetImage("img")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(image.Data) != "\x89PNG data" || string(image.Thumbnail) != "\x89PNG thumb" {
+		t.Errorf("unexpected decrypted image %q / %q", image.Data, image.Thumbnail)
+	}
+
+	// 图片摘要只保存 HMAC，重复检测仍然有效
+	imageHash := sha256Hex([]byte("\x89PNG data"))
+	if image.Hash != imageHash {
+		t.Errorf("expected image hash recomputed from data, got %q", image.Hash)
+	}
+	if value := rawColumn(t, db, "SELECT hash FROM clipboard_images WHERE item_id = ?", "img"); value == imageHash {
+		t.Error("image hash stored in plaintext")
+	}
+	if duplicate, err := repo.IsDuplicateImage(imageHash); err != nil || !duplicate {
+		t.Errorf("expected duplicate image via fingerprint, got %v %v", duplicate, err)
+	}
+	if sessions, err := chatRepo.ListChatSessions(); err != nil || len(sessions) != 1 || sessions[0].Title != "会话" {
+		t.Errorf("unexpected decrypted sessions %+v %v", sessions, err)
+	}
+	if err := chatRepo.UpdateChatSession("s1", "新标题"); err != nil {
+		t.Fatal(err)
+	}
+	if value := rawColumn(t, db, "SELECT title FROM chat_sessions WHERE id = ?", "s1"); strings.Contains(value, "新标题") {
+		t.Errorf("expected renamed title to be encrypted, got %q", value)
+	}
+
+	// 新写入的数据同样加密，读取时透明解密
+	if err := repo.Create(newTestItem("2", "购物清单", "牛奶 面包", now.Add(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if value := rawColumn(t, db, "SELECT content FROM clipboard_items WHERE id = ?", "2"); strings.Contains(value, "牛奶") {
+		t.Errorf("expected new content to be encrypted, got %q", value)
+	}
+	item, err := repo.GetByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "postgres://admin@localhost 连接字符串" || item.Title != "数据库密码" {
+		t.Errorf("unexpected decrypted item %+v", item)
+	}
+
+	duplicate, err := repo.IsDuplicateContent("牛奶 面包")
+	if err != nil || !duplicate {
+		t.Errorf("expected duplicate via fingerprint, got %v %v", duplicate, err)
+	}
+
+	result, err := repo.Search(models.SearchQuery{Query: "连接字符串", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "1" || result.Items[0].Snippet == "" {
+		t.Errorf("expected decrypted search match with snippet, got %+v", result)
+	}
+	db.Close()
+
+	// 重新打开后处于锁定状态
+	db, err = NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo = NewClipboardRepository(db.DB, db.Cipher())
+
+	status, err := db.EncryptionStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Enabled || !status.Locked || status.KeySource != models.EncryptionKeySourcePassphrase {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, err := repo.GetByID("1"); !errors.Is(err, ErrDatabaseLocked) {
+		t.Errorf("expected ErrDatabaseLocked on read, got %v", err)
+	}
+	if err := repo.Create(newTestItem("3", "t", "plain", now)); !errors.Is(err, ErrDatabaseLocked) {
+		t.Errorf("expected ErrDatabaseLocked on write, got %v", err)
+	}
+	if err := db.UnlockWithPassphrase("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if err := db.UnlockWithPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := NewChatRepository(db.DB, db.Cipher()).GetChatMessages("s1", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Content != "帮我总结连接字符串" {
+		t.Errorf("unexpected messages %+v", messages)
+	}
+	chatSession, err := NewChatRepository(db.DB, db.Cipher()).GetChatSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chatSession.Summary != "用户在整理数据库连接字符串" || chatSession.SummaryUntil == nil || !chatSession.SummaryUntil.Equal(now) {
+		t.Errorf("unexpected session summary %q %v", chatSession.Summary, chatSession.SummaryUntil)
+	}
+
+	// 关闭加密后恢复明文，全文索引重建
+	if err := db.DisableEncryption(); err != nil {
+		t.Fatal(err)
+	}
+	if value := rawColumn(t, db, "SELECT content FROM clipboard_items WHERE id = ?", "2"); value != "牛奶 面包" {
+		t.Errorf("expected plaintext after disabling, got %q", value)
+	}
+	if value := rawColumn(t, db, "SELECT data FROM clipboard_images WHERE item_id = ?", "img"); value != "\x89PNG data" {
+		t.Errorf("expected plaintext image after disabling, got %q", value)
+	}
+	if value := rawColumn(t, db, "SELECT hash FROM clipboard_images WHERE item_id = ?", "img"); value != imageHash {
+		t.Errorf("expected plain image hash after disabling, got %q", value)
+	}
+	if value := rawColumn(t, db, "SELECT title FROM chat_sessions WHERE id = ?", "s1"); value != "新标题" {
+		t.Errorf("expected plaintext session title after disabling, got %q", value)
+	}
+	result, err = NewClipboardRepository(db.DB, db.Cipher()).Search(models.SearchQuery{Query: "连接字符串", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("expected plaintext search to match, got %d", result.Total)
+	}
+	if status, _ := db.EncryptionStatus(); status.Enabled {
+		t.Errorf("expected encryption disabled, got %+v", status)
+	}
+}
+
+func TestDatabase_KeyEncryption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := GenerateDatabaseKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnableKeyEncryption(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewClipboardRepository(db.DB, db.Cipher()).Create(newTestItem("1", "t", "secret", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	other, _ := GenerateDatabaseKey()
+	if err := db.UnlockWithKey(other); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase for wrong key, got %v", err)
+	}
+	if err := db.UnlockWithPassphrase("anything"); err == nil {
+		t.Error("expected passphrase unlock to be rejected for key encryption")
+	}
+	if err := db.UnlockWithKey(key); err != nil {
+		t.Fatal(err)
+	}
+	item, err := NewClipboardRepository(db.DB, db.Cipher()).GetByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "secret" {
+		t.Errorf("unexpected content %q", item.Content)
+	}
+}
+
+func TestDatabase_DisableEncryptionRestoresFullTextSearch(t *testing.T) {
+	db := newTestDatabase(t)
+	if !db.ftsAvailable() {
+		t.Skip("SQLite built without FTS5")
+	}
+	key, err := GenerateDatabaseKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnableKeyEncryption(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// 仓库在加密期间（没有全文索引时）创建，关闭加密后应改用重建的索引
+	repo := NewClipboardRepository(db.DB, db.Cipher())
+	now := time.Now()
+	if err := repo.Create(newTestItem("1", "连接字符串", "redis://localhost", now.Add(-time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(newTestItem("2", "购物清单", "牛奶 面包 连接字符串", now)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DisableEncryption(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := repo.Search(models.SearchQuery{Query: "连接字符串", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LIKE 匹配按创建时间排序，全文索引按 BM25 排序时标题命中的权重更高
+	if result.Total != 2 || result.Items[0].ID != "1" {
+		t.Errorf("expected full-text ranking after disabling encryption, got %+v", result.Items)
+	}
+}
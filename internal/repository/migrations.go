@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Migration 数据库结构迁移
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+}
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	DryRun bool // 仅试运行：在事务中执行后回滚，不修改数据库，也不备份
+}
+
+// MigrationReport 迁移执行结果
+type MigrationReport struct {
+	FromVersion int         `json:"from_version"`
+	ToVersion   int         `json:"to_version"`
+	Pending     []Migration `json:"-"`
+	BackupPath  string      `json:"backup_path,omitempty"`
+	DryRun      bool        `json:"dry_run"`
+}
+
+// migrations 按版本号排列的迁移列表，只允许追加，不要修改已发布的迁移
+var migrations = []Migration{
+	{Version: 1, Name: "initial_schema", Up: migrateInitialSchema},
+	{Version: 2, Name: "default_tag_groups", Up: migrateDefaultTagGroups},
+	{Version: 3, Name: "clipboard_images", Up: migrateClipboardImages},
+	{Version: 4, Name: "clipboard_items_is_sensitive", Up: migrateItemSensitiveFlag},
+	{Version: 5, Name: "tagging_jobs", Up: migrateTaggingJobs},
+	{Version: 6, Name: "clipboard_items_expires_at", Up: migrateItemExpiresAt},
+	{Version: 7, Name: "database_encryption", Up: migrateDatabaseEncryption},
+	{Version: 8, Name: "clipboard_items_metadata", Up: migrateItemMetadata},
+	{Version: 9, Name: "capture_rules", Up: migrateCaptureRules},
+	{Version: 10, Name: "clipboard_items_code_language", Up: migrateItemCodeLanguage},
+	{Version: 11, Name: "chat_sessions_summary", Up: migrateChatSessionSummary},
+	{Version: 12, Name: "clipboard_embeddings", Up: migrateClipboardEmbeddings},
+}
+
+// Migrate 执行所有待执行的迁移，每个迁移在独立事务中运行
+func (db *Database) Migrate(opts MigrateOptions) (*MigrationReport, error) {
+	return db.runMigrations(migrations, opts)
+}
+
+// SchemaVersion 获取当前数据库结构版本，未执行过迁移时返回 0
+func (db *Database) SchemaVersion() (int, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// runMigrations 按顺序执行给定迁移列表中尚未执行的部分
+func (db *Database) runMigrations(list []Migration, opts MigrateOptions) (*MigrationReport, error) {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %v", err)
+	}
+
+	report := &MigrationReport{FromVersion: current, ToVersion: current, DryRun: opts.DryRun}
+	for _, m := range list {
+		if m.Version > current {
+			report.Pending = append(report.Pending, m)
+		}
+	}
+	if len(report.Pending) == 0 {
+		return report, nil
+	}
+
+	if opts.DryRun {
+		// 后续迁移依赖前面迁移的结果，因此全部放在同一个事务中执行后回滚
+		tx, err := db.Begin()
+		if err != nil {
+			return report, err
+		}
+		defer tx.Rollback()
+
+		for _, m := range report.Pending {
+			if err := applyMigration(tx, m); err != nil {
+				return report, err
+			}
+			log.Printf("🧪 迁移试运行通过: %03d_%s", m.Version, m.Name)
+			report.ToVersion = m.Version
+		}
+		return report, nil
+	}
+
+	// 已有数据时先备份，迁移失败可从备份恢复
+	hasData, err := db.hasExistingData()
+	if err != nil {
+		return report, err
+	}
+	if hasData {
+		target := report.Pending[len(report.Pending)-1].Version
+		backupPath, err := db.backupBeforeMigration(current, target)
+		if err != nil {
+			return report, err
+		}
+		report.BackupPath = backupPath
+	}
+
+	for _, m := range report.Pending {
+		if err := db.commitMigration(m); err != nil {
+			if report.BackupPath != "" {
+				return report, fmt.Errorf("%v (迁移前备份: %s)", err, report.BackupPath)
+			}
+			return report, err
+		}
+		log.Printf("✅ 数据库迁移完成: %03d_%s", m.Version, m.Name)
+		report.ToVersion = m.Version
+	}
+	return report, nil
+}
+
+// commitMigration 在独立事务中执行单个迁移并提交
+func (db *Database) commitMigration(m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := applyMigration(tx, m); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyMigration 在给定事务中执行迁移并记录版本
+func applyMigration(tx *sql.Tx, m Migration) error {
+	if err := ensureMigrationTable(tx); err != nil {
+		return err
+	}
+	if err := m.Up(tx); err != nil {
+		return fmt.Errorf("migration %03d_%s failed: %v", m.Version, m.Name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now()); err != nil {
+		return fmt.Errorf("migration %03d_%s failed: %v", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// ensureMigrationTable 创建迁移记录表
+func ensureMigrationTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// hasExistingData 检查数据库中是否已有业务表
+func (db *Database) hasExistingData() (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'clipboard_items'").Scan(&count)
+	return count > 0, err
+}
+
+// backupBeforeMigration 使用 VACUUM INTO 生成一致的数据库副本
+func (db *Database) backupBeforeMigration(from, to int) (string, error) {
+	if db.path == "" {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d-to-v%d-%s.bak", db.path, from, to, time.Now().Format("20060102-150405"))
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("backup file already exists: %s", backupPath)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return "", fmt.Errorf("failed to backup database before migration: %v", err)
+	}
+
+	log.Printf("💾 迁移前已备份数据库: %s", backupPath)
+	return backupPath, nil
+}
+
+// addColumnIfMissing 为表添加列（已存在时跳过），用于兼容旧版本创建的表
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if strings.EqualFold(name, column) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// migrateInitialSchema 001: 基础表结构（兼容迁移框架引入前创建的数据库）
+func migrateInitialSchema(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS clipboard_items (
+		id TEXT PRIMARY KEY,
+		content TEXT NOT NULL,
+		content_type TEXT DEFAULT 'text',
+		title TEXT NOT NULL,
+		category TEXT DEFAULT '未分类',
+		is_favorite BOOLEAN DEFAULT 0,
+		use_count INTEGER DEFAULT 0,
+		is_deleted BOOLEAN DEFAULT 0,
+		deleted_at DATETIME NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_used_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_created_at ON clipboard_items(created_at);
+	CREATE INDEX IF NOT EXISTS idx_category ON clipboard_items(category);
+	CREATE INDEX IF NOT EXISTS idx_is_favorite ON clipboard_items(is_favorite);
+	CREATE INDEX IF NOT EXISTS idx_use_count ON clipboard_items(use_count);
+	CREATE INDEX IF NOT EXISTS idx_is_deleted ON clipboard_items(is_deleted);
+
+	-- 聊天会话表
+	CREATE TABLE IF NOT EXISTS chat_sessions (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT DEFAULT '',
+		last_message TEXT DEFAULT '',
+		message_count INTEGER DEFAULT 0,
+		is_active BOOLEAN DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_active_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_chat_sessions_created_at ON chat_sessions(created_at);
+	CREATE INDEX IF NOT EXISTS idx_chat_sessions_is_active ON chat_sessions(is_active);
+	CREATE INDEX IF NOT EXISTS idx_chat_sessions_last_active_at ON chat_sessions(last_active_at);
+
+	-- 聊天消息表
+	CREATE TABLE IF NOT EXISTS chat_messages (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		content TEXT NOT NULL,
+		content_type TEXT DEFAULT 'text',
+		metadata TEXT DEFAULT '{}',
+		is_streaming BOOLEAN DEFAULT 0,
+		is_complete BOOLEAN DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (session_id) REFERENCES chat_sessions(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_chat_messages_session_id ON chat_messages(session_id);
+	CREATE INDEX IF NOT EXISTS idx_chat_messages_created_at ON chat_messages(created_at);
+	CREATE INDEX IF NOT EXISTS idx_chat_messages_role ON chat_messages(role);
+
+	-- 标签分组表
+	CREATE TABLE IF NOT EXISTS tag_groups (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT DEFAULT '',
+		color TEXT DEFAULT '#1890ff',
+		sort_order INTEGER DEFAULT 0,
+		is_system BOOLEAN DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tag_groups_sort_order ON tag_groups(sort_order);
+	CREATE INDEX IF NOT EXISTS idx_tag_groups_is_system ON tag_groups(is_system);
+
+	-- 标签表
+	CREATE TABLE IF NOT EXISTS tags (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT DEFAULT '',
+		color TEXT DEFAULT '#1890ff',
+		group_id TEXT,
+		use_count INTEGER DEFAULT 0,
+		is_system BOOLEAN DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_used_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (group_id) REFERENCES tag_groups(id) ON DELETE SET NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
+	CREATE INDEX IF NOT EXISTS idx_tags_group_id ON tags(group_id);
+	CREATE INDEX IF NOT EXISTS idx_tags_use_count ON tags(use_count);
+	CREATE INDEX IF NOT EXISTS idx_tags_is_system ON tags(is_system);
+	CREATE INDEX IF NOT EXISTS idx_tags_last_used_at ON tags(last_used_at);
+
+	-- 剪切板条目标签关联表
+	CREATE TABLE IF NOT EXISTS clipboard_item_tags (
+		id TEXT PRIMARY KEY,
+		item_id TEXT NOT NULL,
+		tag_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (item_id) REFERENCES clipboard_items(id) ON DELETE CASCADE,
+		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+		UNIQUE(item_id, tag_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_item_id ON clipboard_item_tags(item_id);
+	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_tag_id ON clipboard_item_tags(tag_id);
+	CREATE INDEX IF NOT EXISTS idx_clipboard_item_tags_created_at ON clipboard_item_tags(created_at);
+	`)
+	return err
+}
+
+// migrateDefaultTagGroups 002: 内置 AI 生成标签分组
+func migrateDefaultTagGroups(tx *sql.Tx) error {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO tag_groups (id, name, description, color, sort_order, is_system, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"ai-generated", "AI生成", "AI自动生成的标签", "#52c41a", 0, true, "2024-01-01 00:00:00", "2024-01-01 00:00:00")
+	return err
+}
+
+// migrateClipboardImages 003: 剪切板图片表（PNG 原图和缩略图）
+func migrateClipboardImages(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS clipboard_images (
+		item_id TEXT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		data BLOB NOT NULL,
+		thumbnail BLOB,
+		width INTEGER DEFAULT 0,
+		height INTEGER DEFAULT 0,
+		size INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (item_id) REFERENCES clipboard_items(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_clipboard_images_hash ON clipboard_images(hash);
+	`)
+	return err
+}
+
+// migrateItemSensitiveFlag 004: 条目敏感标记
+func migrateItemSensitiveFlag(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "clipboard_items", "is_sensitive", "BOOLEAN DEFAULT 0")
+}
+
+// migrateTaggingJobs 005: 后台AI打标签任务队列
+func migrateTaggingJobs(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS tagging_jobs (
+		id TEXT PRIMARY KEY,
+		item_id TEXT NOT NULL UNIQUE,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER DEFAULT 0,
+		last_error TEXT DEFAULT '',
+		next_run_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (item_id) REFERENCES clipboard_items(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tagging_jobs_status_next_run_at ON tagging_jobs(status, next_run_at);
+	`)
+	return err
+}
+
+// migrateItemExpiresAt 006: 条目自动过期时间（敏感内容规则设置）
+func migrateItemExpiresAt(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "clipboard_items", "expires_at", "DATETIME NULL"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_clipboard_items_expires_at ON clipboard_items(expires_at)`)
+	return err
+}
+
+// migrateDatabaseEncryption 007: 加密配置表及加密后用于去重的内容指纹列
+func migrateDatabaseEncryption(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "clipboard_items", "content_hash", "TEXT NULL"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+	CREATE INDEX IF NOT EXISTS idx_clipboard_items_content_hash ON clipboard_items(content_hash);
+
+	CREATE TABLE IF NOT EXISTS database_encryption (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		key_source TEXT NOT NULL,
+		salt BLOB NULL,
+		iterations INTEGER NOT NULL DEFAULT 0,
+		wrapped_key BLOB NULL,
+		verifier TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	`)
+	return err
+}
+
+// migrateItemMetadata 008: 条目来源应用、窗口标题和内容统计
+// 未加密的数据库在 SQL 中回填已有文本条目的字节数和行数，语言无法在 SQL 中检测，保持为空
+func migrateItemMetadata(tx *sql.Tx) error {
+	columns := []struct{ name, definition string }{
+		{"source_app", "TEXT NOT NULL DEFAULT ''"},
+		{"source_title", "TEXT NOT NULL DEFAULT ''"},
+		{"content_length", "INTEGER NOT NULL DEFAULT 0"},
+		{"line_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"language", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, column := range columns {
+		if err := addColumnIfMissing(tx, "clipboard_items", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	var encrypted bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM database_encryption)").Scan(&encrypted); err != nil {
+		return err
+	}
+	if !encrypted {
+		if _, err := tx.Exec(`
+		UPDATE clipboard_items
+		SET content_length = length(CAST(content AS BLOB)),
+			line_count = CASE WHEN content = '' THEN 0
+				ELSE length(rtrim(content, char(10))) - length(replace(rtrim(content, char(10)), char(10), '')) + 1 END
+		WHERE content_type = 'text'`); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`
+	UPDATE clipboard_items
+	SET content_length = (SELECT size FROM clipboard_images WHERE item_id = clipboard_items.id)
+	WHERE content_type = 'image' AND EXISTS (SELECT 1 FROM clipboard_images WHERE item_id = clipboard_items.id)`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_clipboard_items_source_app ON clipboard_items(source_app COLLATE NOCASE)`)
+	return err
+}
+
+// migrateCaptureRules 009: 捕获规则表，动作以 JSON 数组保存
+func migrateCaptureRules(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS capture_rules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		sort_order INTEGER NOT NULL DEFAULT 0,
+		stop BOOLEAN NOT NULL DEFAULT 0,
+		source_app TEXT NOT NULL DEFAULT '',
+		pattern TEXT NOT NULL DEFAULT '',
+		min_length INTEGER NOT NULL DEFAULT 0,
+		max_length INTEGER NOT NULL DEFAULT 0,
+		category TEXT NOT NULL DEFAULT '',
+		actions TEXT NOT NULL DEFAULT '[]',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_capture_rules_sort_order ON capture_rules(sort_order);
+	`)
+	return err
+}
+
+// migrateItemCodeLanguage 010: 代码条目猜测的编程语言
+// 内容类型检测无法在 SQL 中完成，已有条目保持原来的内容类型
+func migrateItemCodeLanguage(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "clipboard_items", "code_language", "TEXT NOT NULL DEFAULT ''")
+}
+
+// migrateChatSessionSummary 011: 聊天会话的滚动摘要（加密数据库中摘要同样加密保存）
+func migrateChatSessionSummary(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "chat_sessions", "summary", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return addColumnIfMissing(tx, "chat_sessions", "summary_until", "DATETIME NULL")
+}
+
+// migrateClipboardEmbeddings 012: 条目文本向量表（语义搜索），每个条目只保留当前模型的向量
+func migrateClipboardEmbeddings(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS clipboard_embeddings (
+		item_id TEXT PRIMARY KEY,
+		model TEXT NOT NULL,
+		dimensions INTEGER NOT NULL,
+		vector TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES clipboard_items(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_clipboard_embeddings_model ON clipboard_embeddings(model);
+	`)
+	return err
+}
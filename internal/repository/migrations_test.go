@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabase_MigrateFreshDatabase(t *testing.T) {
+	db := newTestDatabase(t)
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := migrations[len(migrations)-1].Version; version != want {
+		t.Errorf("expected schema version %d, got %d", want, version)
+	}
+
+	// 再次执行不应有待执行迁移
+	report, err := db.Migrate(MigrateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pending) != 0 || report.BackupPath != "" {
+		t.Errorf("expected no pending migrations, got %+v", report)
+	}
+}
+
+func TestDatabase_MigrateDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDatabaseWithOptions(path, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Errorf("dry run must not change schema version, got %d", version)
+	}
+	if ok, _ := db.hasExistingData(); ok {
+		t.Error("dry run must not create tables")
+	}
+
+	report, err := db.Migrate(MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pending) != len(migrations) || report.ToVersion != migrations[len(migrations)-1].Version {
+		t.Errorf("unexpected dry run report %+v", report)
+	}
+}
+
+func TestDatabase_MigrateLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// 迁移框架引入前创建的数据库：已有业务表但没有 schema_migrations
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raw.Exec(`CREATE TABLE clipboard_items (
+		id TEXT PRIMARY KEY, content TEXT NOT NULL, content_type TEXT DEFAULT 'text', title TEXT NOT NULL,
+		category TEXT DEFAULT '未分类', is_favorite BOOLEAN DEFAULT 0, use_count INTEGER DEFAULT 0,
+		is_deleted BOOLEAN DEFAULT 0, deleted_at DATETIME NULL, created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP, last_used_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO clipboard_items (id, content, title) VALUES ('1', 'legacy', 'legacy');`); err != nil {
+		t.Fatal(err)
+	}
+	raw.Close()
+
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	matches, err := filepath.Glob(path + ".v0-to-v*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected one pre-migration backup, got %v", matches)
+	}
+
+	backup, err := sql.Open("sqlite3", matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	var count int
+	if err := backup.QueryRow("SELECT COUNT(*) FROM clipboard_items").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected backup to contain legacy row, got %d", count)
+	}
+
+	item, err := NewClipboardRepository(db.DB, db.Cipher()).GetByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "legacy" {
+		t.Errorf("unexpected content %q", item.Content)
+	}
+	if item.ContentLength != 6 || item.LineCount != 1 {
+		t.Errorf("expected backfilled stats, got length=%d lines=%d", item.ContentLength, item.LineCount)
+	}
+}
+
+func TestDatabase_MigrateRollbackOnFailure(t *testing.T) {
+	db := newTestDatabase(t)
+	before, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := append([]Migration{}, migrations...)
+	list = append(list,
+		Migration{Version: before + 1, Name: "add_column", Up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "clipboard_items", "pinned", "BOOLEAN DEFAULT 0")
+		}},
+		Migration{Version: before + 2, Name: "broken", Up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "clipboard_items", "broken", "TEXT"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		}},
+	)
+
+	report, err := db.runMigrations(list, MigrateOptions{})
+	if err == nil {
+		t.Fatal("expected migration error")
+	}
+	if report.ToVersion != before+1 {
+		t.Errorf("expected to stop at v%d, got v%d", before+1, report.ToVersion)
+	}
+	if report.BackupPath == "" {
+		t.Error("expected backup before migrating existing database")
+	}
+
+	var pinned, broken int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('clipboard_items') WHERE name = 'pinned'").Scan(&pinned); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('clipboard_items') WHERE name = 'broken'").Scan(&broken); err != nil {
+		t.Fatal(err)
+	}
+	if pinned != 1 || broken != 0 {
+		t.Errorf("expected committed column and rolled back column, got pinned=%d broken=%d", pinned, broken)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != before+1 {
+		t.Errorf("expected schema version %d, got %d", before+1, version)
+	}
+}
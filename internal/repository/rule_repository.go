@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// RuleRepository 捕获规则仓库接口
+type RuleRepository interface {
+	List() ([]models.CaptureRule, error)
+	GetByID(id string) (*models.CaptureRule, error)
+	Create(rule models.CaptureRule) error
+	Update(rule models.CaptureRule) error
+	Delete(id string) error
+	Reorder(ids []string) error
+}
+
+// ruleColumns 规则查询列，顺序与 scanRule 一致
+const ruleColumns = "id, name, enabled, sort_order, stop, source_app, pattern, min_length, max_length, category, actions, created_at, updated_at"
+
+// ruleRepository 捕获规则仓库实现
+type ruleRepository struct {
+	db *sql.DB
+}
+
+// NewRuleRepository 创建新的捕获规则仓库
+func NewRuleRepository(db *sql.DB) RuleRepository {
+	return &ruleRepository{db: db}
+}
+
+// scanRule 按 ruleColumns 的顺序扫描一行
+func scanRule(row rowScanner) (models.CaptureRule, error) {
+	var rule models.CaptureRule
+	var actions string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Enabled, &rule.SortOrder, &rule.Stop,
+		&rule.SourceApp, &rule.Pattern, &rule.MinLength, &rule.MaxLength, &rule.Category, &actions,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(actions), &rule.Actions); err != nil {
+		return rule, fmt.Errorf("规则 %s 的动作无法解析: %v", rule.ID, err)
+	}
+	return rule, nil
+}
+
+// List 按匹配顺序返回全部规则
+func (r *ruleRepository) List() ([]models.CaptureRule, error) {
+	rows, err := r.db.Query("SELECT " + ruleColumns + " FROM capture_rules ORDER BY sort_order, created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.CaptureRule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetByID 根据ID获取规则
+func (r *ruleRepository) GetByID(id string) (*models.CaptureRule, error) {
+	rule, err := scanRule(r.db.QueryRow("SELECT "+ruleColumns+" FROM capture_rules WHERE id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create 创建规则
+func (r *ruleRepository) Create(rule models.CaptureRule) error {
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`INSERT INTO capture_rules (`+ruleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Enabled, rule.SortOrder, rule.Stop, rule.SourceApp, rule.Pattern,
+		rule.MinLength, rule.MaxLength, rule.Category, string(actions), rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// Update 更新规则，规则不存在时返回 sql.ErrNoRows
+func (r *ruleRepository) Update(rule models.CaptureRule) error {
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec(`
+	UPDATE capture_rules
+	SET name = ?, enabled = ?, sort_order = ?, stop = ?, source_app = ?, pattern = ?, min_length = ?, max_length = ?,
+		category = ?, actions = ?, updated_at = ?
+	WHERE id = ?`,
+		rule.Name, rule.Enabled, rule.SortOrder, rule.Stop, rule.SourceApp, rule.Pattern, rule.MinLength, rule.MaxLength,
+		rule.Category, string(actions), rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+// Delete 删除规则，规则不存在时返回 sql.ErrNoRows
+func (r *ruleRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM capture_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+// Reorder 按给定顺序重写 sort_order，未列出的规则排在最后并保持原有顺序
+func (r *ruleRepository) Reorder(ids []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec("UPDATE capture_rules SET sort_order = sort_order + ?", len(ids)); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		result, err := tx.Exec("UPDATE capture_rules SET sort_order = ?, updated_at = ? WHERE id = ?", i, now, id)
+		if err != nil {
+			return err
+		}
+		if err := requireRow(result); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// requireRow 没有行受影响时返回 sql.ErrNoRows
+func requireRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func TestRuleRepository_CRUDAndReorder(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewRuleRepository(db.DB)
+
+	now := time.Now()
+	for i, id := range []string{"a", "b", "c"} {
+		rule := models.CaptureRule{
+			ID: id, Name: "规则 " + id, Enabled: true, SortOrder: i, Pattern: `\d+`,
+			Actions:   []models.RuleAction{{Type: models.RuleActionTag, Value: "数字"}},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := repo.Create(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rule, err := repo.GetByID("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Pattern != `\d+` || len(rule.Actions) != 1 || rule.Actions[0].Value != "数字" {
+		t.Fatalf("rule = %+v", rule)
+	}
+
+	rule.Enabled = false
+	rule.Actions = append(rule.Actions, models.RuleAction{Type: models.RuleActionFavorite})
+	if err := repo.Update(*rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(models.CaptureRule{ID: "missing"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+
+	// 未列出的规则排在列出的规则之后
+	if err := repo.Reorder([]string{"c", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := repo.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, r := range rules {
+		order = append(order, r.ID)
+	}
+	if len(order) != 3 || order[0] != "c" || order[1] != "a" || order[2] != "b" {
+		t.Fatalf("order = %v", order)
+	}
+	if rules[2].Enabled || len(rules[2].Actions) != 2 {
+		t.Errorf("update not persisted: %+v", rules[2])
+	}
+	if err := repo.Reorder([]string{"missing"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown rule, got %v", err)
+	}
+
+	if err := repo.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete("a"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"Sid/internal/models"
+)
+
+// trigramMinLength trigram 分词器可索引的最短关键词长度
+const trigramMinLength = 3
+
+// snippetRadius 摘要中命中位置前后保留的字符数
+const snippetRadius = 30
+
+// splitSearchTerms 按空白拆分搜索关键词
+func splitSearchTerms(query string) []string {
+	return strings.Fields(query)
+}
+
+// allTermsIndexable 检查所有关键词是否都能使用 trigram 索引
+func allTermsIndexable(terms []string) bool {
+	for _, term := range terms {
+		if len([]rune(term)) < trigramMinLength {
+			return false
+		}
+	}
+	return true
+}
+
+// buildMatchQuery 构建 FTS5 MATCH 表达式，每个关键词作为短语并以 AND 连接
+func buildMatchQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " AND ")
+}
+
+// matchesAllTerms 检查每个关键词都出现在标题或内容中（不区分大小写），与 LIKE 匹配的语义一致
+func matchesAllTerms(item models.ClipboardItem, terms []string) bool {
+	title, content := strings.ToLower(item.Title), strings.ToLower(item.Content)
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		if !strings.Contains(title, term) && !strings.Contains(content, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyHighlights 计算条目标题和内容中的命中范围，并生成内容摘要
+func applyHighlights(item *models.ClipboardItem, terms []string) {
+	if len(terms) == 0 || item.IsImage() {
+		return
+	}
+
+	var highlights []models.HighlightRange
+	for _, r := range findMatches(item.Title, terms) {
+		highlights = append(highlights, models.HighlightRange{Field: "title", Start: r[0], End: r[1]})
+	}
+
+	contentMatches := findMatches(item.Content, terms)
+	for _, r := range contentMatches {
+		highlights = append(highlights, models.HighlightRange{Field: "content", Start: r[0], End: r[1]})
+	}
+
+	item.Highlights = highlights
+	item.Snippet = makeSnippet(item.Content, contentMatches)
+}
+
+// findMatches 查找所有关键词的命中范围（不区分大小写，按 rune 偏移），结果按起始位置排序且互不重叠
+func findMatches(text string, terms []string) [][2]int {
+	runes := foldRunes([]rune(text))
+	covered := make([]bool, len(runes))
+
+	for _, term := range terms {
+		needle := foldRunes([]rune(term))
+		if len(needle) == 0 || len(needle) > len(runes) {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(runes); i++ {
+			if runesEqual(runes[i:i+len(needle)], needle) {
+				for j := i; j < i+len(needle); j++ {
+					covered[j] = true
+				}
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i := 0; i < len(covered); i++ {
+		if !covered[i] {
+			continue
+		}
+		start := i
+		for i < len(covered) && covered[i] {
+			i++
+		}
+		matches = append(matches, [2]int{start, i})
+	}
+	return matches
+}
+
+// makeSnippet 截取第一个命中位置附近的文本，并用 <mark> 标记命中部分；
+// 文本部分经过 HTML 转义，摘要可以直接作为 HTML 渲染
+func makeSnippet(text string, matches [][2]int) string {
+	if len(matches) == 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	start := max(0, matches[0][0]-snippetRadius)
+	end := min(len(runes), matches[0][1]+snippetRadius)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m[1] <= start || m[0] >= end {
+			continue
+		}
+		mStart, mEnd := max(m[0], start), min(m[1], end)
+		b.WriteString(html.EscapeString(string(runes[pos:mStart])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[mStart:mEnd])))
+		b.WriteString("</mark>")
+		pos = mEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// foldRunes 转为小写用于不区分大小写的比较（逐 rune 转换，偏移保持不变）
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
+}
+
+// runesEqual 比较两个 rune 切片
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
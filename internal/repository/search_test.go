@@ -0,0 +1,26 @@
+package repository
+
+import "testing"
+
+func TestMakeSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{"plain", "买牛奶和面包", []string{"牛奶"}, "买<mark>牛奶</mark>和面包"},
+		{"escape context", `<script>alert("x")</script> token`, []string{"token"}, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>token</mark>"},
+		{"escape match", "a <b>bold</b> c", []string{"<b>"}, "a <mark>&lt;b&gt;</mark>bold&lt;/b&gt; c"},
+		{"ampersand", "Tom & Jerry", []string{"jerry"}, "Tom &amp; <mark>Jerry</mark>"},
+		{"no match", "hello", []string{"world"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := makeSnippet(tt.text, findMatches(tt.text, tt.terms)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Sid/internal/models"
+)
+
+// TaggingRepository 后台打标签任务仓库接口
+type TaggingRepository interface {
+	Enqueue(itemID string) error
+	ClaimNext(now time.Time) (*models.TaggingJob, error)
+	Complete(id string) error
+	Skip(id, reason string) error
+	Retry(id string, attempts int, lastError string, nextRunAt time.Time) error
+	Fail(id string, attempts int, lastError string) error
+	ResetRunning() error
+	CountByStatus() (map[string]int, error)
+}
+
+// taggingRepository 后台打标签任务仓库实现
+type taggingRepository struct {
+	db *sql.DB
+}
+
+// NewTaggingRepository 创建新的打标签任务仓库
+func NewTaggingRepository(db *sql.DB) TaggingRepository {
+	return &taggingRepository{db: db}
+}
+
+// Enqueue 将条目加入队列，已结束的任务会被重新激活，排队中的任务保持不变
+func (r *taggingRepository) Enqueue(itemID string) error {
+	now := time.Now()
+	query := `
+	INSERT INTO tagging_jobs (id, item_id, status, attempts, last_error, next_run_at, created_at, updated_at)
+	VALUES (?, ?, ?, 0, '', ?, ?, ?)
+	ON CONFLICT(item_id) DO UPDATE SET
+		status = excluded.status, attempts = 0, last_error = '', next_run_at = excluded.next_run_at, updated_at = excluded.updated_at
+	WHERE tagging_jobs.status NOT IN (?, ?)
+	`
+	_, err := r.db.Exec(query, uuid.New().String(), itemID, models.TaggingStatusPending, now, now, now,
+		models.TaggingStatusPending, models.TaggingStatusRunning)
+	return err
+}
+
+// ClaimNext 领取一个已到执行时间的任务并标记为执行中，没有任务时返回 nil
+func (r *taggingRepository) ClaimNext(now time.Time) (*models.TaggingJob, error) {
+	query := `
+	UPDATE tagging_jobs SET status = ?, updated_at = ?
+	WHERE id = (
+		SELECT id FROM tagging_jobs
+		WHERE status = ? AND next_run_at <= ?
+		ORDER BY next_run_at, created_at
+		LIMIT 1
+	)
+	RETURNING id, item_id, status, attempts, last_error, next_run_at, created_at, updated_at
+	`
+
+	var job models.TaggingJob
+	err := r.db.QueryRow(query, models.TaggingStatusRunning, now, models.TaggingStatusPending, now).Scan(
+		&job.ID, &job.ItemID, &job.Status, &job.Attempts, &job.LastError, &job.NextRunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Complete 标记任务完成
+func (r *taggingRepository) Complete(id string) error {
+	_, err := r.db.Exec(`UPDATE tagging_jobs SET status = ?, last_error = '', updated_at = ? WHERE id = ?`,
+		models.TaggingStatusDone, time.Now(), id)
+	return err
+}
+
+// Skip 标记任务跳过
+func (r *taggingRepository) Skip(id, reason string) error {
+	_, err := r.db.Exec(`UPDATE tagging_jobs SET status = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		models.TaggingStatusSkipped, reason, time.Now(), id)
+	return err
+}
+
+// Retry 任务失败后重新排队，在 nextRunAt 之后再次执行
+func (r *taggingRepository) Retry(id string, attempts int, lastError string, nextRunAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE tagging_jobs SET status = ?, attempts = ?, last_error = ?, next_run_at = ?, updated_at = ? WHERE id = ?`,
+		models.TaggingStatusPending, attempts, lastError, nextRunAt, time.Now(), id)
+	return err
+}
+
+// Fail 标记任务最终失败
+func (r *taggingRepository) Fail(id string, attempts int, lastError string) error {
+	_, err := r.db.Exec(`UPDATE tagging_jobs SET status = ?, attempts = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		models.TaggingStatusFailed, attempts, lastError, time.Now(), id)
+	return err
+}
+
+// ResetRunning 将上次退出时未完成的任务恢复为待执行
+func (r *taggingRepository) ResetRunning() error {
+	_, err := r.db.Exec(`UPDATE tagging_jobs SET status = ?, updated_at = ? WHERE status = ?`,
+		models.TaggingStatusPending, time.Now(), models.TaggingStatusRunning)
+	return err
+}
+
+// CountByStatus 按状态统计任务数量
+func (r *taggingRepository) CountByStatus() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM tagging_jobs GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
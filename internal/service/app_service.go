@@ -7,7 +7,9 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 
+	model "Sid/internal/agent"
 	"Sid/internal/config"
 	"Sid/internal/models"
 	"Sid/internal/window"
@@ -22,6 +24,13 @@ type AppService interface {
 	GetSettings() (*models.Settings, error)
 	UpdateSettings(settings *models.Settings) error
 
+	// 大模型配置
+	GetLLMSettings() (models.LLMSettings, error)
+	UpdateLLMSettings(settings models.LLMSettings) error
+	TestLLMConnection(ctx context.Context, settings models.LLMSettings) error
+	GetLLMProviderStatus(ctx context.Context) ([]models.LLMProviderStatus, error)
+	ListLocalModels(ctx context.Context, baseURL string) ([]string, error)
+
 	// 窗口管理
 	ShowWindow()
 	HideWindow()
@@ -42,6 +51,10 @@ type appService struct {
 	windowManager    window.Manager
 	clipboardService ClipboardService
 	chatService      ChatService
+	retention        RetentionService
+	backup           BackupService
+	chatModels       model.Provider
+	embeddings       EmbeddingService
 	settings         *models.Settings
 }
 
@@ -51,12 +64,20 @@ func NewAppService(
 	windowManager window.Manager,
 	clipboardService ClipboardService,
 	chatService ChatService,
+	retention RetentionService,
+	backup BackupService,
+	chatModels model.Provider,
+	embeddings EmbeddingService,
 ) AppService {
 	return &appService{
 		configManager:    configManager,
 		windowManager:    windowManager,
 		clipboardService: clipboardService,
 		chatService:      chatService,
+		retention:        retention,
+		backup:           backup,
+		chatModels:       chatModels,
+		embeddings:       embeddings,
 	}
 }
 
@@ -105,9 +126,132 @@ func (s *appService) UpdateSettings(settings *models.Settings) error {
 		clipboardService.UpdateSettings(settings)
 	}
 
+	// 保留策略可能已变化
+	s.retention.UpdateSettings(settings)
+
+	// 备份策略可能已变化
+	s.backup.UpdateSettings(settings)
+
+	// 大模型配置可能已变化
+	s.chatModels.Reload()
+	s.chatService.UpdateSettings(settings.LLM)
+	s.embeddings.UpdateSettings(settings.LLM.Embedding)
+
+	return nil
+}
+
+// GetLLMSettings 获取大模型配置（不包含密钥明文）
+func (s *appService) GetLLMSettings() (models.LLMSettings, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return models.LLMSettings{}, err
+	}
+	return settings.LLM, nil
+}
+
+// UpdateLLMSettings 更新大模型配置，APIKey 为空时保留已保存的密钥
+func (s *appService) UpdateLLMSettings(llm models.LLMSettings) error {
+	current, err := s.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	settings := *current
+	settings.LLM = llm
+	if err := s.configManager.Save(&settings); err != nil {
+		return err
+	}
+	s.settings = &settings
+	s.chatModels.Reload()
+	s.chatService.UpdateSettings(llm)
+	s.embeddings.UpdateSettings(llm.Embedding)
+
+	log.Printf("✅ 大模型配置已更新: %s / %s", llm.BaseURL, llm.Model)
 	return nil
 }
 
+// TestLLMConnection 使用给定配置测试大模型连接，APIKey 为空时使用已保存的密钥
+func (s *appService) TestLLMConnection(ctx context.Context, llm models.LLMSettings) error {
+	apiKey := llm.APIKey
+	if apiKey == "" {
+		var err error
+		if apiKey, err = s.configManager.Secrets().Get(config.SecretLLMAPIKey); err != nil {
+			return err
+		}
+	}
+	return model.TestConnection(ctx, model.ConfigsFromSettings(llm, apiKey))
+}
+
+// GetLLMProviderStatus 检查所有已配置大模型服务的健康状态
+func (s *appService) GetLLMProviderStatus(ctx context.Context) ([]models.LLMProviderStatus, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := s.configManager.Secrets().Get(config.SecretLLMAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := model.ConfigsFromSettings(settings.LLM, apiKey)
+	statuses := make([]models.LLMProviderStatus, len(configs))
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func(i int, cfg *model.ChatModelConfig) {
+			defer wg.Done()
+			statuses[i] = model.CheckHealth(ctx, cfg)
+		}(i, cfg)
+	}
+	wg.Wait()
+	return statuses, nil
+}
+
+// ListLocalModels 获取本地服务提供的模型列表
+func (s *appService) ListLocalModels(ctx context.Context, baseURL string) ([]string, error) {
+	if baseURL == "" {
+		settings, err := s.GetSettings()
+		if err != nil {
+			return nil, err
+		}
+		baseURL = settings.LLM.Local.BaseURL
+	}
+	return model.ListModels(ctx, baseURL, "")
+}
+
+// NewChatModelProvider 创建按应用设置加载配置的聊天模型提供者
+func NewChatModelProvider(configManager config.Manager) model.Provider {
+	return model.NewProvider(func() ([]*model.ChatModelConfig, error) {
+		settings, err := configManager.Load()
+		if err != nil {
+			return nil, err
+		}
+		apiKey, err := configManager.Secrets().Get(config.SecretLLMAPIKey)
+		if err != nil {
+			return nil, err
+		}
+		return model.ConfigsFromSettings(settings.LLM, apiKey), nil
+	})
+}
+
+// NewEmbedderProvider 创建按应用设置加载配置的向量模型提供者，未启用语义搜索时返回 model.ErrEmbeddingDisabled
+func NewEmbedderProvider(configManager config.Manager) model.EmbedderProvider {
+	return model.NewEmbedderProvider(func() (model.Embedder, error) {
+		settings, err := configManager.Load()
+		if err != nil {
+			return nil, err
+		}
+		if !settings.LLM.Embedding.Enabled {
+			return nil, model.ErrEmbeddingDisabled
+		}
+		apiKey, err := configManager.Secrets().Get(config.SecretLLMAPIKey)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewEmbedder(model.EmbedderConfigFromSettings(settings.LLM, apiKey))
+	})
+}
+
 // ShowWindow 显示窗口
 func (s *appService) ShowWindow() {
 	s.windowManager.ShowWindow()
@@ -210,6 +354,11 @@ func (s *appService) handleChatStream(w http.ResponseWriter, r *http.Request) {
 	// 使用回调函数处理流式响应
 	err := s.chatService.SendMessageStream(r.Context(), sessionID, message, func(response *models.StreamResponse) {
 		switch response.Type {
+		case models.StreamTypeStarted, models.StreamTypeCancelled:
+			// 助手消息 ID（用于停止生成）和停止时已生成的部分，数据为 JSON
+			if data, err := json.Marshal(response.Data); err == nil {
+				s.writeSSEEvent(w, response.Type, string(data))
+			}
 		case models.StreamTypeMessage:
 			// 发送简单的content内容
 			if chatResp, ok := response.Data.(models.ChatResponse); ok {
@@ -221,6 +370,17 @@ func (s *appService) handleChatStream(w http.ResponseWriter, r *http.Request) {
 			if chatResp, ok := response.Data.(models.ChatResponse); ok {
 				log.Printf("📤 发送SSE done事件: content='%s'", chatResp.Content)
 				s.writeSSEEvent(w, "done", chatResp.Content)
+				// 参考的剪切板条目和工具调用记录随完成事件一起发送
+				if chatResp.Metadata != nil {
+					if data, err := json.Marshal(chatResp.Metadata); err == nil {
+						s.writeSSEEvent(w, "metadata", string(data))
+					}
+				}
+			}
+		case models.StreamTypeToolCall, models.StreamTypeToolConfirm, models.StreamTypeToolResult:
+			// 工具调用进度，事件名即类型，数据为 JSON
+			if data, err := json.Marshal(response.Data); err == nil {
+				s.writeSSEEvent(w, response.Type, string(data))
 			}
 		case models.StreamTypeError:
 			log.Printf("📤 发送SSE error事件: error='%s'", response.Error)
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// 备份事件名称
+const (
+	EventBackupCompleted = "backup:completed"
+	EventBackupRestored  = "backup:restored"
+)
+
+const (
+	// backupPrefix 备份文件名前缀，只有符合命名规则的文件才会参与轮换
+	backupPrefix = "sid-backup-"
+	// backupTimeLayout 备份文件名中的时间格式
+	backupTimeLayout = "20060102-150405"
+	// backupRetryInterval 自动备份失败后的重试间隔
+	backupRetryInterval = 10 * time.Minute
+)
+
+// BackupService 定期备份数据库并按天、按周轮换，支持从备份恢复
+type BackupService interface {
+	Start(ctx context.Context)
+	Stop()
+	BackupNow() (models.BackupInfo, error)
+	ListBackups() ([]models.BackupInfo, error)
+	RestoreBackup(path string) error
+	UpdateSettings(settings *models.Settings)
+	SetEventEmitter(emit EventEmitter)
+}
+
+// backupService 备份服务实现
+type backupService struct {
+	db         *repository.Database
+	clipboard  ClipboardService
+	defaultDir string
+
+	mu       sync.Mutex
+	settings *models.Settings
+	emit     EventEmitter
+	cancel   context.CancelFunc
+	done     chan struct{}
+	failedAt time.Time
+
+	runMu  sync.Mutex
+	reload chan struct{}
+}
+
+// NewBackupService 创建新的备份服务，恢复备份时会暂停 clipboard 的监听
+func NewBackupService(db *repository.Database, clipboard ClipboardService, settings *models.Settings) BackupService {
+	return &backupService{
+		db:         db,
+		clipboard:  clipboard,
+		defaultDir: filepath.Join(filepath.Dir(db.Path()), "backups"),
+		settings:   settings,
+		reload:     make(chan struct{}, 1),
+	}
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *backupService) SetEventEmitter(emit EventEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// UpdateSettings 更新备份策略并重新计算下次备份时间
+func (s *backupService) UpdateSettings(settings *models.Settings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动定期备份协程，距上次备份已超过间隔时立即备份
+func (s *backupService) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.scheduler(ctx, s.done)
+}
+
+// Stop 停止定期备份
+func (s *backupService) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// scheduler 等到下次备份时间执行备份，未启用自动备份时只等待设置变化
+func (s *backupService) scheduler(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		var timer *time.Timer
+		var tick <-chan time.Time
+		if wait, ok := s.nextBackupIn(); ok {
+			timer = time.NewTimer(wait)
+			tick = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.reload:
+		case <-tick:
+			if _, err := s.BackupNow(); err != nil {
+				log.Printf("❌ 自动备份失败: %v", err)
+				s.mu.Lock()
+				s.failedAt = time.Now()
+				s.mu.Unlock()
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// nextBackupIn 距下次自动备份的等待时间，未启用时返回 false
+func (s *backupService) nextBackupIn() (time.Duration, bool) {
+	s.mu.Lock()
+	settings := s.settings.Backup
+	failedAt := s.failedAt
+	s.mu.Unlock()
+
+	if !settings.Enabled || settings.IntervalHours <= 0 {
+		return 0, false
+	}
+
+	var next time.Time
+	backups, err := s.ListBackups()
+	if err == nil && len(backups) > 0 {
+		next = backups[0].CreatedAt.Add(time.Duration(settings.IntervalHours) * time.Hour)
+	}
+	if retry := failedAt.Add(backupRetryInterval); retry.After(next) {
+		next = retry
+	}
+	return max(time.Until(next), 0), true
+}
+
+// directory 当前备份目录
+func (s *backupService) directory() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settings.Backup.Directory != "" {
+		return s.settings.Backup.Directory
+	}
+	return s.defaultDir
+}
+
+// BackupNow 立即备份，校验通过后按策略清理旧备份
+func (s *backupService) BackupNow() (models.BackupInfo, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	info, err := s.createBackup()
+	if err != nil {
+		return info, err
+	}
+	if err := s.rotate(); err != nil {
+		log.Printf("⚠️  清理旧备份失败: %v", err)
+	}
+	s.publish(EventBackupCompleted, info)
+	return info, nil
+}
+
+// createBackup 写入新的备份文件并校验完整性，校验失败时删除该文件
+func (s *backupService) createBackup() (models.BackupInfo, error) {
+	dir := s.directory()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return models.BackupInfo{}, err
+	}
+
+	now := time.Now()
+	name := backupPrefix + now.Format(backupTimeLayout) + ".db"
+	for i := 2; fileExists(filepath.Join(dir, name)); i++ {
+		name = fmt.Sprintf("%s%s-%d.db", backupPrefix, now.Format(backupTimeLayout), i)
+	}
+	path := filepath.Join(dir, name)
+
+	if err := s.db.BackupTo(path); err != nil {
+		return models.BackupInfo{}, err
+	}
+	if err := repository.VerifyBackup(path); err != nil {
+		os.Remove(path)
+		return models.BackupInfo{}, fmt.Errorf("备份校验失败: %w", err)
+	}
+
+	info := models.BackupInfo{Path: path, Name: name, CreatedAt: now}
+	if stat, err := os.Stat(path); err == nil {
+		info.Size = stat.Size()
+	}
+	log.Printf("💾 数据库已备份: %s", path)
+	return info, nil
+}
+
+// ListBackups 列出备份目录中的备份，按时间从新到旧排列
+func (s *backupService) ListBackups() ([]models.BackupInfo, error) {
+	dir := s.directory()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []models.BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []models.BackupInfo{}
+	for _, entry := range entries {
+		createdAt, ok := parseBackupName(entry.Name())
+		if entry.IsDir() || !ok {
+			continue
+		}
+		info := models.BackupInfo{Path: filepath.Join(dir, entry.Name()), Name: entry.Name(), CreatedAt: createdAt}
+		if stat, err := entry.Info(); err == nil {
+			info.Size = stat.Size()
+		}
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].Name > backups[j].Name
+		}
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// parseBackupName 从文件名解析备份时间
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, ".db") {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimPrefix(name, backupPrefix)
+	if len(stamp) < len(backupTimeLayout) {
+		return time.Time{}, false
+	}
+	createdAt, err := time.ParseInLocation(backupTimeLayout, stamp[:len(backupTimeLayout)], time.Local)
+	return createdAt, err == nil
+}
+
+// rotate 保留最新的一份、最近 KeepDaily 天每天最新的一份和最近 KeepWeekly 周每周最新的一份，删除其余备份
+func (s *backupService) rotate() error {
+	s.mu.Lock()
+	settings := s.settings.Backup
+	s.mu.Unlock()
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	for i, backup := range backups {
+		day := backup.CreatedAt.Format("2006-01-02")
+		year, week := backup.CreatedAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+
+		keep := i == 0
+		if !days[day] && len(days) < settings.KeepDaily {
+			days[day] = true
+			keep = true
+		}
+		if !weeks[weekKey] && len(weeks) < settings.KeepWeekly {
+			weeks[weekKey] = true
+			keep = true
+		}
+		if keep {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return err
+		}
+		log.Printf("🧹 已删除旧备份: %s", backup.Name)
+	}
+	return nil
+}
+
+// RestoreBackup 校验备份后恢复数据库：暂停剪切板监听，先备份当前数据库，再整体替换为备份内容
+func (s *backupService) RestoreBackup(path string) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if err := repository.VerifyBackup(path); err != nil {
+		return fmt.Errorf("备份文件校验失败: %w", err)
+	}
+
+	if s.clipboard != nil && s.clipboard.IsMonitoring() {
+		s.clipboard.StopMonitoring()
+		defer func() {
+			if err := s.clipboard.StartMonitoring(); err != nil {
+				log.Printf("❌ 恢复后重新启动剪切板监听失败: %v", err)
+			}
+		}()
+	}
+
+	// 恢复前的数据同样保留一份，误操作时可以再恢复回来
+	current, err := s.createBackup()
+	if err != nil {
+		return fmt.Errorf("恢复前备份当前数据库失败: %w", err)
+	}
+	if err := s.db.RestoreFrom(path); err != nil {
+		return fmt.Errorf("%v (恢复前的数据已备份到 %s)", err, current.Path)
+	}
+
+	s.publish(EventBackupRestored, path)
+	return nil
+}
+
+// publish 推送事件
+func (s *backupService) publish(name string, data interface{}) {
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+	if emit != nil {
+		emit(name, data)
+	}
+}
+
+// fileExists 检查文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
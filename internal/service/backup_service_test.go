@@ -0,0 +1,139 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// newTestBackupService 创建备份到临时目录的备份服务
+func newTestBackupService(t *testing.T) (*backupService, repository.ClipboardRepository) {
+	t.Helper()
+	clipboardService, _, db := newTestClipboardService(t)
+
+	settings := models.DefaultSettings()
+	settings.Backup.Directory = filepath.Join(t.TempDir(), "backups")
+	settings.Backup.KeepDaily = 2
+	settings.Backup.KeepWeekly = 2
+	return NewBackupService(db, nil, &settings).(*backupService), clipboardService.repo
+}
+
+func TestBackupService_Rotate(t *testing.T) {
+	service, _ := newTestBackupService(t)
+	dir := service.directory()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{
+		"sid-backup-20261016-100000.db", // 最新，当天和当周
+		"sid-backup-20261016-080000.db", // 同一天较旧的一份
+		"sid-backup-20261015-090000.db", // 第二天
+		"sid-backup-20261014-090000.db", // 天数已满，与最新一份同周
+		"sid-backup-20261007-090000.db", // 上一周最新的一份
+		"sid-backup-20261006-090000.db", // 上一周较旧的一份
+		"sid-backup-20260920-090000.db", // 周数已满
+		"notes.txt",                     // 不符合命名规则，不参与轮换
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := service.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, backup := range backups {
+		kept = append(kept, backup.Name)
+	}
+	want := []string{"sid-backup-20261016-100000.db", "sid-backup-20261015-090000.db", "sid-backup-20261007-090000.db"}
+	if len(kept) != len(want) {
+		t.Fatalf("expected %v to be kept, got %v", want, kept)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Errorf("expected %v to be kept, got %v", want, kept)
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("expected unrelated file to be left alone")
+	}
+}
+
+func TestBackupService_BackupAndRestore(t *testing.T) {
+	service, repo := newTestBackupService(t)
+	var events []string
+	service.SetEventEmitter(func(name string, data interface{}) {
+		events = append(events, name)
+	})
+
+	before := createTestItem(t, repo, "备份前", false)
+	info, err := service.BackupNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size == 0 || filepath.Dir(info.Path) != service.directory() {
+		t.Errorf("unexpected backup info %+v", info)
+	}
+
+	after := createTestItem(t, repo, "备份后", false)
+	if err := service.RestoreBackup(info.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(before); err != nil {
+		t.Errorf("expected item from backup to exist, got %v", err)
+	}
+	if _, err := repo.GetByID(after); err == nil {
+		t.Error("expected item created after backup to be gone")
+	}
+
+	// 恢复前的数据另外备份了一份，同一秒内的备份使用不同的文件名
+	backups, err := service.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Name == backups[1].Name {
+		t.Errorf("expected pre-restore backup to be kept, got %+v", backups)
+	}
+	if len(events) != 2 || events[1] != EventBackupRestored {
+		t.Errorf("unexpected events %v", events)
+	}
+
+	if err := service.RestoreBackup(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("expected missing backup to be rejected")
+	}
+}
+
+func TestBackupService_NextBackupIn(t *testing.T) {
+	service, _ := newTestBackupService(t)
+
+	if wait, ok := service.nextBackupIn(); !ok || wait != 0 {
+		t.Errorf("expected immediate backup without previous backups, got %v %v", wait, ok)
+	}
+
+	if _, err := service.BackupNow(); err != nil {
+		t.Fatal(err)
+	}
+	if wait, ok := service.nextBackupIn(); !ok || wait < 23*time.Hour {
+		t.Errorf("expected next backup after the interval, got %v %v", wait, ok)
+	}
+
+	settings := models.DefaultSettings()
+	settings.Backup.Enabled = false
+	service.UpdateSettings(&settings)
+	if _, ok := service.nextBackupIn(); ok {
+		t.Error("expected no scheduled backup when disabled")
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+)
+
+// SetCaptureRules 设置捕获规则来源，未设置时不执行捕获规则
+func (s *clipboardService) SetCaptureRules(rules RuleService) {
+	s.rules = rules
+}
+
+// ruleEngine 当前生效的规则引擎，可能为 nil
+func (s *clipboardService) ruleEngine() *clipboard.RuleEngine {
+	if s.rules == nil {
+		return nil
+	}
+	return s.rules.Engine()
+}
+
+// buildCapture 按忽略的应用、敏感内容规则和捕获规则构建待保存的条目，捕获规则转换内容后重新做敏感内容检测
+// 返回的 skip 不为空时不应保存，内容为跳过原因
+func (s *clipboardService) buildCapture(content string, source clipboard.Source, engine *clipboard.RuleEngine) (models.ClipboardItem, clipboard.RuleOutcome, string) {
+	var outcome clipboard.RuleOutcome
+	settings, itemBuilder := s.current()
+	if settings.IgnoresApp(source.App) {
+		return models.ClipboardItem{}, outcome, fmt.Sprintf("来自 %s 的内容", source.App)
+	}
+
+	item, sensitive := itemBuilder.BuildItem(content)
+	if sensitive.Skip {
+		return item, outcome, "敏感内容: " + strings.Join(sensitive.Detectors(), ", ")
+	}
+	item.SourceApp, item.SourceTitle = source.App, source.Title
+
+	original := item.Content
+	outcome = engine.Apply(&item, source)
+	if outcome.Ignored {
+		return item, outcome, "命中捕获规则的内容: " + strings.Join(outcome.Matched, ", ")
+	}
+
+	// 转换可能还原出原文中检测不到的敏感内容（如 URL 解码后的卡号），需要重新检测
+	if item.Content != original {
+		if sensitive := itemBuilder.RescanItem(&item); sensitive.Skip {
+			return item, outcome, "敏感内容: " + strings.Join(sensitive.Detectors(), ", ")
+		}
+	}
+	return item, outcome, ""
+}
+
+// applyRuleTags 为新保存的条目添加捕获规则指定的标签，标签不存在时自动创建
+func (s *clipboardService) applyRuleTags(itemID string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	for _, name := range tags {
+		if _, err := s.tagService.ImportTag(models.Tag{Name: name}); err != nil {
+			log.Printf("❌ 创建规则标签失败: %s: %v", name, err)
+			return
+		}
+	}
+	if err := s.tagService.AddTagsToItem(itemID, tags); err != nil {
+		log.Printf("❌ 添加规则标签失败: %v", err)
+		return
+	}
+	log.Printf("🏷️  按捕获规则添加标签: %s", strings.Join(tags, ", "))
+}
+
+// TestCaptureRules 用样例文本试运行捕获规则，不保存任何数据
+// 请求中带有规则时只使用这些规则（可用于保存前预览），否则使用已保存的规则
+func (s *clipboardService) TestCaptureRules(request models.RuleTestRequest) (models.RuleTestResult, error) {
+	engine := s.ruleEngine()
+	if len(request.Rules) > 0 {
+		rules := make([]models.CaptureRule, len(request.Rules))
+		for i, rule := range request.Rules {
+			rule.Enabled = true
+			rules[i] = rule
+		}
+		var err error
+		if engine, err = clipboard.NewRuleEngine(rules); err != nil {
+			return models.RuleTestResult{}, err
+		}
+	}
+
+	item, outcome, skip := s.buildCapture(request.Content, clipboard.Source{App: request.SourceApp}, engine)
+	result := models.RuleTestResult{
+		Matched: outcome.Matched,
+		Ignored: skip != "",
+		Reason:  skip,
+		Tags:    outcome.Tags,
+		Item:    item,
+	}
+	if result.Matched == nil {
+		result.Matched = []string{}
+	}
+	if result.Tags == nil {
+		result.Tags = []string{}
+	}
+	return result, nil
+}
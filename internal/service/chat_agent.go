@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+)
+
+// ErrToolCallNotPending 工具调用不存在或已经确认、超时
+var ErrToolCallNotPending = errors.New("工具调用不存在或已不再等待确认")
+
+// defaultConfirmTimeout 未配置时等待用户确认的时间
+const defaultConfirmTimeout = 2 * time.Minute
+
+// SetTools 设置聊天智能体可调用的工具，为空时聊天不调用工具
+func (s *chatService) SetTools(tools []model.AgentTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = tools
+}
+
+// ConfirmToolCall 回复需要确认的工具调用，confirmationID 为 tool_confirm 事件中的 confirmation_id，approved 为 false 表示拒绝
+func (s *chatService) ConfirmToolCall(confirmationID string, approved bool) error {
+	s.mu.Lock()
+	reply, ok := s.confirmations[confirmationID]
+	delete(s.confirmations, confirmationID)
+	s.mu.Unlock()
+	if !ok {
+		return ErrToolCallNotPending
+	}
+	reply <- approved
+	return nil
+}
+
+// runAgent 按配置运行 ReAct 循环；工具调用状态作为流式事件推送，需要确认的工具推送 tool_confirm 后等待 ConfirmToolCall
+// onContent 不为 nil 时流式生成回答
+func (s *chatService) runAgent(ctx context.Context, chatModel einomodel.ToolCallingChatModel, messages []*schema.Message, messageID string, callback func(*models.StreamResponse), onContent func(string)) (*model.AgentResult, error) {
+	s.mu.Lock()
+	settings, tools := s.settings.Agent, s.tools
+	s.mu.Unlock()
+	if !settings.Enabled {
+		tools = nil
+	}
+	timeout := time.Duration(settings.ConfirmTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+
+	send := func(responseType string, call models.ChatToolCall) {
+		callback(&models.StreamResponse{Type: responseType, MessageID: messageID, Data: call})
+	}
+	options := model.AgentOptions{
+		MaxSteps:  settings.MaxSteps,
+		OnContent: onContent,
+		OnToolCall: func(call models.ChatToolCall) {
+			if call.Status == models.ToolCallStatusPending {
+				send(models.StreamTypeToolCall, call)
+			} else {
+				send(models.StreamTypeToolResult, call)
+			}
+		},
+		Confirm: func(ctx context.Context, call models.ChatToolCall) bool {
+			// 模型返回的调用 ID 在不同会话间可能相同（如本地服务的 call_0），等待确认使用服务端生成的 ID
+			call.ConfirmationID = uuid.New().String()
+			reply := make(chan bool, 1)
+			s.mu.Lock()
+			if s.confirmations == nil {
+				s.confirmations = make(map[string]chan bool)
+			}
+			if _, exists := s.confirmations[call.ConfirmationID]; exists {
+				s.mu.Unlock()
+				log.Printf("❌ 工具调用确认 ID 重复，拒绝执行: %s", call.Name)
+				return false
+			}
+			s.confirmations[call.ConfirmationID] = reply
+			s.mu.Unlock()
+			defer func() {
+				s.mu.Lock()
+				delete(s.confirmations, call.ConfirmationID)
+				s.mu.Unlock()
+			}()
+
+			log.Printf("⏳ 等待用户确认工具调用: %s %s", call.Name, call.Arguments)
+			send(models.StreamTypeToolConfirm, call)
+			select {
+			case approved := <-reply:
+				return approved
+			case <-ctx.Done():
+				return false
+			case <-time.After(timeout):
+				log.Printf("⚠️  工具调用确认超时: %s", call.Name)
+				return false
+			}
+		},
+	}
+	return model.RunAgent(ctx, chatModel, messages, tools, options)
+}
+
+// messageMetadata 助手消息的元数据：参考条目、引用和工具调用记录，都没有时返回 nil
+func messageMetadata(references []models.ClipboardItem, result *model.AgentResult) map[string]interface{} {
+	metadata := referenceMetadata(references, result.Content)
+	if len(result.ToolCalls) > 0 {
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		metadata[models.ChatMetadataToolCalls] = result.ToolCalls
+	}
+	return metadata
+}
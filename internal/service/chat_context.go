@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+)
+
+// summaryKeepRatio 触发压缩后最近消息最多占用的预算比例，留出余量避免每条新消息都重新压缩
+const summaryKeepRatio = 2
+
+// summaryInstruction 请求模型更新滚动摘要的指令
+const summaryInstruction = "请把以上对话（包括已有的摘要）压缩为一段新的摘要，保留用户的目标、已确认的事实、结论和待办事项，不超过 500 字，只输出摘要本身。"
+
+// UpdateSettings 更新大模型相关设置（上下文预算、剪切板检索）
+func (s *chatService) UpdateSettings(settings models.LLMSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = settings
+}
+
+// contextBudget 聊天历史（含摘要和参考资料）的 token 预算，未配置时使用默认值
+func (s *chatService) contextBudget() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settings.ContextTokens <= 0 {
+		return models.DefaultContextTokens
+	}
+	return s.settings.ContextTokens
+}
+
+// buildContext 构建发送给模型的历史消息，不包含本次的用户输入；reserved 为用户输入和参考资料预留的 token 数
+// 摘要之后的消息全部放得下时原样使用；超出预算时保留最近的消息（约占预算一半），
+// 更早的消息和已有摘要一起压缩为新的摘要并保存到会话，压缩失败时只丢弃早期消息
+func (s *chatService) buildContext(ctx context.Context, sessionID string, reserved int) ([]*schema.Message, error) {
+	session, err := s.repo.GetChatSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	history, err := s.repo.GetChatMessagesAfter(sessionID, session.SummaryUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+
+	var messages []models.ChatMessage
+	for _, message := range history {
+		// 跳过未完成的空回复（如生成中断留下的记录）
+		if strings.TrimSpace(message.Content) == "" {
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	budget := s.contextBudget() - reserved
+	summary := session.Summary
+	total := summaryTokens(summary)
+	for _, message := range messages {
+		total += chatMessageTokens(message)
+	}
+	if total <= budget {
+		return withSummary(summary, s.convertToSchemaMessages(messages)), nil
+	}
+
+	// 从最新的消息往前保留，直到用完一半预算
+	keepBudget := budget / summaryKeepRatio
+	used, split := 0, len(messages)
+	for split > 0 {
+		tokens := chatMessageTokens(messages[split-1])
+		if used+tokens > keepBudget {
+			break
+		}
+		used += tokens
+		split--
+	}
+	older, recent := messages[:split], messages[split:]
+	if len(older) == 0 {
+		return withSummary(summary, s.convertToSchemaMessages(recent)), nil
+	}
+
+	updated, err := s.summarize(ctx, summary, older)
+	if err != nil {
+		log.Printf("⚠️  压缩聊天历史失败，丢弃早期消息: %v", err)
+		return withSummary(summary, s.convertToSchemaMessages(recent)), nil
+	}
+	until := older[len(older)-1].CreatedAt
+	if err := s.repo.UpdateChatSessionSummary(sessionID, updated, until); err != nil {
+		log.Printf("⚠️  保存会话摘要失败: %v", err)
+	} else {
+		log.Printf("🧹 已将 %d 条早期消息压缩为摘要: sessionID=%s", len(older), sessionID)
+	}
+	return withSummary(updated, s.convertToSchemaMessages(recent)), nil
+}
+
+// summarize 将已有摘要和早期消息压缩为新的摘要
+func (s *chatService) summarize(ctx context.Context, summary string, messages []models.ChatMessage) (string, error) {
+	chatModel, err := s.chatModels.ChatModel(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	history := withSummary(summary, s.convertToSchemaMessages(messages))
+	prompt, err := model.ChatPromptSummarize(ctx, []*schema.Message{schema.UserMessage(summaryInstruction)}, history)
+	if err != nil {
+		return "", err
+	}
+	response, err := chatModel.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	updated := strings.TrimSpace(response.Content)
+	if updated == "" {
+		return "", fmt.Errorf("模型返回了空摘要")
+	}
+	return updated, nil
+}
+
+// withSummary 摘要不为空时作为系统消息放在历史消息之前
+func withSummary(summary string, messages []*schema.Message) []*schema.Message {
+	if summary == "" {
+		return messages
+	}
+	return append([]*schema.Message{schema.SystemMessage(summaryPrefix + summary)}, messages...)
+}
+
+// summaryPrefix 摘要系统消息的前缀
+const summaryPrefix = "以下是之前对话的摘要：\n"
+
+// summaryTokens 摘要系统消息的 token 数
+func summaryTokens(summary string) int {
+	if summary == "" {
+		return 0
+	}
+	return model.MessageTokens(schema.SystemMessage(summaryPrefix + summary))
+}
+
+// chatMessageTokens 单条历史消息的 token 数
+func chatMessageTokens(message models.ChatMessage) int {
+	return model.MessageTokens(&schema.Message{Role: schema.RoleType(message.Role), Content: message.Content})
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// fakeChatModel 记录每次调用的输入，摘要请求返回编号递增的摘要，
+// 其他请求依次返回 script 中的消息，用完后返回 reply（默认“回复”）
+type fakeChatModel struct {
+	calls     [][]*schema.Message
+	summaries int
+	script    []*schema.Message
+	reply     string
+	err       error
+}
+
+func (f *fakeChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.Message, error) {
+	f.calls = append(f.calls, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, message := range input {
+		if strings.Contains(message.Content, summaryInstruction) {
+			f.summaries++
+			return schema.AssistantMessage(fmt.Sprintf("摘要%d", f.summaries), nil), nil
+		}
+	}
+	if len(f.script) > 0 {
+		next := f.script[0]
+		f.script = f.script[1:]
+		return next, nil
+	}
+	if f.reply != "" {
+		return schema.AssistantMessage(f.reply, nil), nil
+	}
+	return schema.AssistantMessage("回复", nil), nil
+}
+
+// Stream 将 Generate 的结果作为单个片段返回
+func (f *fakeChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	message, err := f.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{message}), nil
+}
+
+func (f *fakeChatModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return f, nil
+}
+
+// fakeProvider 始终返回同一个聊天模型
+type fakeProvider struct {
+	model einomodel.ToolCallingChatModel
+}
+
+func (p *fakeProvider) ChatModel(ctx context.Context) (einomodel.ToolCallingChatModel, error) {
+	return p.model, nil
+}
+
+func (p *fakeProvider) Reload() {}
+
+// newTestChatService 创建使用假模型的聊天服务和一个已有 n 轮对话的会话，每条消息约 14 个 token
+func newTestChatService(t *testing.T, chatModel *fakeChatModel, rounds int) (*chatService, repository.ChatRepository, string) {
+	t.Helper()
+	db := newTestDB(t)
+
+	repo := repository.NewChatRepository(db.DB, db.Cipher())
+	service := NewChatService(repo, &fakeProvider{model: chatModel}).(*chatService)
+	session, err := service.CreateSession(context.Background(), "测试")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < rounds*2; i++ {
+		role := models.MessageRoleUser
+		if i%2 == 1 {
+			role = models.MessageRoleAssistant
+		}
+		message := &models.ChatMessage{
+			ID:          fmt.Sprintf("m%02d", i),
+			SessionID:   session.ID,
+			Role:        role,
+			Content:     fmt.Sprintf("message %02d with some padding text", i),
+			ContentType: models.MessageContentTypeText,
+			IsComplete:  true,
+			CreatedAt:   start.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:   start.Add(time.Duration(i) * time.Minute),
+		}
+		if err := repo.CreateChatMessage(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return service, repo, session.ID
+}
+
+func TestChatService_BuildContextWithinBudget(t *testing.T) {
+	chatModel := &fakeChatModel{}
+	service, _, sessionID := newTestChatService(t, chatModel, 3)
+
+	messages, err := service.buildContext(context.Background(), sessionID, model.EstimateTokens("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 6 || !strings.HasPrefix(messages[0].Content, "message 00") || messages[5].Role != schema.Assistant {
+		t.Fatalf("unexpected context: %v", messages)
+	}
+	if len(chatModel.calls) != 0 {
+		t.Errorf("model called %d times for a context within budget", len(chatModel.calls))
+	}
+}
+
+func TestChatService_BuildContextSummarizesOlderMessages(t *testing.T) {
+	chatModel := &fakeChatModel{}
+	service, repo, sessionID := newTestChatService(t, chatModel, 10)
+	service.UpdateSettings(models.LLMSettings{ContextTokens: 100})
+
+	messages, err := service.buildContext(context.Background(), sessionID, model.EstimateTokens("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages[0].Role != schema.System || messages[0].Content != summaryPrefix+"摘要1" {
+		t.Fatalf("first message = %+v", messages[0])
+	}
+	recent := messages[1:]
+	if len(recent) == 0 || recent[len(recent)-1].Content != "message 19 with some padding text" {
+		t.Fatalf("recent messages = %v", recent)
+	}
+	total := 0
+	for _, message := range messages {
+		total += model.MessageTokens(message)
+	}
+	if total > 100 {
+		t.Errorf("context uses %d tokens, budget 100", total)
+	}
+
+	// 摘要和截止时间已保存，截止时间之后的第一条消息就是保留的第一条
+	session, err := repo.GetChatSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Summary != "摘要1" || session.SummaryUntil == nil {
+		t.Fatalf("summary not persisted: %q %v", session.Summary, session.SummaryUntil)
+	}
+	after, err := repo.GetChatMessagesAfter(sessionID, session.SummaryUntil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(recent) || after[0].Content != recent[0].Content {
+		t.Fatalf("messages after summary = %d, want %d", len(after), len(recent))
+	}
+
+	// 再次构建时复用已保存的摘要，不再调用模型
+	calls := len(chatModel.calls)
+	again, err := service.buildContext(context.Background(), sessionID, model.EstimateTokens("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chatModel.calls) != calls || len(again) != len(messages) || again[0].Content != messages[0].Content {
+		t.Errorf("summary not reused: calls %d -> %d, %d messages", calls, len(chatModel.calls), len(again))
+	}
+}
+
+func TestChatService_BuildContextSummaryFailureTruncates(t *testing.T) {
+	chatModel := &fakeChatModel{err: errors.New("unavailable")}
+	service, repo, sessionID := newTestChatService(t, chatModel, 10)
+	service.UpdateSettings(models.LLMSettings{ContextTokens: 100})
+
+	messages, err := service.buildContext(context.Background(), sessionID, model.EstimateTokens("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) == 0 || len(messages) >= 20 || messages[0].Role == schema.System {
+		t.Fatalf("expected truncated context without summary, got %d messages", len(messages))
+	}
+	if messages[len(messages)-1].Content != "message 19 with some padding text" {
+		t.Errorf("newest message dropped: %v", messages[len(messages)-1])
+	}
+	session, err := repo.GetChatSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Summary != "" || session.SummaryUntil != nil {
+		t.Errorf("summary persisted after failure: %q", session.Summary)
+	}
+}
+
+func TestChatService_SendMessageUsesContext(t *testing.T) {
+	chatModel := &fakeChatModel{}
+	service, _, sessionID := newTestChatService(t, chatModel, 1)
+
+	reply, err := service.SendMessage(context.Background(), sessionID, "new question")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Content != "回复" {
+		t.Fatalf("reply = %q", reply.Content)
+	}
+	// 历史两条加本次输入，本次输入只出现一次
+	input := chatModel.calls[0]
+	if len(input) != 3 || input[2].Content != "new question" || input[1].Content == "new question" {
+		t.Errorf("model input = %v", input)
+	}
+}
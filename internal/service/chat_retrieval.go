@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// ClipboardRetriever 为聊天检索与用户消息相关的剪切板条目
+type ClipboardRetriever interface {
+	Retrieve(ctx context.Context, query string, limit int) ([]models.ClipboardItem, error)
+}
+
+// retrievalCandidates 每个关键词最多取回的候选条目数
+const retrievalCandidates = 20
+
+// keywordRetriever 基于关键词搜索的检索实现：从消息中提取关键词和时间范围，
+// 逐个关键词搜索后按命中关键词的多少排序，同分时较新的条目优先
+type keywordRetriever struct {
+	repo repository.ClipboardRepository
+	now  func() time.Time
+}
+
+// NewClipboardRetriever 创建基于关键词搜索的剪切板检索器
+func NewClipboardRetriever(repo repository.ClipboardRepository) ClipboardRetriever {
+	return &keywordRetriever{repo: repo, now: time.Now}
+}
+
+// Retrieve 检索相关条目，敏感条目和图片不会返回；消息中既没有关键词也没有时间范围时不检索
+func (r *keywordRetriever) Retrieve(ctx context.Context, query string, limit int) ([]models.ClipboardItem, error) {
+	keywords := retrievalKeywords(query)
+	after, before := retrievalTimeRange(query, r.now())
+	if len(keywords) == 0 && after == nil {
+		return nil, nil
+	}
+
+	search := models.SearchQuery{Limit: retrievalCandidates, CreatedAfter: after, CreatedBefore: before}
+	scores := make(map[string]int)
+	items := make(map[string]models.ClipboardItem)
+	collect := func(keyword string, weight int) error {
+		search.Query = keyword
+		result, err := r.repo.Search(search)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			if item.IsSensitive || item.IsImage() {
+				continue
+			}
+			scores[item.ID] += weight
+			items[item.ID] = item
+		}
+		return nil
+	}
+
+	if len(keywords) == 0 {
+		// 只有时间范围时取该范围内最新的条目
+		if err := collect("", 1); err != nil {
+			return nil, err
+		}
+	}
+	searchKeywords := func() error {
+		for _, keyword := range keywords {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// 较长的关键词更具体，权重更高
+			if err := collect(keyword, min(len([]rune(keyword)), 8)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := searchKeywords(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 && len(keywords) > 0 && after != nil {
+		// 用户记错时间的情况很常见，时间范围内没有命中时放宽到全部历史
+		search.CreatedAfter, search.CreatedBefore = nil, nil
+		if err := searchKeywords(); err != nil {
+			return nil, err
+		}
+	}
+
+	ranked := make([]models.ClipboardItem, 0, len(items))
+	for _, item := range items {
+		ranked = append(ranked, item)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i].ID] != scores[ranked[j].ID] {
+			return scores[ranked[i].ID] > scores[ranked[j].ID]
+		}
+		return ranked[i].CreatedAt.After(ranked[j].CreatedAt)
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// retrievalStopWords 提取关键词时忽略的英文词
+var retrievalStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true, "in": true,
+	"on": true, "at": true, "for": true, "from": true, "with": true, "about": true, "is": true,
+	"was": true, "were": true, "are": true, "be": true, "it": true, "that": true, "this": true,
+	"what": true, "which": true, "where": true, "when": true, "who": true, "how": true, "why": true,
+	"did": true, "do": true, "does": true, "can": true, "could": true, "you": true, "me": true,
+	"my": true, "i": true, "we": true, "our": true, "some": true, "any": true, "one": true,
+	"copied": true, "copy": true, "pasted": true, "paste": true, "clipboard": true, "find": true,
+	"show": true, "remember": true, "again": true, "please": true, "thing": true, "stuff": true,
+	"last": true, "week": true, "month": true, "today": true, "yesterday": true, "ago": true,
+	"recently": true, "recent": true, "earlier": true, "before": true,
+}
+
+// retrievalStopPhrases 中文片段中需要剔除的虚词和与检索无关的短语，长的在前
+var retrievalStopPhrases = []string{
+	"上个星期", "上星期", "上个月", "这个月", "这星期", "这个星期", "前几天", "剪切板", "剪贴板",
+	"是什么", "是多少", "在哪里", "有没有", "帮我找", "帮我", "一下", "那个", "这个", "那些", "这些",
+	"之前", "以前", "刚才", "最近", "上周", "本周", "这周", "上月", "本月", "今天", "昨天", "前天",
+	"复制", "拷贝", "粘贴", "什么", "哪个", "哪些", "我们", "你们", "还记得", "记得", "找到", "给我",
+	"我", "你", "的", "了", "吗", "呢", "吧", "啊", "过", "是", "在", "和", "把", "被", "个", "找",
+}
+
+// retrievalKeywords 从用户消息中提取搜索关键词：英文和数字按词切分并去掉停用词，
+// 中文按剔除虚词后剩下的片段切分，去重后保持出现顺序
+func retrievalKeywords(message string) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	add := func(keyword string) {
+		if keyword == "" || seen[keyword] {
+			return
+		}
+		seen[keyword] = true
+		keywords = append(keywords, keyword)
+	}
+
+	for _, segment := range splitScripts(message) {
+		if isHanSegment(segment) {
+			for _, phrase := range retrievalStopPhrases {
+				segment = strings.ReplaceAll(segment, phrase, " ")
+			}
+			for _, fragment := range strings.Fields(segment) {
+				if len([]rune(fragment)) >= 2 {
+					add(fragment)
+				}
+			}
+			continue
+		}
+		word := strings.ToLower(strings.Trim(segment, ".:/-_@"))
+		if len(word) >= 2 && !retrievalStopWords[word] {
+			add(word)
+		}
+	}
+	return keywords
+}
+
+// splitScripts 将消息切分为中文片段和英文单词（可包含 . : / - _ @ 等连接符，便于匹配域名、地址等）
+func splitScripts(message string) []string {
+	var segments []string
+	var current []rune
+	currentHan := false
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, string(current))
+			current = current[:0]
+		}
+	}
+	for _, r := range message {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if !currentHan {
+				flush()
+			}
+			currentHan = true
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".:/-_@", r):
+			if currentHan {
+				flush()
+			}
+			currentHan = false
+			current = append(current, r)
+		default:
+			flush()
+			currentHan = false
+		}
+	}
+	flush()
+	return segments
+}
+
+// isHanSegment 判断片段是否为中文
+func isHanSegment(segment string) bool {
+	for _, r := range segment {
+		return unicode.Is(unicode.Han, r)
+	}
+	return false
+}
+
+// retrievalTimeRange 识别消息中的相对时间（今天、昨天、上周、本月等），返回对应的创建时间范围
+func retrievalTimeRange(message string, now time.Time) (*time.Time, *time.Time) {
+	lower := strings.ToLower(message)
+	has := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(lower, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // 周一为一周的第一天
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	span := func(start, end time.Time) (*time.Time, *time.Time) {
+		return &start, &end
+	}
+
+	switch {
+	case has("前天", "day before yesterday"):
+		return span(today.AddDate(0, 0, -2), today.AddDate(0, 0, -1))
+	case has("昨天", "yesterday"):
+		return span(today.AddDate(0, 0, -1), today)
+	case has("今天", "today"):
+		return span(today, today.AddDate(0, 0, 1))
+	case has("上周", "上星期", "上个星期", "last week"):
+		return span(weekStart.AddDate(0, 0, -7), weekStart)
+	case has("本周", "这周", "这星期", "这个星期", "this week"):
+		return span(weekStart, today.AddDate(0, 0, 1))
+	case has("上个月", "上月", "last month"):
+		return span(monthStart.AddDate(0, -1, 0), monthStart)
+	case has("本月", "这个月", "this month"):
+		return span(monthStart, today.AddDate(0, 0, 1))
+	case has("前几天", "最近", "recently", "the other day"):
+		return span(today.AddDate(0, 0, -7), today.AddDate(0, 0, 1))
+	}
+	return nil, nil
+}
+
+// SetClipboardRetriever 设置剪切板检索器，为 nil 时聊天不检索剪切板历史
+func (s *chatService) SetClipboardRetriever(retriever ClipboardRetriever) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retriever = retriever
+}
+
+// retrieveReferences 按配置检索与消息相关的剪切板条目作为参考资料，失败时只记录日志；
+// 条目内容按 MaxChars 截断，参考资料最多占用一半的上下文预算
+func (s *chatService) retrieveReferences(ctx context.Context, message string) []models.ClipboardItem {
+	s.mu.Lock()
+	retriever, settings := s.retriever, s.settings.Retrieval
+	s.mu.Unlock()
+	if retriever == nil || !settings.Enabled || settings.MaxItems <= 0 {
+		return nil
+	}
+
+	items, err := retriever.Retrieve(ctx, message, settings.MaxItems)
+	if err != nil {
+		log.Printf("⚠️  检索剪切板历史失败: %v", err)
+		return nil
+	}
+	for i := range items {
+		items[i].Content = truncateRunes(items[i].Content, settings.MaxChars)
+	}
+	for len(items) > 0 && model.MessageTokens(model.ReferencesMessage(items)) > s.contextBudget()/2 {
+		items = items[:len(items)-1]
+	}
+	if len(items) > 0 {
+		log.Printf("📎 检索到 %d 个相关剪切板条目", len(items))
+	}
+	return items
+}
+
+// truncateRunes 按字符数截断内容，limit 小于等于 0 时不截断
+func truncateRunes(content string, limit int) string {
+	runes := []rune(content)
+	if limit <= 0 || len(runes) <= limit {
+		return content
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// promptTokens 本次用户输入和参考资料占用的 token 数
+func promptTokens(message string, references []models.ClipboardItem) int {
+	tokens := model.EstimateTokens(message)
+	if len(references) > 0 {
+		tokens += model.MessageTokens(model.ReferencesMessage(references))
+	}
+	return tokens
+}
+
+// citationPattern 匹配回答中的引用标注，如 [1]、[1, 3]、[2，4]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*[,，、]\s*\d+)*)\]`)
+
+// referenceMetadata 生成助手消息的检索元数据：全部参考条目及回答中实际引用的条目 ID，没有参考条目时返回 nil
+func referenceMetadata(references []models.ClipboardItem, answer string) map[string]interface{} {
+	if len(references) == 0 {
+		return nil
+	}
+
+	refs := make([]models.ChatReference, len(references))
+	for i, item := range references {
+		refs[i] = models.ChatReference{Index: i + 1, ItemID: item.ID, Title: item.Title}
+	}
+
+	cited := []string{}
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, field := range strings.FieldsFunc(match[1], func(r rune) bool { return !unicode.IsDigit(r) }) {
+			index, err := strconv.Atoi(field)
+			if err != nil || index < 1 || index > len(references) || seen[index] {
+				continue
+			}
+			seen[index] = true
+			cited = append(cited, references[index-1].ID)
+		}
+	}
+
+	return map[string]interface{}{
+		models.ChatMetadataReferences:   refs,
+		models.ChatMetadataCitedItemIDs: cited,
+	}
+}
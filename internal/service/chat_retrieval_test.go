@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+func TestRetrievalKeywords(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"what was that Redis connection string I copied last week?", []string{"redis", "connection", "string"}},
+		{"上周我复制的那个Redis连接字符串是什么", []string{"redis", "连接字符串"}},
+		{"帮我找一下 api.example.com 的地址", []string{"api.example.com", "地址"}},
+		{"我昨天复制了什么？", nil},
+		{"Redis redis REDIS", []string{"redis"}},
+	}
+	for _, tt := range tests {
+		if got := retrievalKeywords(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("retrievalKeywords(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestRetrievalTimeRange(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.Local) // 周五
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		message       string
+		after, before time.Time
+	}{
+		{"上周复制的链接", day(10, 5), day(10, 12)},
+		{"what did I copy yesterday", day(10, 15), day(10, 16)},
+		{"前天的会议纪要", day(10, 14), day(10, 15)},
+		{"本周的命令", day(10, 12), day(10, 17)},
+		{"last month invoices", day(9, 1), day(10, 1)},
+	}
+	for _, tt := range tests {
+		after, before := retrievalTimeRange(tt.message, now)
+		if after == nil || before == nil || !after.Equal(tt.after) || !before.Equal(tt.before) {
+			t.Errorf("%q: got %v - %v, want %v - %v", tt.message, after, before, tt.after, tt.before)
+		}
+	}
+	if after, before := retrievalTimeRange("redis 连接字符串", now); after != nil || before != nil {
+		t.Errorf("unexpected range %v - %v", after, before)
+	}
+}
+
+// newTestRetriever 创建检索器和包含给定条目的临时数据库，now 固定为 2026-10-16
+func newTestRetriever(t *testing.T, items ...models.ClipboardItem) *keywordRetriever {
+	t.Helper()
+	db := newTestDB(t)
+
+	repo := repository.NewClipboardRepository(db.DB, db.Cipher())
+	for _, item := range items {
+		item.Title = item.Content
+		item.ContentType = models.ContentTypeText
+		item.Category = models.CategoryText
+		item.UpdatedAt, item.LastUsedAt = item.CreatedAt, item.CreatedAt
+		if err := repo.Create(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	return &keywordRetriever{repo: repo, now: func() time.Time { return now }}
+}
+
+func TestKeywordRetriever_Retrieve(t *testing.T) {
+	lastWeek := time.Date(2026, 10, 8, 10, 0, 0, 0, time.Local)
+	retriever := newTestRetriever(t,
+		models.ClipboardItem{ID: "redis", Content: "redis://:secret@cache.internal:6379/0 连接字符串", CreatedAt: lastWeek},
+		models.ClipboardItem{ID: "redis-old", Content: "redis://localhost:6379", CreatedAt: lastWeek.AddDate(0, -2, 0)},
+		models.ClipboardItem{ID: "redis-sensitive", Content: "redis password hunter2", CreatedAt: lastWeek, IsSensitive: true},
+		models.ClipboardItem{ID: "pg", Content: "postgres://admin@db.internal/app 连接字符串", CreatedAt: lastWeek},
+		models.ClipboardItem{ID: "today", Content: "今天的购物清单", CreatedAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)},
+	)
+	ids := func(items []models.ClipboardItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	// 时间范围排除了两个月前的条目，同时命中两个关键词的排在前面，敏感条目不返回
+	items, err := retriever.Retrieve(context.Background(), "上周我复制的那个Redis连接字符串是什么", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(items); !reflect.DeepEqual(got, []string{"redis", "pg"}) {
+		t.Errorf("last week redis: got %v", got)
+	}
+
+	// 时间范围内没有命中时放宽到全部历史
+	items, err = retriever.Retrieve(context.Background(), "what was the redis url I copied yesterday", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(items); len(got) != 2 || got[0] != "redis" || got[1] != "redis-old" {
+		t.Errorf("fallback without time range: got %v", got)
+	}
+
+	// 只有时间范围时返回该范围内的条目
+	items, err = retriever.Retrieve(context.Background(), "我今天复制了什么", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(items); !reflect.DeepEqual(got, []string{"today"}) {
+		t.Errorf("today: got %v", got)
+	}
+
+	// 没有关键词和时间范围时不检索
+	if items, err := retriever.Retrieve(context.Background(), "你好", 5); err != nil || len(items) != 0 {
+		t.Errorf("greeting retrieved %v, %v", ids(items), err)
+	}
+}
+
+func TestChatService_SendMessageWithReferences(t *testing.T) {
+	chatModel := &fakeChatModel{reply: "连接字符串是 redis://:secret@cache.internal:6379/0 [1]。"}
+	service, _, sessionID := newTestChatService(t, chatModel, 0)
+	retriever := newTestRetriever(t,
+		models.ClipboardItem{ID: "redis", Content: "redis://:secret@cache.internal:6379/0", CreatedAt: time.Date(2026, 10, 8, 10, 0, 0, 0, time.Local)},
+		models.ClipboardItem{ID: "redis-old", Content: "redis://localhost:6379", CreatedAt: time.Date(2026, 8, 8, 10, 0, 0, 0, time.Local)},
+	)
+	service.SetClipboardRetriever(retriever)
+	service.UpdateSettings(models.DefaultLLMSettings())
+
+	reply, err := service.SendMessage(context.Background(), sessionID, "what was that redis connection string?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 参考资料作为第一条系统消息发送给模型
+	input := chatModel.calls[0]
+	if len(input) != 2 || !strings.Contains(input[0].Content, "[1] redis://:secret@cache.internal:6379/0") || !strings.Contains(input[0].Content, "[2] redis://localhost:6379") {
+		t.Fatalf("model input = %v", input)
+	}
+
+	refs, ok := reply.Metadata[models.ChatMetadataReferences].([]models.ChatReference)
+	if !ok || len(refs) != 2 || refs[0].ItemID != "redis" || refs[1].Index != 2 {
+		t.Errorf("references = %#v", reply.Metadata[models.ChatMetadataReferences])
+	}
+	if cited := reply.Metadata[models.ChatMetadataCitedItemIDs]; !reflect.DeepEqual(cited, []string{"redis"}) {
+		t.Errorf("cited = %#v", cited)
+	}
+
+	// 关闭检索后不再发送参考资料
+	settings := models.DefaultLLMSettings()
+	settings.Retrieval.Enabled = false
+	service.UpdateSettings(settings)
+	reply, err = service.SendMessage(context.Background(), sessionID, "redis again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Metadata != nil || chatModel.calls[1][0].Role == "system" {
+		t.Errorf("retrieval not disabled: %v", reply.Metadata)
+	}
+}
+
+func TestReferenceMetadata_Citations(t *testing.T) {
+	references := []models.ClipboardItem{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	metadata := referenceMetadata(references, "见 [3] 和 [1，3]，[7] 不存在")
+	if cited := metadata[models.ChatMetadataCitedItemIDs]; !reflect.DeepEqual(cited, []string{"c", "a"}) {
+		t.Errorf("cited = %#v", cited)
+	}
+	if referenceMetadata(nil, "[1]") != nil {
+		t.Error("expected nil metadata without references")
+	}
+}
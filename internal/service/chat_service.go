@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
@@ -30,20 +33,73 @@ type ChatService interface {
 	SendMessageStream(ctx context.Context, sessionID, message string, callback func(*models.StreamResponse)) error
 	GetMessages(ctx context.Context, sessionID string, limit, offset int) (*models.ChatMessageListResponse, error)
 
+	// 停止、重新生成和编辑
+	CancelStream(messageID string) error
+	RegenerateMessage(ctx context.Context, messageID string, callback func(*models.StreamResponse)) error
+	EditUserMessage(ctx context.Context, messageID, content string, callback func(*models.StreamResponse)) error
+	RepairInterruptedMessages() (int, error)
+
 	// 实用功能
 	GenerateTitle(ctx context.Context, message string) (string, error)
 	GenerateTags(ctx context.Context, message string) ([]string, error)
+
+	// 导入导出
+	ExportRecords() ([]models.ExportRecord, error)
+	ImportChatSession(session models.ExportChatSession, conflict string) (string, error)
+
+	// 事件推送
+	SetEventEmitter(emit EventEmitter)
+
+	// 上下文与检索
+	UpdateSettings(settings models.LLMSettings)
+	SetClipboardRetriever(retriever ClipboardRetriever)
+
+	// 工具调用
+	SetTools(tools []model.AgentTool)
+	ConfirmToolCall(confirmationID string, approved bool) error
 }
 
 // chatService 聊天服务实现
 type chatService struct {
-	repo repository.ChatRepository
+	repo       repository.ChatRepository
+	chatModels model.Provider
+
+	mu            sync.Mutex
+	emit          EventEmitter
+	settings      models.LLMSettings
+	retriever     ClipboardRetriever
+	tools         []model.AgentTool
+	confirmations map[string]chan bool    // 等待用户确认的工具调用
+	streams       map[string]activeStream // 正在生成的回复，按助手消息 ID 索引
 }
 
 // NewChatService 创建新的聊天服务
-func NewChatService(repo repository.ChatRepository) ChatService {
+func NewChatService(repo repository.ChatRepository, chatModels model.Provider) ChatService {
 	return &chatService{
-		repo: repo,
+		repo:       repo,
+		chatModels: chatModels,
+	}
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *chatService) SetEventEmitter(emit EventEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// streamCallback 包装流式回调，每个片段同时作为 chat:stream 事件推送
+func (s *chatService) streamCallback(sessionID string, callback func(*models.StreamResponse)) func(*models.StreamResponse) {
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+	return func(response *models.StreamResponse) {
+		if callback != nil {
+			callback(response)
+		}
+		if emit != nil {
+			emit(EventChatStream, models.ChatStreamEvent{SessionID: sessionID, StreamResponse: *response})
+		}
 	}
 }
 
@@ -110,6 +166,15 @@ func (s *chatService) UpdateSession(ctx context.Context, sessionID string, title
 func (s *chatService) SendMessage(ctx context.Context, sessionID, message string) (*models.ChatMessage, error) {
 	log.Printf("🔄 开始处理消息: sessionID=%s, message=%s", sessionID, message)
 
+	// 检索相关的剪切板条目，并在剩余预算内构建历史上下文（必要时压缩为摘要）
+	references := s.retrieveReferences(ctx, message)
+	history, err := s.buildContext(ctx, sessionID, promptTokens(message, references))
+	if err != nil {
+		log.Printf("❌ 获取历史消息失败: %v", err)
+		return nil, err
+	}
+	log.Printf("✅ 上下文构建完成，共%d条消息", len(history))
+
 	// 保存用户消息
 	userMessage := &models.ChatMessage{
 		ID:          uuid.New().String(),
@@ -129,42 +194,39 @@ func (s *chatService) SendMessage(ctx context.Context, sessionID, message string
 	}
 	log.Printf("✅ 用户消息已保存: %s", userMessage.ID)
 
-	// 获取历史消息
-	historyResp, err := s.GetMessages(ctx, sessionID, 20, 0)
+	messages, err := model.ChatPromptBase(ctx, message, history, references)
 	if err != nil {
-		log.Printf("❌ 获取历史消息失败: %v", err)
-		return nil, fmt.Errorf("failed to get message history: %w", err)
+		log.Printf("❌ 生成提示词失败: %v", err)
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
 	}
-	log.Printf("✅ 获取历史消息成功，共%d条消息", len(historyResp.Messages))
-
-	// 转换为聊天模型所需的格式
-	messages := s.convertToSchemaMessages(historyResp.Messages)
-	log.Printf("✅ 消息格式转换完成，共%d条消息", len(messages))
 
 	// 调用聊天模型
-	log.Printf("🤖 正在创建聊天模型...")
-	chatModel, err := model.NewChatModel(ctx)
+	log.Printf("🤖 正在获取聊天模型...")
+	chatModel, err := s.chatModels.ChatModel(ctx)
 	if err != nil {
 		log.Printf("❌ 创建聊天模型失败: %v", err)
 		return nil, fmt.Errorf("failed to create chat model: %w", err)
 	}
-	log.Printf("✅ 聊天模型创建成功")
+	log.Printf("✅ 聊天模型已就绪")
 
+	// 工具调用事件通过事件总线推送，需要确认的工具在前端确认后继续
 	log.Printf("🤖 正在生成回复...")
-	response, err := chatModel.Generate(ctx, messages)
+	aiMessageID := uuid.New().String()
+	response, err := s.runAgent(ctx, chatModel, messages, aiMessageID, s.streamCallback(sessionID, nil), nil)
 	if err != nil {
 		log.Printf("❌ 生成回复失败: %v", err)
 		return nil, fmt.Errorf("failed to generate response: %w", err)
 	}
-	log.Printf("✅ 回复生成成功，长度: %d", len(response.Content))
+	log.Printf("✅ 回复生成成功，长度: %d，工具调用%d次", len(response.Content), len(response.ToolCalls))
 
 	// 保存AI响应
 	aiMessage := &models.ChatMessage{
-		ID:          uuid.New().String(),
+		ID:          aiMessageID,
 		SessionID:   sessionID,
 		Role:        models.MessageRoleAssistant,
 		Content:     response.Content,
 		ContentType: models.MessageContentTypeText,
+		Metadata:    messageMetadata(references, response),
 		IsStreaming: false,
 		IsComplete:  true,
 		CreatedAt:   time.Now(),
@@ -189,22 +251,20 @@ func (s *chatService) SendMessage(ctx context.Context, sessionID, message string
 // SendMessageStream 发送消息（流式）
 func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message string, callback func(*models.StreamResponse)) error {
 	log.Printf("🔄 开始流式处理消息: sessionID=%s, message=%s", sessionID, message)
+	callback = s.streamCallback(sessionID, callback)
 
-	// 获取历史消息
-	historyResp, err := s.GetMessages(ctx, sessionID, 20, 0)
+	// 检索相关的剪切板条目，并在剩余预算内构建历史上下文（必要时压缩为摘要）
+	references := s.retrieveReferences(ctx, message)
+	messages, err := s.buildContext(ctx, sessionID, promptTokens(message, references))
 	if err != nil {
 		log.Printf("❌ 获取历史消息失败: %v", err)
 		callback(&models.StreamResponse{
 			Type:  models.StreamTypeError,
-			Error: fmt.Sprintf("failed to get message history: %v", err),
+			Error: err.Error(),
 		})
 		return err
 	}
-	log.Printf("✅ 获取历史消息成功，共%d条消息", len(historyResp.Messages))
-
-	// 转换为聊天模型所需的格式
-	messages := s.convertToSchemaMessages(historyResp.Messages)
-	log.Printf("✅ 消息格式转换完成，共%d条消息", len(messages))
+	log.Printf("✅ 上下文构建完成，共%d条消息", len(messages))
 
 	// 保存用户消息
 	userMessage := &models.ChatMessage{
@@ -229,7 +289,7 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 	}
 	log.Printf("✅ 用户消息已保存: %s", userMessage.ID)
 
-	msg, err := model.ChatPromptBase(ctx, message, messages)
+	msg, err := model.ChatPromptBase(ctx, message, messages, references)
 	if err != nil {
 		log.Printf("❌ 生成提示词失败: %v", err)
 		callback(&models.StreamResponse{
@@ -240,8 +300,8 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 	}
 
 	// 创建聊天模型
-	log.Printf("🤖 正在创建聊天模型...")
-	chatModel, err := model.NewChatModel(ctx)
+	log.Printf("🤖 正在获取聊天模型...")
+	chatModel, err := s.chatModels.ChatModel(ctx)
 	if err != nil {
 		log.Printf("❌ 创建聊天模型失败: %v", err)
 		callback(&models.StreamResponse{
@@ -250,7 +310,7 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 		})
 		return err
 	}
-	log.Printf("✅ 聊天模型创建成功")
+	log.Printf("✅ 聊天模型已就绪")
 
 	// 创建AI消息记录
 	aiMessageID := uuid.New().String()
@@ -276,45 +336,29 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 	}
 	log.Printf("✅ AI消息记录已创建: %s", aiMessageID)
 
-	// 开始流式生成
-	log.Printf("🤖 开始流式生成...")
-	stream, err := chatModel.Stream(ctx, msg)
-	if err != nil {
-		log.Printf("❌ 开始流式生成失败: %v", err)
-		callback(&models.StreamResponse{
-			Type:  models.StreamTypeError,
-			Error: fmt.Sprintf("failed to start stream: %v", err),
-		})
-		return err
-	}
-	log.Printf("✅ 流式生成已开始")
-
-	defer stream.Close()
-
-	var fullContent string
-	messageCount := 0
-	for {
-		msg, err := stream.Recv()
-		if err == io.EOF {
-			log.Printf("✅ 流式生成完成，共处理%d条消息", messageCount)
-			break
-		}
-		if err != nil {
-			log.Printf("❌ 流式生成错误: %v", err)
-			callback(&models.StreamResponse{
-				Type:      models.StreamTypeError,
-				Error:     fmt.Sprintf("stream error: %v", err),
-				MessageID: aiMessageID,
-			})
-			return err
-		}
-
-		messageCount++
-		// 累积内容
-		fullContent += msg.Content
+	// 生成过程可以通过 CancelStream 停止
+	ctx, done := s.beginStream(ctx, sessionID, aiMessageID)
+	defer done()
+	callback(&models.StreamResponse{
+		Type:      models.StreamTypeStarted,
+		MessageID: aiMessageID,
+		Data: models.ChatResponse{
+			SessionID: sessionID,
+			MessageID: aiMessageID,
+			Role:      models.MessageRoleAssistant,
+			IsStream:  true,
+		},
+	})
 
-		if messageCount%10 == 0 {
-			log.Printf("🔄 已处理%d条消息，当前内容长度: %d", messageCount, len(fullContent))
+	// 开始流式生成，模型请求调用工具时执行工具后继续生成
+	log.Printf("🤖 开始流式生成...")
+	chunkCount := 0
+	var partial strings.Builder
+	response, err := s.runAgent(ctx, chatModel, msg, aiMessageID, callback, func(chunk string) {
+		partial.WriteString(chunk)
+		chunkCount++
+		if chunkCount%10 == 0 {
+			log.Printf("🔄 已处理%d条消息", chunkCount)
 		}
 
 		// 发送流式响应
@@ -324,18 +368,26 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 			Data: models.ChatResponse{
 				SessionID:  sessionID,
 				MessageID:  aiMessageID,
-				Content:    msg.Content,
+				Content:    chunk,
 				Role:       models.MessageRoleAssistant,
 				IsStream:   true,
 				IsComplete: false,
 			},
 		})
+	})
+	if err != nil && ctx.Err() != nil {
+		return s.interruptStream(ctx, aiMessage, partial.String(), references, callback)
+	}
+	if err != nil {
+		return s.failStream(aiMessage, partial.String(), references, err, callback)
 	}
+	fullContent := response.Content
 
 	log.Printf("✅ 流式内容生成完成，总长度: %d", len(fullContent))
 
 	// 更新完成的消息
 	aiMessage.Content = fullContent
+	aiMessage.Metadata = messageMetadata(references, response)
 	aiMessage.IsStreaming = false
 	aiMessage.IsComplete = true
 	aiMessage.UpdatedAt = time.Now()
@@ -357,6 +409,7 @@ func (s *chatService) SendMessageStream(ctx context.Context, sessionID, message
 			Role:       models.MessageRoleAssistant,
 			IsStream:   false,
 			IsComplete: true,
+			Metadata:   aiMessage.Metadata,
 		},
 	})
 
@@ -390,7 +443,7 @@ func (s *chatService) GetMessages(ctx context.Context, sessionID string, limit,
 
 // GenerateTitle 生成会话标题
 func (s *chatService) GenerateTitle(ctx context.Context, message string) (string, error) {
-	chatModel, err := model.NewChatModel(ctx)
+	chatModel, err := s.chatModels.ChatModel(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to create chat model: %w", err)
 	}
@@ -410,7 +463,7 @@ func (s *chatService) GenerateTitle(ctx context.Context, message string) (string
 
 // GenerateTags 生成标签
 func (s *chatService) GenerateTags(ctx context.Context, message string) ([]string, error) {
-	chatModel, err := model.NewChatModel(ctx)
+	chatModel, err := s.chatModels.ChatModel(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chat model: %w", err)
 	}
@@ -456,3 +509,116 @@ func (s *chatService) convertToSchemaMessages(messages []models.ChatMessage) []*
 func (s *chatService) updateSessionAfterMessage(sessionID, lastMessage string) error {
 	return s.repo.UpdateChatSessionAfterMessage(sessionID, lastMessage)
 }
+
+// ExportRecords 导出全部会话及其消息
+func (s *chatService) ExportRecords() ([]models.ExportRecord, error) {
+	sessions, err := s.repo.ListChatSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+
+	records := make([]models.ExportRecord, 0, len(sessions))
+	for _, session := range sessions {
+		messages, err := s.allMessages(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, models.ExportRecord{
+			Type:        models.ExportRecordChatSession,
+			ChatSession: &models.ExportChatSession{Session: session, Messages: messages},
+		})
+	}
+	return records, nil
+}
+
+// ImportChatSession 导入会话，按会话ID判断冲突
+// merge 时只补充本地缺少的消息，overwrite 时删除本地会话后重新创建
+func (s *chatService) ImportChatSession(data models.ExportChatSession, conflict string) (string, error) {
+	if data.Session.ID == "" {
+		return "", fmt.Errorf("会话ID不能为空")
+	}
+
+	existing, err := s.repo.GetChatSession(data.Session.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if existing != nil {
+		switch conflict {
+		case models.ImportConflictOverwrite:
+			if err := s.repo.DeleteChatSession(existing.ID); err != nil {
+				return "", err
+			}
+		case models.ImportConflictMerge:
+			return s.mergeChatMessages(existing.ID, data.Messages)
+		default:
+			return models.ImportOutcomeSkipped, nil
+		}
+	}
+
+	session := data.Session
+	session.MessageCount = len(data.Messages)
+	if err := s.repo.CreateChatSession(&session); err != nil {
+		return "", fmt.Errorf("failed to create chat session: %w", err)
+	}
+	for i := range data.Messages {
+		message := data.Messages[i]
+		message.SessionID = session.ID
+		if err := s.repo.CreateChatMessage(&message); err != nil {
+			return "", fmt.Errorf("failed to create chat message: %w", err)
+		}
+	}
+
+	if existing != nil {
+		return models.ImportOutcomeUpdated, nil
+	}
+	return models.ImportOutcomeCreated, nil
+}
+
+// mergeChatMessages 将本地缺少的消息追加到已有会话
+func (s *chatService) mergeChatMessages(sessionID string, messages []models.ChatMessage) (string, error) {
+	local, err := s.allMessages(sessionID)
+	if err != nil {
+		return "", err
+	}
+	known := make(map[string]bool, len(local))
+	for _, message := range local {
+		known[message.ID] = true
+	}
+
+	added := 0
+	for i := range messages {
+		message := messages[i]
+		if known[message.ID] {
+			continue
+		}
+		message.SessionID = sessionID
+		if err := s.repo.CreateChatMessage(&message); err != nil {
+			return "", fmt.Errorf("failed to create chat message: %w", err)
+		}
+		if err := s.repo.UpdateChatSessionAfterMessage(sessionID, message.Content); err != nil {
+			return "", err
+		}
+		added++
+	}
+
+	if added == 0 {
+		return models.ImportOutcomeSkipped, nil
+	}
+	return models.ImportOutcomeUpdated, nil
+}
+
+// allMessages 获取会话的全部消息
+func (s *chatService) allMessages(sessionID string) ([]models.ChatMessage, error) {
+	count, err := s.repo.GetChatMessageCount(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.GetChatMessages(sessionID, count, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat messages: %w", err)
+	}
+	if messages == nil {
+		messages = []models.ChatMessage{}
+	}
+	return messages, nil
+}
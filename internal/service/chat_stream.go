@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"Sid/internal/models"
+)
+
+var (
+	// ErrStreamNotFound 消息不在生成中（已完成、已停止或不存在）
+	ErrStreamNotFound = errors.New("没有正在生成的回复")
+	// ErrSessionStreaming 会话中有正在生成的回复，需要先停止才能重新生成或编辑
+	ErrSessionStreaming = errors.New("会话正在生成回复，请先停止")
+
+	// errStreamCancelled CancelStream 取消生成时的原因，用于区分客户端断开等其他取消
+	errStreamCancelled = errors.New("用户停止生成")
+)
+
+// activeStream 正在生成的回复
+type activeStream struct {
+	sessionID string
+	cancel    context.CancelCauseFunc
+}
+
+// beginStream 登记正在生成的回复，返回可由 CancelStream 取消的 ctx，生成结束后调用 done
+func (s *chatService) beginStream(ctx context.Context, sessionID, messageID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	s.mu.Lock()
+	if s.streams == nil {
+		s.streams = make(map[string]activeStream)
+	}
+	s.streams[messageID] = activeStream{sessionID: sessionID, cancel: cancel}
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.streams, messageID)
+		s.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// CancelStream 停止生成回复，已生成的内容保存并标记为中断
+func (s *chatService) CancelStream(messageID string) error {
+	s.mu.Lock()
+	stream, ok := s.streams[messageID]
+	s.mu.Unlock()
+	if !ok {
+		return ErrStreamNotFound
+	}
+	log.Printf("🛑 停止生成回复: %s", messageID)
+	stream.cancel(errStreamCancelled)
+	return nil
+}
+
+// sessionStreaming 会话中是否有正在生成的回复
+func (s *chatService) sessionStreaming(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stream := range s.streams {
+		if stream.sessionID == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// interruptStream 生成被取消时保存已生成的部分并标记为中断
+// 通过 CancelStream 停止时推送 cancelled 事件并返回 nil，客户端断开等其他原因返回 ctx 的错误
+func (s *chatService) interruptStream(ctx context.Context, message *models.ChatMessage, partial string, references []models.ClipboardItem, callback func(*models.StreamResponse)) error {
+	s.saveInterrupted(message, partial, references, nil)
+
+	if !errors.Is(context.Cause(ctx), errStreamCancelled) {
+		log.Printf("⚠️  回复生成中断: %s: %v", message.ID, ctx.Err())
+		return ctx.Err()
+	}
+	log.Printf("✅ 已停止生成，保存 %d 字节: %s", len(partial), message.ID)
+	callback(&models.StreamResponse{
+		Type:      models.StreamTypeCancelled,
+		MessageID: message.ID,
+		Data: models.ChatResponse{
+			SessionID:  message.SessionID,
+			MessageID:  message.ID,
+			Content:    partial,
+			Role:       models.MessageRoleAssistant,
+			IsStream:   false,
+			IsComplete: false,
+			Metadata:   message.Metadata,
+		},
+	})
+	return nil
+}
+
+// failStream 模型请求出错时保存已生成的部分，标记为中断并记录错误，避免消息一直停留在生成中
+func (s *chatService) failStream(message *models.ChatMessage, partial string, references []models.ClipboardItem, cause error, callback func(*models.StreamResponse)) error {
+	log.Printf("❌ 流式生成错误: %v", cause)
+	s.saveInterrupted(message, partial, references, cause)
+	callback(&models.StreamResponse{
+		Type:      models.StreamTypeError,
+		Error:     fmt.Sprintf("stream error: %v", cause),
+		MessageID: message.ID,
+		Data: models.ChatResponse{
+			SessionID:  message.SessionID,
+			MessageID:  message.ID,
+			Content:    partial,
+			Role:       models.MessageRoleAssistant,
+			IsStream:   false,
+			IsComplete: false,
+			Metadata:   message.Metadata,
+		},
+	})
+	return cause
+}
+
+// saveInterrupted 保存已生成的部分内容并结束消息的生成状态，cause 不为空时写入错误信息
+func (s *chatService) saveInterrupted(message *models.ChatMessage, partial string, references []models.ClipboardItem, cause error) {
+	message.Content = partial
+	message.Metadata = referenceMetadata(references, partial)
+	if message.Metadata == nil {
+		message.Metadata = make(map[string]interface{})
+	}
+	message.Metadata[models.ChatMetadataInterrupted] = true
+	if cause != nil {
+		message.Metadata[models.ChatMetadataError] = cause.Error()
+	}
+	message.IsStreaming = false
+	message.IsComplete = false
+	message.UpdatedAt = time.Now()
+	if err := s.repo.UpdateChatMessage(message); err != nil {
+		log.Printf("❌ 保存中断的AI消息失败: %v", err)
+	}
+	if partial != "" {
+		if err := s.updateSessionAfterMessage(message.SessionID, partial); err != nil {
+			log.Printf("⚠️ 更新会话信息失败: %v", err)
+		}
+	}
+}
+
+// RegenerateMessage 重新生成回复：删除该回复对应的用户消息及之后的全部消息，再以同样的内容重新发送
+// messageID 为助手消息时使用它之前最近的用户消息，为用户消息时直接使用
+func (s *chatService) RegenerateMessage(ctx context.Context, messageID string, callback func(*models.StreamResponse)) error {
+	userMessage, err := s.userTurn(messageID)
+	if err != nil {
+		return err
+	}
+	return s.replay(ctx, userMessage, userMessage.Content, callback)
+}
+
+// EditUserMessage 修改用户消息：删除该消息及之后的全部消息，再以新内容重新发送
+func (s *chatService) EditUserMessage(ctx context.Context, messageID, content string, callback func(*models.StreamResponse)) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("消息内容不能为空")
+	}
+	message, err := s.repo.GetChatMessage(messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+	if message.Role != models.MessageRoleUser {
+		return fmt.Errorf("只能编辑用户消息")
+	}
+	return s.replay(ctx, message, content, callback)
+}
+
+// userTurn 查找消息所属的用户提问
+func (s *chatService) userTurn(messageID string) (*models.ChatMessage, error) {
+	message, err := s.repo.GetChatMessage(messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if message.Role == models.MessageRoleUser {
+		return message, nil
+	}
+
+	history, err := s.repo.GetChatMessagesAfter(message.SessionID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+	var userMessage *models.ChatMessage
+	for i := range history {
+		if history[i].ID == messageID {
+			break
+		}
+		if history[i].Role == models.MessageRoleUser {
+			userMessage = &history[i]
+		}
+	}
+	if userMessage == nil {
+		return nil, fmt.Errorf("消息之前没有用户提问")
+	}
+	return userMessage, nil
+}
+
+// replay 从 from 开始截断会话，再以 content 作为新的用户消息流式生成回复
+func (s *chatService) replay(ctx context.Context, from *models.ChatMessage, content string, callback func(*models.StreamResponse)) error {
+	if s.sessionStreaming(from.SessionID) {
+		return ErrSessionStreaming
+	}
+	deleted, err := s.repo.TruncateChatMessages(from.SessionID, from.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to truncate messages: %w", err)
+	}
+	log.Printf("✂️ 已删除 %d 条消息，重新生成回复: sessionID=%s", deleted, from.SessionID)
+	return s.SendMessageStream(ctx, from.SessionID, content, callback)
+}
+
+// RepairInterruptedMessages 将上次运行时没有生成完的消息标记为中断，应在启动时调用
+func (s *chatService) RepairInterruptedMessages() (int, error) {
+	messages, err := s.repo.GetStreamingChatMessages()
+	if err != nil {
+		return 0, err
+	}
+
+	repaired := 0
+	for i := range messages {
+		message := &messages[i]
+		if s.streaming(message.ID) {
+			continue
+		}
+		if message.Metadata == nil {
+			message.Metadata = make(map[string]interface{})
+		}
+		message.Metadata[models.ChatMetadataInterrupted] = true
+		message.IsStreaming = false
+		message.IsComplete = false
+		message.UpdatedAt = time.Now()
+		if err := s.repo.UpdateChatMessage(message); err != nil {
+			return repaired, err
+		}
+		repaired++
+	}
+	if repaired > 0 {
+		log.Printf("🔧 已将 %d 条未完成的回复标记为中断", repaired)
+	}
+	return repaired, nil
+}
+
+// streaming 消息是否正在生成
+func (s *chatService) streaming(messageID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.streams[messageID]
+	return ok
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"Sid/internal/models"
+)
+
+// blockingModel 流式返回一个片段后一直等待，直到请求被取消
+type blockingModel struct{}
+
+func (m *blockingModel) Generate(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.Message, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *blockingModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	reader, writer := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer writer.Close()
+		writer.Send(schema.AssistantMessage("部分回复", nil), nil)
+		<-ctx.Done()
+		writer.Send(nil, ctx.Err())
+	}()
+	return reader, nil
+}
+
+func (m *blockingModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// failingModel 流式返回一个片段后报错，模拟服务商请求失败
+type failingModel struct{ blockingModel }
+
+func (m *failingModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	reader, writer := schema.Pipe[*schema.Message](2)
+	go func() {
+		defer writer.Close()
+		writer.Send(schema.AssistantMessage("部分回复", nil), nil)
+		writer.Send(nil, errors.New("rate limited"))
+	}()
+	return reader, nil
+}
+
+func (m *failingModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// streamRecorder 记录流式回调收到的事件
+type streamRecorder struct {
+	mu     sync.Mutex
+	events []models.StreamResponse
+}
+
+func (r *streamRecorder) callback(response *models.StreamResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *response)
+}
+
+// wait 等待收到指定类型的事件并返回
+func (r *streamRecorder) wait(t *testing.T, responseType string) models.StreamResponse {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		for _, event := range r.events {
+			if event.Type == responseType {
+				r.mu.Unlock()
+				return event
+			}
+		}
+		r.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s event", responseType)
+	return models.StreamResponse{}
+}
+
+func TestChatService_CancelStream(t *testing.T) {
+	service, repo, sessionID := newTestChatService(t, &fakeChatModel{}, 0)
+	service.chatModels = &fakeProvider{model: &blockingModel{}}
+
+	recorder := &streamRecorder{}
+	result := make(chan error, 1)
+	go func() {
+		result <- service.SendMessageStream(context.Background(), sessionID, "写一篇长文", recorder.callback)
+	}()
+
+	messageID := recorder.wait(t, models.StreamTypeStarted).MessageID
+	recorder.wait(t, models.StreamTypeMessage)
+	if err := service.CancelStream(messageID); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("cancelled stream returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+
+	cancelled := recorder.wait(t, models.StreamTypeCancelled)
+	if data := cancelled.Data.(models.ChatResponse); data.Content != "部分回复" {
+		t.Errorf("cancelled content = %q", data.Content)
+	}
+	message, err := repo.GetChatMessage(messageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Content != "部分回复" || message.IsStreaming || message.IsComplete || message.Metadata[models.ChatMetadataInterrupted] != true {
+		t.Errorf("message = %+v", message)
+	}
+	if err := service.CancelStream(messageID); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("second cancel err = %v", err)
+	}
+}
+
+func TestChatService_ClientDisconnectInterruptsStream(t *testing.T) {
+	service, repo, sessionID := newTestChatService(t, &fakeChatModel{}, 0)
+	service.chatModels = &fakeProvider{model: &blockingModel{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &streamRecorder{}
+	result := make(chan error, 1)
+	go func() {
+		result <- service.SendMessageStream(ctx, sessionID, "写一篇长文", recorder.callback)
+	}()
+	messageID := recorder.wait(t, models.StreamTypeStarted).MessageID
+	cancel()
+
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v", err)
+	}
+	message, err := repo.GetChatMessage(messageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.IsStreaming || message.Metadata[models.ChatMetadataInterrupted] != true {
+		t.Errorf("message = %+v", message)
+	}
+}
+
+func TestChatService_ProviderErrorFinalizesStream(t *testing.T) {
+	service, repo, sessionID := newTestChatService(t, &fakeChatModel{}, 0)
+	service.chatModels = &fakeProvider{model: &failingModel{}}
+
+	recorder := &streamRecorder{}
+	if err := service.SendMessageStream(context.Background(), sessionID, "写一篇长文", recorder.callback); err == nil {
+		t.Fatal("expected provider error")
+	}
+	failed := recorder.wait(t, models.StreamTypeError)
+	message, err := repo.GetChatMessage(failed.MessageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Content != "部分回复" || message.IsStreaming || message.IsComplete || message.Metadata[models.ChatMetadataInterrupted] != true {
+		t.Errorf("message = %+v", message)
+	}
+	if reason, _ := message.Metadata[models.ChatMetadataError].(string); !strings.Contains(reason, "rate limited") {
+		t.Errorf("error metadata = %v", message.Metadata[models.ChatMetadataError])
+	}
+	if repaired, _ := service.RepairInterruptedMessages(); repaired != 0 {
+		t.Errorf("failed stream left %d streaming messages", repaired)
+	}
+}
+
+func TestChatService_RegenerateMessage(t *testing.T) {
+	chatModel := &fakeChatModel{reply: "新回复"}
+	service, repo, sessionID := newTestChatService(t, chatModel, 2)
+
+	if err := service.RegenerateMessage(context.Background(), "m03", nil); err != nil {
+		t.Fatal(err)
+	}
+	messages, err := repo.GetChatMessages(sessionID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 4 || messages[1].ID != "m01" {
+		t.Fatalf("messages = %+v", messages)
+	}
+	if messages[2].Content != "message 02 with some padding text" || messages[3].Content != "新回复" || !messages[3].IsComplete {
+		t.Errorf("replayed turn = %+v, %+v", messages[2], messages[3])
+	}
+
+	// 重新发送的提问只出现一次，之前的回复不在上下文中
+	var prompt strings.Builder
+	for _, message := range chatModel.calls[len(chatModel.calls)-1] {
+		prompt.WriteString(message.Content)
+	}
+	if strings.Count(prompt.String(), "message 02") != 1 || strings.Contains(prompt.String(), "message 03") {
+		t.Errorf("prompt = %s", prompt.String())
+	}
+
+	if _, err := repo.GetChatMessage("missing"); err == nil {
+		t.Error("expected error for missing message")
+	}
+}
+
+func TestChatService_EditUserMessage(t *testing.T) {
+	service, repo, sessionID := newTestChatService(t, &fakeChatModel{reply: "新回复"}, 3)
+
+	// 摘要覆盖了被删除的消息，编辑后需要清空
+	first, err := repo.GetChatMessage("m00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateChatSessionSummary(sessionID, "旧摘要", first.CreatedAt.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.EditUserMessage(context.Background(), "m01", "改过的问题", nil); !strings.Contains(fmt.Sprint(err), "只能编辑用户消息") {
+		t.Errorf("edit assistant message err = %v", err)
+	}
+	if err := service.EditUserMessage(context.Background(), "m02", "改过的问题", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := repo.GetChatMessages(sessionID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 4 || messages[2].Content != "改过的问题" || messages[3].Content != "新回复" {
+		t.Fatalf("messages = %+v", messages)
+	}
+	session, err := repo.GetChatSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Summary != "旧摘要" {
+		t.Errorf("summary before the edit point should be kept, got %q", session.Summary)
+	}
+
+	if err := service.EditUserMessage(context.Background(), "m00", "从头开始", nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err = repo.GetChatSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Summary != "" || session.SummaryUntil != nil {
+		t.Errorf("summary covering deleted messages should be cleared, got %q", session.Summary)
+	}
+}
+
+func TestChatService_RepairInterruptedMessages(t *testing.T) {
+	service, repo, sessionID := newTestChatService(t, &fakeChatModel{}, 1)
+	now := time.Now()
+	orphan := &models.ChatMessage{
+		ID: "orphan", SessionID: sessionID, Role: models.MessageRoleAssistant, Content: "一半",
+		ContentType: models.MessageContentTypeText, IsStreaming: true, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repo.CreateChatMessage(orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	repaired, err := service.RepairInterruptedMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repaired != 1 {
+		t.Errorf("repaired = %d", repaired)
+	}
+	message, err := repo.GetChatMessage("orphan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.IsStreaming || message.Content != "一半" || message.Metadata[models.ChatMetadataInterrupted] != true {
+		t.Errorf("message = %+v", message)
+	}
+	if repaired, _ := service.RepairInterruptedMessages(); repaired != 0 {
+		t.Errorf("second repair = %d", repaired)
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+)
+
+// 工具返回给模型的内容长度上限（字符数）
+const (
+	toolPreviewChars = 200
+	toolContentChars = 4000
+	toolSearchLimit  = 10
+)
+
+// sensitiveContentPlaceholder 敏感条目的内容不会交给模型
+const sensitiveContentPlaceholder = "[敏感内容，已隐藏]"
+
+// toolItem 工具返回给模型的条目信息
+type toolItem struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	ContentType string   `json:"content_type"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags,omitempty"`
+	IsFavorite  bool     `json:"is_favorite"`
+	SourceApp   string   `json:"source_app,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// newToolItem 转换条目，内容按 limit 截断，敏感条目和图片不返回内容（敏感条目的标题由内容生成，同样隐藏）
+func newToolItem(item models.ClipboardItem, limit int) toolItem {
+	content := truncateRunes(item.Content, limit)
+	switch {
+	case item.IsSensitive:
+		content = sensitiveContentPlaceholder
+		item.Title = sensitiveContentPlaceholder
+	case item.IsImage():
+		content = "[图片]"
+	}
+	tags := make([]string, 0, len(item.Tags))
+	for _, tag := range item.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return toolItem{
+		ID:          item.ID,
+		Title:       item.Title,
+		Content:     content,
+		ContentType: item.ContentType,
+		Category:    item.Category,
+		Tags:        tags,
+		IsFavorite:  item.IsFavorite,
+		SourceApp:   item.SourceApp,
+		CreatedAt:   item.CreatedAt.Format(time.DateTime),
+	}
+}
+
+type searchItemsInput struct {
+	Query    string   `json:"query" jsonschema:"description=搜索关键词，多个关键词用空格分隔且需全部命中；为空时按时间倒序返回最新条目"`
+	Tags     []string `json:"tags,omitempty" jsonschema:"description=只返回带有任一标签的条目"`
+	Category string   `json:"category,omitempty" jsonschema:"description=只返回该分类的条目"`
+	Limit    int      `json:"limit,omitempty" jsonschema:"description=返回数量，默认 10，最多 10"`
+}
+
+type searchItemsOutput struct {
+	Total int        `json:"total"`
+	Items []toolItem `json:"items"`
+}
+
+type itemIDInput struct {
+	ID string `json:"id" jsonschema:"description=条目 ID,required"`
+}
+
+type tagItemInput struct {
+	ID   string   `json:"id" jsonschema:"description=条目 ID,required"`
+	Tags []string `json:"tags" jsonschema:"description=要添加的标签名称,required"`
+}
+
+type favoriteItemInput struct {
+	ID       string `json:"id" jsonschema:"description=条目 ID,required"`
+	Favorite bool   `json:"favorite" jsonschema:"description=true 为收藏，false 为取消收藏,required"`
+}
+
+type createItemInput struct {
+	Content string `json:"content" jsonschema:"description=条目内容,required"`
+}
+
+type toolActionOutput struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// NewClipboardTools 创建聊天智能体操作剪切板数据库的工具集：
+// 搜索、查看、打标签、收藏、新建为普通工具；复制到系统剪切板（覆盖当前内容）和删除需要用户确认
+func NewClipboardTools(clipboard ClipboardService, tags TagService) ([]model.AgentTool, error) {
+	type definition struct {
+		build       func() (tool.InvokableTool, error)
+		destructive bool
+	}
+
+	definitions := []definition{
+		{build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("search_clipboard_items", "搜索剪切板历史条目，返回条目 ID、标题和内容预览",
+				func(ctx context.Context, input searchItemsInput) (searchItemsOutput, error) {
+					limit := input.Limit
+					if limit <= 0 || limit > toolSearchLimit {
+						limit = toolSearchLimit
+					}
+					result, err := clipboard.SearchItems(models.SearchQuery{
+						Query: input.Query, Tags: input.Tags, Category: input.Category, Limit: limit,
+					})
+					if err != nil {
+						return searchItemsOutput{}, err
+					}
+					output := searchItemsOutput{Total: result.Total, Items: make([]toolItem, 0, len(result.Items))}
+					for _, item := range result.Items {
+						output.Items = append(output.Items, newToolItem(item, toolPreviewChars))
+					}
+					return output, nil
+				})
+		}},
+		{build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("get_clipboard_item", "查看剪切板条目的完整内容和标签",
+				func(ctx context.Context, input itemIDInput) (toolItem, error) {
+					item, err := clipboard.GetItem(input.ID)
+					if err != nil {
+						return toolItem{}, toolItemError(input.ID, err)
+					}
+					if item.Tags, err = tags.GetTagsForItem(item.ID); err != nil {
+						return toolItem{}, err
+					}
+					return newToolItem(*item, toolContentChars), nil
+				})
+		}},
+		{build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("tag_clipboard_item", "为剪切板条目添加标签",
+				func(ctx context.Context, input tagItemInput) (toolActionOutput, error) {
+					if len(input.Tags) == 0 {
+						return toolActionOutput{}, errors.New("tags 不能为空")
+					}
+					if _, err := clipboard.GetItem(input.ID); err != nil {
+						return toolActionOutput{}, toolItemError(input.ID, err)
+					}
+					if err := tags.AddTagsToItem(input.ID, input.Tags); err != nil {
+						return toolActionOutput{}, err
+					}
+					return toolActionOutput{OK: true, Message: "已添加标签: " + strings.Join(input.Tags, ", ")}, nil
+				})
+		}},
+		{build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("favorite_clipboard_item", "收藏或取消收藏剪切板条目",
+				func(ctx context.Context, input favoriteItemInput) (toolActionOutput, error) {
+					item, err := clipboard.GetItem(input.ID)
+					if err != nil {
+						return toolActionOutput{}, toolItemError(input.ID, err)
+					}
+					item.IsFavorite = input.Favorite
+					if err := clipboard.UpdateItem(*item); err != nil {
+						return toolActionOutput{}, err
+					}
+					if input.Favorite {
+						return toolActionOutput{OK: true, Message: "已收藏"}, nil
+					}
+					return toolActionOutput{OK: true, Message: "已取消收藏"}, nil
+				})
+		}},
+		{build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("create_clipboard_item", "新建一条剪切板历史条目（不会修改系统剪切板）",
+				func(ctx context.Context, input createItemInput) (toolItem, error) {
+					if strings.TrimSpace(input.Content) == "" {
+						return toolItem{}, errors.New("content 不能为空")
+					}
+					item, err := clipboard.CreateItem(input.Content)
+					if err != nil {
+						return toolItem{}, err
+					}
+					return newToolItem(*item, toolPreviewChars), nil
+				})
+		}},
+		{destructive: true, build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("copy_clipboard_item", "把剪切板条目复制到系统剪切板，会覆盖系统剪切板当前的内容",
+				func(ctx context.Context, input itemIDInput) (toolActionOutput, error) {
+					if err := clipboard.UseItem(input.ID); err != nil {
+						return toolActionOutput{}, toolItemError(input.ID, err)
+					}
+					return toolActionOutput{OK: true, Message: "已复制到系统剪切板"}, nil
+				})
+		}},
+		{destructive: true, build: func() (tool.InvokableTool, error) {
+			return utils.InferTool("delete_clipboard_item", "删除剪切板条目（移入回收站）",
+				func(ctx context.Context, input itemIDInput) (toolActionOutput, error) {
+					if _, err := clipboard.GetItem(input.ID); err != nil {
+						return toolActionOutput{}, toolItemError(input.ID, err)
+					}
+					if err := clipboard.DeleteItem(input.ID); err != nil {
+						return toolActionOutput{}, err
+					}
+					return toolActionOutput{OK: true, Message: "已移入回收站"}, nil
+				})
+		}},
+	}
+
+	tools := make([]model.AgentTool, 0, len(definitions))
+	for _, definition := range definitions {
+		t, err := definition.build()
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, model.AgentTool{Tool: t, Destructive: definition.destructive})
+	}
+	return tools, nil
+}
+
+// toolItemError 条目读取失败时给模型一个明确的错误
+func toolItemError(id string, err error) error {
+	return fmt.Errorf("条目 %s 不存在或无法读取: %w", id, err)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+)
+
+// invokeTool 按名称调用工具，返回工具输出
+func invokeTool(t *testing.T, tools []model.AgentTool, name, arguments string) (string, error) {
+	t.Helper()
+	for _, agentTool := range tools {
+		info, err := agentTool.Tool.Info(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name == name {
+			return agentTool.Tool.InvokableRun(context.Background(), arguments)
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return "", nil
+}
+
+func TestClipboardTools(t *testing.T) {
+	clipboard, tags, _ := newTestClipboardService(t)
+	tools, err := NewClipboardTools(clipboard, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, agentTool := range tools {
+		info, _ := agentTool.Tool.Info(context.Background())
+		destructive := info.Name == "copy_clipboard_item" || info.Name == "delete_clipboard_item"
+		if agentTool.Destructive != destructive {
+			t.Errorf("%s destructive = %v", info.Name, agentTool.Destructive)
+		}
+	}
+
+	output, err := invokeTool(t, tools, "create_clipboard_item", `{"content":"docker compose up -d"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created toolItem
+	if err := json.Unmarshal([]byte(output), &created); err != nil || created.ID == "" {
+		t.Fatalf("create output = %s, %v", output, err)
+	}
+
+	if _, err := tags.ImportTag(models.Tag{Name: "运维"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invokeTool(t, tools, "tag_clipboard_item", `{"id":"`+created.ID+`","tags":["运维"]}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invokeTool(t, tools, "favorite_clipboard_item", `{"id":"`+created.ID+`","favorite":true}`); err != nil {
+		t.Fatal(err)
+	}
+	output, err = invokeTool(t, tools, "get_clipboard_item", `{"id":"`+created.ID+`"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item toolItem
+	if err := json.Unmarshal([]byte(output), &item); err != nil {
+		t.Fatal(err)
+	}
+	if !item.IsFavorite || len(item.Tags) != 1 || item.Tags[0] != "运维" || item.Content != "docker compose up -d" {
+		t.Errorf("item = %+v", item)
+	}
+
+	output, err = invokeTool(t, tools, "search_clipboard_items", `{"query":"docker"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, created.ID) {
+		t.Errorf("search output = %s", output)
+	}
+
+	if _, err := invokeTool(t, tools, "get_clipboard_item", `{"id":"missing"}`); err == nil {
+		t.Error("expected error for missing item")
+	}
+}
+
+func TestClipboardTools_HidesSensitiveContent(t *testing.T) {
+	clipboard, tags, _ := newTestClipboardService(t)
+	item, err := clipboard.CreateItem("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.IsSensitive = true
+	if err := clipboard.repo.Update(*item); err != nil {
+		t.Fatal(err)
+	}
+	tools, err := NewClipboardTools(clipboard, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := invokeTool(t, tools, "get_clipboard_item", `{"id":"`+item.ID+`"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "hunter2") || !strings.Contains(output, sensitiveContentPlaceholder) {
+		t.Errorf("sensitive content leaked: %s", output)
+	}
+}
+
+func TestChatService_ToolCallConfirmation(t *testing.T) {
+	clipboard, tags, _ := newTestClipboardService(t)
+	item, err := clipboard.CreateItem("temporary note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools, err := NewClipboardTools(clipboard, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chatModel := &fakeChatModel{reply: "已删除", script: []*schema.Message{
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "call-1", Function: schema.FunctionCall{
+			Name: "delete_clipboard_item", Arguments: `{"id":"` + item.ID + `"}`,
+		}}}),
+	}}
+	service, _, sessionID := newTestChatService(t, chatModel, 0)
+	service.UpdateSettings(models.DefaultLLMSettings())
+	service.SetTools(tools)
+
+	confirms := make(chan models.ChatToolCall, 1)
+	service.SetEventEmitter(func(name string, data interface{}) {
+		event := data.(models.ChatStreamEvent)
+		if event.Type == models.StreamTypeToolConfirm {
+			confirms <- event.Data.(models.ChatToolCall)
+		}
+	})
+
+	type result struct {
+		reply *models.ChatMessage
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		reply, err := service.SendMessage(context.Background(), sessionID, "删除 temporary note")
+		done <- result{reply, err}
+	}()
+
+	var confirmed string
+	select {
+	case call := <-confirms:
+		if call.ID != "call-1" || !call.Destructive || call.ConfirmationID == "" || call.ConfirmationID == call.ID {
+			t.Errorf("confirm event = %+v", call)
+		}
+		// 模型返回的调用 ID 不能用于确认
+		if err := service.ConfirmToolCall(call.ID, true); !errors.Is(err, ErrToolCallNotPending) {
+			t.Errorf("confirm by model call ID err = %v", err)
+		}
+		if err := service.ConfirmToolCall(call.ConfirmationID, true); err != nil {
+			t.Fatal(err)
+		}
+		confirmed = call.ConfirmationID
+	case <-time.After(5 * time.Second):
+		t.Fatal("no confirmation requested")
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	calls, ok := res.reply.Metadata[models.ChatMetadataToolCalls].([]models.ChatToolCall)
+	if !ok || len(calls) != 1 || calls[0].Status != models.ToolCallStatusSuccess {
+		t.Errorf("tool calls = %#v", res.reply.Metadata[models.ChatMetadataToolCalls])
+	}
+	if deleted, err := clipboard.GetItem(item.ID); err != nil || !deleted.IsDeleted {
+		t.Errorf("item not moved to trash after confirmation: %v", err)
+	}
+
+	// 已处理的调用不能重复确认
+	if err := service.ConfirmToolCall(confirmed, true); !errors.Is(err, ErrToolCallNotPending) {
+		t.Errorf("second confirm err = %v", err)
+	}
+}
+
+// fixedIDToolModel 每次都以相同的调用 ID 请求删除用户消息中给出的条目，收到工具结果后直接回答
+// 模拟在不同会话中返回 call_0 的本地模型服务，不保存状态，可以在多个会话中并发使用
+type fixedIDToolModel struct{}
+
+func (m fixedIDToolModel) Generate(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.Message, error) {
+	last := input[len(input)-1]
+	if last.Role == schema.Tool {
+		return schema.AssistantMessage("完成", nil), nil
+	}
+	id := strings.TrimPrefix(last.Content, "删除 ")
+	return schema.AssistantMessage("", []schema.ToolCall{{ID: "call_0", Function: schema.FunctionCall{
+		Name: "delete_clipboard_item", Arguments: `{"id":"` + id + `"}`,
+	}}}), nil
+}
+
+func (m fixedIDToolModel) Stream(ctx context.Context, input []*schema.Message, opts ...einomodel.Option) (*schema.StreamReader[*schema.Message], error) {
+	message, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{message}), nil
+}
+
+func (m fixedIDToolModel) WithTools(tools []*schema.ToolInfo) (einomodel.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func TestChatService_ToolCallConfirmationAcrossSessions(t *testing.T) {
+	clipboard, tags, _ := newTestClipboardService(t)
+	first, err := clipboard.CreateItem("first note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := clipboard.CreateItem("second note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools, err := NewClipboardTools(clipboard, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, _, firstSession := newTestChatService(t, &fakeChatModel{}, 0)
+	service.chatModels = &fakeProvider{model: fixedIDToolModel{}}
+	service.UpdateSettings(models.DefaultLLMSettings())
+	service.SetTools(tools)
+	secondSession, err := service.CreateSession(context.Background(), "第二个会话")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirms := make(chan models.ChatToolCall, 2)
+	service.SetEventEmitter(func(name string, data interface{}) {
+		if event := data.(models.ChatStreamEvent); event.Type == models.StreamTypeToolConfirm {
+			confirms <- event.Data.(models.ChatToolCall)
+		}
+	})
+
+	done := make(chan error, 2)
+	for _, run := range []struct{ session, item string }{{firstSession, first.ID}, {secondSession.ID, second.ID}} {
+		go func(session, item string) {
+			_, err := service.SendMessage(context.Background(), session, "删除 "+item)
+			done <- err
+		}(run.session, run.item)
+	}
+
+	pending := map[string]models.ChatToolCall{}
+	for len(pending) < 2 {
+		select {
+		case call := <-confirms:
+			pending[call.Arguments] = call
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected two confirmation requests, got %d", len(pending))
+		}
+	}
+	firstCall := pending[`{"id":"`+first.ID+`"}`]
+	secondCall := pending[`{"id":"`+second.ID+`"}`]
+	if firstCall.ConfirmationID == "" || firstCall.ConfirmationID == secondCall.ConfirmationID {
+		t.Fatalf("confirmation IDs must be unique: %q %q", firstCall.ConfirmationID, secondCall.ConfirmationID)
+	}
+
+	// 批准第一个会话的调用只删除第一个条目
+	if err := service.ConfirmToolCall(firstCall.ConfirmationID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if item, err := clipboard.GetItem(first.ID); err != nil || !item.IsDeleted {
+		t.Errorf("approved item not deleted: %v", err)
+	}
+	if item, err := clipboard.GetItem(second.ID); err != nil || item.IsDeleted {
+		t.Errorf("item in the other session deleted without approval: %v", err)
+	}
+
+	if err := service.ConfirmToolCall(secondCall.ConfirmationID, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if item, err := clipboard.GetItem(second.ID); err != nil || item.IsDeleted {
+		t.Errorf("rejected item deleted: %v", err)
+	}
+}
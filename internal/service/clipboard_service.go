@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
 	clipboardLib "golang.design/x/clipboard"
@@ -18,13 +21,17 @@ type ClipboardService interface {
 	// 基础CRUD操作
 	GetItems(limit, offset int) ([]models.ClipboardItem, error)
 	GetItem(id string) (*models.ClipboardItem, error)
-	CreateItem(content string) error
+	CreateItem(content string) (*models.ClipboardItem, error)
 	UpdateItem(item models.ClipboardItem) error
 	DeleteItem(id string) error
 	UseItem(id string) error
+	CopyItem(id string) (<-chan struct{}, error)
+	GetItemImage(id string) (*models.ClipboardImage, error)
 
 	// 搜索功能
 	SearchItems(query models.SearchQuery) (models.SearchResult, error)
+	SemanticSearch(query string, k int) (models.SearchResult, error)
+	SetEmbeddings(embeddings EmbeddingService)
 
 	// 回收站管理
 	GetTrashItems(limit, offset int) ([]models.ClipboardItem, error)
@@ -46,20 +53,51 @@ type ClipboardService interface {
 
 	// AI功能
 	GenerateTagsForItem(ctx context.Context, id string) ([]string, error)
+
+	// 批量重新打标签
+	StartRetag(ctx context.Context, options models.RetagOptions) error
+	PauseRetag() error
+	ResumeRetag() error
+	CancelRetag() error
+	GetRetagStatus() models.RetagSummary
+	SetEventEmitter(emit EventEmitter)
+
+	// 导入导出
+	Export(w io.Writer, options models.ExportOptions) (models.ExportReport, error)
+	Import(r io.Reader, options models.ImportOptions) (models.ImportReport, error)
+
+	// 内容转换
+	TransformItem(id, transform string) (string, error)
+	UseItemTransformed(id, transform string) (string, error)
+	SaveTransformedItem(id, transform string) (*models.ClipboardItem, error)
+
+	// 捕获规则
+	SetCaptureRules(rules RuleService)
+	TestCaptureRules(request models.RuleTestRequest) (models.RuleTestResult, error)
 }
 
 // clipboardService 剪切板服务实现
 type clipboardService struct {
-	repo        repository.ClipboardRepository
-	monitor     clipboard.Monitor
+	repo    repository.ClipboardRepository
+	monitor clipboard.Monitor
+
+	mu          sync.Mutex // 保护 settings 和 itemBuilder，设置在 API 协程中替换，监听协程同时读取
 	itemBuilder *clipboard.ItemBuilder
 	settings    *models.Settings
+
 	chatService ChatService
 	tagService  TagService
+	tagging     TaggingService
+	rules       RuleService
+	embeddings  EmbeddingService
+
+	retagMu sync.Mutex
+	retag   *retagJob
+	emit    EventEmitter
 }
 
 // NewClipboardService 创建新的剪切板服务
-func NewClipboardService(repo repository.ClipboardRepository, settings *models.Settings, chatService ChatService, tagService TagService) ClipboardService {
+func NewClipboardService(repo repository.ClipboardRepository, settings *models.Settings, chatService ChatService, tagService TagService, tagging TaggingService) ClipboardService {
 	analyzer := clipboard.NewAnalyzer()
 	monitor := clipboard.NewMonitor(settings)
 	itemBuilder := clipboard.NewItemBuilder(analyzer, settings)
@@ -71,16 +109,19 @@ func NewClipboardService(repo repository.ClipboardRepository, settings *models.S
 		settings:    settings,
 		chatService: chatService,
 		tagService:  tagService,
+		tagging:     tagging,
 	}
 
 	// 设置监听器的内容处理器
 	monitor.SetProcessor(service)
+	// 设置打标签队列的标签生成器
+	tagging.SetTagger(service)
 
 	return service
 }
 
 // ProcessContent 实现ContentProcessor接口，处理剪切板内容
-func (s *clipboardService) ProcessContent(content string) error {
+func (s *clipboardService) ProcessContent(content string, source clipboard.Source) error {
 	// 确保内容是有效的UTF-8字符串，如果不是则尝试修复
 	if !isValidUTF8(content) {
 		log.Println("⚠️  检测到非UTF-8内容，尝试修复...")
@@ -92,8 +133,15 @@ func (s *clipboardService) ProcessContent(content string) error {
 		log.Println("✅ UTF-8内容修复成功")
 	}
 
+	// 构建剪切板条目（按忽略的应用、敏感内容规则和捕获规则跳过或调整）
+	item, outcome, skip := s.buildCapture(content, source, s.ruleEngine())
+	if skip != "" {
+		log.Printf("🚫 跳过%s", skip)
+		return nil
+	}
+
 	// 检查是否重复内容
-	isDuplicate, err := s.repo.IsDuplicateContent(content)
+	isDuplicate, err := s.repo.IsDuplicateContent(item.Content)
 	if err != nil {
 		return err
 	}
@@ -102,9 +150,6 @@ func (s *clipboardService) ProcessContent(content string) error {
 		return nil
 	}
 
-	// 构建剪切板条目
-	item := s.itemBuilder.BuildItem(content)
-
 	// 保存到数据库
 	if err := s.repo.Create(item); err != nil {
 		log.Printf("❌ 保存剪切板条目失败: %v", err)
@@ -112,6 +157,71 @@ func (s *clipboardService) ProcessContent(content string) error {
 	}
 
 	log.Printf("✅ 保存剪切板条目: %s", item.Title)
+	s.publish(EventItemCreated, item)
+	s.applyRuleTags(item.ID, outcome.Tags)
+	s.enqueueTagging(item)
+	s.notifyEmbeddings()
+	return nil
+}
+
+// current 获取当前设置和条目构建器
+func (s *clipboardService) current() (*models.Settings, *clipboard.ItemBuilder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings, s.itemBuilder
+}
+
+// ProcessImage 实现ContentProcessor接口，处理剪切板图片
+func (s *clipboardService) ProcessImage(data []byte, source clipboard.Source) error {
+	settings, itemBuilder := s.current()
+	if settings.IgnoreImages {
+		return nil
+	}
+	if settings.IgnoresApp(source.App) {
+		log.Printf("🚫 跳过来自 %s 的图片", source.App)
+		return nil
+	}
+
+	info, err := clipboard.ParseImage(data)
+	if err != nil {
+		return err
+	}
+
+	// 检查是否重复图片
+	isDuplicate, err := s.repo.IsDuplicateImage(info.Hash)
+	if err != nil {
+		return err
+	}
+	if isDuplicate {
+		log.Println("🔄 图片已存在，跳过")
+		return nil
+	}
+
+	item := itemBuilder.BuildImageItem(info)
+	item.SourceApp, item.SourceTitle = source.App, source.Title
+	if err := s.repo.Create(item); err != nil {
+		log.Printf("❌ 保存图片条目失败: %v", err)
+		return err
+	}
+
+	image := models.ClipboardImage{
+		ItemID:    item.ID,
+		Hash:      info.Hash,
+		Data:      info.Data,
+		Thumbnail: info.Thumbnail,
+		Width:     info.Width,
+		Height:    info.Height,
+		Size:      len(info.Data),
+		CreatedAt: item.CreatedAt,
+	}
+	if err := s.repo.CreateImage(image); err != nil {
+		log.Printf("❌ 保存图片数据失败: %v", err)
+		s.repo.PermanentDelete(item.ID)
+		return err
+	}
+
+	log.Printf("✅ 保存剪切板图片: %s", item.Title)
+	s.publish(EventItemCreated, item)
 	return nil
 }
 
@@ -126,39 +236,101 @@ func (s *clipboardService) GetItem(id string) (*models.ClipboardItem, error) {
 }
 
 // CreateItem 创建新的剪切板条目
-func (s *clipboardService) CreateItem(content string) error {
-	item := s.itemBuilder.BuildItem(content)
-	return s.repo.Create(item)
+func (s *clipboardService) CreateItem(content string) (*models.ClipboardItem, error) {
+	_, itemBuilder := s.current()
+	item, sensitive := itemBuilder.BuildItem(content)
+	if sensitive.Skip {
+		return nil, fmt.Errorf("内容包含敏感信息（%s），已按规则跳过保存", strings.Join(sensitive.Detectors(), ", "))
+	}
+	if err := s.repo.Create(item); err != nil {
+		return nil, err
+	}
+	s.publish(EventItemCreated, item)
+	s.enqueueTagging(item)
+	s.notifyEmbeddings()
+	return &item, nil
 }
 
-// UpdateItem 更新剪切板条目
+// enqueueTagging 将新条目加入后台打标签队列（敏感内容不发送给AI）
+func (s *clipboardService) enqueueTagging(item models.ClipboardItem) {
+	settings, _ := s.current()
+	if !settings.AutoTag || item.IsSensitive || item.IsImage() {
+		return
+	}
+	if err := s.tagging.Enqueue(item.ID); err != nil {
+		log.Printf("❌ 加入打标签队列失败: %v", err)
+	}
+}
+
+// UpdateItem 更新剪切板条目，内容或敏感标记变化时清除已生成的向量
 func (s *clipboardService) UpdateItem(item models.ClipboardItem) error {
-	return s.repo.Update(item)
+	existing, err := s.repo.GetByID(item.ID)
+	if err != nil {
+		return err
+	}
+	clipboard.FillContentStats(&item)
+	clipboard.FillContentType(&item)
+	if err := s.repo.Update(item); err != nil {
+		return err
+	}
+	if existing.Content != item.Content || existing.IsSensitive != item.IsSensitive {
+		s.invalidateEmbedding(item.ID)
+	}
+	s.publishUpdated(item.ID)
+	return nil
 }
 
 // DeleteItem 删除剪切板条目（软删除）
 func (s *clipboardService) DeleteItem(id string) error {
-	return s.repo.SoftDelete(id)
+	if err := s.repo.SoftDelete(id); err != nil {
+		return err
+	}
+	s.publish(EventItemDeleted, models.ItemDeletedEvent{IDs: []string{id}})
+	return nil
 }
 
 // UseItem 使用剪切板条目
 func (s *clipboardService) UseItem(id string) error {
+	_, err := s.CopyItem(id)
+	return err
+}
+
+// CopyItem 将条目复制到系统剪切板并记录使用，返回的通道在剪切板内容被其他程序替换时关闭
+func (s *clipboardService) CopyItem(id string) (<-chan struct{}, error) {
 	// 获取条目内容
 	item, err := s.repo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 复制到剪切板
-	clipboardLib.Write(clipboardLib.FmtText, []byte(item.Content))
+	var changed <-chan struct{}
+	if item.IsImage() {
+		image, err := s.repo.GetImage(id)
+		if err != nil {
+			return nil, err
+		}
+		changed = clipboardLib.Write(clipboardLib.FmtImage, image.Data)
+	} else {
+		changed = clipboardLib.Write(clipboardLib.FmtText, []byte(item.Content))
+	}
 
 	// 更新使用次数和最后使用时间
-	return s.repo.UseItem(id)
+	if err := s.repo.UseItem(id); err != nil {
+		return nil, err
+	}
+	s.publishUpdated(id)
+	return changed, nil
 }
 
-// SearchItems 搜索剪切板条目
+// GetItemImage 获取图片条目的原始图片数据
+func (s *clipboardService) GetItemImage(id string) (*models.ClipboardImage, error) {
+	return s.repo.GetImage(id)
+}
+
+// SearchItems 搜索剪切板条目，按 query.Mode 选择关键词、语义或混合搜索
 func (s *clipboardService) SearchItems(query models.SearchQuery) (models.SearchResult, error) {
-	return s.repo.Search(query)
+	return s.searchByMode(query)
 }
 
 // GetTrashItems 获取回收站条目
@@ -168,22 +340,63 @@ func (s *clipboardService) GetTrashItems(limit, offset int) ([]models.ClipboardI
 
 // RestoreItem 恢复剪切板条目
 func (s *clipboardService) RestoreItem(id string) error {
-	return s.repo.Restore(id)
+	if err := s.repo.Restore(id); err != nil {
+		return err
+	}
+	s.publishUpdated(id)
+	return nil
 }
 
 // PermanentDeleteItem 永久删除剪切板条目
 func (s *clipboardService) PermanentDeleteItem(id string) error {
-	return s.repo.PermanentDelete(id)
+	if err := s.repo.PermanentDelete(id); err != nil {
+		return err
+	}
+	s.publish(EventItemDeleted, models.ItemDeletedEvent{IDs: []string{id}, Permanent: true})
+	return nil
 }
 
 // BatchPermanentDelete 批量永久删除
 func (s *clipboardService) BatchPermanentDelete(ids []string) error {
-	return s.repo.BatchPermanentDelete(ids)
+	if err := s.repo.BatchPermanentDelete(ids); err != nil {
+		return err
+	}
+	s.publish(EventItemDeleted, models.ItemDeletedEvent{IDs: ids, Permanent: true})
+	return nil
 }
 
 // EmptyTrash 清空回收站
 func (s *clipboardService) EmptyTrash() error {
-	return s.repo.EmptyTrash()
+	if err := s.repo.EmptyTrash(); err != nil {
+		return err
+	}
+	s.publish(EventItemDeleted, models.ItemDeletedEvent{Permanent: true, EmptiedTrash: true})
+	return nil
+}
+
+// publish 推送实时事件
+func (s *clipboardService) publish(name string, data interface{}) {
+	s.retagMu.Lock()
+	emit := s.emit
+	s.retagMu.Unlock()
+	if emit != nil {
+		emit(name, data)
+	}
+}
+
+// publishUpdated 推送条目更新事件，携带更新后的完整条目
+func (s *clipboardService) publishUpdated(id string) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		log.Printf("⚠️  读取已更新条目失败: %v", err)
+		return
+	}
+	s.publish(EventItemUpdated, item)
+}
+
+// publishMonitorState 推送剪切板监听状态
+func (s *clipboardService) publishMonitorState() {
+	s.publish(EventMonitorState, models.MonitorStateEvent{Monitoring: s.monitor.IsRunning()})
 }
 
 // GetStatistics 获取统计信息
@@ -202,15 +415,20 @@ func (s *clipboardService) GetStatistics() (models.Statistics, error) {
 
 // StartMonitoring 开始监听剪切板
 func (s *clipboardService) StartMonitoring() error {
-	if !s.settings.AutoCapture {
+	if settings, _ := s.current(); !settings.AutoCapture {
 		return nil
 	}
-	return s.monitor.Start()
+	if err := s.monitor.Start(); err != nil {
+		return err
+	}
+	s.publishMonitorState()
+	return nil
 }
 
 // StopMonitoring 停止监听剪切板
 func (s *clipboardService) StopMonitoring() {
 	s.monitor.Stop()
+	s.publishMonitorState()
 }
 
 // IsMonitoring 检查是否正在监听
@@ -220,14 +438,20 @@ func (s *clipboardService) IsMonitoring() bool {
 
 // UpdateSettings 更新设置（用于动态调整监听行为）
 func (s *clipboardService) UpdateSettings(settings *models.Settings) {
+	itemBuilder := clipboard.NewItemBuilder(clipboard.NewAnalyzer(), settings)
+	s.mu.Lock()
 	s.settings = settings
-	s.itemBuilder = clipboard.NewItemBuilder(clipboard.NewAnalyzer(), settings)
+	s.itemBuilder = itemBuilder
+	s.mu.Unlock()
+	s.monitor.UpdateSettings(settings)
 
 	// 根据新设置调整监听状态
 	if settings.AutoCapture && !s.monitor.IsRunning() {
 		s.monitor.Start()
+		s.publishMonitorState()
 	} else if !settings.AutoCapture && s.monitor.IsRunning() {
 		s.monitor.Stop()
+		s.publishMonitorState()
 	}
 }
 
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// newTestDB 打开临时数据库，测试结束时关闭
+func newTestDB(t *testing.T) *repository.Database {
+	t.Helper()
+	db, err := repository.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testServiceConfig 测试用剪切板服务的可选参数
+type testServiceConfig struct {
+	db             *repository.Database
+	chatService    func(db *repository.Database) ChatService
+	taggingOptions TaggingOptions
+}
+
+// testServiceOption 修改测试用剪切板服务的参数
+type testServiceOption func(*testServiceConfig)
+
+// withTestDatabase 使用指定的数据库代替新建的临时数据库
+func withTestDatabase(db *repository.Database) testServiceOption {
+	return func(c *testServiceConfig) { c.db = db }
+}
+
+// withChatService 设置聊天服务，默认不设置
+func withChatService(chat ChatService) testServiceOption {
+	return func(c *testServiceConfig) { c.chatService = func(*repository.Database) ChatService { return chat } }
+}
+
+// withRealChatService 使用基于同一数据库的真实聊天服务（不配置大模型）
+func withRealChatService() testServiceOption {
+	return func(c *testServiceConfig) {
+		c.chatService = func(db *repository.Database) ChatService {
+			return NewChatService(repository.NewChatRepository(db.DB, db.Cipher()), nil)
+		}
+	}
+}
+
+// withTaggingOptions 设置打标签队列参数，默认使用 DefaultTaggingOptions
+func withTaggingOptions(options TaggingOptions) testServiceOption {
+	return func(c *testServiceConfig) { c.taggingOptions = options }
+}
+
+// newTestClipboardService 创建使用临时数据库、关闭自动打标签的剪切板服务
+func newTestClipboardService(t *testing.T, opts ...testServiceOption) (*clipboardService, TagService, *repository.Database) {
+	t.Helper()
+	config := testServiceConfig{taggingOptions: DefaultTaggingOptions()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	db := config.db
+	if db == nil {
+		db = newTestDB(t)
+	}
+	var chatService ChatService
+	if config.chatService != nil {
+		chatService = config.chatService(db)
+	}
+
+	settings := models.DefaultSettings()
+	settings.AutoTag = false
+	clipboardRepo := repository.NewClipboardRepository(db.DB, db.Cipher())
+	tagService := NewTagService(repository.NewTagRepository(db.DB), clipboardRepo, nil)
+	tagging := NewTaggingService(repository.NewTaggingRepository(db.DB), clipboardRepo, config.taggingOptions)
+	service := NewClipboardService(clipboardRepo, &settings, chatService, tagService, tagging).(*clipboardService)
+	return service, tagService, db
+}
+
+func TestClipboardService_ProcessContentSource(t *testing.T) {
+	service, _, _ := newTestClipboardService(t)
+
+	// 默认忽略列表中的密码管理器，按名称不区分大小写匹配
+	if err := service.ProcessContent("hunter2 hunter2", clipboard.Source{App: "keepassxc", Title: "Passwords.kdbx"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ProcessContent("第一行\n第二行", clipboard.Source{App: "Firefox", Title: "文档 - Mozilla Firefox"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := service.GetItems(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected only the Firefox item, got %d items", len(items))
+	}
+	item := items[0]
+	if item.SourceApp != "Firefox" || item.SourceTitle != "文档 - Mozilla Firefox" {
+		t.Errorf("source = %q / %q", item.SourceApp, item.SourceTitle)
+	}
+	if item.ContentLength != len("第一行\n第二行") || item.LineCount != 2 || item.Language != "zh" {
+		t.Errorf("stats = %d bytes, %d lines, %q", item.ContentLength, item.LineCount, item.Language)
+	}
+
+	result, err := service.SearchItems(models.SearchQuery{SourceApp: "firefox", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("expected 1 item from Firefox, got %d", result.Total)
+	}
+}
+
+func TestClipboardService_UpdateSettingsWhileCapturing(t *testing.T) {
+	service, _, _ := newTestClipboardService(t)
+
+	// 保存设置与监听协程的捕获并发进行，配合 -race 检查
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			settings := models.DefaultSettings()
+			settings.AutoCapture = false
+			settings.AutoTag = false
+			settings.IgnoreApps = append(settings.IgnoreApps, fmt.Sprintf("app-%d", i))
+			service.UpdateSettings(&settings)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if err := service.ProcessContent(fmt.Sprintf("note %d", i), clipboard.Source{App: "Firefox"}); err != nil {
+			t.Fatal(err)
+		}
+		// 无效的图片数据只会返回解析错误，这里只关心读取设置
+		service.ProcessImage([]byte("not a png"), clipboard.Source{App: "Firefox"})
+	}
+	<-done
+
+	items, err := service.GetItems(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 50 {
+		t.Errorf("expected 50 items, got %d", len(items))
+	}
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// EmbeddingOptions 后台生成向量的参数
+type EmbeddingOptions struct {
+	BatchSize    int           // 每次请求生成向量的条目数
+	MaxChars     int           // 每个条目发送给模型的最大字符数
+	PollInterval time.Duration // 没有待处理条目时的轮询间隔
+	MaxBackoff   time.Duration // 失败后重试等待时间上限，每次失败翻倍
+}
+
+// DefaultEmbeddingOptions 返回默认参数
+func DefaultEmbeddingOptions() EmbeddingOptions {
+	return EmbeddingOptions{
+		BatchSize:    16,
+		MaxChars:     2000,
+		PollInterval: 30 * time.Second,
+		MaxBackoff:   10 * time.Minute,
+	}
+}
+
+// EmbeddingService 语义索引接口：后台为条目生成向量，并按向量相似度检索
+type EmbeddingService interface {
+	Start(ctx context.Context) error
+	Stop()
+	// Notify 有新条目时唤醒后台索引
+	Notify()
+	// Invalidate 条目内容变化后删除已有向量并唤醒后台索引重新生成
+	Invalidate(itemID string) error
+	// Search 返回与 query 最相似的 k 个条目，未启用时返回 model.ErrEmbeddingDisabled
+	Search(ctx context.Context, query string, k int) ([]models.SemanticMatch, error)
+	Status() (models.EmbeddingStatus, error)
+	UpdateSettings(settings models.EmbeddingSettings)
+	// HybridWeight 混合搜索中向量相似度的权重
+	HybridWeight() float64
+}
+
+// embeddingService 语义索引实现，向量保存在 clipboard_embeddings 表，检索时加载到内存
+type embeddingService struct {
+	repo          repository.EmbeddingRepository
+	clipboardRepo repository.ClipboardRepository
+	embedders     model.EmbedderProvider
+	options       EmbeddingOptions
+
+	mu        sync.Mutex
+	settings  models.EmbeddingSettings
+	index     *vectorIndex
+	stale     map[string]bool // 生成向量期间内容发生变化的条目，本批结果不保存
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	lastError string
+
+	wake chan struct{}
+}
+
+// NewEmbeddingService 创建新的语义索引服务
+func NewEmbeddingService(repo repository.EmbeddingRepository, clipboardRepo repository.ClipboardRepository, embedders model.EmbedderProvider, options EmbeddingOptions) EmbeddingService {
+	return &embeddingService{
+		repo:          repo,
+		clipboardRepo: clipboardRepo,
+		embedders:     embedders,
+		options:       options,
+		stale:         make(map[string]bool),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// UpdateSettings 更新语义搜索配置，模型可能已变化，重新创建向量模型并唤醒后台索引
+func (s *embeddingService) UpdateSettings(settings models.EmbeddingSettings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+	s.embedders.Reload()
+	s.Notify()
+}
+
+// HybridWeight 混合搜索中向量相似度的权重，限制在 0-1 之间
+func (s *embeddingService) HybridWeight() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return math.Max(0, math.Min(1, s.settings.HybridWeight))
+}
+
+// Start 启动后台索引协程
+func (s *embeddingService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.worker(ctx)
+
+	log.Printf("🧭 语义索引已启动")
+	return nil
+}
+
+// Stop 停止后台索引并等待当前批次结束
+func (s *embeddingService) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+	log.Println("🛑 语义索引已停止")
+}
+
+// Notify 唤醒后台索引，已有唤醒信号时忽略
+func (s *embeddingService) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Invalidate 删除条目的向量并从内存索引中移除，正在生成的向量不会被保存
+func (s *embeddingService) Invalidate(itemID string) error {
+	s.mu.Lock()
+	s.stale[itemID] = true
+	if s.index != nil {
+		s.index.remove(itemID)
+	}
+	err := s.repo.DeleteEmbedding(itemID)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.Notify()
+	return nil
+}
+
+// worker 循环为待处理条目生成向量，没有待处理条目时等待唤醒或轮询，失败后指数退避
+func (s *embeddingService) worker(ctx context.Context) {
+	defer s.wg.Done()
+
+	var backoff time.Duration
+	for {
+		n, err := s.indexPending(ctx)
+		wait := s.options.PollInterval
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, model.ErrEmbeddingDisabled):
+			backoff = 0
+		case err != nil:
+			backoff = min(max(backoff*2, s.options.PollInterval), s.options.MaxBackoff)
+			wait = backoff
+			s.setLastError(err.Error())
+			log.Printf("❌ 生成条目向量失败，%v 后重试: %v", wait, err)
+		case n > 0:
+			backoff = 0
+			s.setLastError("")
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// indexPending 为一批还没有向量的条目生成向量，返回处理的条目数
+func (s *embeddingService) indexPending(ctx context.Context) (int, error) {
+	embedder, err := s.embedder()
+	if err != nil {
+		return 0, err
+	}
+	ids, err := s.repo.PendingItemIDs(embedder.Name(), s.options.BatchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	s.mu.Lock()
+	for _, id := range ids {
+		delete(s.stale, id)
+	}
+	s.mu.Unlock()
+
+	var embeddings []models.ItemEmbedding
+	var texts []string
+	var blank []models.ItemEmbedding
+	for _, id := range ids {
+		item, err := s.clipboardRepo.GetByID(id)
+		if err != nil {
+			log.Printf("⚠️  读取待生成向量的条目失败: %s: %v", id, err)
+			continue
+		}
+		text := embeddingText(*item, s.options.MaxChars)
+		if text == "" {
+			// 空白内容不请求模型，保存空向量避免反复处理
+			blank = append(blank, models.ItemEmbedding{ItemID: id, Model: embedder.Name()})
+			continue
+		}
+		embeddings = append(embeddings, models.ItemEmbedding{ItemID: id, Model: embedder.Name()})
+		texts = append(texts, text)
+	}
+	if len(embeddings)+len(blank) == 0 {
+		return 0, errors.New("待生成向量的条目都无法读取")
+	}
+
+	if len(texts) > 0 {
+		vectors, err := embedder.EmbedStrings(ctx, texts)
+		if err != nil {
+			return 0, err
+		}
+		if len(vectors) != len(texts) {
+			return 0, fmt.Errorf("向量数量不匹配: 请求 %d 条，返回 %d 条", len(texts), len(vectors))
+		}
+		for i := range embeddings {
+			embeddings[i].Vector = normalizeVector(vectors[i])
+			embeddings[i].CreatedAt = time.Now()
+		}
+	}
+	embeddings = append(embeddings, blank...)
+
+	// 读取内容后条目被修改的，丢弃本次结果，条目仍在待处理列表中
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fresh := embeddings[:0]
+	for _, embedding := range embeddings {
+		if !s.stale[embedding.ItemID] {
+			fresh = append(fresh, embedding)
+		}
+	}
+	embeddings = fresh
+	if err := s.repo.SaveEmbeddings(embeddings); err != nil {
+		return 0, err
+	}
+	if s.index != nil && s.index.model == embedder.Name() {
+		s.index.add(embeddings)
+	}
+	log.Printf("🧭 已为 %d 个条目生成向量", len(embeddings))
+	return len(embeddings), nil
+}
+
+// embedder 当前的向量模型，未启用语义搜索时返回 model.ErrEmbeddingDisabled
+func (s *embeddingService) embedder() (model.Embedder, error) {
+	s.mu.Lock()
+	enabled := s.settings.Enabled
+	s.mu.Unlock()
+	if !enabled {
+		return nil, model.ErrEmbeddingDisabled
+	}
+	return s.embedders.Embedder()
+}
+
+// Search 按余弦相似度返回最相似的 k 个条目，首次检索时从数据库加载当前模型的全部向量
+func (s *embeddingService) Search(ctx context.Context, query string, k int) ([]models.SemanticMatch, error) {
+	embedder, err := s.embedder()
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New("向量模型没有返回查询向量")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index == nil || s.index.model != embedder.Name() {
+		embeddings, err := s.repo.ListEmbeddings(embedder.Name())
+		if err != nil {
+			return nil, err
+		}
+		s.index = newVectorIndex(embedder.Name())
+		s.index.add(embeddings)
+		log.Printf("🧭 已加载 %d 个条目向量（%s）", len(embeddings), embedder.Name())
+	}
+	return s.index.search(normalizeVector(vectors[0]), k), nil
+}
+
+// Status 返回语义索引状态
+func (s *embeddingService) Status() (models.EmbeddingStatus, error) {
+	s.mu.Lock()
+	status := models.EmbeddingStatus{
+		Enabled:   s.settings.Enabled,
+		Model:     s.settings.Model,
+		Running:   s.cancel != nil,
+		LastError: s.lastError,
+	}
+	s.mu.Unlock()
+	if !status.Enabled {
+		return status, nil
+	}
+
+	embedder, err := s.embedders.Embedder()
+	if err != nil {
+		status.LastError = err.Error()
+		return status, nil
+	}
+	status.Model = embedder.Name()
+	status.Indexed, status.Pending, err = s.repo.CountEmbeddings(embedder.Name())
+	return status, err
+}
+
+// setLastError 记录最近一次失败原因，成功后清空
+func (s *embeddingService) setLastError(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = message
+}
+
+// embeddingText 发送给向量模型的文本，图片和敏感条目不会进入待处理列表
+func embeddingText(item models.ClipboardItem, maxChars int) string {
+	return strings.TrimSpace(truncateRunes(item.Content, maxChars))
+}
+
+// normalizeVector 转换为 float32 并归一化为单位向量，之后余弦相似度等于点积
+func normalizeVector(vector []float64) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vector {
+		normalized[i] = float32(v / norm)
+	}
+	return normalized
+}
+
+// vectorIndex 内存中的向量索引，逐个计算点积取前 k 个；剪切板历史规模下比近似索引更简单且结果精确
+type vectorIndex struct {
+	model   string
+	vectors map[string][]float32
+}
+
+// newVectorIndex 创建指定模型的空索引
+func newVectorIndex(model string) *vectorIndex {
+	return &vectorIndex{model: model, vectors: make(map[string][]float32)}
+}
+
+// add 加入或替换条目向量
+func (x *vectorIndex) add(embeddings []models.ItemEmbedding) {
+	for _, embedding := range embeddings {
+		x.vectors[embedding.ItemID] = embedding.Vector
+	}
+}
+
+// remove 移除条目向量
+func (x *vectorIndex) remove(itemID string) {
+	delete(x.vectors, itemID)
+}
+
+// search 返回与 query 点积最大的 k 个条目，按相似度从高到低排列，维度不一致的向量跳过
+func (x *vectorIndex) search(query []float32, k int) []models.SemanticMatch {
+	if k <= 0 {
+		return nil
+	}
+	top := &matchHeap{}
+	for id, vector := range x.vectors {
+		if len(vector) != len(query) {
+			continue
+		}
+		var score float64
+		for i, v := range vector {
+			score += float64(v) * float64(query[i])
+		}
+		if top.Len() < k {
+			heap.Push(top, models.SemanticMatch{ItemID: id, Score: score})
+		} else if score > (*top)[0].Score {
+			(*top)[0] = models.SemanticMatch{ItemID: id, Score: score}
+			heap.Fix(top, 0)
+		}
+	}
+
+	matches := make([]models.SemanticMatch, top.Len())
+	for i := len(matches) - 1; i >= 0; i-- {
+		matches[i] = heap.Pop(top).(models.SemanticMatch)
+	}
+	return matches
+}
+
+// matchHeap 按相似度排列的小顶堆，堆顶是当前前 k 个中最不相似的
+type matchHeap []models.SemanticMatch
+
+func (h matchHeap) Len() int { return len(h) }
+func (h matchHeap) Less(i, j int) bool {
+	if h[i].Score != h[j].Score {
+		return h[i].Score < h[j].Score
+	}
+	return h[i].ItemID > h[j].ItemID
+}
+func (h matchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(models.SemanticMatch)) }
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// newTestEmbeddingService 创建使用本地哈希向量的语义索引，并接入剪切板服务
+func newTestEmbeddingService(t *testing.T) (*clipboardService, EmbeddingService) {
+	t.Helper()
+	service, _, db := newTestClipboardService(t)
+	embedders := model.NewEmbedderProvider(func() (model.Embedder, error) {
+		return model.NewHashEmbedder(256), nil
+	})
+	options := DefaultEmbeddingOptions()
+	options.PollInterval = 20 * time.Millisecond
+	embeddings := NewEmbeddingService(repository.NewEmbeddingRepository(db.DB, db.Cipher()), service.repo, embedders, options)
+	embeddings.UpdateSettings(models.EmbeddingSettings{Enabled: true, HybridWeight: 0.5})
+	service.SetEmbeddings(embeddings)
+	return service, embeddings
+}
+
+// waitForIndexed 启动语义索引并等待全部条目生成向量
+func waitForIndexed(t *testing.T, embeddings EmbeddingService, want int) {
+	t.Helper()
+	if err := embeddings.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(embeddings.Stop)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := embeddings.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Indexed == want && status.Pending == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for index, last: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEmbeddingService_SemanticSearch(t *testing.T) {
+	service, embeddings := newTestEmbeddingService(t)
+	sql := createTestItem(t, service.repo, "SELECT * FROM orders JOIN users ON users.id = orders.user_id", false)
+	createTestItem(t, service.repo, "今天的购物清单：牛奶、面包", false)
+	secret := createTestItem(t, service.repo, "orders users password", true)
+	waitForIndexed(t, embeddings, 2)
+
+	result, err := service.SemanticSearch("join users with orders", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) == 0 || result.Items[0].ID != sql || result.Items[0].Score <= 0 {
+		t.Fatalf("result = %+v", result.Items)
+	}
+	for _, item := range result.Items {
+		if item.ID == secret {
+			t.Error("sensitive item must not be indexed")
+		}
+	}
+
+	// 新条目唤醒后台索引后可以被检索
+	added, err := service.CreateItem("users and orders report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForIndexed(t, embeddings, 3)
+	result, err = service.SearchItems(models.SearchQuery{Query: "orders users", Mode: models.SearchModeSemantic, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total < 2 || len(result.Items) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.Items[0].ID != added.ID && result.Items[0].ID != sql {
+		t.Errorf("unexpected top item %+v", result.Items[0])
+	}
+}
+
+func TestEmbeddingService_UpdateItemInvalidatesVector(t *testing.T) {
+	service, embeddings := newTestEmbeddingService(t)
+	id := createTestItem(t, service.repo, "kubectl get pods", false)
+	createTestItem(t, service.repo, "shopping list milk bread", false)
+	waitForIndexed(t, embeddings, 2)
+
+	score := func(query string) float64 {
+		t.Helper()
+		result, err := service.SemanticSearch(query, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range result.Items {
+			if item.ID == id {
+				return item.Score
+			}
+		}
+		return 0
+	}
+	if got := score("kubectl pods"); got < 0.3 {
+		t.Fatalf("expected item to match its original content, score %v", got)
+	}
+
+	item, err := service.GetItem(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Content = "weekend hiking trip plan"
+	if err := service.UpdateItem(*item); err != nil {
+		t.Fatal(err)
+	}
+	waitForIndexed(t, embeddings, 2)
+
+	if got := score("kubectl pods"); got >= 0.3 {
+		t.Errorf("stale vector still matches old content, score %v", got)
+	}
+	if got := score("hiking trip"); got < 0.3 {
+		t.Errorf("expected vector for new content, score %v", got)
+	}
+}
+
+func TestEmbeddingService_HybridSearch(t *testing.T) {
+	service, embeddings := newTestEmbeddingService(t)
+	keyword := createTestItem(t, service.repo, "kubectl get pods", false)
+	semantic := createTestItem(t, service.repo, "pods pods pods running in the cluster", false)
+	createTestItem(t, service.repo, "今天的购物清单", false)
+	waitForIndexed(t, embeddings, 3)
+
+	result, err := service.SearchItems(models.SearchQuery{Query: "kubectl", Mode: models.SearchModeHybrid, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) == 0 || result.Items[0].ID != keyword || result.Items[0].Score <= 0 {
+		t.Fatalf("result = %+v", result.Items)
+	}
+
+	// 关键词没有命中的条目也可以按向量相似度返回
+	result, err = service.SearchItems(models.SearchQuery{Query: "cluster pods", Mode: models.SearchModeHybrid, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, item := range result.Items {
+		found[item.ID] = true
+	}
+	if !found[keyword] || !found[semantic] {
+		t.Errorf("result = %+v", result.Items)
+	}
+
+	// 过滤条件在向量命中的条目上同样生效
+	result, err = service.SearchItems(models.SearchQuery{Query: "pods", Mode: models.SearchModeHybrid, Category: models.CategoryCode, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 0 {
+		t.Errorf("category filter ignored: %+v", result.Items)
+	}
+}
+
+func TestEmbeddingService_DisabledFallsBackToKeyword(t *testing.T) {
+	service, embeddings := newTestEmbeddingService(t)
+	embeddings.UpdateSettings(models.EmbeddingSettings{Enabled: false})
+	id := createTestItem(t, service.repo, "kubectl get pods", false)
+
+	result, err := service.SearchItems(models.SearchQuery{Query: "kubectl", Mode: models.SearchModeSemantic, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != id {
+		t.Errorf("result = %+v", result.Items)
+	}
+	if _, err := service.SemanticSearch("kubectl", 5); err != model.ErrEmbeddingDisabled {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// shortEmbedder 返回的向量比请求的文本少一条，模拟行为异常的 OpenAI 兼容服务
+type shortEmbedder struct{ model.Embedder }
+
+func (e shortEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	vectors, err := e.Embedder.EmbedStrings(ctx, texts, opts...)
+	if err != nil || len(vectors) == 0 {
+		return vectors, err
+	}
+	return vectors[:len(vectors)-1], nil
+}
+
+func TestEmbeddingService_VectorCountMismatch(t *testing.T) {
+	service, _, db := newTestClipboardService(t)
+	embedders := model.NewEmbedderProvider(func() (model.Embedder, error) {
+		return shortEmbedder{model.NewHashEmbedder(64)}, nil
+	})
+	embeddings := NewEmbeddingService(repository.NewEmbeddingRepository(db.DB, db.Cipher()), service.repo, embedders, DefaultEmbeddingOptions())
+	embeddings.UpdateSettings(models.EmbeddingSettings{Enabled: true})
+	createTestItem(t, service.repo, "kubectl get pods", false)
+	createTestItem(t, service.repo, "shopping list", false)
+
+	n, err := embeddings.(*embeddingService).indexPending(context.Background())
+	if err == nil || n != 0 {
+		t.Fatalf("expected mismatch error, got n=%d err=%v", n, err)
+	}
+	status, err := embeddings.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Indexed != 0 || status.Pending != 2 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestVectorIndex_Search(t *testing.T) {
+	index := newVectorIndex("m")
+	index.add([]models.ItemEmbedding{
+		{ItemID: "a", Vector: []float32{1, 0}},
+		{ItemID: "b", Vector: []float32{0.6, 0.8}},
+		{ItemID: "c", Vector: []float32{0, 1}},
+		{ItemID: "d", Vector: []float32{1, 0, 0}},
+	})
+	matches := index.search([]float32{1, 0}, 2)
+	if len(matches) != 2 || matches[0].ItemID != "a" || matches[1].ItemID != "b" {
+		t.Errorf("matches = %+v", matches)
+	}
+}
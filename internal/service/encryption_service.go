@@ -0,0 +1,226 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"Sid/internal/config"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// EncryptionService 数据库加密管理：启用、解锁和关闭
+type EncryptionService interface {
+	GetStatus() (models.EncryptionStatus, error)
+	Enable(keySource, passphrase string) error
+	Unlock(passphrase string) error
+	Disable() error
+	AutoUnlock() error
+}
+
+// encryptionService 加密管理实现，keyring 模式的数据密钥保存在系统密钥环中
+// legacy 是旧版本保存数据密钥的本地加密文件，解锁时迁移到系统密钥环
+// 不再使用的数据密钥不会删除，而是归档保留，启用加密期间生成的备份恢复后仍可解锁
+type encryptionService struct {
+	db      *repository.Database
+	keyring config.SecretStore
+	legacy  config.SecretStore
+}
+
+// NewEncryptionService 创建新的加密管理服务
+func NewEncryptionService(db *repository.Database, keyring, legacy config.SecretStore) EncryptionService {
+	return &encryptionService{db: db, keyring: keyring, legacy: legacy}
+}
+
+// GetStatus 获取加密状态
+func (s *encryptionService) GetStatus() (models.EncryptionStatus, error) {
+	return s.db.EncryptionStatus()
+}
+
+// Enable 启用加密并就地加密已有数据
+// keySource 为 passphrase 时使用口令保护数据密钥；为 keyring 时生成随机密钥保存在系统密钥环中
+func (s *encryptionService) Enable(keySource, passphrase string) error {
+	switch keySource {
+	case models.EncryptionKeySourcePassphrase:
+		return s.db.EnablePassphraseEncryption(passphrase)
+	case models.EncryptionKeySourceKeyring:
+		key, err := repository.GenerateDatabaseKey()
+		if err != nil {
+			return err
+		}
+		// 覆盖前归档现有密钥，它可能仍被旧备份使用
+		if err := s.retireCurrentKey(); err != nil {
+			return err
+		}
+		// 先保存密钥再加密数据，避免数据已加密而密钥丢失
+		if err := s.keyring.Set(config.SecretDatabaseKey, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return fmt.Errorf("保存数据库密钥失败，请改用口令加密: %w", err)
+		}
+		if err := s.db.EnableKeyEncryption(key); err != nil {
+			s.keyring.Delete(config.SecretDatabaseKey)
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的密钥来源: %s", keySource)
+	}
+}
+
+// Unlock 解锁数据库，keyring 模式忽略口令
+func (s *encryptionService) Unlock(passphrase string) error {
+	status, err := s.db.EncryptionStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Enabled {
+		return repository.ErrEncryptionDisabled
+	}
+	if !status.Locked {
+		return nil
+	}
+	if status.KeySource == models.EncryptionKeySourceKeyring {
+		return s.unlockFromKeyring()
+	}
+	return s.db.UnlockWithPassphrase(passphrase)
+}
+
+// Disable 解密全部数据并关闭加密，需要先解锁
+// keyring 模式的数据密钥移入归档而不是删除，启用加密期间的备份仍需要它解密
+func (s *encryptionService) Disable() error {
+	status, err := s.db.EncryptionStatus()
+	if err != nil {
+		return err
+	}
+	if status.KeySource == models.EncryptionKeySourceKeyring {
+		// 先归档再解密，归档失败时保持加密状态，避免密钥随后丢失
+		if err := s.retireCurrentKey(); err != nil {
+			return err
+		}
+	}
+	return s.db.DisableEncryption()
+}
+
+// AutoUnlock 启动时自动解锁 keyring 模式的数据库，口令模式需要等待用户输入
+func (s *encryptionService) AutoUnlock() error {
+	status, err := s.db.EncryptionStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Locked || status.KeySource != models.EncryptionKeySourceKeyring {
+		return nil
+	}
+	return s.unlockFromKeyring()
+}
+
+// unlockFromKeyring 依次尝试系统密钥环中的当前密钥和归档密钥解锁
+// 恢复的旧备份可能使用归档密钥；系统密钥环中没有当前密钥时读取旧版本保存在本地文件中的密钥，解锁成功后迁移到系统密钥环
+func (s *encryptionService) unlockFromKeyring() error {
+	encoded, err := s.keyring.Get(config.SecretDatabaseKey)
+	if err != nil {
+		return fmt.Errorf("读取数据库密钥失败: %w", err)
+	}
+	migrate := false
+	if encoded == "" && s.legacy != nil {
+		if encoded, err = s.legacy.Get(config.SecretDatabaseKey); err != nil {
+			return fmt.Errorf("读取数据库密钥失败: %w", err)
+		}
+		migrate = encoded != ""
+	}
+	retired, err := s.retiredKeys()
+	if err != nil {
+		return err
+	}
+
+	candidates := retired
+	if encoded != "" {
+		candidates = append([]string{encoded}, retired...)
+	}
+	if len(candidates) == 0 {
+		return errors.New("系统密钥环中没有数据库密钥")
+	}
+	unlocked := ""
+	for _, candidate := range candidates {
+		key, err := base64.StdEncoding.DecodeString(candidate)
+		if err != nil {
+			log.Printf("⚠️  跳过已损坏的数据库密钥: %v", err)
+			continue
+		}
+		err = s.db.UnlockWithKey(key)
+		if err == nil {
+			unlocked = candidate
+			break
+		}
+		if !errors.Is(err, repository.ErrWrongPassphrase) {
+			return err
+		}
+	}
+	if unlocked == "" {
+		return repository.ErrWrongPassphrase
+	}
+
+	if migrate && unlocked == encoded {
+		// 先写入系统密钥环再删除文件中的副本，任一步失败都保留文件中的密钥
+		if err := s.keyring.Set(config.SecretDatabaseKey, encoded); err != nil {
+			log.Printf("⚠️  迁移数据库密钥到系统密钥环失败: %v", err)
+		} else if err := s.legacy.Delete(config.SecretDatabaseKey); err != nil {
+			log.Printf("⚠️  删除本地文件中的数据库密钥失败: %v", err)
+		} else {
+			log.Printf("🔐 数据库密钥已迁移到系统密钥环")
+		}
+	}
+	return nil
+}
+
+// retiredKeys 读取归档的数据库密钥
+func (s *encryptionService) retiredKeys() ([]string, error) {
+	encoded, err := s.keyring.Get(config.SecretRetiredDatabaseKeys)
+	if err != nil {
+		return nil, fmt.Errorf("读取归档的数据库密钥失败: %w", err)
+	}
+	if encoded == "" {
+		return nil, nil
+	}
+	var keys []string
+	if err := json.Unmarshal([]byte(encoded), &keys); err != nil {
+		return nil, fmt.Errorf("归档的数据库密钥已损坏: %w", err)
+	}
+	return keys, nil
+}
+
+// retireCurrentKey 把系统密钥环中的当前数据密钥移入归档，归档写入成功后才删除当前密钥
+func (s *encryptionService) retireCurrentKey() error {
+	current, err := s.keyring.Get(config.SecretDatabaseKey)
+	if err != nil {
+		return fmt.Errorf("读取数据库密钥失败: %w", err)
+	}
+	if current == "" {
+		return nil
+	}
+	keys, err := s.retiredKeys()
+	if err != nil {
+		return err
+	}
+	archived := false
+	for _, key := range keys {
+		if key == current {
+			archived = true
+			break
+		}
+	}
+	if !archived {
+		data, err := json.Marshal(append(keys, current))
+		if err != nil {
+			return err
+		}
+		if err := s.keyring.Set(config.SecretRetiredDatabaseKeys, string(data)); err != nil {
+			return fmt.Errorf("归档数据库密钥失败: %w", err)
+		}
+	}
+	if err := s.keyring.Delete(config.SecretDatabaseKey); err != nil {
+		log.Printf("⚠️  删除数据库密钥失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+
+	"Sid/internal/config"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// newTestSecretStores 使用内存模拟的系统密钥环和临时目录中的旧版密钥文件
+func newTestSecretStores(t *testing.T) (config.SecretStore, config.SecretStore) {
+	t.Helper()
+	keyring.MockInit()
+	dir := t.TempDir()
+	legacy := config.NewFileSecretStore(filepath.Join(dir, "secrets.json"), filepath.Join(dir, "secret.key"))
+	return config.NewKeyringSecretStore(config.KeyringService), legacy
+}
+
+func TestEncryptionService_Keyring(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	secrets, legacy := newTestSecretStores(t)
+
+	db, err := repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryption := NewEncryptionService(db, secrets, legacy)
+	if err := encryption.Enable("unknown", ""); err == nil {
+		t.Error("expected unknown key source to be rejected")
+	}
+	if err := encryption.Enable(models.EncryptionKeySourceKeyring, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !secrets.Has(config.SecretDatabaseKey) {
+		t.Fatal("expected database key to be stored")
+	}
+	if legacy.Has(config.SecretDatabaseKey) {
+		t.Error("database key must not be written to the local secrets file")
+	}
+	createTestItem(t, repository.NewClipboardRepository(db.DB, db.Cipher()), "secret", false)
+	db.Close()
+
+	// 重新打开后自动解锁
+	db, err = repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	encryption = NewEncryptionService(db, secrets, legacy)
+	if err := encryption.AutoUnlock(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := encryption.GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Enabled || status.Locked {
+		t.Errorf("expected unlocked database, got %+v", status)
+	}
+	items, err := repository.NewClipboardRepository(db.DB, db.Cipher()).List(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Content != "secret" {
+		t.Errorf("unexpected items %+v", items)
+	}
+
+	if err := encryption.Disable(); err != nil {
+		t.Fatal(err)
+	}
+	if secrets.Has(config.SecretDatabaseKey) {
+		t.Error("expected database key to be retired after disabling")
+	}
+	if !secrets.Has(config.SecretRetiredDatabaseKeys) {
+		t.Error("expected database key to be archived after disabling")
+	}
+}
+
+func TestEncryptionService_RestoredBackupUsesRetiredKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	secrets, legacy := newTestSecretStores(t)
+
+	db, err := repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryption := NewEncryptionService(db, secrets, legacy)
+	if err := encryption.Enable(models.EncryptionKeySourceKeyring, ""); err != nil {
+		t.Fatal(err)
+	}
+	createTestItem(t, repository.NewClipboardRepository(db.DB, db.Cipher()), "secret", false)
+	backup := filepath.Join(dir, "backup.db")
+	if _, err := db.Exec(`VACUUM INTO ?`, backup); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭加密后再用新密钥重新启用，备份使用的密钥已不是当前密钥
+	if err := encryption.Disable(); err != nil {
+		t.Fatal(err)
+	}
+	if err := encryption.Enable(models.EncryptionKeySourceKeyring, ""); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	restored, err := repository.NewDatabase(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if err := NewEncryptionService(restored, secrets, legacy).AutoUnlock(); err != nil {
+		t.Fatalf("backup taken while encrypted can no longer be unlocked: %v", err)
+	}
+	items, err := repository.NewClipboardRepository(restored.DB, restored.Cipher()).List(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Content != "secret" {
+		t.Errorf("unexpected items %+v", items)
+	}
+}
+
+func TestEncryptionService_MigratesLegacyKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	secrets, legacy := newTestSecretStores(t)
+
+	// 旧版本把数据密钥保存在本地加密文件中
+	db, err := repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := repository.GenerateDatabaseKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Set(config.SecretDatabaseKey, base64.StdEncoding.EncodeToString(key)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnableKeyEncryption(key); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := NewEncryptionService(db, secrets, legacy).AutoUnlock(); err != nil {
+		t.Fatal(err)
+	}
+	if !secrets.Has(config.SecretDatabaseKey) || legacy.Has(config.SecretDatabaseKey) {
+		t.Error("expected database key to move to the system keyring")
+	}
+}
+
+func TestEncryptionService_KeyringUnavailable(t *testing.T) {
+	secrets, legacy := newTestSecretStores(t)
+	keyring.MockInitWithError(errors.New("no secret service"))
+	db := newTestDB(t)
+
+	err := NewEncryptionService(db, secrets, legacy).Enable(models.EncryptionKeySourceKeyring, "")
+	if !errors.Is(err, config.ErrKeyringUnavailable) {
+		t.Fatalf("err = %v", err)
+	}
+	if legacy.Has(config.SecretDatabaseKey) {
+		t.Error("database key must not fall back to the local secrets file")
+	}
+	status, err := db.EncryptionStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Enabled {
+		t.Error("encryption must stay disabled when the key cannot be stored")
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 实时事件名称，前端通过 Wails 事件订阅，外部客户端通过 /api/v1/events 订阅
+const (
+	EventItemCreated  = "item:created"
+	EventItemUpdated  = "item:updated"
+	EventItemDeleted  = "item:deleted"
+	EventTagsChanged  = "tags:changed"
+	EventMonitorState = "monitor:state"
+	EventChatStream   = "chat:stream"
+)
+
+// Event 事件总线上传递的事件
+type Event struct {
+	Name string      `json:"name"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// EventBus 服务层事件总线，各服务通过 SetEventEmitter(bus.Publish) 接入
+type EventBus interface {
+	// Publish 发布事件，不会阻塞发布方
+	Publish(name string, data interface{})
+	// Subscribe 订阅全部事件，返回事件通道和取消订阅函数
+	Subscribe(buffer int) (<-chan Event, func())
+}
+
+// eventBus 事件总线实现
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber 订阅者，通道写满时丢弃事件
+type subscriber struct {
+	ch      chan Event
+	dropped atomic.Int64
+}
+
+// NewEventBus 创建新的事件总线
+func NewEventBus() EventBus {
+	return &eventBus{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Publish 发布事件，订阅者来不及消费时丢弃该事件而不是阻塞服务
+func (b *eventBus) Publish(name string, data interface{}) {
+	event := Event{Name: name, Data: data, Time: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				log.Printf("⚠️  事件订阅者处理过慢，已丢弃 %d 个事件", dropped)
+			}
+		}
+	}
+}
+
+// Subscribe 订阅全部事件，取消订阅后通道会被关闭
+func (b *eventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	sub := &subscriber{ch: make(chan Event, buffer)}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+func TestEventBus_PublishSubscribe(t *testing.T) {
+	bus := NewEventBus()
+	first, unsubscribeFirst := bus.Subscribe(1)
+	second, unsubscribeSecond := bus.Subscribe(1)
+	defer unsubscribeSecond()
+
+	bus.Publish(EventItemCreated, "a")
+	// 缓冲区已满，发布方不阻塞，事件被丢弃
+	bus.Publish(EventItemUpdated, "b")
+
+	for _, ch := range []<-chan Event{first, second} {
+		event := <-ch
+		if event.Name != EventItemCreated || event.Data != "a" || event.Time.IsZero() {
+			t.Fatalf("event = %+v", event)
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Fatal("channel should be closed after unsubscribe")
+	}
+	bus.Publish(EventItemDeleted, "c")
+	if event := <-second; event.Name != EventItemDeleted {
+		t.Fatalf("event = %+v", event)
+	}
+}
+
+func TestClipboardService_PublishesEvents(t *testing.T) {
+	service, tagService, _ := newTestClipboardService(t)
+
+	bus := NewEventBus()
+	service.SetEventEmitter(bus.Publish)
+	tagService.SetEventEmitter(bus.Publish)
+	events, unsubscribe := bus.Subscribe(16)
+	defer unsubscribe()
+	next := func() Event {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+			return Event{}
+		}
+	}
+
+	item, err := service.CreateItem("event content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Name != EventItemCreated || event.Data.(models.ClipboardItem).ID != item.ID {
+		t.Fatalf("created event = %+v", event)
+	}
+
+	if _, err := tagService.ImportTag(models.Tag{Name: "事件"}); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Name != EventTagsChanged || event.Data.(models.TagsChangedEvent).ItemID != "" {
+		t.Fatalf("tag created event = %+v", event)
+	}
+	if err := tagService.AddTagsToItem(item.ID, []string{"事件"}); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Name != EventTagsChanged || event.Data.(models.TagsChangedEvent).ItemID != item.ID {
+		t.Fatalf("item tags event = %+v", event)
+	}
+
+	if err := service.DeleteItem(item.ID); err != nil {
+		t.Fatal(err)
+	}
+	deleted := next()
+	if data, ok := deleted.Data.(models.ItemDeletedEvent); deleted.Name != EventItemDeleted || !ok || data.Permanent || len(data.IDs) != 1 {
+		t.Fatalf("deleted event = %+v", deleted)
+	}
+
+	if err := service.RestoreItem(item.ID); err != nil {
+		t.Fatal(err)
+	}
+	updated := next()
+	if data, ok := updated.Data.(*models.ClipboardItem); updated.Name != EventItemUpdated || !ok || data.IsDeleted {
+		t.Fatalf("updated event = %+v", updated)
+	}
+
+	if err := service.EmptyTrash(); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Name != EventItemDeleted || !event.Data.(models.ItemDeletedEvent).EmptiedTrash {
+		t.Fatalf("empty trash event = %+v", event)
+	}
+}
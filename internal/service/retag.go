@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// EventRetagProgress 批量重新打标签进度事件名称
+const EventRetagProgress = "retag:progress"
+
+const (
+	// retagPageSize 收集待处理条目时每页查询的数量
+	retagPageSize = 200
+	// defaultRetagConcurrency 默认并发数
+	defaultRetagConcurrency = 2
+)
+
+// retagTarget 待处理条目，skipReason 不为空时直接跳过
+type retagTarget struct {
+	id         string
+	skipReason string
+}
+
+// retagJob 批量重新打标签任务，同一时间只允许一个任务执行
+type retagJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	summary models.RetagSummary
+	resume  chan struct{}   // 暂停期间阻塞，恢复时关闭
+	known   map[string]bool // 任务开始前已存在的标签名称，用于区分新建与合并
+	created map[string]bool
+	merged  map[string]bool
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *clipboardService) SetEventEmitter(emit EventEmitter) {
+	s.retagMu.Lock()
+	defer s.retagMu.Unlock()
+	s.emit = emit
+}
+
+// StartRetag 对符合查询条件的历史条目重新生成AI标签，任务在后台执行
+// 取消 ctx 或调用 CancelRetag 都会终止任务
+func (s *clipboardService) StartRetag(ctx context.Context, options models.RetagOptions) error {
+	if job := s.currentRetag(); job != nil && job.active() {
+		return errors.New("已有批量打标签任务正在执行")
+	}
+
+	// 收集条目可能较慢，在加锁前完成，以免阻塞进度事件的推送
+	targets, err := s.collectRetagTargets(options.Query)
+	if err != nil {
+		return err
+	}
+	tags, err := s.tagService.GetTags()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		known[tag.Name] = true
+	}
+
+	s.retagMu.Lock()
+	defer s.retagMu.Unlock()
+	if s.retag != nil && s.retag.active() {
+		return errors.New("已有批量打标签任务正在执行")
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultRetagConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	job := &retagJob{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		known:   known,
+		created: make(map[string]bool),
+		merged:  make(map[string]bool),
+		summary: models.RetagSummary{
+			State:       models.RetagStateRunning,
+			Total:       len(targets),
+			TagsCreated: []string{},
+			TagsMerged:  []string{},
+			StartedAt:   time.Now(),
+		},
+	}
+	s.retag = job
+
+	log.Printf("🏷️  开始批量重新打标签，共 %d 个条目，并发数: %d", len(targets), concurrency)
+	go s.runRetag(ctx, job, targets, concurrency)
+	return nil
+}
+
+// PauseRetag 暂停批量打标签任务，执行中的条目会继续完成
+func (s *clipboardService) PauseRetag() error {
+	job := s.currentRetag()
+	if job == nil {
+		return errors.New("没有正在执行的批量打标签任务")
+	}
+	if err := job.pause(); err != nil {
+		return err
+	}
+	s.publishRetag(models.RetagProgress{Summary: job.snapshot()})
+	return nil
+}
+
+// ResumeRetag 恢复已暂停的批量打标签任务
+func (s *clipboardService) ResumeRetag() error {
+	job := s.currentRetag()
+	if job == nil {
+		return errors.New("没有正在执行的批量打标签任务")
+	}
+	if err := job.unpause(); err != nil {
+		return err
+	}
+	s.publishRetag(models.RetagProgress{Summary: job.snapshot()})
+	return nil
+}
+
+// CancelRetag 取消批量打标签任务并等待工作协程退出
+func (s *clipboardService) CancelRetag() error {
+	job := s.currentRetag()
+	if job == nil || !job.active() {
+		return errors.New("没有正在执行的批量打标签任务")
+	}
+	job.cancel()
+	<-job.done
+	return nil
+}
+
+// GetRetagStatus 获取当前或最近一次批量打标签任务的进度
+func (s *clipboardService) GetRetagStatus() models.RetagSummary {
+	job := s.currentRetag()
+	if job == nil {
+		return models.RetagSummary{State: models.RetagStateIdle, TagsCreated: []string{}, TagsMerged: []string{}}
+	}
+	return job.snapshot()
+}
+
+// currentRetag 获取当前任务
+func (s *clipboardService) currentRetag() *retagJob {
+	s.retagMu.Lock()
+	defer s.retagMu.Unlock()
+	return s.retag
+}
+
+// collectRetagTargets 分页收集符合条件的条目，先收集再处理以免打标签后分页错位
+func (s *clipboardService) collectRetagTargets(query models.SearchQuery) ([]retagTarget, error) {
+	query.Limit = retagPageSize
+	query.Offset = 0
+
+	var targets []retagTarget
+	seen := make(map[string]bool)
+	for {
+		result, err := s.repo.Search(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+
+			target := retagTarget{id: item.ID}
+			switch {
+			case item.IsSensitive:
+				target.skipReason = "敏感内容不发送给AI"
+			case item.IsImage():
+				target.skipReason = "图片条目暂不支持"
+			}
+			targets = append(targets, target)
+		}
+		if len(result.Items) < retagPageSize {
+			return targets, nil
+		}
+		query.Offset += retagPageSize
+	}
+}
+
+// runRetag 分发条目给工作协程，暂停时停止分发，取消时等待执行中的条目结束
+func (s *clipboardService) runRetag(ctx context.Context, job *retagJob, targets []retagTarget, concurrency int) {
+	defer close(job.done)
+	defer job.cancel()
+
+	queue := make(chan retagTarget)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				// 暂停前已分发的条目同样等待恢复
+				if err := job.waitIfPaused(ctx); err != nil {
+					return
+				}
+				s.retagItem(ctx, job, target)
+			}
+		}()
+	}
+
+dispatch:
+	for _, target := range targets {
+		if err := job.waitIfPaused(ctx); err != nil {
+			break
+		}
+		select {
+		case queue <- target:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	summary := job.finish(ctx.Err() != nil)
+	log.Printf("✅ 批量重新打标签结束（%s）: 处理 %d/%d，成功 %d，跳过 %d，失败 %d，新建标签 %d 个，合并到已有标签 %d 个",
+		summary.State, summary.Processed, summary.Total, summary.Tagged, summary.Skipped, summary.Failed,
+		len(summary.TagsCreated), len(summary.TagsMerged))
+	s.publishRetag(models.RetagProgress{Summary: summary})
+}
+
+// retagItem 为单个条目生成标签并记录结果
+func (s *clipboardService) retagItem(ctx context.Context, job *retagJob, target retagTarget) {
+	progress := models.RetagProgress{ItemID: target.id}
+	if target.skipReason != "" {
+		progress.Status = models.TaggingStatusSkipped
+		progress.Error = target.skipReason
+		progress.Summary = job.record(progress)
+		s.publishRetag(progress)
+		return
+	}
+
+	// 与后台打标签队列共用调用大模型的频率限制
+	if s.tagging != nil {
+		if err := s.tagging.Throttle(ctx); err != nil {
+			return
+		}
+	}
+
+	tags, err := s.GenerateTagsForItem(ctx, target.id)
+	if err != nil && ctx.Err() != nil {
+		// 任务取消导致的中断不计入结果
+		return
+	}
+	if err != nil {
+		progress.Status = models.TaggingStatusFailed
+		progress.Error = err.Error()
+	} else {
+		progress.Status = models.TaggingStatusDone
+		progress.Tags = tags
+	}
+	progress.Summary = job.record(progress)
+	s.publishRetag(progress)
+}
+
+// publishRetag 推送批量打标签进度事件
+func (s *clipboardService) publishRetag(progress models.RetagProgress) {
+	s.publish(EventRetagProgress, progress)
+}
+
+// active 任务是否仍在执行（包括暂停）
+func (j *retagJob) active() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.summary.State == models.RetagStateRunning || j.summary.State == models.RetagStatePaused
+}
+
+// pause 暂停分发新条目
+func (j *retagJob) pause() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.summary.State != models.RetagStateRunning {
+		return errors.New("批量打标签任务未在执行")
+	}
+	j.summary.State = models.RetagStatePaused
+	j.resume = make(chan struct{})
+	return nil
+}
+
+// unpause 恢复分发
+func (j *retagJob) unpause() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.summary.State != models.RetagStatePaused {
+		return errors.New("批量打标签任务未暂停")
+	}
+	j.summary.State = models.RetagStateRunning
+	close(j.resume)
+	return nil
+}
+
+// waitIfPaused 暂停时阻塞直到恢复或取消
+func (j *retagJob) waitIfPaused(ctx context.Context) error {
+	j.mu.Lock()
+	paused, resume := j.summary.State == models.RetagStatePaused, j.resume
+	j.mu.Unlock()
+	if !paused {
+		return ctx.Err()
+	}
+
+	select {
+	case <-resume:
+		return ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// record 累计单个条目的结果，区分新建的标签和关联到已有标签的标签
+func (j *retagJob) record(progress models.RetagProgress) models.RetagSummary {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.summary.Processed++
+	switch progress.Status {
+	case models.TaggingStatusSkipped:
+		j.summary.Skipped++
+	case models.TaggingStatusFailed:
+		j.summary.Failed++
+		j.summary.LastError = progress.Error
+	default:
+		j.summary.Tagged++
+	}
+
+	for _, name := range progress.Tags {
+		switch {
+		case j.known[name] && !j.merged[name]:
+			j.merged[name] = true
+			j.summary.TagsMerged = append(j.summary.TagsMerged, name)
+		case !j.known[name] && !j.created[name]:
+			j.created[name] = true
+			j.summary.TagsCreated = append(j.summary.TagsCreated, name)
+		}
+	}
+	return j.snapshotLocked()
+}
+
+// finish 标记任务结束
+func (j *retagJob) finish(cancelled bool) models.RetagSummary {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.summary.State = models.RetagStateCompleted
+	if cancelled {
+		j.summary.State = models.RetagStateCancelled
+	}
+	now := time.Now()
+	j.summary.FinishedAt = &now
+	return j.snapshotLocked()
+}
+
+// snapshot 获取进度副本
+func (j *retagJob) snapshot() models.RetagSummary {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snapshotLocked()
+}
+
+// snapshotLocked 获取进度副本，调用方需持有锁
+func (j *retagJob) snapshotLocked() models.RetagSummary {
+	summary := j.summary
+	summary.TagsCreated = append([]string{}, j.summary.TagsCreated...)
+	summary.TagsMerged = append([]string{}, j.summary.TagsMerged...)
+	return summary
+}
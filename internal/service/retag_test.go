@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// fakeChatService 只实现 GenerateTags，release 不为空时每次调用先通知 started 再等待放行
+type fakeChatService struct {
+	ChatService
+	tags    []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *fakeChatService) GenerateTags(ctx context.Context, message string) ([]string, error) {
+	if f.release != nil {
+		f.started <- struct{}{}
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return f.tags, nil
+}
+
+// retagTestInterval 测试中打标签队列调用大模型的最小间隔
+const retagTestInterval = 20 * time.Millisecond
+
+func newTestRetagService(t *testing.T, chat ChatService) (*clipboardService, TagService, *sync.Map) {
+	t.Helper()
+	service, tagService, _ := newTestClipboardService(t, withChatService(chat), withTaggingOptions(TaggingOptions{MinInterval: retagTestInterval}))
+
+	events := &sync.Map{}
+	service.SetEventEmitter(func(name string, data interface{}) {
+		if progress, ok := data.(models.RetagProgress); ok && name == EventRetagProgress {
+			events.Store(progress.Summary.State, progress.Summary)
+		}
+	})
+	return service, tagService, events
+}
+
+// waitForRetag 等待批量打标签任务达到期望状态
+func waitForRetag(t *testing.T, service ClipboardService, check func(models.RetagSummary) bool) models.RetagSummary {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		summary := service.GetRetagStatus()
+		if check(summary) {
+			return summary
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for retag, last: %+v", summary)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClipboardService_Retag(t *testing.T) {
+	chat := &fakeChatService{tags: []string{"工作", "新标签"}}
+	service, tagService, events := newTestRetagService(t, chat)
+
+	if _, err := tagService.GetOrCreateTagByName("工作", "ai-generated"); err != nil {
+		t.Fatal(err)
+	}
+	tagged := createTestItem(t, service.repo, "already tagged", false)
+	if err := tagService.UpdateItemTags(tagged, []string{"工作"}, "ai-generated"); err != nil {
+		t.Fatal(err)
+	}
+	first := createTestItem(t, service.repo, "first", false)
+	createTestItem(t, service.repo, "P@ssw0rd!", true)
+	createTestItem(t, service.repo, "second", false)
+
+	err := service.StartRetag(context.Background(), models.RetagOptions{Query: models.SearchQuery{Untagged: true}, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := waitForRetag(t, service, func(s models.RetagSummary) bool { return s.State == models.RetagStateCompleted })
+
+	if summary.Total != 3 || summary.Tagged != 2 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(summary.TagsCreated) != 1 || summary.TagsCreated[0] != "新标签" {
+		t.Errorf("expected one created tag, got %v", summary.TagsCreated)
+	}
+	if len(summary.TagsMerged) != 1 || summary.TagsMerged[0] != "工作" {
+		t.Errorf("expected one merged tag, got %v", summary.TagsMerged)
+	}
+	if _, ok := events.Load(models.RetagStateCompleted); !ok {
+		t.Error("expected completed progress event")
+	}
+
+	tags, err := tagService.GetTagsForItem(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Errorf("expected 2 tags on item, got %d", len(tags))
+	}
+}
+
+func TestClipboardService_RetagPauseAndCancel(t *testing.T) {
+	chat := &fakeChatService{tags: []string{"测试"}, started: make(chan struct{}, 3), release: make(chan struct{})}
+	service, _, events := newTestRetagService(t, chat)
+	for _, content := range []string{"one", "two", "three"} {
+		createTestItem(t, service.repo, content, false)
+	}
+
+	if err := service.StartRetag(context.Background(), models.RetagOptions{Concurrency: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.StartRetag(context.Background(), models.RetagOptions{}); err == nil {
+		t.Error("expected second job to be rejected")
+	}
+
+	// 第一个条目执行中暂停，放行后不再处理新的条目
+	<-chat.started
+	if err := service.PauseRetag(); err != nil {
+		t.Fatal(err)
+	}
+	chat.release <- struct{}{}
+	waitForRetag(t, service, func(s models.RetagSummary) bool { return s.Processed == 1 })
+	time.Sleep(50 * time.Millisecond)
+	if summary := service.GetRetagStatus(); summary.Processed != 1 || summary.State != models.RetagStatePaused {
+		t.Fatalf("expected paused job with one processed item, got %+v", summary)
+	}
+
+	if err := service.ResumeRetag(); err != nil {
+		t.Fatal(err)
+	}
+	<-chat.started
+	chat.release <- struct{}{}
+	waitForRetag(t, service, func(s models.RetagSummary) bool { return s.Processed == 2 })
+
+	if err := service.CancelRetag(); err != nil {
+		t.Fatal(err)
+	}
+	summary := service.GetRetagStatus()
+	if summary.State != models.RetagStateCancelled || summary.Processed != 2 || summary.FinishedAt == nil {
+		t.Errorf("unexpected summary after cancel %+v", summary)
+	}
+	if _, ok := events.Load(models.RetagStatePaused); !ok {
+		t.Error("expected paused progress event")
+	}
+}
+
+func TestClipboardService_RetagSharesTaggingLimiter(t *testing.T) {
+	chat := &fakeChatService{tags: []string{"测试"}}
+	service, _, _ := newTestRetagService(t, chat)
+	for _, content := range []string{"one", "two", "three", "four"} {
+		createTestItem(t, service.repo, content, false)
+	}
+
+	// 并发数不影响调用间隔，4 个条目至少间隔 3 次
+	start := time.Now()
+	if err := service.StartRetag(context.Background(), models.RetagOptions{Concurrency: 4}); err != nil {
+		t.Fatal(err)
+	}
+	summary := waitForRetag(t, service, func(s models.RetagSummary) bool { return s.State == models.RetagStateCompleted })
+	if summary.Tagged != 4 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if elapsed := time.Since(start); elapsed < 3*retagTestInterval {
+		t.Errorf("expected calls to be spaced by the tagging queue limiter, took %v", elapsed)
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// EventRetentionCompleted 自动清理完成事件名称
+const EventRetentionCompleted = "retention:completed"
+
+// expiryCheckInterval 检查自动过期条目的间隔，独立于清理间隔以便及时删除敏感内容
+const expiryCheckInterval = time.Minute
+
+// RetentionService 按保留策略定期清理历史条目
+type RetentionService interface {
+	Start(ctx context.Context)
+	Stop()
+	RunNow() (models.RetentionReport, error)
+	UpdateSettings(settings *models.Settings)
+	SetEventEmitter(emit EventEmitter)
+}
+
+// retentionService 保留策略实现：分类过期和超出条目上限的条目移入回收站，回收站中过期的条目永久删除
+type retentionService struct {
+	repo repository.ClipboardRepository
+
+	mu       sync.Mutex
+	settings *models.Settings
+	emit     EventEmitter
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	runMu  sync.Mutex
+	reload chan struct{}
+}
+
+// NewRetentionService 创建新的保留策略服务
+func NewRetentionService(repo repository.ClipboardRepository, settings *models.Settings) RetentionService {
+	return &retentionService{
+		repo:     repo,
+		settings: settings,
+		reload:   make(chan struct{}, 1),
+	}
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *retentionService) SetEventEmitter(emit EventEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// UpdateSettings 更新保留策略，并按新策略立即执行一次清理
+func (s *retentionService) UpdateSettings(settings *models.Settings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动定期清理协程，启动时先执行一次
+func (s *retentionService) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.janitor(ctx, s.done)
+}
+
+// Stop 停止定期清理
+func (s *retentionService) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// janitor 按设置的间隔执行清理，间隔为 0 时只等待设置变化；自动过期的条目每分钟检查一次
+func (s *retentionService) janitor(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	expiry := time.NewTicker(expiryCheckInterval)
+	defer expiry.Stop()
+
+	for {
+		s.runAndPublish(s.RunNow)
+
+		var timer *time.Timer
+		var tick <-chan time.Time
+		if interval := s.interval(); interval > 0 {
+			timer = time.NewTimer(interval)
+			tick = timer.C
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				break wait
+			case <-s.reload:
+				break wait
+			case <-tick:
+				break wait
+			case <-expiry.C:
+				s.runAndPublish(s.deleteExpired)
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// interval 获取当前清理间隔
+func (s *retentionService) interval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.settings.Retention.IntervalMinutes) * time.Minute
+}
+
+// runAndPublish 执行清理并在有条目被移除时推送事件
+func (s *retentionService) runAndPublish(run func() (models.RetentionReport, error)) {
+	report, err := run()
+	if err != nil {
+		log.Printf("❌ 自动清理失败: %v", err)
+		return
+	}
+	if report.Removed() == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+	if emit != nil {
+		emit(EventRetentionCompleted, report)
+	}
+}
+
+// RunNow 立即按当前策略执行一次清理并返回结果
+func (s *retentionService) RunNow() (models.RetentionReport, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	s.mu.Lock()
+	settings := *s.settings
+	s.mu.Unlock()
+
+	now := time.Now()
+	report := models.RetentionReport{Expired: make(map[string]int), RanAt: now}
+
+	// 分类过期
+	categories := make([]string, 0, len(settings.Retention.CategoryTTLHours))
+	for category := range settings.Retention.CategoryTTLHours {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		hours := settings.Retention.CategoryTTLHours[category]
+		if hours <= 0 {
+			continue
+		}
+		n, err := s.repo.ExpireCategory(category, now.Add(-time.Duration(hours)*time.Hour))
+		if err != nil {
+			return report, err
+		}
+		if n > 0 {
+			report.Expired[category] = n
+		}
+	}
+
+	// 条目数量上限
+	n, err := s.repo.TrimToLimit(settings.MaxItems)
+	if err != nil {
+		return report, err
+	}
+	report.Overflow = n
+
+	// 回收站过期
+	if settings.Retention.TrashDays > 0 {
+		n, err := s.repo.PurgeTrash(now.AddDate(0, 0, -settings.Retention.TrashDays))
+		if err != nil {
+			return report, err
+		}
+		report.Purged = n
+	}
+
+	// 自动过期
+	n, err = s.repo.DeleteExpired(now)
+	if err != nil {
+		return report, err
+	}
+	report.Timeout = n
+
+	if report.Removed() > 0 {
+		log.Printf("🧹 自动清理完成: 分类过期 %v，超出上限 %d，回收站永久删除 %d，自动过期删除 %d",
+			report.Expired, report.Overflow, report.Purged, report.Timeout)
+	}
+	return report, nil
+}
+
+// deleteExpired 仅删除已到自动过期时间的条目
+func (s *retentionService) deleteExpired() (models.RetentionReport, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	now := time.Now()
+	report := models.RetentionReport{Expired: make(map[string]int), RanAt: now}
+	n, err := s.repo.DeleteExpired(now)
+	if err != nil {
+		return report, err
+	}
+	report.Timeout = n
+	if n > 0 {
+		log.Printf("🧹 已删除 %d 个自动过期的条目", n)
+	}
+	return report, nil
+}
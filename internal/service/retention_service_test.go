@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+func TestRetentionService_RunNow(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewClipboardRepository(db.DB, db.Cipher())
+
+	for _, content := range []string{"one", "two", "three"} {
+		createTestItem(t, repo, content, false)
+	}
+	favorite := createTestItem(t, repo, "favorite", false)
+	item, err := repo.GetByID(favorite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.IsFavorite = true
+	if err := repo.Update(*item); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := models.DefaultSettings()
+	settings.MaxItems = 2
+	settings.Retention.TrashDays = 0
+	retention := NewRetentionService(repo, &settings)
+
+	report, err := retention.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Overflow != 2 || report.Purged != 0 || report.Removed() != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	// 刚移入回收站的条目未超过保留期限，不会被永久删除
+	settings.Retention.TrashDays = 1
+	retention.UpdateSettings(&settings)
+	report, err = retention.RunNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed() != 0 {
+		t.Errorf("expected nothing removed, got %+v", report)
+	}
+
+	items, err := repo.List(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || !(items[0].ID == favorite || items[1].ID == favorite) {
+		t.Errorf("favorite must survive the cap, got %+v", items)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// RuleService 捕获规则服务接口
+type RuleService interface {
+	ListRules() ([]models.CaptureRule, error)
+	CreateRule(rule models.CaptureRule) (*models.CaptureRule, error)
+	UpdateRule(rule models.CaptureRule) error
+	DeleteRule(id string) error
+	ReorderRules(ids []string) error
+
+	// Engine 当前生效的规则引擎，规则变化后自动重新编译
+	Engine() *clipboard.RuleEngine
+	// Reload 从数据库重新加载规则（如恢复备份后）
+	Reload() error
+}
+
+// ruleService 捕获规则服务实现
+type ruleService struct {
+	repo repository.RuleRepository
+
+	mu     sync.RWMutex
+	engine *clipboard.RuleEngine
+}
+
+// NewRuleService 创建新的捕获规则服务并编译已保存的规则
+func NewRuleService(repo repository.RuleRepository) RuleService {
+	s := &ruleService{repo: repo}
+	if err := s.Reload(); err != nil {
+		log.Printf("⚠️  加载捕获规则失败: %v", err)
+	}
+	return s
+}
+
+// ListRules 按匹配顺序返回全部规则
+func (s *ruleService) ListRules() ([]models.CaptureRule, error) {
+	return s.repo.List()
+}
+
+// CreateRule 创建规则，新规则排在最后
+func (s *ruleService) CreateRule(rule models.CaptureRule) (*models.CaptureRule, error) {
+	normalizeRule(&rule)
+	if err := clipboard.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID = uuid.New().String()
+	rule.SortOrder = 0
+	if len(rules) > 0 {
+		rule.SortOrder = rules[len(rules)-1].SortOrder + 1
+	}
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+	log.Printf("✅ 创建捕获规则: %s", rule.Name)
+	return &rule, s.Reload()
+}
+
+// UpdateRule 更新规则的条件、动作和启用状态，匹配顺序通过 ReorderRules 调整
+func (s *ruleService) UpdateRule(rule models.CaptureRule) error {
+	normalizeRule(&rule)
+	if err := clipboard.ValidateRule(rule); err != nil {
+		return err
+	}
+	existing, err := s.repo.GetByID(rule.ID)
+	if err != nil {
+		return err
+	}
+	rule.SortOrder = existing.SortOrder
+	rule.UpdatedAt = time.Now()
+	if err := s.repo.Update(rule); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// DeleteRule 删除规则
+func (s *ruleService) DeleteRule(id string) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// ReorderRules 按给定的ID顺序调整匹配顺序
+func (s *ruleService) ReorderRules(ids []string) error {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return fmt.Errorf("规则ID重复: %s", id)
+		}
+		seen[id] = true
+	}
+	if err := s.repo.Reorder(ids); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// Engine 当前生效的规则引擎
+func (s *ruleService) Engine() *clipboard.RuleEngine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.engine
+}
+
+// Reload 重新读取并编译规则，编译失败时保留原引擎
+func (s *ruleService) Reload() error {
+	rules, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	engine, err := clipboard.NewRuleEngine(rules)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.engine = engine
+	s.mu.Unlock()
+	return nil
+}
+
+// normalizeRule 去除名称、来源应用和分类的首尾空白
+func normalizeRule(rule *models.CaptureRule) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.SourceApp = strings.TrimSpace(rule.SourceApp)
+	rule.Category = strings.TrimSpace(rule.Category)
+	if rule.Actions == nil {
+		rule.Actions = []models.RuleAction{}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+func TestRuleService_ProcessContent(t *testing.T) {
+	service, tagService, db := newTestClipboardService(t)
+	rules := NewRuleService(repository.NewRuleRepository(db.DB))
+	service.SetCaptureRules(rules)
+
+	ignore, err := rules.CreateRule(models.CaptureRule{Name: "终端密码", Enabled: true, SourceApp: "Terminal", Pattern: `^pass `,
+		Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := rules.CreateRule(models.CaptureRule{Name: "终端", Enabled: true, SourceApp: "Terminal",
+		Actions: []models.RuleAction{{Type: models.RuleActionTag, Value: "命令"}, {Type: models.RuleActionFavorite}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.CreateRule(models.CaptureRule{Name: "无效", Pattern: "(", Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}}); err == nil {
+		t.Fatal("expected invalid pattern to be rejected")
+	}
+	if err := rules.ReorderRules([]string{tag.ID, tag.ID}); err == nil {
+		t.Fatal("expected duplicate IDs to be rejected")
+	}
+
+	// 试运行不保存任何数据
+	result, err := service.TestCaptureRules(models.RuleTestRequest{Content: "pass show github", SourceApp: "terminal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Ignored || len(result.Matched) != 1 || result.Matched[0] != "终端密码" {
+		t.Errorf("dry run = %+v", result)
+	}
+
+	if err := service.ProcessContent("pass show github", clipboard.Source{App: "Terminal"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ProcessContent("ls -la", clipboard.Source{App: "Terminal"}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := service.GetItems(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Content != "ls -la" || !items[0].IsFavorite {
+		t.Fatalf("items = %+v", items)
+	}
+	tags, err := tagService.GetTagsForItem(items[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "命令" {
+		t.Errorf("tags = %+v", tags)
+	}
+
+	// 停用后规则不再生效
+	ignore.Enabled = false
+	if err := rules.UpdateRule(*ignore); err != nil {
+		t.Fatal(err)
+	}
+	result, err = service.TestCaptureRules(models.RuleTestRequest{Content: "pass show github", SourceApp: "Terminal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Ignored || len(result.Tags) != 1 {
+		t.Errorf("dry run after disabling = %+v", result)
+	}
+}
+
+func TestRuleService_TransformRescansSensitiveContent(t *testing.T) {
+	service, _, db := newTestClipboardService(t)
+	rules := NewRuleService(repository.NewRuleRepository(db.DB))
+	service.SetCaptureRules(rules)
+
+	if _, err := rules.CreateRule(models.CaptureRule{Name: "URL 解码", Enabled: true,
+		Actions: []models.RuleAction{{Type: models.RuleActionTransform, Value: "url_decode"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 原文中的卡号被编码，转换后才能检测到
+	if err := service.ProcessContent("card%204111%201111%201111%201111", clipboard.Source{}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := service.GetItems(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("items = %+v", items)
+	}
+	if !items[0].IsSensitive || strings.Contains(items[0].Content, "4111 1111 1111 1111") || strings.Contains(items[0].Title, "1111 1111") {
+		t.Errorf("expected redacted sensitive item, got %+v", items[0])
+	}
+
+	// 转换后命中 skip 规则的内容不保存
+	result, err := service.TestCaptureRules(models.RuleTestRequest{Content: "key%3A%20AKIAIOSFODNN7EXAMPLE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Ignored || !strings.Contains(result.Reason, models.SensitiveDetectorAWSKey) {
+		t.Errorf("dry run = %+v", result)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+
+	model "Sid/internal/agent"
+	"Sid/internal/models"
+)
+
+// semanticCandidates 语义和混合搜索最少取回的候选条目数，过滤条件在候选集合上生效
+const semanticCandidates = 50
+
+// SetEmbeddings 设置语义索引，未设置时只支持关键词搜索
+func (s *clipboardService) SetEmbeddings(embeddings EmbeddingService) {
+	s.embeddings = embeddings
+}
+
+// notifyEmbeddings 有新条目时唤醒语义索引
+func (s *clipboardService) notifyEmbeddings() {
+	if s.embeddings != nil {
+		s.embeddings.Notify()
+	}
+}
+
+// invalidateEmbedding 条目内容变化后让语义索引重新生成向量
+func (s *clipboardService) invalidateEmbedding(id string) {
+	if s.embeddings == nil {
+		return
+	}
+	if err := s.embeddings.Invalidate(id); err != nil {
+		log.Printf("❌ 清除条目向量失败: %s: %v", id, err)
+	}
+}
+
+// SemanticSearch 按向量相似度返回与 query 最相似的 k 个条目
+func (s *clipboardService) SemanticSearch(query string, k int) (models.SearchResult, error) {
+	if k <= 0 {
+		k = 10
+	}
+	return s.semanticSearch(models.SearchQuery{Query: query, Mode: models.SearchModeSemantic, Limit: k})
+}
+
+// searchByMode 按搜索模式检索；未启用语义搜索时按关键词搜索，混合搜索的向量检索失败时也退回关键词搜索
+func (s *clipboardService) searchByMode(query models.SearchQuery) (models.SearchResult, error) {
+	if strings.TrimSpace(query.Query) == "" || s.embeddings == nil {
+		return s.repo.Search(query)
+	}
+
+	var result models.SearchResult
+	var err error
+	switch query.Mode {
+	case models.SearchModeSemantic:
+		result, err = s.semanticSearch(query)
+	case models.SearchModeHybrid:
+		result, err = s.hybridSearch(query)
+		if err != nil && !errors.Is(err, model.ErrEmbeddingDisabled) {
+			log.Printf("⚠️  向量检索失败，按关键词搜索: %v", err)
+			return s.repo.Search(query)
+		}
+	default:
+		return s.repo.Search(query)
+	}
+	if errors.Is(err, model.ErrEmbeddingDisabled) {
+		return s.repo.Search(query)
+	}
+	return result, err
+}
+
+// semanticSearch 按向量相似度排序，query 中的其他过滤条件同样生效
+func (s *clipboardService) semanticSearch(query models.SearchQuery) (models.SearchResult, error) {
+	matches, err := s.embeddings.Search(context.Background(), query.Query, max(query.Offset+query.Limit, semanticCandidates))
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+	items, err := s.filterMatches(query, matches)
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+
+	scores := make(map[string]float64, len(matches))
+	for _, match := range matches {
+		scores[match.ItemID] = max(match.Score, 0)
+	}
+	for i := range items {
+		items[i].Score = scores[items[i].ID]
+	}
+	return pageByScore(items, query), nil
+}
+
+// hybridSearch 关键词排名和向量相似度加权混合：score = w*相似度 + (1-w)*(1 - 关键词排名/关键词结果数)
+func (s *clipboardService) hybridSearch(query models.SearchQuery) (models.SearchResult, error) {
+	candidates := max(query.Offset+query.Limit, semanticCandidates)
+	matches, err := s.embeddings.Search(context.Background(), query.Query, candidates)
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+	semantic, err := s.filterMatches(query, matches)
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+
+	keywordQuery := query
+	keywordQuery.Offset, keywordQuery.Limit = 0, candidates
+	keyword, err := s.repo.Search(keywordQuery)
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+
+	weight := s.embeddings.HybridWeight()
+	seen := make(map[string]bool, len(semantic)+len(keyword.Items))
+	var items []models.ClipboardItem
+	for _, item := range append(semantic, keyword.Items...) {
+		if !seen[item.ID] {
+			items = append(items, item)
+			seen[item.ID] = true
+		}
+	}
+	scores := make(map[string]float64, len(items))
+	for _, match := range matches {
+		scores[match.ItemID] += weight * max(match.Score, 0)
+	}
+	for rank, item := range keyword.Items {
+		scores[item.ID] += (1 - weight) * (1 - float64(rank)/float64(len(keyword.Items)))
+	}
+	for i := range items {
+		items[i].Score = scores[items[i].ID]
+	}
+	return pageByScore(items, query), nil
+}
+
+// filterMatches 在命中的条目上应用 query 的过滤条件，返回仍然满足条件的条目
+func (s *clipboardService) filterMatches(query models.SearchQuery, matches []models.SemanticMatch) ([]models.ClipboardItem, error) {
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	filter := query
+	filter.Query = ""
+	filter.IDs = make([]string, len(matches))
+	for i, match := range matches {
+		filter.IDs[i] = match.ItemID
+	}
+	filter.Offset, filter.Limit = 0, len(matches)
+	result, err := s.repo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// pageByScore 按分数从高到低排序后取 query 指定的分页
+func pageByScore(items []models.ClipboardItem, query models.SearchQuery) models.SearchResult {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+
+	limit := max(query.Limit, 1)
+	total := len(items)
+	start := min(query.Offset, total)
+	page := make([]models.ClipboardItem, 0, min(limit, total-start))
+	return models.SearchResult{
+		Items:      append(page, items[start:min(start+limit, total)]...),
+		Total:      total,
+		Page:       query.Offset/limit + 1,
+		PageSize:   limit,
+		TotalPages: (total + limit - 1) / limit,
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
@@ -51,20 +53,54 @@ type TagService interface {
 	CleanupUnusedTags() error
 	MergeTags(sourceTagName, targetTagName string) error
 	ValidateTagName(name string) error
+
+	// 导入导出
+	ExportRecords() ([]models.ExportRecord, error)
+	ImportTagGroup(group models.TagGroup) (string, bool, error)
+	ImportTag(tag models.Tag) (bool, error)
+
+	// 事件推送
+	SetEventEmitter(emit EventEmitter)
 }
 
 // tagService 标签服务实现
 type tagService struct {
 	tagRepo       repository.TagRepository
 	clipboardRepo repository.ClipboardRepository
+	chatModels    model.Provider
+
+	mu   sync.Mutex
+	emit EventEmitter
 }
 
 // NewTagService 创建新的标签服务
-func NewTagService(tagRepo repository.TagRepository, clipboardRepo repository.ClipboardRepository) TagService {
+func NewTagService(tagRepo repository.TagRepository, clipboardRepo repository.ClipboardRepository, chatModels model.Provider) TagService {
 	return &tagService{
 		tagRepo:       tagRepo,
 		clipboardRepo: clipboardRepo,
+		chatModels:    chatModels,
+	}
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *tagService) SetEventEmitter(emit EventEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// notifyChanged 操作成功时推送 tags:changed 事件，itemID 为空表示标签或分组本身发生变化
+func (s *tagService) notifyChanged(itemID string, err error) error {
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+	if emit != nil {
+		emit(EventTagsChanged, models.TagsChangedEvent{ItemID: itemID})
 	}
+	return nil
 }
 
 // CreateTagGroup 创建标签分组
@@ -87,7 +123,7 @@ func (s *tagService) CreateTagGroup(name, description, color string, sortOrder i
 		group.Color = "#1890ff"
 	}
 
-	err := s.tagRepo.CreateTagGroup(group)
+	err := s.notifyChanged("", s.tagRepo.CreateTagGroup(group))
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +141,7 @@ func (s *tagService) UpdateTagGroup(group models.TagGroup) error {
 	if group.Name == "" {
 		return fmt.Errorf("标签分组名称不能为空")
 	}
-	return s.tagRepo.UpdateTagGroup(group)
+	return s.notifyChanged("", s.tagRepo.UpdateTagGroup(group))
 }
 
 // DeleteTagGroup 删除标签分组
@@ -115,7 +151,7 @@ func (s *tagService) DeleteTagGroup(id string) error {
 	if err != nil {
 		return err
 	}
-	return s.tagRepo.DeleteTagGroup(id)
+	return s.notifyChanged("", s.tagRepo.DeleteTagGroup(id))
 }
 
 // CreateTag 创建标签
@@ -150,7 +186,7 @@ func (s *tagService) CreateTag(name, description, color, groupID string) (*model
 		tag.GroupID = "user-custom"
 	}
 
-	err := s.tagRepo.CreateTag(tag)
+	err := s.notifyChanged("", s.tagRepo.CreateTag(tag))
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +232,7 @@ func (s *tagService) UpdateTag(tag models.Tag) error {
 		return err
 	}
 
-	return s.tagRepo.UpdateTag(tag)
+	return s.notifyChanged("", s.tagRepo.UpdateTag(tag))
 }
 
 // DeleteTag 删除标签
@@ -207,7 +243,7 @@ func (s *tagService) DeleteTag(id string) error {
 		return err
 	}
 
-	return s.tagRepo.DeleteTag(id)
+	return s.notifyChanged("", s.tagRepo.DeleteTag(id))
 }
 
 // GetOrCreateTagByName 根据名称获取或创建标签
@@ -256,9 +292,10 @@ func (s *tagService) ProcessClipboardItemTags(item *models.ClipboardItem) ([]str
 func (s *tagService) AutoGenerateTags(content, contentType string) ([]string, error) {
 	// 使用AI生成标签
 	ctx := context.Background()
-	chatModel, err := model.NewChatModel(ctx)
+	chatModel, err := s.chatModels.ChatModel(ctx)
 	if err != nil {
 		// 如果AI不可用，使用简单的备用逻辑
+		log.Printf("⚠️  大模型不可用，使用备用标签: %v", err)
 		return s.generateFallbackTags(content, contentType), nil
 	}
 
@@ -273,6 +310,7 @@ func (s *tagService) AutoGenerateTags(content, contentType string) ([]string, er
 
 	response, err := chatModel.Generate(ctx, tagsPrompt)
 	if err != nil {
+		log.Printf("⚠️  AI生成标签失败，使用备用标签: %v", err)
 		return s.generateFallbackTags(content, contentType), nil
 	}
 
@@ -308,18 +346,34 @@ func (s *tagService) generateFallbackTags(content, contentType string) []string
 	// 基于内容类型的基础标签
 	var typeTag string
 	switch contentType {
-	case "url":
+	case models.ContentTypeURL:
 		typeTag = "链接"
-	case "email":
+	case models.ContentTypeEmail:
 		typeTag = "邮箱"
-	case "phone":
+	case models.ContentTypePhone:
 		typeTag = "电话"
 	case "file":
 		typeTag = "路径"
-	case "code":
+	case models.ContentTypeCode, models.ContentTypeHTML, models.ContentTypeSQL:
 		typeTag = "代码"
-	case "json":
+	case models.ContentTypeShell:
+		typeTag = "命令"
+	case models.ContentTypeStackTrace:
+		typeTag = "报错"
+	case models.ContentTypeJSON, models.ContentTypeYAML, models.ContentTypeXML:
 		typeTag = "数据"
+	case models.ContentTypeMarkdown:
+		typeTag = "文档"
+	case models.ContentTypeColor:
+		typeTag = "颜色"
+	case models.ContentTypeIP:
+		typeTag = "网络"
+	case models.ContentTypeDate:
+		typeTag = "日期"
+	case models.ContentTypeCoordinates:
+		typeTag = "位置"
+	case models.ContentTypeUUID:
+		typeTag = "标识符"
 	default:
 		typeTag = "文本"
 	}
@@ -399,7 +453,7 @@ func (s *tagService) AddTagsToItem(itemID string, tagNames []string) error {
 		}
 	}
 
-	return nil
+	return s.notifyChanged(itemID, nil)
 }
 
 // RemoveTagsFromItem 从条目移除标签
@@ -416,7 +470,7 @@ func (s *tagService) RemoveTagsFromItem(itemID string, tagNames []string) error
 		}
 	}
 
-	return nil
+	return s.notifyChanged(itemID, nil)
 }
 
 // GetTagsForItem 获取条目的标签
@@ -448,7 +502,7 @@ func (s *tagService) UpdateItemTags(itemID string, tagNames []string, source str
 	}
 
 	// 批量更新关联
-	return s.tagRepo.BatchUpdateItemTags(itemID, tagIDs)
+	return s.notifyChanged(itemID, s.tagRepo.BatchUpdateItemTags(itemID, tagIDs))
 }
 
 // GetTagStatistics 获取标签统计信息
@@ -502,7 +556,7 @@ func (s *tagService) GetSimilarTags(tagName string, limit int) ([]models.Tag, er
 
 // CleanupUnusedTags 清理未使用标签
 func (s *tagService) CleanupUnusedTags() error {
-	return s.tagRepo.CleanupUnusedTags()
+	return s.notifyChanged("", s.tagRepo.CleanupUnusedTags())
 }
 
 // MergeTags 合并标签
@@ -519,7 +573,7 @@ func (s *tagService) MergeTags(sourceTagName, targetTagName string) error {
 
 	// 标签合并检查通过
 
-	return s.tagRepo.MergeTags(sourceTag.ID, targetTag.ID)
+	return s.notifyChanged("", s.tagRepo.MergeTags(sourceTag.ID, targetTag.ID))
 }
 
 // ValidateTagName 验证标签名称
@@ -544,6 +598,96 @@ func (s *tagService) ValidateTagName(name string) error {
 }
 
 // 辅助函数
+// defaultImportTagGroup 导入时分组不存在的标签归入的分组（迁移创建的内置分组）
+const defaultImportTagGroup = "ai-generated"
+
+// ExportRecords 导出全部标签分组和标签，分组在前以便导入时先建立分组
+func (s *tagService) ExportRecords() ([]models.ExportRecord, error) {
+	groups, err := s.tagRepo.GetTagGroups()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tagRepo.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models.ExportRecord, 0, len(groups)+len(tags))
+	for i := range groups {
+		records = append(records, models.ExportRecord{Type: models.ExportRecordTagGroup, TagGroup: &groups[i]})
+	}
+	for i := range tags {
+		records = append(records, models.ExportRecord{Type: models.ExportRecordTag, Tag: &tags[i]})
+	}
+	return records, nil
+}
+
+// ImportTagGroup 导入标签分组，ID 或名称相同的分组视为同一分组
+// 返回本地对应的分组ID，以及是否新建了分组
+func (s *tagService) ImportTagGroup(group models.TagGroup) (string, bool, error) {
+	if group.Name == "" {
+		return "", false, fmt.Errorf("标签分组名称不能为空")
+	}
+	if existing, err := s.tagRepo.GetTagGroupByID(group.ID); err == nil {
+		return existing.ID, false, nil
+	}
+	groups, err := s.tagRepo.GetTagGroups()
+	if err != nil {
+		return "", false, err
+	}
+	for _, existing := range groups {
+		if existing.Name == group.Name {
+			return existing.ID, false, nil
+		}
+	}
+
+	if group.ID == "" {
+		group.ID = fmt.Sprintf("group-%d", time.Now().UnixNano())
+	}
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+	group.UpdatedAt = time.Now()
+	if err := s.notifyChanged("", s.tagRepo.CreateTagGroup(group)); err != nil {
+		return "", false, err
+	}
+	return group.ID, true, nil
+}
+
+// ImportTag 导入标签，已有同名标签时按名称合并，返回是否新建了标签
+func (s *tagService) ImportTag(tag models.Tag) (bool, error) {
+	if err := s.ValidateTagName(tag.Name); err != nil {
+		return false, err
+	}
+	if _, err := s.tagRepo.GetTagByName(tag.Name); err == nil {
+		return false, nil
+	}
+
+	if _, err := s.tagRepo.GetTagGroupByID(tag.GroupID); err != nil {
+		tag.GroupID = defaultImportTagGroup
+	}
+	if _, err := s.tagRepo.GetTagByID(tag.ID); tag.ID == "" || err == nil {
+		tag.ID = fmt.Sprintf("tag-%d", time.Now().UnixNano())
+	}
+	if tag.Color == "" {
+		tag.Color = "#1890ff"
+	}
+	now := time.Now()
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = now
+	}
+	if tag.LastUsedAt.IsZero() {
+		tag.LastUsedAt = now
+	}
+	tag.UpdatedAt = now
+	tag.UseCount = 0
+
+	if err := s.notifyChanged("", s.tagRepo.CreateTag(tag)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func contains(s, substr string) bool {
 	return len(substr) > 0 && len(s) >= len(substr) &&
 		(s == substr || findInString(s, substr))
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// EventTaggingProgress 打标签进度事件名称
+const EventTaggingProgress = "tagging:progress"
+
+// EventEmitter 向前端推送事件（由 App 注入 Wails 运行时实现）
+type EventEmitter func(name string, data interface{})
+
+// TagGenerator 为条目生成标签
+type TagGenerator interface {
+	GenerateTagsForItem(ctx context.Context, id string) ([]string, error)
+}
+
+// TaggingOptions 打标签队列参数
+type TaggingOptions struct {
+	Workers      int           // 并发数
+	MinInterval  time.Duration // 两次调用大模型的最小间隔
+	PollInterval time.Duration // 队列为空时的轮询间隔
+	MaxAttempts  int           // 最大尝试次数
+	BaseBackoff  time.Duration // 首次重试等待时间，之后每次翻倍
+	MaxBackoff   time.Duration // 重试等待时间上限
+}
+
+// DefaultTaggingOptions 返回默认队列参数
+func DefaultTaggingOptions() TaggingOptions {
+	return TaggingOptions{
+		Workers:      2,
+		MinInterval:  2 * time.Second,
+		PollInterval: 5 * time.Second,
+		MaxAttempts:  5,
+		BaseBackoff:  15 * time.Second,
+		MaxBackoff:   10 * time.Minute,
+	}
+}
+
+// TaggingService 后台AI打标签队列接口
+type TaggingService interface {
+	Start(ctx context.Context) error
+	Stop()
+	Enqueue(itemID string) error
+	Status() (models.TaggingQueueStatus, error)
+	SetTagger(tagger TagGenerator)
+	SetEventEmitter(emit EventEmitter)
+	Throttle(ctx context.Context) error
+}
+
+// taggingService 后台AI打标签队列实现，任务持久化在 tagging_jobs 表中
+type taggingService struct {
+	repo          repository.TaggingRepository
+	clipboardRepo repository.ClipboardRepository
+	options       TaggingOptions
+
+	mu        sync.Mutex
+	tagger    TagGenerator
+	emit      EventEmitter
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	lastError string
+
+	limiter *intervalLimiter
+	wake    chan struct{}
+}
+
+// NewTaggingService 创建新的打标签队列
+func NewTaggingService(repo repository.TaggingRepository, clipboardRepo repository.ClipboardRepository, options TaggingOptions) TaggingService {
+	return &taggingService{
+		repo:          repo,
+		clipboardRepo: clipboardRepo,
+		options:       options,
+		limiter:       &intervalLimiter{interval: options.MinInterval},
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// SetTagger 设置标签生成器
+func (s *taggingService) SetTagger(tagger TagGenerator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tagger = tagger
+}
+
+// SetEventEmitter 设置事件推送函数
+func (s *taggingService) SetEventEmitter(emit EventEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// Start 启动工作协程，上次退出时执行中的任务会重新排队
+func (s *taggingService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+	if s.tagger == nil {
+		return errors.New("tagger not set")
+	}
+	if err := s.repo.ResetRunning(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	for i := 0; i < s.options.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker(ctx)
+	}
+
+	log.Printf("🏷️  打标签队列已启动，并发数: %d", s.options.Workers)
+	return nil
+}
+
+// Stop 停止工作协程并等待当前任务结束
+func (s *taggingService) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+	log.Println("🛑 打标签队列已停止")
+}
+
+// Enqueue 将条目加入打标签队列
+func (s *taggingService) Enqueue(itemID string) error {
+	if err := s.repo.Enqueue(itemID); err != nil {
+		return err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	s.publish(models.TaggingProgress{ItemID: itemID, Status: models.TaggingStatusPending})
+	return nil
+}
+
+// Status 获取队列状态
+func (s *taggingService) Status() (models.TaggingQueueStatus, error) {
+	counts, err := s.repo.CountByStatus()
+	if err != nil {
+		return models.TaggingQueueStatus{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return models.TaggingQueueStatus{
+		Running:   s.cancel != nil,
+		Workers:   s.options.Workers,
+		Pending:   counts[models.TaggingStatusPending],
+		InFlight:  counts[models.TaggingStatusRunning],
+		Completed: counts[models.TaggingStatusDone],
+		Failed:    counts[models.TaggingStatusFailed],
+		Skipped:   counts[models.TaggingStatusSkipped],
+		LastError: s.lastError,
+	}, nil
+}
+
+// Throttle 等待下一次调用大模型的时机，批量重新打标签与后台队列共用最小调用间隔
+func (s *taggingService) Throttle(ctx context.Context) error {
+	return s.limiter.wait(ctx)
+}
+
+// worker 循环领取并执行任务
+func (s *taggingService) worker(ctx context.Context) {
+	defer s.wg.Done()
+
+	for ctx.Err() == nil {
+		job, err := s.repo.ClaimNext(time.Now())
+		if err != nil {
+			log.Printf("❌ 领取打标签任务失败: %v", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-s.wake:
+			case <-time.After(s.options.PollInterval):
+			}
+			continue
+		}
+
+		s.process(ctx, job)
+	}
+}
+
+// process 执行单个任务
+func (s *taggingService) process(ctx context.Context, job *models.TaggingJob) {
+	item, err := s.clipboardRepo.GetByID(job.ItemID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || (err == nil && item.IsDeleted):
+		s.finish(job, s.repo.Skip(job.ID, "条目不存在或已删除"), models.TaggingProgress{Status: models.TaggingStatusSkipped})
+		return
+	case err != nil:
+		s.retry(ctx, job, err)
+		return
+	case item.IsSensitive:
+		s.finish(job, s.repo.Skip(job.ID, "敏感内容不发送给AI"), models.TaggingProgress{Status: models.TaggingStatusSkipped})
+		return
+	case item.IsImage():
+		s.finish(job, s.repo.Skip(job.ID, "图片条目暂不支持"), models.TaggingProgress{Status: models.TaggingStatusSkipped})
+		return
+	}
+
+	// 限制调用大模型的频率
+	if err := s.limiter.wait(ctx); err != nil {
+		s.requeue(job, 0)
+		return
+	}
+
+	s.mu.Lock()
+	tagger := s.tagger
+	s.mu.Unlock()
+
+	tags, err := tagger.GenerateTagsForItem(ctx, item.ID)
+	if err != nil {
+		s.retry(ctx, job, err)
+		return
+	}
+
+	s.finish(job, s.repo.Complete(job.ID), models.TaggingProgress{Status: models.TaggingStatusDone, Tags: tags})
+}
+
+// retry 按指数退避重新排队，超过最大次数后标记失败
+func (s *taggingService) retry(ctx context.Context, job *models.TaggingJob, cause error) {
+	if ctx.Err() != nil {
+		// 队列停止导致的中断不计入失败次数
+		s.requeue(job, 0)
+		return
+	}
+	if errors.Is(cause, repository.ErrDatabaseLocked) {
+		// 口令加密的数据库尚未解锁，等待解锁后再处理，不计入失败次数
+		s.requeue(job, s.options.PollInterval)
+		return
+	}
+
+	s.mu.Lock()
+	s.lastError = cause.Error()
+	s.mu.Unlock()
+
+	attempts := job.Attempts + 1
+	if attempts >= s.options.MaxAttempts {
+		log.Printf("❌ 条目 %s 打标签失败，已达最大重试次数: %v", job.ItemID, cause)
+		s.finish(job, s.repo.Fail(job.ID, attempts, cause.Error()), models.TaggingProgress{Status: models.TaggingStatusFailed, Error: cause.Error()})
+		return
+	}
+
+	backoff := s.backoff(attempts)
+	log.Printf("⚠️  条目 %s 打标签失败，%v 后重试（第 %d 次）: %v", job.ItemID, backoff, attempts, cause)
+	s.finish(job, s.repo.Retry(job.ID, attempts, cause.Error(), time.Now().Add(backoff)),
+		models.TaggingProgress{Status: models.TaggingStatusPending, Error: cause.Error()})
+}
+
+// requeue 将任务放回队列，delay 后才能再次领取，不改变重试次数
+func (s *taggingService) requeue(job *models.TaggingJob, delay time.Duration) {
+	if err := s.repo.Retry(job.ID, job.Attempts, job.LastError, time.Now().Add(delay)); err != nil {
+		log.Printf("❌ 打标签任务重新排队失败: %v", err)
+	}
+}
+
+// backoff 计算第 attempts 次失败后的等待时间
+func (s *taggingService) backoff(attempts int) time.Duration {
+	backoff := s.options.BaseBackoff
+	for i := 1; i < attempts && backoff < s.options.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, s.options.MaxBackoff)
+}
+
+// finish 记录任务结果并推送进度
+func (s *taggingService) finish(job *models.TaggingJob, err error, progress models.TaggingProgress) {
+	if err != nil {
+		log.Printf("❌ 更新打标签任务状态失败: %v", err)
+	}
+	progress.ItemID = job.ItemID
+	s.publish(progress)
+}
+
+// publish 推送进度事件
+func (s *taggingService) publish(progress models.TaggingProgress) {
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+	if emit == nil {
+		return
+	}
+
+	if status, err := s.Status(); err == nil {
+		progress.Queue = status
+	}
+	emit(EventTaggingProgress, progress)
+}
+
+// intervalLimiter 保证两次调用之间至少间隔 interval，调用方按到达顺序依次预约时间
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait 阻塞到预约的时间或 ctx 取消
+func (l *intervalLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
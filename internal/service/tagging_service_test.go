@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+// fakeTagger 前 failures 次调用返回错误
+type fakeTagger struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+}
+
+func (f *fakeTagger) GenerateTagsForItem(ctx context.Context, id string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[id]++
+	if f.calls[id] <= f.failures {
+		return nil, errors.New("llm unavailable")
+	}
+	return []string{"测试"}, nil
+}
+
+func (f *fakeTagger) callCount(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[id]
+}
+
+func newTestTaggingService(t *testing.T, tagger TagGenerator) (TaggingService, repository.ClipboardRepository, *sync.Map) {
+	t.Helper()
+	return newTestTaggingServiceForDB(t, newTestDB(t), tagger)
+}
+
+// newTestTaggingServiceForDB 在指定数据库上创建打标签队列
+func newTestTaggingServiceForDB(t *testing.T, db *repository.Database, tagger TagGenerator) (TaggingService, repository.ClipboardRepository, *sync.Map) {
+	t.Helper()
+	service, _, _ := newTestClipboardService(t, withTestDatabase(db), withTaggingOptions(TaggingOptions{
+		Workers:      2,
+		MinInterval:  time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+	}))
+	tagging := service.tagging
+	tagging.SetTagger(tagger)
+
+	events := &sync.Map{}
+	tagging.SetEventEmitter(func(name string, data interface{}) {
+		if progress, ok := data.(models.TaggingProgress); ok && name == EventTaggingProgress {
+			events.Store(progress.ItemID+":"+progress.Status, true)
+		}
+	})
+	return tagging, service.repo, events
+}
+
+func createTestItem(t *testing.T, repo repository.ClipboardRepository, content string, sensitive bool) string {
+	t.Helper()
+	now := time.Now()
+	item := models.ClipboardItem{
+		ID:          uuid.New().String(),
+		Content:     content,
+		ContentType: models.ContentTypeText,
+		Title:       content,
+		Category:    models.CategoryText,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LastUsedAt:  now,
+		IsSensitive: sensitive,
+	}
+	if err := repo.Create(item); err != nil {
+		t.Fatal(err)
+	}
+	return item.ID
+}
+
+// waitForStatus 等待队列达到期望状态
+func waitForStatus(t *testing.T, tagging TaggingService, check func(models.TaggingQueueStatus) bool) models.TaggingQueueStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := tagging.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if check(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for queue status, last: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTaggingService_RetryAndSkip(t *testing.T) {
+	tagger := &fakeTagger{failures: 1, calls: make(map[string]int)}
+	tagging, repo, events := newTestTaggingService(t, tagger)
+
+	normal := createTestItem(t, repo, "hello world", false)
+	sensitive := createTestItem(t, repo, "P@ssw0rd!", true)
+	for _, id := range []string{normal, sensitive} {
+		if err := tagging.Enqueue(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tagging.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer tagging.Stop()
+
+	status := waitForStatus(t, tagging, func(s models.TaggingQueueStatus) bool {
+		return s.Completed == 1 && s.Skipped == 1
+	})
+	if status.Pending != 0 || status.Failed != 0 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if got := tagger.callCount(normal); got != 2 {
+		t.Errorf("expected one retry, got %d calls", got)
+	}
+	if got := tagger.callCount(sensitive); got != 0 {
+		t.Errorf("sensitive item must not be sent to the tagger, got %d calls", got)
+	}
+	if _, ok := events.Load(normal + ":" + models.TaggingStatusDone); !ok {
+		t.Error("expected done progress event")
+	}
+}
+
+func TestTaggingService_FailAfterMaxAttempts(t *testing.T) {
+	tagger := &fakeTagger{failures: 100, calls: make(map[string]int)}
+	tagging, repo, _ := newTestTaggingService(t, tagger)
+
+	id := createTestItem(t, repo, "always fails", false)
+	if err := tagging.Enqueue(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := tagging.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer tagging.Stop()
+
+	status := waitForStatus(t, tagging, func(s models.TaggingQueueStatus) bool { return s.Failed == 1 })
+	if status.LastError == "" {
+		t.Error("expected last error to be reported")
+	}
+	if got := tagger.callCount(id); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+
+	// 重新加入队列后可以再次执行
+	if err := tagging.Enqueue(id); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, tagging, func(s models.TaggingQueueStatus) bool { return s.Failed == 1 && tagger.callCount(id) == 6 })
+}
+
+func TestTaggingService_ProcessQueuedJobsOnStart(t *testing.T) {
+	tagger := &fakeTagger{calls: make(map[string]int)}
+	tagging, repo, _ := newTestTaggingService(t, tagger)
+
+	// 未启动时加入的任务在启动后执行
+	id := createTestItem(t, repo, "queued before start", false)
+	if err := tagging.Enqueue(id); err != nil {
+		t.Fatal(err)
+	}
+	status, err := tagging.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Running || status.Pending != 1 {
+		t.Fatalf("unexpected status before start %+v", status)
+	}
+
+	if err := tagging.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, tagging, func(s models.TaggingQueueStatus) bool { return s.Completed == 1 })
+	tagging.Stop()
+
+	status, err = tagging.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Running {
+		t.Error("expected queue to be stopped")
+	}
+}
+
+func TestTaggingService_WaitsForUnlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnablePassphraseEncryption("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	id := createTestItem(t, repository.NewClipboardRepository(db.DB, db.Cipher()), "queued while locked", false)
+	db.Close()
+
+	// 重新打开后数据库处于锁定状态
+	db, err = repository.NewDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	tagger := &fakeTagger{calls: make(map[string]int)}
+	tagging, _, _ := newTestTaggingServiceForDB(t, db, tagger)
+	if err := tagging.Enqueue(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := tagging.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer tagging.Stop()
+
+	// 远超最大重试次数所需的时间内，任务既不执行也不会被标记失败
+	time.Sleep(200 * time.Millisecond)
+	status, err := tagging.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Failed != 0 || status.Completed != 0 || tagger.callCount(id) != 0 {
+		t.Fatalf("locked database must not consume attempts: %+v", status)
+	}
+
+	if err := db.UnlockWithPassphrase("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, tagging, func(s models.TaggingQueueStatus) bool { return s.Completed == 1 })
+}
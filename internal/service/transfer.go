@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Sid/internal/models"
+)
+
+// exportFormatName 导出文件头中的格式标识
+const exportFormatName = "sid-export"
+
+// exportPageSize 导出时每页查询的条目数
+const exportPageSize = 200
+
+// Export 按指定格式导出符合查询条件的条目
+// jsonl 格式依次写入文件头、标签分组、标签、条目和聊天会话，每行一条记录；csv 和 markdown 只包含条目
+func (s *clipboardService) Export(w io.Writer, options models.ExportOptions) (models.ExportReport, error) {
+	var report models.ExportReport
+
+	switch options.Format {
+	case models.ExportFormatJSONL, "":
+		return s.exportJSONL(w, options)
+	case models.ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "created_at", "category", "content_type", "title", "content", "tags", "is_favorite", "use_count"})
+		err := s.forEachExportItem(options.Query, func(item models.ClipboardItem) error {
+			report.Items++
+			return cw.Write([]string{
+				item.ID, item.CreatedAt.Format(time.RFC3339), item.Category, item.ContentType, item.Title, item.Content,
+				strings.Join(item.GetTagNames(), ";"), strconv.FormatBool(item.IsFavorite), strconv.Itoa(item.UseCount),
+			})
+		})
+		if err != nil {
+			return report, err
+		}
+		cw.Flush()
+		return report, cw.Error()
+	case models.ExportFormatMarkdown:
+		bw := bufio.NewWriter(w)
+		fmt.Fprintf(bw, "# 剪切板历史\n\n导出时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+		err := s.forEachExportItem(options.Query, func(item models.ClipboardItem) error {
+			report.Items++
+			writeMarkdownItem(bw, item)
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+		return report, bw.Flush()
+	default:
+		return report, fmt.Errorf("不支持的导出格式: %s", options.Format)
+	}
+}
+
+// exportJSONL 导出完整数据
+func (s *clipboardService) exportJSONL(w io.Writer, options models.ExportOptions) (models.ExportReport, error) {
+	var report models.ExportReport
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	header := models.ExportHeader{Format: exportFormatName, Version: models.ExportFormatVersion, ExportedAt: time.Now()}
+	if err := enc.Encode(models.ExportRecord{Type: models.ExportRecordHeader, Header: &header}); err != nil {
+		return report, err
+	}
+
+	tagRecords, err := s.tagService.ExportRecords()
+	if err != nil {
+		return report, err
+	}
+	for _, record := range tagRecords {
+		if record.Type == models.ExportRecordTagGroup {
+			report.TagGroups++
+		} else {
+			report.Tags++
+		}
+		if err := enc.Encode(record); err != nil {
+			return report, err
+		}
+	}
+
+	err = s.forEachExportItem(options.Query, func(item models.ClipboardItem) error {
+		exported := models.NewExportItem(item)
+		if item.IsImage() {
+			image, err := s.repo.GetImage(item.ID)
+			if err != nil {
+				return fmt.Errorf("读取图片 %s 失败: %w", item.ID, err)
+			}
+			exported.Image = &models.ExportImage{
+				Hash: image.Hash, Data: image.Data, Thumbnail: image.Thumbnail, Width: image.Width, Height: image.Height,
+			}
+		}
+		report.Items++
+		return enc.Encode(models.ExportRecord{Type: models.ExportRecordItem, Item: &exported})
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if options.IncludeChats {
+		chatRecords, err := s.chatService.ExportRecords()
+		if err != nil {
+			return report, err
+		}
+		for _, record := range chatRecords {
+			report.ChatSessions++
+			if err := enc.Encode(record); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return report, err
+	}
+	log.Printf("✅ 导出完成: %d 个条目，%d 个标签，%d 个会话", report.Items, report.Tags, report.ChatSessions)
+	return report, nil
+}
+
+// forEachExportItem 分页遍历符合条件的条目，按创建时间倒序
+func (s *clipboardService) forEachExportItem(query models.SearchQuery, fn func(item models.ClipboardItem) error) error {
+	query.Limit = exportPageSize
+	query.Offset = 0
+	for {
+		result, err := s.repo.Search(query)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if len(result.Items) < exportPageSize {
+			return nil
+		}
+		query.Offset += exportPageSize
+	}
+}
+
+// writeMarkdownItem 写入一个 Markdown 条目，内容放在代码块中以保留原始格式
+func writeMarkdownItem(w io.Writer, item models.ClipboardItem) {
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		title = item.ID
+	}
+	fmt.Fprintf(w, "\n## %s\n\n", title)
+	fmt.Fprintf(w, "- 时间: %s\n- 分类: %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"), item.Category)
+	if tags := item.GetTagNames(); len(tags) > 0 {
+		fmt.Fprintf(w, "- 标签: %s\n", strings.Join(tags, ", "))
+	}
+	if item.IsImage() {
+		fmt.Fprintf(w, "\n_[图片]_\n")
+		return
+	}
+
+	// 代码块围栏比内容中最长的连续反引号更长
+	fence := "```"
+	for strings.Contains(item.Content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "\n%s\n%s\n%s\n", fence, item.Content, fence)
+}
+
+// Import 导入 jsonl 格式的数据，单条记录出错时记录错误并继续
+func (s *clipboardService) Import(r io.Reader, options models.ImportOptions) (models.ImportReport, error) {
+	report := models.ImportReport{Errors: []string{}}
+	conflict := options.Conflict
+	switch conflict {
+	case "":
+		conflict = models.ImportConflictSkip
+	case models.ImportConflictSkip, models.ImportConflictOverwrite, models.ImportConflictMerge:
+	default:
+		return report, fmt.Errorf("不支持的冲突处理方式: %s", conflict)
+	}
+
+	reader := bufio.NewReader(r)
+	groupIDs := make(map[string]string) // 导入文件中的分组ID -> 本地分组ID
+	lineNo := 0
+	headerSeen := false
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return report, readErr
+		}
+		lineNo++
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var record models.ExportRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				if !headerSeen {
+					return report, fmt.Errorf("不是有效的导出文件: %w", err)
+				}
+				report.Errors = append(report.Errors, fmt.Sprintf("第 %d 行: %v", lineNo, err))
+			} else if !headerSeen {
+				if err := checkExportHeader(record); err != nil {
+					return report, err
+				}
+				headerSeen = true
+			} else if err := s.importRecord(record, conflict, groupIDs, &report); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("第 %d 行: %v", lineNo, err))
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if !headerSeen {
+		return report, errors.New("导出文件为空")
+	}
+
+	log.Printf("✅ 导入完成: 新建 %d 个条目，更新 %d 个，跳过 %d 个，新建 %d 个标签、%d 个会话，错误 %d 条",
+		report.ItemsCreated, report.ItemsUpdated, report.ItemsSkipped, report.TagsCreated, report.ChatSessionsCreated, len(report.Errors))
+	return report, nil
+}
+
+// checkExportHeader 校验文件头
+func checkExportHeader(record models.ExportRecord) error {
+	if record.Type != models.ExportRecordHeader || record.Header == nil || record.Header.Format != exportFormatName {
+		return errors.New("不是有效的导出文件：缺少文件头")
+	}
+	if record.Header.Version > models.ExportFormatVersion {
+		return fmt.Errorf("导出文件版本 v%d 高于当前支持的 v%d，请升级应用", record.Header.Version, models.ExportFormatVersion)
+	}
+	return nil
+}
+
+// importRecord 按记录类型导入
+func (s *clipboardService) importRecord(record models.ExportRecord, conflict string, groupIDs map[string]string, report *models.ImportReport) error {
+	switch {
+	case record.Type == models.ExportRecordTagGroup && record.TagGroup != nil:
+		id, created, err := s.tagService.ImportTagGroup(*record.TagGroup)
+		if err != nil {
+			return err
+		}
+		groupIDs[record.TagGroup.ID] = id
+		if created {
+			report.TagGroupsCreated++
+		}
+	case record.Type == models.ExportRecordTag && record.Tag != nil:
+		tag := *record.Tag
+		if id, ok := groupIDs[tag.GroupID]; ok {
+			tag.GroupID = id
+		}
+		created, err := s.tagService.ImportTag(tag)
+		if err != nil {
+			return err
+		}
+		if created {
+			report.TagsCreated++
+		}
+	case record.Type == models.ExportRecordItem && record.Item != nil:
+		outcome, err := s.importItem(*record.Item, conflict, report)
+		if err != nil {
+			return err
+		}
+		switch outcome {
+		case models.ImportOutcomeCreated:
+			report.ItemsCreated++
+		case models.ImportOutcomeUpdated:
+			report.ItemsUpdated++
+		default:
+			report.ItemsSkipped++
+		}
+	case record.Type == models.ExportRecordChatSession && record.ChatSession != nil:
+		outcome, err := s.chatService.ImportChatSession(*record.ChatSession, conflict)
+		if err != nil {
+			return err
+		}
+		switch outcome {
+		case models.ImportOutcomeCreated:
+			report.ChatSessionsCreated++
+		case models.ImportOutcomeUpdated:
+			report.ChatSessionsUpdated++
+		default:
+			report.ChatSessionsSkipped++
+		}
+	default:
+		return fmt.Errorf("未知的记录类型: %s", record.Type)
+	}
+	return nil
+}
+
+// importItem 导入单个条目，按条目ID判断冲突
+// merge 时保留本地条目并按名称补充标签，overwrite 时删除本地条目后重新创建
+func (s *clipboardService) importItem(data models.ExportItem, conflict string, report *models.ImportReport) (string, error) {
+	if data.ID == "" {
+		data.ID = uuid.New().String()
+	}
+	if data.ContentType == models.ContentTypeImage && data.Image == nil {
+		return "", errors.New("图片条目缺少图片数据")
+	}
+
+	existing, err := s.repo.GetByID(data.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if existing != nil {
+		switch conflict {
+		case models.ImportConflictMerge:
+			var missing []string
+			for _, name := range data.Tags {
+				if !containsString(existing.GetTagNames(), name) {
+					missing = append(missing, name)
+				}
+			}
+			if len(missing) == 0 {
+				return models.ImportOutcomeSkipped, nil
+			}
+			if err := s.importItemTags(existing.ID, missing, report); err != nil {
+				return "", err
+			}
+			return models.ImportOutcomeUpdated, nil
+		case models.ImportConflictOverwrite:
+			if err := s.repo.PermanentDelete(existing.ID); err != nil {
+				return "", err
+			}
+		default:
+			return models.ImportOutcomeSkipped, nil
+		}
+	}
+
+	if err := s.repo.Create(data.ClipboardItem()); err != nil {
+		return "", err
+	}
+	if data.Image != nil {
+		image := models.ClipboardImage{
+			ItemID: data.ID, Hash: data.Image.Hash, Data: data.Image.Data, Thumbnail: data.Image.Thumbnail,
+			Width: data.Image.Width, Height: data.Image.Height, Size: len(data.Image.Data), CreatedAt: data.CreatedAt,
+		}
+		if err := s.repo.CreateImage(image); err != nil {
+			s.repo.PermanentDelete(data.ID)
+			return "", err
+		}
+	}
+	if err := s.importItemTags(data.ID, data.Tags, report); err != nil {
+		return "", err
+	}
+
+	if existing != nil {
+		return models.ImportOutcomeUpdated, nil
+	}
+	return models.ImportOutcomeCreated, nil
+}
+
+// importItemTags 为条目关联标签，文件中没有定义的标签先按名称创建
+func (s *clipboardService) importItemTags(itemID string, names []string, report *models.ImportReport) error {
+	if len(names) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		created, err := s.tagService.ImportTag(models.Tag{Name: name})
+		if err != nil {
+			return err
+		}
+		if created {
+			report.TagsCreated++
+		}
+	}
+	return s.tagService.AddTagsToItem(itemID, names)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"Sid/internal/models"
+)
+
+// newTestTransferService 创建使用真实仓库的剪切板、标签和聊天服务
+func newTestTransferService(t *testing.T) *clipboardService {
+	t.Helper()
+	service, _, _ := newTestClipboardService(t, withRealChatService())
+	return service
+}
+
+// seedTransferData 写入一个带标签的条目、一个图片条目和一个聊天会话
+func seedTransferData(t *testing.T, service *clipboardService) (string, string) {
+	t.Helper()
+	group, _, err := service.tagService.ImportTagGroup(models.TagGroup{ID: "group-work", Name: "工作"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.tagService.ImportTag(models.Tag{ID: "tag-db", Name: "数据库", GroupID: group, Color: "#ff0000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	text := createTestItem(t, service.repo, "SELECT * FROM users; -- ```code```", false)
+	if err := service.tagService.AddTagsToItem(text, []string{"数据库"}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	image := models.ClipboardItem{
+		ID: "image-1", Content: "[图片 1x1]", ContentType: models.ContentTypeImage, Title: "图片",
+		Category: models.CategoryImage, CreatedAt: now, UpdatedAt: now, LastUsedAt: now,
+	}
+	if err := service.repo.Create(image); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.repo.CreateImage(models.ClipboardImage{ItemID: "image-1", Hash: "abc", Data: []byte{0x89, 'P', 'N', 'G'}, Width: 1, Height: 1, Size: 4, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	session, err := service.chatService.CreateSession(ctx, "会话")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chatRepo := service.chatService.(*chatService).repo
+	for i, content := range []string{"你好", "你好，有什么可以帮你？"} {
+		message := &models.ChatMessage{
+			ID: session.ID + "-" + string(rune('a'+i)), SessionID: session.ID, Role: models.MessageRoleUser,
+			Content: content, CreatedAt: now.Add(time.Duration(i) * time.Second), UpdatedAt: now,
+		}
+		if err := chatRepo.CreateChatMessage(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return text, session.ID
+}
+
+func TestClipboardService_ExportImportRoundTrip(t *testing.T) {
+	source := newTestTransferService(t)
+	textID, sessionID := seedTransferData(t, source)
+
+	var buf bytes.Buffer
+	report, err := source.Export(&buf, models.ExportOptions{Format: models.ExportFormatJSONL, IncludeChats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Items != 2 || report.ChatSessions != 1 || report.Tags != 1 {
+		t.Errorf("unexpected export report %+v", report)
+	}
+	if !strings.HasPrefix(buf.String(), `{"type":"header"`) {
+		t.Errorf("expected header first, got %q", strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+
+	target := newTestTransferService(t)
+	imported, err := target.Import(bytes.NewReader(buf.Bytes()), models.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.ItemsCreated != 2 || imported.ChatSessionsCreated != 1 || imported.TagGroupsCreated != 1 || len(imported.Errors) != 0 {
+		t.Errorf("unexpected import report %+v", imported)
+	}
+
+	item, err := target.repo.GetByID(textID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "SELECT * FROM users; -- ```code```" || len(item.Tags) != 1 || item.Tags[0].Name != "数据库" {
+		t.Errorf("unexpected imported item %+v", item)
+	}
+	if item.Tags[0].GroupID != "group-work" || item.Tags[0].Color != "#ff0000" {
+		t.Errorf("expected tag definition to round-trip, got %+v", item.Tags[0])
+	}
+	image, err := target.repo.GetImage("image-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(image.Data) != "\x89PNG" {
+		t.Errorf("unexpected image data %v", image.Data)
+	}
+	messages, err := target.chatService.GetMessages(context.Background(), sessionID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages.Messages) != 2 || messages.Messages[1].Content != "你好，有什么可以帮你？" {
+		t.Errorf("unexpected imported messages %+v", messages.Messages)
+	}
+
+	// 再次导入时全部冲突，默认跳过
+	again, err := target.Import(bytes.NewReader(buf.Bytes()), models.ImportOptions{Conflict: models.ImportConflictSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ItemsCreated != 0 || again.ItemsSkipped != 2 || again.ChatSessionsSkipped != 1 {
+		t.Errorf("expected everything skipped, got %+v", again)
+	}
+}
+
+func TestClipboardService_ImportConflicts(t *testing.T) {
+	service := newTestTransferService(t)
+	id := createTestItem(t, service.repo, "本地内容", false)
+	if err := service.tagService.UpdateItemTags(id, []string{"本地"}, "ai-generated"); err != nil {
+		t.Fatal(err)
+	}
+
+	file := func(content string, tags ...string) *bytes.Reader {
+		var buf bytes.Buffer
+		buf.WriteString(`{"type":"header","header":{"format":"sid-export","version":1}}` + "\n")
+		buf.WriteString(`{"type":"item","item":{"id":"` + id + `","content":"` + content + `","content_type":"text","title":"t","category":"text","tags":["` + strings.Join(tags, `","`) + `"]}}` + "\n")
+		buf.WriteString("not json\n")
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	// merge：保留本地内容，按名称合并标签
+	report, err := service.Import(file("导入内容", "本地", "导入"), models.ImportOptions{Conflict: models.ImportConflictMerge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ItemsUpdated != 1 || len(report.Errors) != 1 || !strings.HasPrefix(report.Errors[0], "第 3 行") {
+		t.Errorf("unexpected merge report %+v", report)
+	}
+	item, err := service.repo.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "本地内容" || len(item.Tags) != 2 {
+		t.Errorf("expected local content with merged tags, got %q %v", item.Content, item.GetTagNames())
+	}
+
+	// overwrite：用导入的数据替换
+	if _, err := service.Import(file("导入内容", "导入"), models.ImportOptions{Conflict: models.ImportConflictOverwrite}); err != nil {
+		t.Fatal(err)
+	}
+	item, err = service.repo.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "导入内容" || len(item.Tags) != 1 || item.Tags[0].Name != "导入" {
+		t.Errorf("expected overwritten item, got %q %v", item.Content, item.GetTagNames())
+	}
+
+	if _, err := service.Import(strings.NewReader(`{"type":"item"}`), models.ImportOptions{}); err == nil {
+		t.Error("expected file without header to be rejected")
+	}
+	if _, err := service.Import(file("x"), models.ImportOptions{Conflict: "replace"}); err == nil {
+		t.Error("expected unknown conflict mode to be rejected")
+	}
+}
+
+func TestClipboardService_ExportCSVAndMarkdown(t *testing.T) {
+	service := newTestTransferService(t)
+	seedTransferData(t, service)
+	createTestItem(t, service.repo, "牛奶, \"面包\"\n鸡蛋", false)
+
+	var buf bytes.Buffer
+	report, err := service.Export(&buf, models.ExportOptions{Format: models.ExportFormatCSV, Query: models.SearchQuery{Query: "面包"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Items != 1 || len(rows) != 2 || rows[1][5] != "牛奶, \"面包\"\n鸡蛋" {
+		t.Errorf("unexpected csv export %d %v", report.Items, rows)
+	}
+
+	buf.Reset()
+	if _, err := service.Export(&buf, models.ExportOptions{Format: models.ExportFormatMarkdown}); err != nil {
+		t.Fatal(err)
+	}
+	markdown := buf.String()
+	if !strings.Contains(markdown, "- 标签: 数据库") || !strings.Contains(markdown, "````\nSELECT * FROM users; -- ```code```\n````") {
+		t.Errorf("unexpected markdown export:\n%s", markdown)
+	}
+	if !strings.Contains(markdown, "_[图片]_") {
+		t.Error("expected image placeholder in markdown export")
+	}
+
+	if _, err := service.Export(&buf, models.ExportOptions{Format: "xml"}); err == nil {
+		t.Error("expected unknown format to be rejected")
+	}
+}
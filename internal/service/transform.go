@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	clipboardLib "golang.design/x/clipboard"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+)
+
+// TransformItem 对文本条目执行转换并返回结果，不修改条目
+func (s *clipboardService) TransformItem(id, transform string) (string, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return "", err
+	}
+	if item.IsImage() {
+		return "", fmt.Errorf("图片条目不支持文本转换")
+	}
+	return clipboard.ApplyTransform(transform, item.Content)
+}
+
+// UseItemTransformed 将条目转换后的文本复制到剪切板，并记为使用了原条目
+// 监听开启时复制的结果会和其他复制一样被保存（内容已存在时跳过）
+func (s *clipboardService) UseItemTransformed(id, transform string) (string, error) {
+	text, err := s.TransformItem(id, transform)
+	if err != nil {
+		return "", err
+	}
+
+	clipboardLib.Write(clipboardLib.FmtText, []byte(text))
+
+	if err := s.repo.UseItem(id); err != nil {
+		return "", err
+	}
+	s.publishUpdated(id)
+	return text, nil
+}
+
+// SaveTransformedItem 将条目转换后的文本保存为新条目，沿用原条目的来源应用
+// 和捕获的内容一样经过忽略的应用、敏感内容规则和捕获规则
+func (s *clipboardService) SaveTransformedItem(id, transform string) (*models.ClipboardItem, error) {
+	original, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	text, err := s.TransformItem(id, transform)
+	if err != nil {
+		return nil, err
+	}
+
+	source := clipboard.Source{App: original.SourceApp, Title: original.SourceTitle}
+	item, outcome, skip := s.buildCapture(text, source, s.ruleEngine())
+	if skip != "" {
+		return nil, fmt.Errorf("转换结果已按规则跳过保存: %s", skip)
+	}
+
+	isDuplicate, err := s.repo.IsDuplicateContent(item.Content)
+	if err != nil {
+		return nil, err
+	}
+	if isDuplicate {
+		return nil, fmt.Errorf("转换结果已存在于历史记录中")
+	}
+
+	if err := s.repo.Create(item); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 保存转换结果 (%s): %s", transform, item.Title)
+	s.publish(EventItemCreated, item)
+	s.applyRuleTags(item.ID, outcome.Tags)
+	s.enqueueTagging(item)
+	s.notifyEmbeddings()
+	return &item, nil
+}
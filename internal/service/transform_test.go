@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+
+	"Sid/internal/clipboard"
+	"Sid/internal/models"
+	"Sid/internal/repository"
+)
+
+func TestClipboardService_SaveTransformedItem(t *testing.T) {
+	service, _, _ := newTestClipboardService(t)
+	if err := service.ProcessContent(`{"name": "sid",  "ok": true}`, clipboard.Source{App: "Code"}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := service.GetItems(10, 0)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("items = %v, err = %v", items, err)
+	}
+	id := items[0].ID
+
+	preview, err := service.TransformItem(id, "json_minify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview != `{"name":"sid","ok":true}` {
+		t.Errorf("preview = %q", preview)
+	}
+	if _, err := service.TransformItem(id, "tabs_to_csv"); err == nil {
+		t.Error("expected transform error for content without tabs")
+	}
+
+	item, err := service.SaveTransformedItem(id, "json_minify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != preview || item.SourceApp != "Code" || item.ContentLength != len(preview) {
+		t.Errorf("saved item = %+v", item)
+	}
+	if _, err := service.SaveTransformedItem(id, "json_minify"); err == nil {
+		t.Error("expected duplicate transform result to be rejected")
+	}
+
+	original, err := service.GetItem(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if original.Content != `{"name": "sid",  "ok": true}` {
+		t.Errorf("original item changed: %q", original.Content)
+	}
+}
+
+func TestClipboardService_SaveTransformedItemAppliesRules(t *testing.T) {
+	service, tagService, db := newTestClipboardService(t)
+	rules := NewRuleService(repository.NewRuleRepository(db.DB))
+	service.SetCaptureRules(rules)
+	if _, err := rules.CreateRule(models.CaptureRule{Name: "压缩的 JSON", Enabled: true, SourceApp: "Code", Pattern: `^\{"`,
+		Actions: []models.RuleAction{{Type: models.RuleActionTag, Value: "JSON"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.CreateRule(models.CaptureRule{Name: "小写问候", Enabled: true, SourceApp: "Code", Pattern: `^hello world$`,
+		Actions: []models.RuleAction{{Type: models.RuleActionIgnore}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessContent(`{"a":  1}`, clipboard.Source{App: "Code"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ProcessContent("Hello World", clipboard.Source{App: "Code"}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := service.GetItems(10, 0)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("items = %v, err = %v", items, err)
+	}
+	byContent := map[string]string{}
+	for _, item := range items {
+		byContent[item.Content] = item.ID
+	}
+
+	item, err := service.SaveTransformedItem(byContent[`{"a":  1}`], "json_minify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := tagService.GetTagsForItem(item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "JSON" {
+		t.Errorf("tags = %+v", tags)
+	}
+
+	// 转换结果命中忽略规则时不保存
+	if _, err := service.SaveTransformedItem(byContent["Hello World"], "lower"); err == nil {
+		t.Error("expected ignored transform result to be rejected")
+	}
+	if items, _ := service.GetItems(10, 0); len(items) != 3 {
+		t.Errorf("items after ignored transform = %d", len(items))
+	}
+}